@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"strings"
+
 	"github.com/kujtimiihoxha/kit/generator"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -18,6 +20,20 @@ var clientCmd = &cobra.Command{
 			return
 		}
 
+		breaker := viper.GetString("g_c_breaker")
+		if breaker != "" && breaker != generator.BreakerGobreaker && breaker != generator.BreakerHystrix {
+			logrus.Errorf("Circuit breaker `%s` not supported", breaker)
+			return
+		}
+		language := viper.GetString("g_c_language")
+		if language != generator.ClientLanguageGo && language != generator.ClientLanguageTypeScript {
+			logrus.Errorf("Client language `%s` not supported", language)
+			return
+		}
+		if language == generator.ClientLanguageTypeScript && viper.GetString("g_c_transport") != "http" {
+			logrus.Error("The typescript client calls the http transport, use --transport http")
+			return
+		}
 		pbImportPath := viper.GetString("g_c_pb_import_path")
 		if viper.GetString("g_c_transport") == "grpc" {
 			if pbImportPath == "" {
@@ -29,8 +45,13 @@ var clientCmd = &cobra.Command{
 			args[0],
 			viper.GetString("g_c_transport"),
 			pbImportPath,
+			viper.GetBool("g_c_lb"),
+			breaker,
+			viper.GetFloat64("g_c_ratelimit"),
+			viper.GetBool("g_c_cli"),
+			language,
 		)
-		if err := g.Generate(); err != nil {
+		if err := runGenerators(g); err != nil {
 			logrus.Error(err)
 		}
 	},
@@ -42,6 +63,16 @@ func init() {
 	clientCmd.Flags().StringP("pb_import_path", "i", "", "Specify path to import pb")
 	viper.BindPFlag("g_c_transport", clientCmd.Flags().Lookup("transport"))
 	viper.BindPFlag("g_c_pb_import_path", clientCmd.Flags().Lookup("pb_import_path"))
+	clientCmd.Flags().Bool("lb", false, "Generate a http client that load balances and retries between the instances of a service discovery instancer")
+	viper.BindPFlag("g_c_lb", clientCmd.Flags().Lookup("lb"))
+	clientCmd.Flags().String("breaker", "", "Wrap each method endpoint with a circuit breaker ("+strings.Join(generator.SupportedBreakers, "|")+")")
+	clientCmd.Flags().Float64("ratelimit", 0, "Wrap each method endpoint with a rate limiter allowing this many requests per second")
+	viper.BindPFlag("g_c_breaker", clientCmd.Flags().Lookup("breaker"))
+	viper.BindPFlag("g_c_ratelimit", clientCmd.Flags().Lookup("ratelimit"))
+	clientCmd.Flags().Bool("cli", false, "Generate a command line client with a subcommand for each service method")
+	viper.BindPFlag("g_c_cli", clientCmd.Flags().Lookup("cli"))
+	clientCmd.Flags().StringP("language", "l", generator.ClientLanguageGo, "The language of the client ("+strings.Join(generator.SupportedClientLanguages, "|")+")")
+	viper.BindPFlag("g_c_language", clientCmd.Flags().Lookup("language"))
 	// Here you will define your flags and configuration settings.
 
 	// Cobra supports Persistent Flags which will work for this command
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"github.com/kujtimiihoxha/kit/generator"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// crudCmd represents the crud command
+var crudCmd = &cobra.Command{
+	Use:   "crud",
+	Short: "Generate the create, get, list, update and delete methods of a model",
+	Long: `Generate the create, get, list, update and delete methods of a model and serve them
+as a REST resource with the http transport, e.x:
+kit g crud todo Todo --fields "Title:string,Done:bool"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 2 {
+			logrus.Error("You must provide the name of the service and the name of the model")
+			return
+		}
+		dmw := viper.GetBool("g_crud_dmw")
+		if err := runGenerators(
+			generator.NewGenerateCRUD(args[0], args[1], viper.GetString("g_crud_fields")),
+			generator.NewGenerateService(args[0], "http", "", "", dmw, viper.GetBool("g_crud_gorilla"), dmw, nil),
+		); err != nil {
+			logrus.Error(err)
+		}
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(crudCmd)
+	crudCmd.Flags().String("fields", "", "The fields of the model if it does not exist, e.x \"Title:string,Done:bool\"")
+	crudCmd.Flags().BoolP("dmw", "w", false, "Generate default middleware for service and endpoint")
+	crudCmd.Flags().Bool("gorilla", false, "Generate http using gorilla mux")
+	viper.BindPFlag("g_crud_fields", crudCmd.Flags().Lookup("fields"))
+	viper.BindPFlag("g_crud_dmw", crudCmd.Flags().Lookup("dmw"))
+	viper.BindPFlag("g_crud_gorilla", crudCmd.Flags().Lookup("gorilla"))
+}
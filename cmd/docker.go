@@ -14,7 +14,7 @@ var dockerCmd = &cobra.Command{
 	Short:   "Generate docker files",
 	Run: func(cmd *cobra.Command, args []string) {
 		g := generator.NewGenerateDocker(viper.GetBool("g_d_glide"))
-		if err := g.Generate(); err != nil {
+		if err := runGenerators(g); err != nil {
 			logrus.Error(err)
 		}
 	},
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/kujtimiihoxha/kit/generator"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// eventsCmd represents the events command
+var eventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Generate the outbox of the events of a service",
+	Long: `Generate the outbox table, a transactional Publish helper and the relay that
+publishes the events of the outbox, e.x:
+kit g events todo --publisher kafka`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			logrus.Error("You must provide the name of the service")
+			return
+		}
+		if err := runGenerators(generator.NewGenerateEvents(args[0], viper.GetString("g_events_publisher"))); err != nil {
+			logrus.Error(err)
+		}
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(eventsCmd)
+	eventsCmd.Flags().StringP("publisher", "p", generator.PublisherNATS, "The publisher of the events ("+strings.Join(generator.SupportedPublishers, "|")+")")
+	viper.BindPFlag("g_events_publisher", eventsCmd.Flags().Lookup("publisher"))
+}
@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"strings"
+
 	"github.com/kujtimiihoxha/kit/generator"
 	"github.com/kujtimiihoxha/kit/utils"
 	"github.com/sirupsen/logrus"
@@ -40,6 +42,12 @@ var initserviceCmd = &cobra.Command{
 			}
 		}
 
+		tracing := viper.GetString("g_s_tracing")
+		if tracing != "" && tracing != generator.TracingOpenTracing && tracing != generator.TracingOTel {
+			logrus.Errorf("Tracing `%s` not supported", tracing)
+			return
+		}
+
 		var emw, smw bool
 		if viper.GetBool("g_s_dmw") {
 			emw = true
@@ -58,7 +66,11 @@ var initserviceCmd = &cobra.Command{
 			emw,
 			methods,
 		)
-		if err := g.Generate(); err != nil {
+		gens := []generator.Gen{}
+		if tracing == generator.TracingOTel {
+			gens = append(gens, generator.NewGenerateTracing(args[0]))
+		}
+		if err := runGenerators(append(gens, g)...); err != nil {
 			logrus.Error(err)
 		}
 	},
@@ -74,6 +86,7 @@ func init() {
 	initserviceCmd.Flags().StringArrayVarP(&methods, "methods", "m", []string{}, "Specify methods to be generated")
 	initserviceCmd.Flags().Bool("svc-mdw", false, "If set a default Logging and Instrumental middleware will be created and attached to the service")
 	initserviceCmd.Flags().Bool("endpoint-mdw", false, "If set a default Logging and Tracking middleware will be created and attached to the endpoint")
+	initserviceCmd.Flags().String("tracing", "", "The tracing of the service ("+strings.Join(generator.SupportedTracings, "|")+"), once the otel tracing package is generated the service keeps using it")
 	viper.BindPFlag("g_s_transport", initserviceCmd.Flags().Lookup("transport"))
 	viper.BindPFlag("g_s_pb_path", initserviceCmd.Flags().Lookup("pb_path"))
 	viper.BindPFlag("g_s_pb_import_path", initserviceCmd.Flags().Lookup("pb_import_path"))
@@ -81,4 +94,5 @@ func init() {
 	viper.BindPFlag("g_s_gorilla", initserviceCmd.Flags().Lookup("gorilla"))
 	viper.BindPFlag("g_s_svc_mdw", initserviceCmd.Flags().Lookup("svc-mdw"))
 	viper.BindPFlag("g_s_endpoint_mdw", initserviceCmd.Flags().Lookup("endpoint-mdw"))
+	viper.BindPFlag("g_s_tracing", initserviceCmd.Flags().Lookup("tracing"))
 }
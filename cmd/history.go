@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/kujtimiihoxha/kit/fs"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List the runs that can be undone",
+	Run: func(cmd *cobra.Command, args []string) {
+		runs, err := fs.Get().History()
+		if err != nil {
+			logrus.Error(err)
+			return
+		}
+		if len(runs) == 0 {
+			logrus.Info("There are no runs in the history")
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tTIME\tFILES\tCOMMAND")
+		for _, r := range runs {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", r.ID, r.Time.Format("2006-01-02 15:04:05"), len(r.ProjectFiles()), r.Command)
+		}
+		w.Flush()
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(historyCmd)
+}
@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"strings"
+
 	"github.com/kujtimiihoxha/kit/generator"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -22,12 +24,30 @@ var middlewareCmd = &cobra.Command{
 			logrus.Error("You must provide the name of the service")
 			return
 		}
+		if kind := viper.GetString("g_m_kind"); kind != "" {
+			if args[0] != "auth" {
+				logrus.Error("The kind is only supported by the auth middleware, use `kit g m auth`")
+				return
+			}
+			if kind != generator.AuthJWT && kind != generator.AuthAPIKey {
+				logrus.Errorf("Auth kind `%s` not supported", kind)
+				return
+			}
+			if err := runGenerators(generator.NewGenerateAuthMiddleware(sn, kind)); err != nil {
+				logrus.Error(err)
+				return
+			}
+			logrus.Info("Do not forget to add the auth middleware to your endpoint middlewares")
+			logrus.Info("Add it to cmd/service/service.go#getEndpointMiddleware() with auth.AddMiddleware(mw, auth.Middleware(...))")
+			logrus.Info("Regenerate the service and the clients to add the token extractors and injectors to the transports")
+			return
+		}
 		g := generator.NewGenerateMiddleware(
 			args[0],
 			sn,
 			viper.GetBool("g_m_endpoint"),
 		)
-		if err := g.Generate(); err != nil {
+		if err := runGenerators(g); err != nil {
 			logrus.Error(err)
 		}
 		if viper.GetBool("g_m_endpoint") {
@@ -50,4 +70,7 @@ func init() {
 	middlewareCmd.Flags().BoolP("endpoint", "e", false,
 		"If set create endpoint middleware")
 	viper.BindPFlag("g_m_endpoint", middlewareCmd.Flags().Lookup("endpoint"))
+	middlewareCmd.Flags().String("kind", "",
+		"Generate the auth middleware of this kind ("+strings.Join(generator.SupportedAuthKinds, "|")+")")
+	viper.BindPFlag("g_m_kind", middlewareCmd.Flags().Lookup("kind"))
 }
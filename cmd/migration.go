@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"github.com/kujtimiihoxha/kit/generator"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// migrationCmd represents the migration command
+var migrationCmd = &cobra.Command{
+	Use:   "migration",
+	Short: "Generate a versioned SQL migration",
+	Long: `Generate the up and down SQL files of a migration, e.x:
+kit g migration todo create_todos`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 2 {
+			logrus.Error("You must provide the name of the service and the name of the migration")
+			return
+		}
+		if err := runGenerators(generator.NewGenerateMigration(args[0], args[1])); err != nil {
+			logrus.Error(err)
+		}
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(migrationCmd)
+}
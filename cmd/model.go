@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"github.com/kujtimiihoxha/kit/generator"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// modelCmd represents the model command
+var modelCmd = &cobra.Command{
+	Use:   "model",
+	Short: "Generate a model and its repository",
+	Long: `Generate a model and its repository, e.x:
+kit g model todo Todo --fields "Title:string,Done:bool"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 2 {
+			logrus.Error("You must provide the name of the service and the name of the model")
+			return
+		}
+		if err := runGenerators(generator.NewGenerateModel(args[0], args[1], viper.GetString("g_model_fields"))); err != nil {
+			logrus.Error(err)
+		}
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(modelCmd)
+	modelCmd.Flags().String("fields", "", "The fields of the model, e.x \"Title:string,Done:bool\"")
+	viper.BindPFlag("g_model_fields", modelCmd.Flags().Lookup("fields"))
+}
@@ -4,7 +4,10 @@ import (
 	"os"
 	"os/exec"
 	"runtime"
+	"strings"
 
+	"github.com/kujtimiihoxha/kit/fs"
+	"github.com/kujtimiihoxha/kit/generator"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -35,6 +38,74 @@ func init() {
 	viper.BindPFlag("gk_folder", RootCmd.PersistentFlags().Lookup("folder"))
 	viper.BindPFlag("gk_force", RootCmd.PersistentFlags().Lookup("force"))
 	viper.BindPFlag("gk_debug", RootCmd.PersistentFlags().Lookup("debug"))
+
+	RootCmd.PersistentFlags().String(
+		"on-conflict",
+		"",
+		"What to do when a file already exists ("+strings.Join(fs.ConflictPolicies, "|")+"), "+
+			"defaults to the project config or ask, skip if not running in a terminal",
+	)
+	viper.BindPFlag("gk_on_conflict", RootCmd.PersistentFlags().Lookup("on-conflict"))
+	RootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if p := viper.GetString("gk_on_conflict"); p != "" {
+			return fs.CheckConflictPolicy(p)
+		}
+		return nil
+	}
+}
+
+// runGenerators runs the generators in order inside a file system transaction,
+// if one of them fails the files written by the command are restored.
+func runGenerators(gens ...generator.Gen) error {
+	kfs := fs.Get()
+	kfs.Begin()
+	rollback := func() {
+		logrus.Warn("Generation failed, reverting the changes made by this command")
+		if err := kfs.Rollback(); err != nil {
+			logrus.Error(err)
+		}
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			rollback()
+			panic(r)
+		}
+	}()
+	for _, g := range gens {
+		if err := g.Generate(); err != nil {
+			rollback()
+			return err
+		}
+	}
+	if err := kfs.Commit(); err != nil {
+		return err
+	}
+	logReport(kfs.Report())
+	return nil
+}
+
+// logReport prints the summary of the existing files touched by the command.
+func logReport(r fs.Report) {
+	if r.Empty() {
+		return
+	}
+	logrus.Info("Summary of the existing files:")
+	for _, v := range []struct {
+		name  string
+		files []string
+	}{
+		{"Overwritten", r.Overwritten},
+		{"Merged", r.Merged},
+		{"Merged with conflicts", r.Conflicted},
+		{"Skipped", r.Skipped},
+	} {
+		for _, f := range v.files {
+			logrus.Infof("  %s: %s", v.name, f)
+		}
+	}
+	if len(r.Conflicted) > 0 {
+		logrus.Warn("Resolve the conflict markers before building the project")
+	}
 }
 
 func checkProtoc() bool {
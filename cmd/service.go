@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"strings"
+
 	"github.com/kujtimiihoxha/kit/generator"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -16,28 +18,22 @@ var serviceCmd = &cobra.Command{
 			logrus.Error("You must provide a name for the service")
 			return
 		}
-		g := generator.NewNewService(args[0])
-		if err := g.Generate(); err != nil {
-			logrus.Error(err)
-		}
-
-		m := generator.NewNewModel(args[0])
-		if err:= m.Generate(); err != nil {
-			logrus.Error(err)
-		}
-
-		c := generator.NewNewConfig(args[0])
-		if err:= c.Generate(); err != nil {
-			logrus.Error(err)
+		db := viper.GetString("n_s_db")
+		supported := false
+		for _, v := range generator.SupportedDatabases {
+			supported = supported || v == db
 		}
-
-		u := generator.NewNewUtils(args[0])
-		if err:= u.Generate(); err != nil {
-			logrus.Error(err)
+		if !supported {
+			logrus.Errorf("Database `%s` not supported", db)
+			return
 		}
-
-		db := generator.NewNewPostgreDatabase(args[0])
-		if err := db.Generate(); err != nil {
+		if err := runGenerators(
+			generator.NewNewService(args[0]),
+			generator.NewNewModel(args[0], db),
+			generator.NewNewConfig(args[0], db),
+			generator.NewNewUtils(args[0], db),
+			generator.NewNewDatabase(args[0], db),
+		); err != nil {
 			logrus.Error(err)
 		}
 	},
@@ -46,5 +42,7 @@ var serviceCmd = &cobra.Command{
 func init() {
 	newCmd.AddCommand(serviceCmd)
 	serviceCmd.Flags().StringP("module", "m", "", "The module name that you plan to set in the project")
+	serviceCmd.Flags().String("db", generator.DBPostgres, "The database of the service ("+strings.Join(generator.SupportedDatabases, "|")+"), none generates a service without a database")
 	viper.BindPFlag("n_s_module", serviceCmd.Flags().Lookup("module"))
+	viper.BindPFlag("n_s_db", serviceCmd.Flags().Lookup("db"))
 }
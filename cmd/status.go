@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"github.com/kujtimiihoxha/kit/generator"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// statusCmd represents the status command
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Manage the status catalog of a service",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+// statusAddCmd represents the status add command
+var statusAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add a status to the status catalog and regenerate the typed statuses",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			logrus.Error("You must provide a key for the status")
+			return
+		}
+		sn := viper.GetString("g_st_service")
+		if sn == "" {
+			logrus.Error("You must provide the name of the service")
+			return
+		}
+		g := generator.NewGenerateStatusEntry(
+			sn,
+			args[0],
+			viper.GetInt("g_st_code"),
+			viper.GetInt("g_st_http_status"),
+			viper.GetString("g_st_message"),
+		)
+		if err := runGenerators(g); err != nil {
+			logrus.Error(err)
+			return
+		}
+		logrus.Infof("The status is available as utils.Lookup(%q)", args[0])
+		logrus.Infof("Return service.NewStatusError(%q, kind, message) to encode an error with the status", args[0])
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(statusCmd)
+	statusCmd.AddCommand(statusAddCmd)
+	statusAddCmd.Flags().StringP("service", "s", "", "Service name that the status will be added to")
+	viper.BindPFlag("g_st_service", statusAddCmd.Flags().Lookup("service"))
+	statusAddCmd.Flags().Int("code", 0, "The code of the status, the next free code of the catalog if it is not set")
+	viper.BindPFlag("g_st_code", statusAddCmd.Flags().Lookup("code"))
+	statusAddCmd.Flags().Int("http-status", 0, "The http status the status is responded with")
+	viper.BindPFlag("g_st_http_status", statusAddCmd.Flags().Lookup("http-status"))
+	statusAddCmd.Flags().String("message", "", "The message of the status")
+	viper.BindPFlag("g_st_message", statusAddCmd.Flags().Lookup("message"))
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"github.com/kujtimiihoxha/kit/fs"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// undoCmd represents the undo command
+var undoCmd = &cobra.Command{
+	Use:   "undo [run-id]",
+	Short: "Restore the files changed by a run, the last run if no id is given",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id := ""
+		if len(args) > 0 {
+			id = args[0]
+		}
+		r, err := fs.Get().Undo(id)
+		if err != nil {
+			logrus.Error(err)
+			return
+		}
+		logrus.Infof("Reverted `%s`", r.Command)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(undoCmd)
+}
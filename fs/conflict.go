@@ -0,0 +1,146 @@
+package fs
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+)
+
+// The policies that decide what happens when kit wants to write a file that
+// already exists with a different content.
+const (
+	ConflictAsk       = "ask"
+	ConflictSkip      = "skip"
+	ConflictOverwrite = "overwrite"
+	ConflictMerge     = "merge"
+	ConflictFail      = "fail"
+)
+
+// ConflictPolicies are all the supported conflict policies.
+var ConflictPolicies = []string{ConflictAsk, ConflictSkip, ConflictOverwrite, ConflictMerge, ConflictFail}
+
+// ProjectConfigFile is the project configuration file, it is read from the
+// root of the project.
+var ProjectConfigFile = path.Join(".kit", "config.yml")
+
+// ProjectConfig is the project configuration, e.x:
+//
+//	on_conflict: skip
+//	conflicts:
+//	  - path: "*_gen.go"
+//	    policy: overwrite
+//	  - path: "hello/pkg/service/service.go"
+//	    policy: merge
+//
+// Patterns without a `/` are matched against the file name, the others against
+// the whole path, the first matching pattern wins.
+type ProjectConfig struct {
+	OnConflict string         `yaml:"on_conflict"`
+	Conflicts  []ConflictRule `yaml:"conflicts"`
+}
+
+// ConflictRule sets the conflict policy of the files matching Path.
+type ConflictRule struct {
+	Path   string `yaml:"path"`
+	Policy string `yaml:"policy"`
+}
+
+// Report lists the existing files that were skipped, overwritten or merged
+// while the transaction was running.
+type Report struct {
+	Skipped     []string
+	Overwritten []string
+	Merged      []string
+	Conflicted  []string
+}
+
+// Empty returns true if no existing file was touched.
+func (r Report) Empty() bool {
+	return len(r.Skipped)+len(r.Overwritten)+len(r.Merged)+len(r.Conflicted) == 0
+}
+
+// CheckConflictPolicy returns an error if `policy` is not a supported policy.
+func CheckConflictPolicy(policy string) error {
+	for _, v := range ConflictPolicies {
+		if v == policy {
+			return nil
+		}
+	}
+	return fmt.Errorf(
+		"conflict policy `%s` not supported, use one of %s",
+		policy,
+		strings.Join(ConflictPolicies, "|"),
+	)
+}
+
+// conflictPolicy returns the policy for the existing file in `pth`, `--force`
+// means overwrite, then comes the `--on-conflict` flag, the project config and
+// finally ask if we are running in a terminal or skip if we are not.
+func (f *KitFs) conflictPolicy(pth string) (string, error) {
+	if viper.GetBool("gk_force") || viper.GetBool("gk_force_override") {
+		return ConflictOverwrite, nil
+	}
+	policy := viper.GetString("gk_on_conflict")
+	if policy == "" {
+		cfg, err := f.projectConfig()
+		if err != nil {
+			return "", err
+		}
+		policy = cfg.OnConflict
+		for _, r := range cfg.Conflicts {
+			if matchPath(r.Path, pth) {
+				policy = r.Policy
+				break
+			}
+		}
+	}
+	if policy == "" {
+		policy = ConflictAsk
+	}
+	if err := CheckConflictPolicy(policy); err != nil {
+		return "", err
+	}
+	if policy == ConflictAsk && !isTerminal() {
+		return ConflictSkip, nil
+	}
+	return policy, nil
+}
+
+func (f *KitFs) projectConfig() (ProjectConfig, error) {
+	if f.config != nil {
+		return *f.config, nil
+	}
+	cfg := ProjectConfig{}
+	if b, err := afero.Exists(f.Fs, ProjectConfigFile); err != nil {
+		return cfg, err
+	} else if b {
+		data, err := afero.ReadFile(f.Fs, ProjectConfigFile)
+		if err != nil {
+			return cfg, err
+		}
+		if err = yaml.Unmarshal(data, &cfg); err != nil {
+			return cfg, fmt.Errorf("could not read `%s`: %s", ProjectConfigFile, err)
+		}
+	}
+	f.config = &cfg
+	return cfg, nil
+}
+
+func matchPath(pattern, pth string) bool {
+	pth = path.Clean(pth)
+	if !strings.Contains(pattern, "/") {
+		pth = path.Base(pth)
+	}
+	b, _ := path.Match(pattern, pth)
+	return b
+}
+
+func isTerminal() bool {
+	return isatty.IsTerminal(os.Stdin.Fd()) || isatty.IsCygwinTerminal(os.Stdin.Fd())
+}
@@ -14,7 +14,10 @@ var defaultFs *KitFs
 
 // KitFs wraps an afero.Fs
 type KitFs struct {
-	Fs afero.Fs
+	Fs      afero.Fs
+	journal *journal
+	report  Report
+	config  *ProjectConfig
 }
 
 func (f *KitFs) init(dir string) {
@@ -43,32 +46,155 @@ func (f *KitFs) ReadFile(path string) (string, error) {
 }
 
 // WriteFile writs a file to the `path` with `data` as content, if `force` is set
-// to true it will override the file if it already exists.
+// to true it will override the file if it already exists, otherwise the conflict
+// policy of the path decides if the file is skipped, overwritten or merged.
+// Files changed by the user since kit last generated them are three-way merged
+// unless the policy is overwrite.
 func (f *KitFs) WriteFile(path string, data string, force bool) error {
-	if b, _ := f.Exists(path); b && !(viper.GetBool("gk_force_override") || force) {
+	generated := data
+	if b, _ := f.Exists(path); b {
 		s, _ := f.ReadFile(path)
-		if s == data {
-			logrus.Warnf("`%s` exists and is identical it will be ignored", path)
-			return nil
+		base, hasBase, err := f.generatedBase(path)
+		if err != nil {
+			return err
 		}
-		b := prompter.YN(fmt.Sprintf("`%s` already exists do you want to override it ?", path), false)
-		if !b {
-			return nil
+		switch {
+		case s == data:
+			if !force {
+				logrus.Warnf("`%s` exists and is identical it will be ignored", path)
+			}
+			return f.saveGenerated(path, generated)
+		case hasBase && s == base:
+			// The file was not changed since kit generated it.
+		case force && hasBase:
+			policy, err := f.conflictPolicy(path)
+			if err != nil {
+				return err
+			}
+			if policy == ConflictOverwrite {
+				f.report.Overwritten = append(f.report.Overwritten, path)
+				break
+			}
+			if containsLines(data, s) {
+				// The generator built the file from its content on disk, the changes
+				// of the user are already there.
+				f.report.Merged = append(f.report.Merged, path)
+				break
+			}
+			data = f.merge(path, base, s, data, true)
+		case force:
+		default:
+			policy, err := f.conflictPolicy(path)
+			if err != nil {
+				return err
+			}
+			switch policy {
+			case ConflictFail:
+				return fmt.Errorf("`%s` already exists", path)
+			case ConflictAsk:
+				if !prompter.YN(fmt.Sprintf("`%s` already exists do you want to override it ?", path), false) {
+					f.report.Skipped = append(f.report.Skipped, path)
+					return nil
+				}
+				f.report.Overwritten = append(f.report.Overwritten, path)
+			case ConflictSkip:
+				logrus.Warnf("`%s` already exists it will be skipped", path)
+				f.report.Skipped = append(f.report.Skipped, path)
+				return nil
+			case ConflictMerge:
+				data = f.merge(path, base, s, data, hasBase)
+			default:
+				f.report.Overwritten = append(f.report.Overwritten, path)
+			}
 		}
 	}
-	return afero.WriteFile(f.Fs, path, []byte(data), os.ModePerm)
+	if f.journal != nil {
+		if err := f.journal.recordFile(f.Fs, path); err != nil {
+			return err
+		}
+	}
+	if err := afero.WriteFile(f.Fs, path, []byte(data), os.ModePerm); err != nil {
+		return err
+	}
+	return f.saveGenerated(path, generated)
+}
+
+// merge merges the file content on disk with the generated one, it uses the
+// last generated version as the base if there is one.
+func (f *KitFs) merge(path, base, current, generated string, hasBase bool) string {
+	var data string
+	var conflicts int
+	if hasBase {
+		data, conflicts = mergeThreeWay(base, current, generated)
+	} else {
+		data, conflicts = mergeTwoWay(current, generated)
+	}
+	if conflicts > 0 {
+		logrus.Warnf("`%s` was merged with %d conflicts", path, conflicts)
+		f.report.Conflicted = append(f.report.Conflicted, path)
+	} else {
+		f.report.Merged = append(f.report.Merged, path)
+	}
+	return data
 }
 
 // Mkdir creates a directory.
 func (f *KitFs) Mkdir(dir string) error {
+	if f.journal != nil {
+		if err := f.journal.recordFolder(f.Fs, dir); err != nil {
+			return err
+		}
+	}
 	return f.Fs.Mkdir(dir, os.ModePerm)
 }
 
 // MkdirAll creates a directory and its parents if they don't exist.
 func (f *KitFs) MkdirAll(path string) error {
+	if f.journal != nil {
+		if err := f.journal.recordFolder(f.Fs, path); err != nil {
+			return err
+		}
+	}
 	return f.Fs.MkdirAll(path, os.ModePerm)
 }
 
+// Begin starts a transaction, from now on every written file and created folder
+// is journaled until Commit or Rollback is called. Calling Begin while a
+// transaction is running has no effect.
+func (f *KitFs) Begin() {
+	if f.journal == nil {
+		f.journal = newJournal()
+		f.report = Report{}
+	}
+}
+
+// Report returns what happened to the existing files during the last transaction.
+func (f *KitFs) Report() Report {
+	return f.report
+}
+
+// Commit ends the running transaction keeping all the changes, the replaced
+// files are saved in the history so the run can be undone later.
+func (f *KitFs) Commit() error {
+	if f.journal == nil {
+		return nil
+	}
+	j := f.journal
+	f.journal = nil
+	return f.saveHistory(j)
+}
+
+// Rollback ends the running transaction restoring the files that were
+// overwritten and removing the files and folders that were created.
+func (f *KitFs) Rollback() error {
+	if f.journal == nil {
+		return nil
+	}
+	j := f.journal
+	f.journal = nil
+	return j.rollback(f.Fs)
+}
+
 // Exists returns true,nil if the dir/file exists or false,nil if
 // the dir/file does not exist, it will return an error if something
 // went wrong.
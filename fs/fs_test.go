@@ -0,0 +1,201 @@
+package fs
+
+import (
+	"os"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+)
+
+func newTestFs() *KitFs {
+	viper.Set("gk_testing", true)
+	f := NewDefaultFs("")
+	f.MkdirAll("test/pkg")
+	f.WriteFile("test/pkg/service.go", "package service", true)
+	return f
+}
+
+func TestKitFs_Rollback(t *testing.T) {
+	Convey("Test if rollback reverts the changes of the transaction", t, func() {
+		f := newTestFs()
+		f.Begin()
+		So(f.WriteFile("test/pkg/service.go", "package changed", true), ShouldBeNil)
+		So(f.WriteFile("test/pkg/new.go", "package service", true), ShouldBeNil)
+		So(f.MkdirAll("test/pkg/http/client"), ShouldBeNil)
+		So(f.WriteFile("test/pkg/http/client/http.go", "package client", true), ShouldBeNil)
+		So(f.Rollback(), ShouldBeNil)
+		Convey("Test if overwritten files are restored", func() {
+			s, err := f.ReadFile("test/pkg/service.go")
+			So(err, ShouldBeNil)
+			So(s, ShouldEqual, "package service")
+		})
+		Convey("Test if created files and folders are removed", func() {
+			b, _ := f.Exists("test/pkg/new.go")
+			So(b, ShouldBeFalse)
+			b, _ = f.Exists("test/pkg/http")
+			So(b, ShouldBeFalse)
+			b, _ = f.Exists("test/pkg")
+			So(b, ShouldBeTrue)
+		})
+	})
+}
+
+func TestKitFs_Commit(t *testing.T) {
+	Convey("Test if commit keeps the changes of the transaction", t, func() {
+		f := newTestFs()
+		f.Begin()
+		So(f.WriteFile("test/pkg/service.go", "package changed", true), ShouldBeNil)
+		So(f.Commit(), ShouldBeNil)
+		So(f.Rollback(), ShouldBeNil)
+		s, err := f.ReadFile("test/pkg/service.go")
+		So(err, ShouldBeNil)
+		So(s, ShouldEqual, "package changed")
+	})
+}
+
+func TestKitFs_Undo(t *testing.T) {
+	Convey("Test if a committed run can be undone", t, func() {
+		viper.Set("gk_history_limit", 2)
+		defer viper.Set("gk_history_limit", 0)
+		f := newTestFs()
+		f.Begin()
+		So(f.WriteFile("test/pkg/service.go", "package changed", true), ShouldBeNil)
+		So(f.WriteFile("test/pkg/new.go", "package service", true), ShouldBeNil)
+		So(f.MkdirAll("test/pkg/auth"), ShouldBeNil)
+		So(f.WriteFile("test/pkg/auth/auth.go", "package auth", true), ShouldBeNil)
+		So(f.Commit(), ShouldBeNil)
+		runs, err := f.History()
+		So(err, ShouldBeNil)
+		So(len(runs), ShouldEqual, 1)
+		So(len(runs[0].ProjectFiles()), ShouldEqual, 3)
+		r, err := f.Undo("")
+		So(err, ShouldBeNil)
+		So(r.ID, ShouldEqual, runs[0].ID)
+		s, _ := f.ReadFile("test/pkg/service.go")
+		So(s, ShouldEqual, "package service")
+		b, _ := f.Exists("test/pkg/new.go")
+		So(b, ShouldBeFalse)
+		b, _ = f.Exists("test/pkg/auth")
+		So(b, ShouldBeFalse)
+		runs, _ = f.History()
+		So(len(runs), ShouldEqual, 0)
+		_, err = f.Undo("")
+		So(err, ShouldNotBeNil)
+	})
+	Convey("Test if old runs are pruned", t, func() {
+		viper.Set("gk_history_limit", 2)
+		defer viper.Set("gk_history_limit", 0)
+		f := newTestFs()
+		for _, v := range []string{"a", "b", "c"} {
+			f.Begin()
+			So(f.WriteFile("test/pkg/service.go", "package "+v, true), ShouldBeNil)
+			So(f.Commit(), ShouldBeNil)
+		}
+		runs, err := f.History()
+		So(err, ShouldBeNil)
+		So(len(runs), ShouldEqual, 2)
+	})
+}
+
+func TestKitFs_WriteFileConflictPolicy(t *testing.T) {
+	Convey("Test if the conflict policy is honoured for existing files", t, func() {
+		defer viper.Set("gk_on_conflict", "")
+		f := newTestFs()
+		// The user changes the file after kit generated it.
+		afero.WriteFile(f.Fs, "test/pkg/service.go", []byte("package service"), os.ModePerm)
+		f.WriteFile(".kit/generated/test/pkg/service.go", "package generated", true)
+		f.Begin()
+		defer f.Commit()
+		Convey("Test if skip keeps the existing file", func() {
+			viper.Set("gk_on_conflict", ConflictSkip)
+			So(f.WriteFile("test/pkg/service.go", "package changed", false), ShouldBeNil)
+			s, _ := f.ReadFile("test/pkg/service.go")
+			So(s, ShouldEqual, "package service")
+			So(f.Report().Skipped, ShouldResemble, []string{"test/pkg/service.go"})
+		})
+		Convey("Test if overwrite replaces the existing file", func() {
+			viper.Set("gk_on_conflict", ConflictOverwrite)
+			So(f.WriteFile("test/pkg/service.go", "package changed", false), ShouldBeNil)
+			s, _ := f.ReadFile("test/pkg/service.go")
+			So(s, ShouldEqual, "package changed")
+			So(f.Report().Overwritten, ShouldResemble, []string{"test/pkg/service.go"})
+		})
+		Convey("Test if fail returns an error", func() {
+			viper.Set("gk_on_conflict", ConflictFail)
+			So(f.WriteFile("test/pkg/service.go", "package changed", false), ShouldNotBeNil)
+		})
+		Convey("Test if the project config is used per path", func() {
+			f.WriteFile(ProjectConfigFile, "on_conflict: fail\nconflicts:\n  - path: \"*.go\"\n    policy: overwrite\n", true)
+			f.config = nil
+			So(f.WriteFile("test/pkg/service.go", "package changed", false), ShouldBeNil)
+			So(f.WriteFile(ProjectConfigFile, "on_conflict: skip", false), ShouldNotBeNil)
+		})
+		Convey("Test if unknown policies are rejected", func() {
+			viper.Set("gk_on_conflict", "maybe")
+			So(f.WriteFile("test/pkg/service.go", "package changed", false), ShouldNotBeNil)
+		})
+	})
+}
+
+func TestMergeTwoWay(t *testing.T) {
+	Convey("Test if lines added on one side are kept", t, func() {
+		s, c := mergeTwoWay("a\nb\nuser\nc", "a\nkit\nb\nc")
+		So(c, ShouldEqual, 0)
+		So(s, ShouldEqual, "a\nkit\nb\nuser\nc")
+	})
+	Convey("Test if lines changed on both sides are marked as conflicts", t, func() {
+		s, c := mergeTwoWay("a\nuser\nc", "a\nkit\nc")
+		So(c, ShouldEqual, 1)
+		So(s, ShouldEqual, "a\n<<<<<<< current\nuser\n=======\nkit\n>>>>>>> generated\nc")
+	})
+}
+
+func TestKitFs_WriteFileThreeWayMerge(t *testing.T) {
+	Convey("Test if regenerated files keep the changes of the user", t, func() {
+		f := newTestFs()
+		So(f.WriteFile("test/pkg/handler.go", "package http\n\nfunc a() {}\n", true), ShouldBeNil)
+		afero.WriteFile(f.Fs, "test/pkg/handler.go", []byte("package http\n\n// a is mine\nfunc a() {}\n"), os.ModePerm)
+		So(f.WriteFile("test/pkg/handler.go", "package http\n\nfunc a() {}\n\nfunc b() {}\n", true), ShouldBeNil)
+		s, _ := f.ReadFile("test/pkg/handler.go")
+		So(s, ShouldEqual, "package http\n\n// a is mine\nfunc a() {}\n\nfunc b() {}\n")
+		Convey("Test if the generated version is kept as the next base", func() {
+			base, b, err := f.generatedBase("test/pkg/handler.go")
+			So(err, ShouldBeNil)
+			So(b, ShouldBeTrue)
+			So(base, ShouldEqual, "package http\n\nfunc a() {}\n\nfunc b() {}\n")
+		})
+	})
+}
+
+func TestKitFs_WriteFileFromCurrent(t *testing.T) {
+	Convey("Test if files generated from the content on disk are not merged", t, func() {
+		f := newTestFs()
+		So(f.WriteFile("test/pkg/svc.go", "package service\n\ntype S interface {\n}\n", true), ShouldBeNil)
+		afero.WriteFile(f.Fs, "test/pkg/svc.go", []byte("package service\n\ntype S interface {\n\tFoo()\n}\n\ntype Todo struct{Title string}\n"), os.ModePerm)
+		generated := "package service\n\nimport \"context\"\n\ntype S interface {\n\tFoo()\n}\n\ntype Todo struct{ Title string }\n\ntype basicS struct{}\n"
+		So(f.WriteFile("test/pkg/svc.go", generated, true), ShouldBeNil)
+		s, _ := f.ReadFile("test/pkg/svc.go")
+		So(s, ShouldEqual, generated)
+		So(f.Report().Conflicted, ShouldBeEmpty)
+	})
+}
+
+func TestMergeThreeWay(t *testing.T) {
+	Convey("Test if changes of different regions are merged", t, func() {
+		s, c := mergeThreeWay("a\nb\nc\nd", "a\nuser\nc\nd", "a\nb\nc\nkit")
+		So(c, ShouldEqual, 0)
+		So(s, ShouldEqual, "a\nuser\nc\nkit")
+	})
+	Convey("Test if the same change on both sides is not a conflict", t, func() {
+		s, c := mergeThreeWay("a\nb", "a\nc", "a\nc")
+		So(c, ShouldEqual, 0)
+		So(s, ShouldEqual, "a\nc")
+	})
+	Convey("Test if different changes of the same region are conflicts", t, func() {
+		s, c := mergeThreeWay("a\nb\nc", "a\nuser\nc", "a\nkit\nc")
+		So(c, ShouldEqual, 1)
+		So(s, ShouldEqual, "a\n<<<<<<< current\nuser\n=======\nkit\n>>>>>>> generated\nc")
+	})
+}
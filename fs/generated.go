@@ -0,0 +1,49 @@
+package fs
+
+import (
+	"os"
+	"path"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// GeneratedFolder keeps the last version kit generated of each file, it is the
+// base of the three-way merge when the file is regenerated.
+var GeneratedFolder = path.Join(".kit", "generated")
+
+// generatedBase returns the last generated version of the file in `pth`.
+func (f *KitFs) generatedBase(pth string) (string, bool, error) {
+	basePath := path.Join(GeneratedFolder, path.Clean(pth))
+	if b, err := afero.Exists(f.Fs, basePath); err != nil || !b {
+		return "", false, err
+	}
+	data, err := afero.ReadFile(f.Fs, basePath)
+	return string(data), err == nil, err
+}
+
+// saveGenerated stores `data` as the last generated version of the file in `pth`,
+// the write is journaled so it is reverted together with the file.
+func (f *KitFs) saveGenerated(pth string, data string) error {
+	pth = path.Clean(pth)
+	if strings.HasPrefix(pth, ".kit/") || path.IsAbs(pth) || strings.HasPrefix(pth, "../") {
+		return nil
+	}
+	basePath := path.Join(GeneratedFolder, pth)
+	// The generated folder itself is never reverted, it holds the bases of other runs.
+	if err := f.Fs.MkdirAll(GeneratedFolder, os.ModePerm); err != nil {
+		return err
+	}
+	if f.journal != nil {
+		if err := f.journal.recordFolder(f.Fs, path.Dir(basePath)); err != nil {
+			return err
+		}
+		if err := f.journal.recordFile(f.Fs, basePath); err != nil {
+			return err
+		}
+	}
+	if err := f.Fs.MkdirAll(path.Dir(basePath), os.ModePerm); err != nil {
+		return err
+	}
+	return afero.WriteFile(f.Fs, basePath, []byte(data), os.ModePerm)
+}
@@ -0,0 +1,182 @@
+package fs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+)
+
+// HistoryFolder is the folder where the snapshots of each run are kept.
+var HistoryFolder = path.Join(".kit", "history")
+
+const (
+	manifestFileName = "manifest.json"
+	snapshotFolder   = "files"
+)
+
+// Run describes a command that changed the project, the files it replaced are
+// kept in the run snapshot so they can be restored by Undo.
+type Run struct {
+	ID      string    `json:"id"`
+	Command string    `json:"command"`
+	Time    time.Time `json:"time"`
+	Files   []RunFile `json:"files"`
+	Folders []string  `json:"folders"`
+}
+
+// RunFile is a file written during a run, Existed is false for files the
+// run created.
+type RunFile struct {
+	Path    string `json:"path"`
+	Existed bool   `json:"existed"`
+}
+
+// ProjectFiles returns the files of the project written during the run, the
+// files kit keeps for itself under `.kit` are left out.
+func (r Run) ProjectFiles() []RunFile {
+	files := []RunFile{}
+	for _, v := range r.Files {
+		if !strings.HasPrefix(v.Path, ".kit/") {
+			files = append(files, v)
+		}
+	}
+	return files
+}
+
+// saveHistory writes the snapshot of the journal to the history folder and
+// prunes the old snapshots, nothing is saved if `gk_history_limit` is not positive.
+func (f *KitFs) saveHistory(j *journal) error {
+	limit := viper.GetInt("gk_history_limit")
+	if limit <= 0 || (len(j.files) == 0 && len(j.folders) == 0) {
+		return nil
+	}
+	now := time.Now()
+	r := Run{
+		ID:      now.Format("20060102150405.000000"),
+		Command: strings.Join(append([]string{"kit"}, os.Args[1:]...), " "),
+		Time:    now,
+		Folders: j.folders,
+	}
+	runPath := path.Join(HistoryFolder, r.ID)
+	for _, v := range j.files {
+		r.Files = append(r.Files, RunFile{Path: v.path, Existed: v.existed})
+		if !v.existed {
+			continue
+		}
+		snapshotPath := path.Join(runPath, snapshotFolder, v.path)
+		if err := f.Fs.MkdirAll(path.Dir(snapshotPath), os.ModePerm); err != nil {
+			return err
+		}
+		if err := afero.WriteFile(f.Fs, snapshotPath, v.data, os.ModePerm); err != nil {
+			return err
+		}
+	}
+	if err := f.Fs.MkdirAll(runPath, os.ModePerm); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = afero.WriteFile(f.Fs, path.Join(runPath, manifestFileName), data, os.ModePerm); err != nil {
+		return err
+	}
+	return f.pruneHistory(limit)
+}
+
+// pruneHistory removes the oldest snapshots so only `limit` runs are kept.
+func (f *KitFs) pruneHistory(limit int) error {
+	runs, err := f.History()
+	if err != nil {
+		return err
+	}
+	for i := limit; i < len(runs); i++ {
+		if err := f.Fs.RemoveAll(path.Join(HistoryFolder, runs[i].ID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// History returns the recorded runs, the newest run comes first.
+func (f *KitFs) History() ([]Run, error) {
+	runs := []Run{}
+	if b, err := afero.DirExists(f.Fs, HistoryFolder); err != nil || !b {
+		return runs, err
+	}
+	infos, err := afero.ReadDir(f.Fs, HistoryFolder)
+	if err != nil {
+		return runs, err
+	}
+	for _, v := range infos {
+		if !v.IsDir() {
+			continue
+		}
+		r, err := f.readRun(v.Name())
+		if err != nil {
+			return runs, err
+		}
+		runs = append(runs, r)
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].ID > runs[j].ID
+	})
+	return runs, nil
+}
+
+func (f *KitFs) readRun(id string) (r Run, err error) {
+	data, err := afero.ReadFile(f.Fs, path.Join(HistoryFolder, id, manifestFileName))
+	if err != nil {
+		return r, err
+	}
+	err = json.Unmarshal(data, &r)
+	return r, err
+}
+
+// Undo reverts the run with the given id, or the last run if the id is empty,
+// and removes its snapshot from the history.
+func (f *KitFs) Undo(id string) (r Run, err error) {
+	if id == "" {
+		runs, err := f.History()
+		if err != nil {
+			return r, err
+		}
+		if len(runs) == 0 {
+			return r, errors.New("there is nothing to undo")
+		}
+		id = runs[0].ID
+	}
+	if b, err := afero.DirExists(f.Fs, path.Join(HistoryFolder, id)); err != nil {
+		return r, err
+	} else if !b {
+		return r, fmt.Errorf("run `%s` was not found in the history", id)
+	}
+	r, err = f.readRun(id)
+	if err != nil {
+		return r, err
+	}
+	j := newJournal()
+	j.folders = r.Folders
+	for _, v := range r.Files {
+		e := journalEntry{path: v.Path, existed: v.Existed}
+		if v.Existed {
+			e.data, err = afero.ReadFile(f.Fs, path.Join(HistoryFolder, id, snapshotFolder, v.Path))
+			if err != nil {
+				return r, err
+			}
+		}
+		j.files = append(j.files, e)
+	}
+	if err = j.rollback(f.Fs); err != nil {
+		return r, err
+	}
+	return r, f.Fs.RemoveAll(path.Join(HistoryFolder, id))
+}
@@ -0,0 +1,112 @@
+package fs
+
+import (
+	"os"
+	"path"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+// journal keeps track of the files and folders changed by a command so the
+// changes can be reverted if one of the generators fails.
+type journal struct {
+	files   []journalEntry
+	seen    map[string]bool
+	folders []string
+}
+
+// journalEntry holds the content a file had before it was first written
+// during the transaction.
+type journalEntry struct {
+	path    string
+	existed bool
+	data    []byte
+}
+
+func newJournal() *journal {
+	return &journal{
+		seen: map[string]bool{},
+	}
+}
+
+// recordFile saves the original state of the file in `pth`, only the first
+// write of a file is recorded so rollback restores what was there before the command.
+// The files of the new folders are recorded too so the history lists them.
+func (j *journal) recordFile(fs afero.Fs, pth string) error {
+	pth = path.Clean(pth)
+	if j.seen[pth] {
+		return nil
+	}
+	e := journalEntry{path: pth}
+	b, err := afero.Exists(fs, pth)
+	if err != nil {
+		return err
+	}
+	if b {
+		e.existed = true
+		e.data, err = afero.ReadFile(fs, pth)
+		if err != nil {
+			return err
+		}
+	}
+	j.seen[pth] = true
+	j.files = append(j.files, e)
+	return nil
+}
+
+// recordFolder saves the topmost folder of `pth` that does not exist yet,
+// removing it on rollback removes everything created beneath it.
+func (j *journal) recordFolder(fs afero.Fs, pth string) error {
+	missing := ""
+	for d := path.Clean(pth); d != "." && d != "/"; d = path.Dir(d) {
+		b, err := afero.Exists(fs, d)
+		if err != nil {
+			return err
+		}
+		if b {
+			break
+		}
+		missing = d
+	}
+	if missing != "" && !j.insideNewFolder(missing) {
+		j.folders = append(j.folders, missing)
+	}
+	return nil
+}
+
+func (j *journal) insideNewFolder(pth string) bool {
+	for _, v := range j.folders {
+		if pth == v || len(pth) > len(v) && pth[:len(v)+1] == v+"/" {
+			return true
+		}
+	}
+	return false
+}
+
+// rollback restores the recorded files and removes the created folders in
+// reverse order, it tries to revert everything and returns the first error.
+func (j *journal) rollback(fs afero.Fs) (err error) {
+	keep := func(e error) {
+		if e != nil && err == nil {
+			err = e
+		}
+	}
+	for i := len(j.files) - 1; i >= 0; i-- {
+		e := j.files[i]
+		if e.existed {
+			logrus.Debugf("Restoring `%s`", e.path)
+			keep(afero.WriteFile(fs, e.path, e.data, os.ModePerm))
+			continue
+		}
+		logrus.Debugf("Removing `%s`", e.path)
+		if b, _ := afero.Exists(fs, e.path); b {
+			keep(fs.Remove(e.path))
+		}
+	}
+	for i := len(j.folders) - 1; i >= 0; i-- {
+		logrus.Debugf("Removing folder `%s`", j.folders[i])
+		keep(fs.RemoveAll(j.folders[i]))
+	}
+	return err
+}
@@ -0,0 +1,180 @@
+package fs
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Markers used to wrap the regions that could not be merged.
+const (
+	conflictStart     = "<<<<<<< current"
+	conflictSeparator = "======="
+	conflictEnd       = ">>>>>>> generated"
+)
+
+// mergeTwoWay merges the current content of a file with the generated one, the
+// lines of both are kept and only the regions where both sides have different
+// lines are wrapped in conflict markers. It returns the merged content and the
+// number of conflicts.
+func mergeTwoWay(current, generated string) (string, int) {
+	a, b := splitLines(current), splitLines(generated)
+	out := []string{}
+	conflicts := 0
+	i, j := 0, 0
+	for _, m := range commonLines(a, b) {
+		ca, cb := a[i:m[0]], b[j:m[1]]
+		switch {
+		case len(ca) == 0:
+			out = append(out, cb...)
+		case len(cb) == 0:
+			out = append(out, ca...)
+		default:
+			conflicts++
+			out = append(out, conflictStart)
+			out = append(out, ca...)
+			out = append(out, conflictSeparator)
+			out = append(out, cb...)
+			out = append(out, conflictEnd)
+		}
+		if m[0] < len(a) {
+			out = append(out, a[m[0]])
+		}
+		i, j = m[0]+1, m[1]+1
+	}
+	return strings.Join(out, "\n"), conflicts
+}
+
+// commonLines returns the indexes of the longest common subsequence of lines
+// of `a` and `b`, it always ends with the sentinel {len(a), len(b)}.
+func commonLines(a, b []string) [][2]int {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	m := [][2]int{}
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			m = append(m, [2]int{i, j})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return append(m, [2]int{len(a), len(b)})
+}
+
+// importLine matches the lines of an import declaration, generators rewrite them
+// when they add imports.
+var importLine = regexp.MustCompile(`^(import.*|\(|\)|[\w.]*"[^"]*")$`)
+
+// containsLines returns true if `generated` keeps every line of `current` in the
+// same order, only blank lines and imports may differ and the spaces are ignored
+// as the generators format the files.
+func containsLines(generated, current string) bool {
+	a, b := splitLines(current), splitLines(generated)
+	for _, l := range [][]string{a, b} {
+		for i, v := range l {
+			l[i] = strings.Join(strings.Fields(v), "")
+		}
+	}
+	i := 0
+	for _, m := range commonLines(a, b) {
+		for _, v := range a[i:m[0]] {
+			if v != "" && !importLine.MatchString(v) {
+				return false
+			}
+		}
+		i = m[0] + 1
+	}
+	return true
+}
+
+func splitLines(s string) []string {
+	return strings.Split(s, "\n")
+}
+
+// mergeThreeWay merges the changes made to `base` by the user (`current`) and
+// by kit (`generated`), regions changed by only one side take that side and
+// regions changed differently by both sides are wrapped in conflict markers. It
+// returns the merged content and the number of conflicts.
+func mergeThreeWay(base, current, generated string) (string, int) {
+	o, a, b := splitLines(base), splitLines(current), splitLines(generated)
+	ma, mb := matchIndexes(o, a), matchIndexes(o, b)
+	out := []string{}
+	conflicts := 0
+	io, ia, ib := 0, 0, 0
+	for {
+		for io < len(o) && ma[io] == ia && mb[io] == ib {
+			out = append(out, o[io])
+			io, ia, ib = io+1, ia+1, ib+1
+		}
+		if io == len(o) && ia == len(a) && ib == len(b) {
+			break
+		}
+		no, na, nb := len(o), len(a), len(b)
+		for i := io; i < len(o); i++ {
+			if ma[i] >= 0 && mb[i] >= 0 {
+				no, na, nb = i, ma[i], mb[i]
+				break
+			}
+		}
+		co, ca, cb := o[io:no], a[ia:na], b[ib:nb]
+		switch {
+		case equalLines(ca, co):
+			out = append(out, cb...)
+		case equalLines(cb, co), equalLines(ca, cb):
+			out = append(out, ca...)
+		default:
+			conflicts++
+			out = append(out, conflictStart)
+			out = append(out, ca...)
+			out = append(out, conflictSeparator)
+			out = append(out, cb...)
+			out = append(out, conflictEnd)
+		}
+		io, ia, ib = no, na, nb
+	}
+	return strings.Join(out, "\n"), conflicts
+}
+
+// matchIndexes returns for each line of `o` the index of the matching line in
+// `a` or -1 if the line was removed.
+func matchIndexes(o, a []string) []int {
+	m := make([]int, len(o))
+	for i := range m {
+		m[i] = -1
+	}
+	for _, v := range commonLines(o, a) {
+		if v[0] < len(o) {
+			m[v[0]] = v[1]
+		}
+	}
+	return m
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
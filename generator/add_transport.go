@@ -15,6 +15,8 @@ import (
 
 	"errors"
 
+	"net/http"
+
 	"github.com/dave/jennifer/jen"
 	"github.com/emicklei/proto"
 	"github.com/emicklei/proto-contrib/pkg/protofmt"
@@ -83,6 +85,9 @@ func (g *GenerateTransport) Generate() (err error) {
 		return err
 	}
 	g.file, err = parser.NewFileParser().Parse([]byte(svcSrc))
+	if err != nil {
+		return err
+	}
 	if !g.serviceFound() {
 		return errors.New(fmt.Sprintf("could not find the service interface in `%s`", g.name))
 	}
@@ -92,8 +97,16 @@ func (g *GenerateTransport) Generate() (err error) {
 	if len(g.serviceInterface.Methods) == 0 {
 		return errors.New("the service has no suitable methods please implement the interface methods")
 	}
+	err = newGeneratePropagation(g.name).Generate()
+	if err != nil {
+		return err
+	}
 	switch g.transport {
 	case "http":
+		g.removeStreamingMethods()
+		if len(g.serviceInterface.Methods) == 0 {
+			return errors.New("the service has no suitable methods for the http transport")
+		}
 		tG := newGenerateHTTPTransport(g.name, g.gorillaMux, g.serviceInterface, g.methods)
 		err = tG.Generate()
 		if err != nil {
@@ -104,6 +117,18 @@ func (g *GenerateTransport) Generate() (err error) {
 		if err != nil {
 			return err
 		}
+		if b, err := statusCatalogExists(g.fs, g.name); err != nil {
+			return err
+		} else if b {
+			err = newGenerateStatusCatalog(g.name).Generate()
+			if err != nil {
+				return err
+			}
+			err = newGenerateHTTPStatusEncoder(g.name).Generate()
+			if err != nil {
+				return err
+			}
+		}
 	case "grpc":
 		gp := newGenerateGRPCTransportProto(g.name, g.pbPath, g.serviceInterface, g.methods)
 		err = gp.Generate()
@@ -186,6 +211,20 @@ func (g *GenerateTransport) removeUnwantedMethods() {
 	g.serviceInterface.Methods = keepMethods
 }
 
+// removeStreamingMethods removes the methods that stream over channels, only the grpc
+// transport knows how to serve them.
+func (g *GenerateTransport) removeStreamingMethods() {
+	keepMethods := []parser.Method{}
+	for _, v := range g.serviceInterface.Methods {
+		if isStreamingMethod(v) {
+			logrus.Warnf("The method '%s' is streaming, only the grpc transport supports it so it will be ignored", v.Name)
+			continue
+		}
+		keepMethods = append(keepMethods, v)
+	}
+	g.serviceInterface.Methods = keepMethods
+}
+
 type generateHTTPTransport struct {
 	BaseGenerator
 	name                          string
@@ -223,6 +262,10 @@ func (g *generateHTTPTransport) Generate() (err error) {
 	if err != nil {
 		return err
 	}
+	serviceImport, err := utils.GetServiceImportPath(g.name)
+	if err != nil {
+		return err
+	}
 	if b, err := g.fs.Exists(g.filePath); err != nil {
 		return err
 	} else if !b {
@@ -238,6 +281,12 @@ func (g *generateHTTPTransport) Generate() (err error) {
 	if err != nil {
 		return err
 	}
+	errorEncoder := "ErrorEncoder"
+	if b, err := statusCatalogExists(g.fs, g.name); err != nil {
+		return err
+	} else if b {
+		errorEncoder = "StatusErrorEncoder"
+	}
 	hasError := false
 	errorEncoderFound := false
 	err2codeFound := false
@@ -277,13 +326,24 @@ func (g *generateHTTPTransport) Generate() (err error) {
 				handlerFound = true
 			}
 		}
+		route, err := newHTTPRoute(m)
+		if err != nil {
+			return err
+		}
 		if !handlerFound {
 			g.code.appendMultilineComment([]string{
 				fmt.Sprintf("make%sHandler creates the handler logic", m.Name),
 			})
 			g.code.NewLine()
 			var st *jen.Statement
-			if g.gorillaMux {
+			if route != nil {
+				st = route.handle(g.gorillaMux, jen.Qual("github.com/go-kit/kit/transport/http", "NewServer").Call(
+					jen.Id(fmt.Sprintf("endpoints.%sEndpoint", m.Name)),
+					jen.Id(fmt.Sprintf("decode%sRequest", m.Name)),
+					jen.Id(fmt.Sprintf("encode%sResponse", m.Name)),
+					jen.Id("options..."),
+				))
+			} else if g.gorillaMux {
 				st = jen.Id("m").Dot("Methods").Call(
 					jen.Lit("POST"),
 				).Dot("Path").Call(
@@ -341,7 +401,28 @@ func (g *generateHTTPTransport) Generate() (err error) {
 
 		}
 
-		if !decoderFound {
+		if !decoderFound && route != nil {
+			g.code.appendMultilineComment([]string{
+				fmt.Sprintf("decode%sRequest is a transport/http.DecodeRequestFunc that decodes the", m.Name),
+				"request from the path, the query and the JSON-encoded body of the HTTP request.",
+			})
+			g.code.NewLine()
+			g.code.appendFunction(
+				fmt.Sprintf("decode%sRequest", m.Name),
+				nil,
+				[]jen.Code{
+					jen.Id("_").Qual("context", "Context"),
+					jen.Id("r").Id("*").Qual("net/http", "Request"),
+				},
+				[]jen.Code{
+					jen.Interface(),
+					jen.Error(),
+				},
+				"",
+				route.decodeRequest(g.gorillaMux, jen.Qual(endpointImport, m.Name+"Request"), serviceImport)...,
+			)
+			g.code.NewLine()
+		} else if !decoderFound {
 			g.code.appendMultilineComment([]string{
 				fmt.Sprintf("decode%sRequest is a transport/http.DecodeRequestFunc that decodes a", m.Name),
 				"JSON-encoded request from the HTTP request body.",
@@ -385,7 +466,7 @@ func (g *generateHTTPTransport) Generate() (err error) {
 							),
 						).Id(";").Id("ok").Id("&&").Id("f").Dot("Failed").Call().Op("!=").Nil(),
 					).Block(
-						jen.Id("ErrorEncoder").Call(
+						jen.Id(errorEncoder).Call(
 							jen.Id("ctx"),
 							jen.Id("f").Dot("Failed").Call(),
 							jen.Id("w"),
@@ -394,15 +475,36 @@ func (g *generateHTTPTransport) Generate() (err error) {
 					),
 				)
 			}
-			pt = append(
-				pt,
-				jen.Id("w").Dot("Header").Call().Dot("Set").Call(
-					jen.Lit("Content-Type"), jen.Lit("application/json; charset=utf-8")),
-				jen.Err().Op("=").Qual("encoding/json", "NewEncoder").Call(
-					jen.Id("w"),
-				).Dot("Encode").Call(jen.Id("response")),
-				jen.Return(),
-			)
+			switch {
+			case route != nil && route.noContent():
+				pt = append(
+					pt,
+					jen.Id("w").Dot("WriteHeader").Call(route.statusCode()),
+					jen.Return(),
+				)
+			case route != nil && route.status != http.StatusOK:
+				pt = append(
+					pt,
+					jen.Id("w").Dot("Header").Call().Dot("Set").Call(
+						jen.Lit("Content-Type"), jen.Lit("application/json; charset=utf-8")),
+					jen.Id("w").Dot("WriteHeader").Call(route.statusCode()),
+				)
+			default:
+				pt = append(
+					pt,
+					jen.Id("w").Dot("Header").Call().Dot("Set").Call(
+						jen.Lit("Content-Type"), jen.Lit("application/json; charset=utf-8")),
+				)
+			}
+			if route == nil || !route.noContent() {
+				pt = append(
+					pt,
+					jen.Err().Op("=").Qual("encoding/json", "NewEncoder").Call(
+						jen.Id("w"),
+					).Dot("Encode").Call(jen.Id("response")),
+					jen.Return(),
+				)
+			}
 			g.code.appendFunction(
 				fmt.Sprintf("encode%sResponse", m.Name),
 				nil,
@@ -437,6 +539,7 @@ func (g *generateHTTPTransport) Generate() (err error) {
 					jen.Id("errorWrapper").Values(
 						jen.Dict{
 							jen.Id("Error"): jen.Err().Dot("Error").Call(),
+							jen.Id("Kind"):  jen.Qual(serviceImport, "KindOf").Call(jen.Err()),
 						},
 					),
 				),
@@ -460,7 +563,13 @@ func (g *generateHTTPTransport) Generate() (err error) {
 				).Block(
 					jen.Return(jen.Err()),
 				),
-				jen.Return(jen.Qual("errors", "New").Call(jen.Id("w").Dot("Error"))),
+				jen.If(jen.Id("w").Dot("Kind").Op("==").Lit("")).Block(
+					jen.Return(jen.Qual(serviceImport, "FromHTTPStatus").Call(jen.Id("r").Dot("StatusCode"), jen.Id("w").Dot("Error"))),
+				),
+				jen.Return(jen.Op("&").Qual(serviceImport, "Error").Values(jen.Dict{
+					jen.Id("Kind"):    jen.Id("w").Dot("Kind"),
+					jen.Id("Message"): jen.Id("w").Dot("Error"),
+				})),
 			)
 			g.code.NewLine()
 
@@ -468,8 +577,8 @@ func (g *generateHTTPTransport) Generate() (err error) {
 		if !err2codeFound {
 			g.code.appendMultilineComment(
 				[]string{
-					"This is used to set the http status, see an example here :",
-					"https://github.com/go-kit/kit/blob/master/examples/addsvc/pkg/addtransport/http.go#L133",
+					"err2code returns the http status of the error, the service errors are mapped",
+					"by their kind and the other errors are internal server errors.",
 				},
 			)
 			g.code.NewLine()
@@ -481,7 +590,7 @@ func (g *generateHTTPTransport) Generate() (err error) {
 				},
 				[]jen.Code{},
 				"int",
-				jen.Return(jen.Qual("net/http", "StatusInternalServerError")),
+				jen.Return(jen.Qual(serviceImport, "KindOf").Call(jen.Err()).Dot("HTTPStatus").Call()),
 			)
 			g.code.NewLine()
 		}
@@ -492,6 +601,11 @@ func (g *generateHTTPTransport) Generate() (err error) {
 						"json": "error",
 					},
 				),
+				jen.Id("Kind").Qual(serviceImport, "Kind").Tag(
+					map[string]string{
+						"json": "kind,omitempty",
+					},
+				),
 			)
 			g.code.NewLine()
 		}
@@ -852,16 +966,82 @@ func (g *generateGRPCTransportProto) getServiceRPC(svc *proto.Service) {
 		if found {
 			continue
 		}
+		_, streamsRequest := streamParameter(v)
+		_, streamsReturns := streamResult(v)
 		svc.Elements = append(svc.Elements,
 			&proto.RPC{
-				Name:        v.Name,
-				ReturnsType: v.Name + "Reply",
-				RequestType: v.Name + "Request",
+				Name:           v.Name,
+				ReturnsType:    v.Name + "Reply",
+				RequestType:    v.Name + "Request",
+				StreamsRequest: streamsRequest,
+				StreamsReturns: streamsReturns,
 			},
 		)
 	}
 }
 
+// StreamAnnotation streams a parameter or result of a named channel type in the grpc
+// transport, the annotation sets the type of the streamed messages keyed by the name of
+// the parameter, e.x `// kit:stream events:"Event"` for `type Events <-chan Event`.
+const StreamAnnotation = "stream"
+
+// streamParameter returns the parameter that is streamed by the client, methods that
+// receive a channel (`<-chan T` or `chan T`) or an annotated stream are client streaming.
+func streamParameter(m parser.Method) (parser.NamedTypeValue, bool) {
+	for _, p := range m.Parameters {
+		if streamElem(m, p) != "" {
+			return p, true
+		}
+	}
+	return parser.NamedTypeValue{}, false
+}
+
+// streamResult returns the result that is streamed by the server, methods that
+// return a channel (`<-chan T` or `chan T`) or an annotated stream are server streaming.
+func streamResult(m parser.Method) (parser.NamedTypeValue, bool) {
+	for _, p := range m.Results {
+		if streamElem(m, p) != "" {
+			return p, true
+		}
+	}
+	return parser.NamedTypeValue{}, false
+}
+
+// isStreamingMethod returns true if the method is client, server or bidirectional streaming.
+func isStreamingMethod(m parser.Method) bool {
+	_, in := streamParameter(m)
+	_, out := streamResult(m)
+	return in || out
+}
+
+// streamElem returns the type of the messages streamed by the parameter or result of
+// the method, or an empty string if it is not streamed.
+func streamElem(m parser.Method, p parser.NamedTypeValue) string {
+	if tp := m.ParamTag(StreamAnnotation, p.Name); tp != "" {
+		return tp
+	}
+	if strings.HasPrefix(p.Type, "chan<- ") {
+		return ""
+	}
+	return parser.ChanElemType(p.Type)
+}
+
+// streamElemType returns the type of the streamed messages, types declared in the
+// service package are qualified with the service import.
+func streamElemType(elem, serviceImport string) *jen.Statement {
+	if !strings.Contains(elem, ".") && elem[:1] == strings.ToUpper(elem[:1]) &&
+		elem[0] != '[' && elem[0] != '*' {
+		return jen.Qual(serviceImport, elem)
+	}
+	return jen.Id(elem)
+}
+
+// drainStream receives the rest of the values of the streamed channel `ch` in a new
+// goroutine, so the producer of the channel is not blocked once the stream failed.
+func drainStream(ch jen.Code) *jen.Statement {
+	return jen.Go().Func().Params().Block(jen.For(jen.Range().Add(ch)).Block()).Call()
+}
+
 type generateGRPCTransportBase struct {
 	BaseGenerator
 	name             string
@@ -938,7 +1118,7 @@ func (g *generateGRPCTransportBase) Generate() (err error) {
 					)
 				}
 			}
-			fields = append(fields, jen.Id(n).Qual("github.com/go-kit/kit/transport/grpc", "Handler"))
+			fields = append(fields, jen.Id(n).Add(g.handlerType(m)))
 		}
 	} else {
 		for _, m := range g.serviceInterface.Methods {
@@ -947,7 +1127,7 @@ func (g *generateGRPCTransportBase) Generate() (err error) {
 				jen.Id("endpoints"),
 				jen.Id("options").Index(jen.Lit(m.Name)),
 			)
-			fields = append(fields, jen.Id(n).Qual("github.com/go-kit/kit/transport/grpc", "Handler"))
+			fields = append(fields, jen.Id(n).Add(g.handlerType(m)))
 		}
 	}
 	g.code.appendStruct("grpcServer", fields...)
@@ -969,6 +1149,15 @@ func (g *generateGRPCTransportBase) Generate() (err error) {
 	return g.fs.WriteFile(g.filePath, g.srcFile.GoString(), true)
 }
 
+// handlerType returns the type of the grpcServer field that serves the method, streaming
+// methods are served straight from the endpoint because transport/grpc only handles unary calls.
+func (g *generateGRPCTransportBase) handlerType(m parser.Method) *jen.Statement {
+	if isStreamingMethod(m) {
+		return jen.Qual("github.com/go-kit/kit/endpoint", "Endpoint")
+	}
+	return jen.Qual("github.com/go-kit/kit/transport/grpc", "Handler")
+}
+
 type generateGRPCTransport struct {
 	BaseGenerator
 	name              string
@@ -1010,6 +1199,10 @@ func (g *generateGRPCTransport) Generate() (err error) {
 	if err != nil {
 		return err
 	}
+	svcImport, err := utils.GetServiceImportPath(g.name)
+	if err != nil {
+		return err
+	}
 	if b, err := g.fs.Exists(g.filePath); err != nil {
 		return err
 	} else if !b {
@@ -1030,11 +1223,21 @@ func (g *generateGRPCTransport) Generate() (err error) {
 		encoderFound := false
 		handlerFound := false
 		funcFound := false
+		in, streamsRequest := streamParameter(m)
+		out, streamsReturns := streamResult(m)
+		decoder := fmt.Sprintf("decode%sRequest", m.Name)
+		if streamsRequest {
+			decoder = fmt.Sprintf("decode%sStreamRequest", m.Name)
+		}
+		encoder := fmt.Sprintf("encode%sResponse", m.Name)
+		if streamsReturns {
+			encoder = fmt.Sprintf("encode%sStreamResponse", m.Name)
+		}
 		for _, v := range g.file.Methods {
-			if v.Name == fmt.Sprintf("decode%sRequest", m.Name) {
+			if v.Name == decoder {
 				decoderFound = true
 			}
-			if v.Name == fmt.Sprintf("encode%sResponse", m.Name) {
+			if v.Name == encoder {
 				encoderFound = true
 			}
 			if v.Name == fmt.Sprintf("make%sHandler", m.Name) {
@@ -1044,7 +1247,30 @@ func (g *generateGRPCTransport) Generate() (err error) {
 				funcFound = true
 			}
 		}
-		if !handlerFound {
+		if !handlerFound && isStreamingMethod(m) {
+			g.code.appendMultilineComment([]string{
+				fmt.Sprintf("make%sHandler creates the handler logic, streaming rpcs are served", m.Name),
+				"directly by the endpoint because transport/grpc only supports unary calls.",
+			})
+			g.code.NewLine()
+			g.code.appendFunction(
+				fmt.Sprintf("make%sHandler", m.Name),
+				nil,
+				[]jen.Code{
+					jen.Id("endpoints").Qual(endpImports, "Endpoints"),
+					jen.Id("options").Index().Qual(
+						"github.com/go-kit/kit/transport/grpc",
+						"ServerOption",
+					),
+				},
+				[]jen.Code{
+					jen.Qual("github.com/go-kit/kit/endpoint", "Endpoint"),
+				},
+				"",
+				jen.Return(jen.Id(fmt.Sprintf("endpoints.%sEndpoint", m.Name))),
+			)
+			g.code.NewLine()
+		} else if !handlerFound {
 			g.code.appendMultilineComment([]string{
 				fmt.Sprintf("make%sHandler creates the handler logic", m.Name),
 			})
@@ -1076,7 +1302,33 @@ func (g *generateGRPCTransport) Generate() (err error) {
 
 		}
 
-		if !decoderFound {
+		if !decoderFound && streamsRequest {
+			g.code.appendMultilineComment([]string{
+				fmt.Sprintf("decode%sStreamRequest converts each gRPC request of the %s stream", m.Name, m.Name),
+				"to a user-domain value of the streamed channel.",
+				"TODO implement the decoder",
+			})
+			g.code.NewLine()
+			g.code.appendFunction(
+				decoder,
+				nil,
+				[]jen.Code{
+					jen.Id("_").Qual("context", "Context"),
+					jen.Id("r").Id("*").Qual(pbImport, m.Name+"Request"),
+				},
+				[]jen.Code{
+					jen.Id("v").Add(streamElemType(streamElem(m, in), svcImport)),
+					jen.Err().Error(),
+				},
+				"",
+				jen.Return(
+					jen.Id("v"), jen.Qual("errors", "New").Call(
+						jen.Lit(fmt.Sprintf("'%s' Decoder is not impelemented", utils.ToCamelCase(g.name))),
+					),
+				),
+			)
+			g.code.NewLine()
+		} else if !decoderFound {
 			g.code.appendMultilineComment([]string{
 				fmt.Sprintf("decode%sResponse is a transport/grpc.DecodeRequestFunc that converts a", m.Name),
 				fmt.Sprintf("gRPC request to a user-domain %s request.", m.Name),
@@ -1103,13 +1355,51 @@ func (g *generateGRPCTransport) Generate() (err error) {
 			)
 			g.code.NewLine()
 		}
-		if !encoderFound {
+		if !encoderFound && streamsReturns {
+			g.code.appendMultilineComment([]string{
+				fmt.Sprintf("encode%sStreamResponse converts each user-domain value of the streamed", m.Name),
+				fmt.Sprintf("channel to a gRPC reply of the %s stream.", m.Name),
+				"TODO implement the encoder",
+			})
+			g.code.NewLine()
+			g.code.appendFunction(
+				encoder,
+				nil,
+				[]jen.Code{
+					jen.Id("_").Qual("context", "Context"),
+					jen.Id("v").Add(streamElemType(streamElem(m, out), svcImport)),
+				},
+				[]jen.Code{
+					jen.Id("*").Qual(pbImport, m.Name+"Reply"),
+					jen.Error(),
+				},
+				"",
+				jen.Return(
+					jen.Nil(), jen.Qual("errors", "New").Call(
+						jen.Lit(fmt.Sprintf("'%s' Encoder is not impelemented", utils.ToCamelCase(g.name))),
+					),
+				),
+			)
+			g.code.NewLine()
+		} else if !encoderFound {
 			g.code.appendMultilineComment([]string{
 				fmt.Sprintf("encode%sResponse is a transport/grpc.EncodeResponseFunc that converts", m.Name),
 				"a user-domain response to a gRPC reply.",
 				"TODO implement the encoder",
 			})
 			g.code.NewLine()
+			body := []jen.Code{}
+			for _, v := range m.Results {
+				if v.Type == "error" {
+					body = append(body, jen.If(
+						jen.List(jen.Id("f"), jen.Id("ok")).Op(":=").Id("r").Assert(jen.Qual(endpImports, "Failure")),
+						jen.Id("ok").Op("&&").Id("f").Dot("Failed").Call().Op("!=").Nil(),
+					).Block(
+						jen.Return(jen.Nil(), jen.Id("f").Dot("Failed").Call()),
+					))
+					break
+				}
+			}
 			g.code.appendFunction(
 				fmt.Sprintf("encode%sResponse", m.Name),
 				nil,
@@ -1122,15 +1412,17 @@ func (g *generateGRPCTransport) Generate() (err error) {
 					jen.Error(),
 				},
 				"",
-				jen.Return(
+				append(body, jen.Return(
 					jen.Nil(), jen.Qual("errors", "New").Call(
 						jen.Lit(fmt.Sprintf("'%s' Encoder is not impelemented", utils.ToCamelCase(g.name))),
 					),
-				),
+				))...,
 			)
 			g.code.NewLine()
 		}
-		if !funcFound {
+		if !funcFound && isStreamingMethod(m) {
+			g.generateStreamMethod(m, endpImports, pbImport, svcImport)
+		} else if !funcFound {
 			stp := g.GenerateNameBySample("grpcServer", append(m.Parameters, m.Results...))
 			n := utils.ToCamelCase(m.Name)
 			g.code.appendFunction(
@@ -1192,3 +1484,141 @@ func (g *generateGRPCTransport) Generate() (err error) {
 	}
 	return g.fs.WriteFile(g.filePath, s, true)
 }
+
+// generateStreamMethod generates the grpcServer method of a streaming rpc, it adapts the
+// gRPC stream to the channels of the endpoint request and response.
+func (g *generateGRPCTransport) generateStreamMethod(m parser.Method, endpImports, pbImport, svcImport string) {
+	stp := g.GenerateNameBySample("grpcServer", append(m.Parameters, m.Results...))
+	n := utils.ToCamelCase(m.Name)
+	in, streamsRequest := streamParameter(m)
+	out, streamsReturns := streamResult(m)
+	errName := ""
+	for _, p := range m.Results {
+		if p.Type == "error" {
+			errName = utils.ToCamelCase(p.Name)
+		}
+	}
+	streamType := jen.Qual(pbImport, fmt.Sprintf("%s_%sServer", utils.ToCamelCase(g.name), n))
+	params := []jen.Code{jen.Id("stream").Add(streamType)}
+	body := []jen.Code{
+		jen.List(jen.Id("ctx"), jen.Id("cancel")).Op(":=").Qual("context", "WithCancel").Call(
+			jen.Id("stream").Dot("Context").Call(),
+		),
+		jen.Defer().Id("cancel").Call(),
+	}
+	if streamsRequest {
+		elem := streamElemType(streamElem(m, in), svcImport)
+		body = append(
+			body,
+			jen.Id("in").Op(":=").Make(jen.Chan().Add(elem)),
+			jen.Id("errc").Op(":=").Make(jen.Chan().Error(), jen.Lit(1)),
+			jen.Go().Func().Params().Block(
+				jen.Defer().Close(jen.Id("in")),
+				jen.For().Block(
+					jen.List(jen.Id("req"), jen.Err()).Op(":=").Id("stream").Dot("Recv").Call(),
+					jen.If(jen.Err().Op("==").Qual("io", "EOF")).Block(jen.Return()),
+					jen.If(jen.Err().Op("!=").Nil()).Block(
+						jen.Id("errc").Op("<-").Err(),
+						jen.Return(),
+					),
+					jen.List(jen.Id("v"), jen.Err()).Op(":=").Id(fmt.Sprintf("decode%sStreamRequest", m.Name)).Call(
+						jen.Id("ctx"), jen.Id("req"),
+					),
+					jen.If(jen.Err().Op("!=").Nil()).Block(
+						jen.Id("errc").Op("<-").Err(),
+						jen.Return(),
+					),
+					jen.Select().Block(
+						jen.Case(jen.Id("in").Op("<-").Id("v")),
+						jen.Case(jen.Op("<-").Id("ctx").Dot("Done").Call()).Block(jen.Return()),
+					),
+				),
+			).Call(),
+			jen.List(jen.Id("response"), jen.Err()).Op(":=").Id(stp).Dot(utils.ToLowerFirstCamelCase(m.Name)).Call(
+				jen.Id("ctx"),
+				jen.Qual(endpImports, m.Name+"Request").Values(jen.Dict{
+					jen.Id(utils.ToCamelCase(in.Name)): jen.Id("in"),
+				}),
+			),
+		)
+	} else {
+		params = append([]jen.Code{jen.Id("req").Id("*").Qual(pbImport, n+"Request")}, params...)
+		body = append(
+			body,
+			jen.List(jen.Id("request"), jen.Err()).Op(":=").Id(fmt.Sprintf("decode%sRequest", m.Name)).Call(
+				jen.Id("ctx"), jen.Id("req"),
+			),
+			jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Err())),
+			jen.List(jen.Id("response"), jen.Err()).Op(":=").Id(stp).Dot(utils.ToLowerFirstCamelCase(m.Name)).Call(
+				jen.Id("ctx"), jen.Id("request"),
+			),
+		)
+	}
+	body = append(body, jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Err())))
+	if streamsReturns {
+		body = append(
+			body,
+			jen.Id("rs").Op(":=").Id("response").Assert(jen.Qual(endpImports, m.Name+"Response")),
+		)
+		if errName != "" {
+			body = append(
+				body,
+				jen.If(jen.Id("rs").Dot(errName).Op("!=").Nil()).Block(
+					jen.Return(jen.Id("rs").Dot(errName)),
+				),
+			)
+		}
+		body = append(
+			body,
+			jen.For(jen.Id("v").Op(":=").Range().Id("rs").Dot(utils.ToCamelCase(out.Name))).Block(
+				jen.List(jen.Id("reply"), jen.Err()).Op(":=").Id(fmt.Sprintf("encode%sStreamResponse", m.Name)).Call(
+					jen.Id("ctx"), jen.Id("v"),
+				),
+				jen.If(jen.Err().Op("==").Nil()).Block(
+					jen.Err().Op("=").Id("stream").Dot("Send").Call(jen.Id("reply")),
+				),
+				jen.If(jen.Err().Op("!=").Nil()).Block(
+					drainStream(jen.Id("rs").Dot(utils.ToCamelCase(out.Name))),
+					jen.Return(jen.Err()),
+				),
+			),
+		)
+		if streamsRequest {
+			body = append(
+				body,
+				jen.Select().Block(
+					jen.Case(jen.Err().Op(":=").Op("<-").Id("errc")).Block(jen.Return(jen.Err())),
+					jen.Default().Block(jen.Return(jen.Nil())),
+				),
+			)
+		} else {
+			body = append(body, jen.Return(jen.Nil()))
+		}
+	} else {
+		body = append(
+			body,
+			jen.Select().Block(
+				jen.Case(jen.Err().Op(":=").Op("<-").Id("errc")).Block(jen.Return(jen.Err())),
+				jen.Default(),
+			),
+			jen.List(jen.Id("reply"), jen.Err()).Op(":=").Id(fmt.Sprintf("encode%sResponse", m.Name)).Call(
+				jen.Id("ctx"), jen.Id("response"),
+			),
+			jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Err())),
+			jen.Return(
+				jen.Id("stream").Dot("SendAndClose").Call(
+					jen.Id("reply").Assert(jen.Id("*").Qual(pbImport, n+"Reply")),
+				),
+			),
+		)
+	}
+	g.code.appendFunction(
+		n,
+		jen.Id(stp).Id("*grpcServer"),
+		params,
+		[]jen.Code{},
+		"error",
+		body...,
+	)
+	g.code.NewLine()
+}
@@ -2,6 +2,7 @@ package generator
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/dave/jennifer/jen"
@@ -1145,3 +1146,90 @@ func Test_generateGRPCTransport_Generate(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateTransport_GenerateStreaming(t *testing.T) {
+	setDefaults()
+	f := fs.NewDefaultFs("")
+	f.MkdirAll("test/pkg/service")
+	f.WriteFile("test/pkg/service/service.go", `package service
+import "context"
+type Item struct{ Name string }
+type Events <-chan Item
+type TestService interface{
+		Foo(ctx context.Context, a string)(b int, err error)
+		Watch(ctx context.Context, a string)(items <-chan Item, err error)
+		Upload(ctx context.Context, items <-chan Item)(n int, err error)
+		Chat(ctx context.Context, in <-chan string)(out <-chan string, err error)
+		// kit:stream events:"Item"
+		Subscribe(ctx context.Context, topic string)(events Events, err error)
+}`, true)
+	if err := NewGenerateTransport("test", false, "grpc", "", "", []string{}).Generate(); err != nil {
+		t.Fatalf("GenerateTransport.Generate() error = %v", err)
+	}
+	pb, err := f.ReadFile("test/pkg/grpc/pb/test.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The rpcs are aligned by the proto formatter.
+	pb = strings.Join(strings.Fields(pb), "")
+	for _, v := range []string{
+		"rpcFoo(FooRequest)returns(FooReply);",
+		"rpcWatch(WatchRequest)returns(streamWatchReply);",
+		"rpcUpload(streamUploadRequest)returns(UploadReply);",
+		"rpcChat(streamChatRequest)returns(streamChatReply);",
+		"rpcSubscribe(SubscribeRequest)returns(streamSubscribeReply);",
+	} {
+		if !strings.Contains(pb, v) {
+			t.Errorf("GenerateTransport.Generate() proto does not contain %q", v)
+		}
+	}
+	src, err := f.ReadFile("test/pkg/grpc/handler.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		// Server streaming decodes the request like unary rpcs and encodes each item.
+		"func decodeWatchRequest(_ context.Context, r interface{}) (interface{}, error)",
+		"func encodeWatchStreamResponse(_ context.Context, v service.Item) (*pb.WatchReply, error)",
+		"func (g *grpcServer) Watch(req *pb.WatchRequest, stream pb.Test_WatchServer) error",
+		"reply, err := encodeWatchStreamResponse(ctx, v)",
+		"stream.Send(reply)",
+		// Client streaming decodes each item and encodes the response like unary rpcs.
+		"func decodeUploadStreamRequest(_ context.Context, r *pb.UploadRequest) (v service.Item, err error)",
+		"func encodeUploadResponse(_ context.Context, r interface{}) (interface{}, error)",
+		"func (g *grpcServer) Upload(stream pb.Test_UploadServer) error",
+		"v, err := decodeUploadStreamRequest(ctx, req)",
+		"case in <- v:",
+		"stream.SendAndClose(reply.(*pb.UploadReply))",
+		// Bidirectional streaming only uses the stream codecs.
+		"func decodeChatStreamRequest(_ context.Context, r *pb.ChatRequest) (v string, err error)",
+		"func encodeChatStreamResponse(_ context.Context, v string) (*pb.ChatReply, error)",
+		"func (g *grpcServer) Chat(stream pb.Test_ChatServer) error",
+		"func makeChatHandler(endpoints endpoint.Endpoints, options []grpc.ServerOption) endpoint1.Endpoint",
+		// Annotated streams are streamed like channels.
+		"func encodeSubscribeStreamResponse(_ context.Context, v service.Item) (*pb.SubscribeReply, error)",
+		"func (g *grpcServer) Subscribe(req *pb.SubscribeRequest, stream pb.Test_SubscribeServer) error",
+		"for v := range rs.Events {",
+		// A failed stream cancels the service and drains the channel it produces.
+		"ctx, cancel := context.WithCancel(stream.Context())",
+		"defer cancel()",
+		"err = stream.Send(reply)",
+		"for range rs.Events {",
+	} {
+		if !strings.Contains(src, v) {
+			t.Errorf("GenerateTransport.Generate() grpc handler does not contain %q", v)
+		}
+	}
+	for _, v := range []string{
+		"encodeWatchResponse",
+		"decodeUploadRequest",
+		"decodeChatRequest",
+		"encodeChatResponse",
+		".(service.Item)",
+		".(string)",
+	} {
+		if strings.Contains(src, v) {
+			t.Errorf("GenerateTransport.Generate() grpc handler should not contain %q", v)
+		}
+	}
+}
@@ -0,0 +1,593 @@
+package generator
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/kujtimiihoxha/kit/fs"
+	"github.com/kujtimiihoxha/kit/parser"
+	"github.com/kujtimiihoxha/kit/utils"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// The kinds of authentication the auth middleware supports.
+const (
+	AuthJWT    = "jwt"
+	AuthAPIKey = "apikey"
+)
+
+// SupportedAuthKinds are the kinds of authentication the auth middleware supports.
+var SupportedAuthKinds = []string{AuthJWT, AuthAPIKey}
+
+// NoAuthAnnotation opts a method of the service interface out of the auth
+// middleware (e.x `// kit:noauth`).
+const NoAuthAnnotation = "noauth"
+
+// GenerateAuthMiddleware implements Gen and is used to generate the auth
+// middleware of a service.
+type GenerateAuthMiddleware struct {
+	BaseGenerator
+	serviceName       string
+	kind              string
+	interfaceName     string
+	destPath          string
+	filePath          string
+	serviceFilePath   string
+	generateFirstTime bool
+	file              *parser.File
+	serviceInterface  parser.Interface
+}
+
+// NewGenerateAuthMiddleware returns a generator of the `kind` auth middleware.
+func NewGenerateAuthMiddleware(serviceName, kind string) Gen {
+	i := &GenerateAuthMiddleware{
+		serviceName:   serviceName,
+		kind:          kind,
+		interfaceName: utils.ToCamelCase(serviceName + "Service"),
+		destPath:      fmt.Sprintf(viper.GetString("gk_auth_path_format"), utils.ToLowerSnakeCase(serviceName)),
+	}
+	i.filePath = path.Join(i.destPath, viper.GetString("gk_auth_file_name"))
+	i.serviceFilePath = path.Join(
+		fmt.Sprintf(viper.GetString("gk_service_path_format"), utils.ToLowerSnakeCase(serviceName)),
+		viper.GetString("gk_service_file_name"),
+	)
+	i.srcFile = jen.NewFilePath(i.destPath)
+	i.srcFile.ImportAlias("github.com/go-kit/kit/auth/jwt", "kitjwt")
+	i.srcFile.ImportAlias("github.com/golang-jwt/jwt/v4", "stdjwt")
+	i.InitPg()
+	i.fs = fs.Get()
+	return i
+}
+
+// Generate generates the auth middleware, the token extractors of the
+// transports and injectors of the clients and the list of the methods that
+// require authentication.
+func (g *GenerateAuthMiddleware) Generate() (err error) {
+	if b, err := g.fs.Exists(g.serviceFilePath); err != nil {
+		return err
+	} else if !b {
+		logrus.Errorf("Service %s was not found", g.serviceName)
+		return nil
+	}
+	svcSrc, err := g.fs.ReadFile(g.serviceFilePath)
+	if err != nil {
+		return err
+	}
+	svcFile, err := parser.NewFileParser().Parse([]byte(svcSrc))
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, v := range svcFile.Interfaces {
+		if v.Name == g.interfaceName {
+			g.serviceInterface = v
+			found = true
+		}
+	}
+	if !found {
+		logrus.Errorf("Could not find the service interface in `%s`", g.serviceName)
+		return nil
+	}
+	if err = g.CreateFolderStructure(g.destPath); err != nil {
+		return err
+	}
+	if b, err := g.fs.Exists(g.filePath); err != nil {
+		return err
+	} else if !b {
+		g.generateFirstTime = true
+		f := jen.NewFile("auth")
+		g.fs.WriteFile(g.filePath, f.GoString(), false)
+	}
+	src, err := g.fs.ReadFile(g.filePath)
+	if err != nil {
+		return err
+	}
+	g.file, err = parser.NewFileParser().Parse([]byte(src))
+	if err != nil {
+		return err
+	}
+	// The middleware rejects the requests with the errors of the service.
+	if err = newGenerateServiceErrors(g.serviceName).Generate(); err != nil {
+		return err
+	}
+	svcImport, err := utils.GetServiceImportPath(g.serviceName)
+	if err != nil {
+		return err
+	}
+	switch g.kind {
+	case AuthJWT:
+		g.generateJWT(svcImport)
+	case AuthAPIKey:
+		g.generateAPIKey(svcImport)
+	default:
+		logrus.Errorf("Auth kind `%s` not supported", g.kind)
+		return nil
+	}
+	if g.generateFirstTime {
+		err = g.fs.WriteFile(g.filePath, g.srcFile.GoString(), true)
+	} else {
+		err = g.appendToFile(src)
+	}
+	if err != nil {
+		return err
+	}
+	return newGenerateAuthBase(g.serviceName, g.serviceInterface).Generate()
+}
+
+func (g *GenerateAuthMiddleware) appendToFile(src string) error {
+	pSrc, err := g.partialSource()
+	if err != nil {
+		return err
+	}
+	src += "\n" + pSrc
+	f, err := parser.NewFileParser().Parse([]byte(g.srcFile.GoString()))
+	if err != nil {
+		return err
+	}
+	imp, err := g.getMissingImports(f.Imports, g.file)
+	if err != nil {
+		return err
+	}
+	if len(imp) > 0 {
+		src, err = g.AddImportsToFile(imp, src)
+		if err != nil {
+			return err
+		}
+	}
+	s, err := utils.GoImportsSource(g.destPath, src)
+	if err != nil {
+		return err
+	}
+	return g.fs.WriteFile(g.filePath, s, true)
+}
+
+func (g *GenerateAuthMiddleware) exists(name string) bool {
+	for _, v := range g.file.Methods {
+		if v.Name == name {
+			return true
+		}
+	}
+	for _, v := range append(g.file.Vars, g.file.Constants...) {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// appendWrapper appends a function that calls the go-kit jwt function `kitFunc`.
+func (g *GenerateAuthMiddleware) appendWrapper(name string, comment []string, params []jen.Code, kitFunc string, args ...jen.Code) {
+	if g.exists(name) {
+		return
+	}
+	g.code.appendMultilineComment(comment)
+	g.code.NewLine()
+	g.code.appendFunction(
+		name,
+		nil,
+		params,
+		[]jen.Code{},
+		"context.Context",
+		jen.Return(jen.Qual("github.com/go-kit/kit/auth/jwt", kitFunc).Call().Call(args...)),
+	)
+	g.code.NewLine()
+}
+
+func (g *GenerateAuthMiddleware) generateJWT(svcImport string) {
+	if !g.exists("jwtErrors") {
+		g.code.appendMultilineComment([]string{
+			"jwtErrors are the errors of the go-kit JWT parser, their message is kept in the",
+			"unauthorized errors of the service.",
+		})
+		g.code.NewLine()
+		errs := []jen.Code{}
+		for _, v := range []string{
+			"ErrTokenContextMissing", "ErrTokenInvalid", "ErrTokenExpired",
+			"ErrTokenMalformed", "ErrTokenNotActive", "ErrUnexpectedSigningMethod",
+		} {
+			errs = append(errs, jen.Qual("github.com/go-kit/kit/auth/jwt", v))
+		}
+		g.code.Raw().Var().Id("jwtErrors").Op("=").Index().Error().Values(errs...).Line()
+		g.code.NewLine()
+	}
+	if !g.exists("Middleware") {
+		g.code.appendMultilineComment([]string{
+			"Middleware returns an endpoint middleware that rejects the requests without",
+			"a valid JWT signed with the key using HS256, the claims of the token are",
+			"stored in the context. The requests are rejected with unauthorized errors of",
+			"the service so the transports respond with 401 or Unauthenticated.",
+		})
+		g.code.NewLine()
+		endpointFunc := func(body ...jen.Code) *jen.Statement {
+			return jen.Func().Params(
+				jen.Id("ctx").Qual("context", "Context"),
+				jen.Id("request").Interface(),
+			).Params(jen.Interface(), jen.Error()).Block(body...)
+		}
+		g.code.Raw().Func().Id("Middleware").Params(
+			jen.Id("key").Index().Byte(),
+		).Qual("github.com/go-kit/kit/endpoint", "Middleware").Block(
+			jen.Id("keyFunc").Op(":=").Func().Params(
+				jen.Id("token").Op("*").Qual("github.com/golang-jwt/jwt/v4", "Token"),
+			).Params(jen.Interface(), jen.Error()).Block(
+				jen.Return(jen.Id("key"), jen.Nil()),
+			),
+			jen.Id("parser").Op(":=").Qual("github.com/go-kit/kit/auth/jwt", "NewParser").Call(
+				jen.Id("keyFunc"),
+				jen.Qual("github.com/golang-jwt/jwt/v4", "SigningMethodHS256"),
+				jen.Qual("github.com/go-kit/kit/auth/jwt", "StandardClaimsFactory"),
+			),
+			jen.Return(jen.Func().Params(
+				jen.Id("next").Qual("github.com/go-kit/kit/endpoint", "Endpoint"),
+			).Qual("github.com/go-kit/kit/endpoint", "Endpoint").Block(
+				jen.Return(endpointFunc(
+					jen.Comment("The errors returned before next is called are the errors of the token."),
+					jen.Id("parsed").Op(":=").False(),
+					jen.List(jen.Id("response"), jen.Err()).Op(":=").Id("parser").Call(endpointFunc(
+						jen.Id("parsed").Op("=").True(),
+						jen.Return(jen.Id("next").Call(jen.Id("ctx"), jen.Id("request"))),
+					)).Call(jen.Id("ctx"), jen.Id("request")),
+					jen.If(jen.Err().Op("!=").Nil().Op("&&").Op("!").Id("parsed")).Block(
+						jen.Return(jen.Nil(), jen.Id("unauthorized").Call(jen.Err())),
+					),
+					jen.Return(jen.Id("response"), jen.Err()),
+				)),
+			)),
+		).Line()
+		g.code.NewLine()
+	}
+	if !g.exists("unauthorized") {
+		g.code.appendMultilineComment([]string{
+			"unauthorized returns the unauthorized error of the service for an error of the",
+			"JWT parser, the errors that are not go-kit JWT errors are not disclosed.",
+		})
+		g.code.NewLine()
+		g.code.appendFunction(
+			"unauthorized",
+			nil,
+			[]jen.Code{jen.Err().Error()},
+			[]jen.Code{},
+			"error",
+			jen.For(jen.List(jen.Id("_"), jen.Id("v")).Op(":=").Range().Id("jwtErrors")).Block(
+				jen.If(jen.Qual("errors", "Is").Call(jen.Err(), jen.Id("v"))).Block(
+					jen.Return(jen.Qual(svcImport, "Unauthorized").Call(jen.Lit("%v"), jen.Err())),
+				),
+			),
+			jen.Return(jen.Qual(svcImport, "Unauthorized").Call(
+				jen.Lit("%v"), jen.Qual("github.com/go-kit/kit/auth/jwt", "ErrTokenInvalid"),
+			)),
+		)
+		g.code.NewLine()
+	}
+	ctx := jen.Id("ctx").Qual("context", "Context")
+	g.appendWrapper(
+		"HTTPToContext",
+		[]string{
+			"HTTPToContext moves the JWT of the Authorization header to the context, use",
+			"it as a http.ServerBefore.",
+		},
+		[]jen.Code{ctx, jen.Id("r").Op("*").Qual("net/http", "Request")},
+		"HTTPToContext",
+		jen.Id("ctx"), jen.Id("r"),
+	)
+	g.appendWrapper(
+		"GRPCToContext",
+		[]string{
+			"GRPCToContext moves the JWT of the authorization metadata to the context, use",
+			"it as a grpc.ServerBefore.",
+		},
+		[]jen.Code{ctx, jen.Id("md").Qual("google.golang.org/grpc/metadata", "MD")},
+		"GRPCToContext",
+		jen.Id("ctx"), jen.Id("md"),
+	)
+	g.appendWrapper(
+		"ContextToHTTP",
+		[]string{
+			"ContextToHTTP adds the JWT stored in the context to the Authorization header",
+			"of the outgoing request, use it as a http.ClientBefore.",
+		},
+		[]jen.Code{ctx, jen.Id("r").Op("*").Qual("net/http", "Request")},
+		"ContextToHTTP",
+		jen.Id("ctx"), jen.Id("r"),
+	)
+	g.appendWrapper(
+		"ContextToGRPC",
+		[]string{
+			"ContextToGRPC adds the JWT stored in the context to the authorization metadata",
+			"of the outgoing request, use it as a grpc.ClientBefore.",
+		},
+		[]jen.Code{ctx, jen.Id("md").Op("*").Qual("google.golang.org/grpc/metadata", "MD")},
+		"ContextToGRPC",
+		jen.Id("ctx"), jen.Id("md"),
+	)
+	if !g.exists("NewContext") {
+		g.code.appendMultilineComment([]string{
+			"NewContext returns a copy of the context that carries the JWT, the generated",
+			"clients send it with every request.",
+		})
+		g.code.NewLine()
+		g.code.appendFunction(
+			"NewContext",
+			nil,
+			[]jen.Code{ctx, jen.Id("token").String()},
+			[]jen.Code{},
+			"context.Context",
+			jen.Return(jen.Qual("context", "WithValue").Call(
+				jen.Id("ctx"),
+				jen.Qual("github.com/go-kit/kit/auth/jwt", "JWTTokenContextKey"),
+				jen.Id("token"),
+			)),
+		)
+		g.code.NewLine()
+	}
+}
+
+func (g *GenerateAuthMiddleware) generateAPIKey(svcImport string) {
+	if !g.exists("Header") {
+		g.code.appendMultilineComment([]string{
+			"Header is the header that carries the API key.",
+		})
+		g.code.NewLine()
+		g.code.Raw().Const().Id("Header").Op("=").Lit("X-API-Key").Line()
+		g.code.NewLine()
+		g.code.Raw().Type().Id("contextKey").Struct().Line()
+		g.code.NewLine()
+	}
+	if !g.exists("ErrUnauthorized") {
+		g.code.appendMultilineComment([]string{
+			"ErrUnauthorized is returned when the request does not carry a valid API key,",
+			"the transports respond with 401 or Unauthenticated.",
+		})
+		g.code.NewLine()
+		g.code.Raw().Var().Id("ErrUnauthorized").Op("=").Qual(svcImport, "Unauthorized").Call(jen.Lit("unauthorized")).Line()
+		g.code.NewLine()
+	}
+	if !g.exists("Middleware") {
+		g.code.appendMultilineComment([]string{
+			"Middleware returns an endpoint middleware that rejects the requests that do",
+			"not carry one of the keys.",
+		})
+		g.code.NewLine()
+		inF := NewPartialGenerator(nil)
+		inF.appendFunction(
+			"",
+			nil,
+			[]jen.Code{
+				jen.Id("ctx").Qual("context", "Context"),
+				jen.Id("request").Interface(),
+			},
+			[]jen.Code{
+				jen.Id("response").Interface(),
+				jen.Id("err").Error(),
+			},
+			"",
+			jen.List(jen.Id("key"), jen.Id("_")).Op(":=").Id("ctx").Dot("Value").Call(
+				jen.Id("contextKey").Values(),
+			).Assert(jen.String()),
+			jen.For(jen.List(jen.Id("_"), jen.Id("k")).Op(":=").Range().Id("keys")).Block(
+				jen.If(
+					jen.Id("key").Op("!=").Lit("").Op("&&").Qual("crypto/subtle", "ConstantTimeCompare").Call(
+						jen.Index().Byte().Parens(jen.Id("key")),
+						jen.Index().Byte().Parens(jen.Id("k")),
+					).Op("==").Lit(1),
+				).Block(
+					jen.Return(jen.Id("next").Call(jen.Id("ctx"), jen.Id("request"))),
+				),
+			),
+			jen.Return(jen.Nil(), jen.Id("ErrUnauthorized")),
+		)
+		g.code.appendFunction(
+			"Middleware",
+			nil,
+			[]jen.Code{jen.Id("keys").Op("...").String()},
+			[]jen.Code{},
+			"endpoint.Middleware",
+			jen.Return(
+				jen.Func().Params(
+					jen.Id("next").Qual("github.com/go-kit/kit/endpoint", "Endpoint"),
+				).Id("endpoint.Endpoint").Block(
+					jen.Return(inF.Raw()),
+				),
+			),
+		)
+		g.code.NewLine()
+	}
+	ctx := jen.Id("ctx").Qual("context", "Context")
+	if !g.exists("HTTPToContext") {
+		g.code.appendMultilineComment([]string{
+			"HTTPToContext moves the API key of the request to the context, use it as a",
+			"http.ServerBefore.",
+		})
+		g.code.NewLine()
+		g.code.appendFunction(
+			"HTTPToContext",
+			nil,
+			[]jen.Code{ctx, jen.Id("r").Op("*").Qual("net/http", "Request")},
+			[]jen.Code{},
+			"context.Context",
+			jen.Return(jen.Id("NewContext").Call(jen.Id("ctx"), jen.Id("r").Dot("Header").Dot("Get").Call(jen.Id("Header")))),
+		)
+		g.code.NewLine()
+	}
+	if !g.exists("GRPCToContext") {
+		g.code.appendMultilineComment([]string{
+			"GRPCToContext moves the API key of the metadata to the context, use it as a",
+			"grpc.ServerBefore.",
+		})
+		g.code.NewLine()
+		g.code.appendFunction(
+			"GRPCToContext",
+			nil,
+			[]jen.Code{ctx, jen.Id("md").Qual("google.golang.org/grpc/metadata", "MD")},
+			[]jen.Code{},
+			"context.Context",
+			jen.If(
+				jen.Id("v").Op(":=").Id("md").Dot("Get").Call(jen.Id("Header")),
+				jen.Len(jen.Id("v")).Op(">").Lit(0),
+			).Block(
+				jen.Return(jen.Id("NewContext").Call(jen.Id("ctx"), jen.Id("v").Index(jen.Lit(0)))),
+			),
+			jen.Return(jen.Id("ctx")),
+		)
+		g.code.NewLine()
+	}
+	if !g.exists("ContextToHTTP") {
+		g.code.appendMultilineComment([]string{
+			"ContextToHTTP adds the API key stored in the context to the outgoing request,",
+			"use it as a http.ClientBefore.",
+		})
+		g.code.NewLine()
+		g.code.appendFunction(
+			"ContextToHTTP",
+			nil,
+			[]jen.Code{ctx, jen.Id("r").Op("*").Qual("net/http", "Request")},
+			[]jen.Code{},
+			"context.Context",
+			jen.If(
+				jen.List(jen.Id("key"), jen.Id("_")).Op(":=").Id("ctx").Dot("Value").Call(
+					jen.Id("contextKey").Values(),
+				).Assert(jen.String()),
+				jen.Id("key").Op("!=").Lit(""),
+			).Block(
+				jen.Id("r").Dot("Header").Dot("Set").Call(jen.Id("Header"), jen.Id("key")),
+			),
+			jen.Return(jen.Id("ctx")),
+		)
+		g.code.NewLine()
+	}
+	if !g.exists("ContextToGRPC") {
+		g.code.appendMultilineComment([]string{
+			"ContextToGRPC adds the API key stored in the context to the outgoing metadata,",
+			"use it as a grpc.ClientBefore.",
+		})
+		g.code.NewLine()
+		g.code.appendFunction(
+			"ContextToGRPC",
+			nil,
+			[]jen.Code{ctx, jen.Id("md").Op("*").Qual("google.golang.org/grpc/metadata", "MD")},
+			[]jen.Code{},
+			"context.Context",
+			jen.If(
+				jen.List(jen.Id("key"), jen.Id("_")).Op(":=").Id("ctx").Dot("Value").Call(
+					jen.Id("contextKey").Values(),
+				).Assert(jen.String()),
+				jen.Id("key").Op("!=").Lit(""),
+			).Block(
+				jen.Id("md").Dot("Set").Call(jen.Id("Header"), jen.Id("key")),
+			),
+			jen.Return(jen.Id("ctx")),
+		)
+		g.code.NewLine()
+	}
+	if !g.exists("NewContext") {
+		g.code.appendMultilineComment([]string{
+			"NewContext returns a copy of the context that carries the API key, the",
+			"generated clients send it with every request.",
+		})
+		g.code.NewLine()
+		g.code.appendFunction(
+			"NewContext",
+			nil,
+			[]jen.Code{ctx, jen.Id("key").String()},
+			[]jen.Code{},
+			"context.Context",
+			jen.Return(jen.Qual("context", "WithValue").Call(
+				jen.Id("ctx"),
+				jen.Id("contextKey").Values(),
+				jen.Id("key"),
+			)),
+		)
+		g.code.NewLine()
+	}
+}
+
+// generateAuthBase generates the list of the methods that require
+// authentication, it is regenerated with the service so the `kit:noauth`
+// annotations stay in sync.
+type generateAuthBase struct {
+	BaseGenerator
+	name             string
+	destPath         string
+	filePath         string
+	serviceInterface parser.Interface
+}
+
+func newGenerateAuthBase(name string, serviceInterface parser.Interface) Gen {
+	i := &generateAuthBase{
+		name:             name,
+		destPath:         fmt.Sprintf(viper.GetString("gk_auth_path_format"), utils.ToLowerSnakeCase(name)),
+		serviceInterface: serviceInterface,
+	}
+	i.filePath = path.Join(i.destPath, viper.GetString("gk_auth_base_file_name"))
+	i.srcFile = jen.NewFilePath(i.destPath)
+	i.InitPg()
+	i.fs = fs.Get()
+	return i
+}
+
+func (g *generateAuthBase) Generate() (err error) {
+	g.srcFile.PackageComment("THIS FILE IS AUTO GENERATED BY GK-CLI DO NOT EDIT!!")
+	methods := []jen.Code{}
+	for _, v := range g.serviceInterface.Methods {
+		if !v.HasAnnotation(NoAuthAnnotation) {
+			methods = append(methods, jen.Lit(v.Name))
+		}
+	}
+	g.code.appendMultilineComment([]string{
+		"Methods are the methods that require authentication, the methods annotated",
+		"with `kit:" + NoAuthAnnotation + "` in the service interface are left out.",
+	})
+	g.code.NewLine()
+	g.code.Raw().Var().Id("Methods").Op("=").Index().String().Values(methods...).Line()
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"AddMiddleware appends the auth middleware to the methods that require",
+		"authentication.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"AddMiddleware",
+		nil,
+		[]jen.Code{
+			jen.Id("mw").Map(jen.String()).Index().Qual("github.com/go-kit/kit/endpoint", "Middleware"),
+			jen.Id("m").Qual("github.com/go-kit/kit/endpoint", "Middleware"),
+		},
+		[]jen.Code{},
+		"",
+		jen.For(jen.List(jen.Id("_"), jen.Id("v")).Op(":=").Range().Id("Methods")).Block(
+			jen.Id("mw").Index(jen.Id("v")).Op("=").Append(jen.Id("mw").Index(jen.Id("v")), jen.Id("m")),
+		),
+	)
+	g.code.NewLine()
+	return g.fs.WriteFile(g.filePath, g.srcFile.GoString(), true)
+}
+
+// authExists returns true if the auth middleware of the service was generated.
+func authExists(f *fs.KitFs, name string) (bool, error) {
+	return f.Exists(path.Join(
+		fmt.Sprintf(viper.GetString("gk_auth_path_format"), utils.ToLowerSnakeCase(name)),
+		viper.GetString("gk_auth_file_name"),
+	))
+}
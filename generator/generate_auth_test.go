@@ -0,0 +1,131 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kujtimiihoxha/kit/fs"
+)
+
+func newAuthTestFs() *fs.KitFs {
+	f := newClientTestFs()
+	f.WriteFile("test/pkg/service/service.go", `package service
+import "context"
+type TestService interface{
+		Foo(ctx context.Context, a string)(b int, err error)
+		// Health reports if the service is up.
+		// kit:noauth
+		Health(ctx context.Context)(ok bool, err error)
+}`, true)
+	return f
+}
+
+func TestGenerateAuthMiddleware_Generate(t *testing.T) {
+	tests := []struct {
+		name string
+		kind string
+		want []string
+	}{
+		{
+			name: "Test if the jwt middleware is generated",
+			kind: AuthJWT,
+			want: []string{
+				"func Middleware(key []byte) endpoint.Middleware",
+				"kitjwt.NewParser(keyFunc, stdjwt.SigningMethodHS256, kitjwt.StandardClaimsFactory)",
+				"return kitjwt.HTTPToContext()(ctx, r)",
+				"return kitjwt.ContextToGRPC()(ctx, md)",
+				"return nil, unauthorized(err)",
+				"kitjwt.ErrTokenExpired",
+				`return service.Unauthorized("%v", err)`,
+			},
+		},
+		{
+			name: "Test if the api key middleware is generated",
+			kind: AuthAPIKey,
+			want: []string{
+				"func Middleware(keys ...string) endpoint.Middleware",
+				`const Header = "X-API-Key"`,
+				"subtle.ConstantTimeCompare([]byte(key), []byte(k)) == 1",
+				"func GRPCToContext(ctx context.Context, md metadata.MD) context.Context",
+				"r.Header.Set(Header, key)",
+				`var ErrUnauthorized = service.Unauthorized("unauthorized")`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAuthTestFs()
+			if err := NewGenerateAuthMiddleware("test", tt.kind).Generate(); err != nil {
+				t.Fatalf("GenerateAuthMiddleware.Generate() error = %v", err)
+			}
+			src, err := f.ReadFile("test/pkg/auth/auth.go")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, v := range tt.want {
+				if !strings.Contains(src, v) {
+					t.Errorf("GenerateAuthMiddleware.Generate() auth.go does not contain %q", v)
+				}
+			}
+			src, err = f.ReadFile("test/pkg/auth/auth_gen.go")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(src, `var Methods = []string{"Foo"}`) {
+				t.Errorf("GenerateAuthMiddleware.Generate() should leave the noauth methods out, got %s", src)
+			}
+		})
+	}
+}
+
+func TestGenerateAuthMiddleware_GenerateUnauthorizedStatus(t *testing.T) {
+	f := newAuthTestFs()
+	if err := NewGenerateService("test", "http", "", "", false, false, false, nil).Generate(); err != nil {
+		t.Fatalf("GenerateService.Generate() error = %v", err)
+	}
+	if err := NewGenerateAuthMiddleware("test", AuthJWT).Generate(); err != nil {
+		t.Fatalf("GenerateAuthMiddleware.Generate() error = %v", err)
+	}
+	// The auth errors are unauthorized errors of the service, the transports map
+	// their kind to 401 and Unauthenticated.
+	for file, want := range map[string][]string{
+		"test/pkg/auth/auth.go": {
+			"return nil, unauthorized(err)",
+			`return service.Unauthorized("%v", err)`,
+		},
+		"test/pkg/service/errors.go": {
+			"case KindUnauthorized:\n\t\treturn http.StatusUnauthorized",
+			"case KindUnauthorized:\n\t\treturn codes.Unauthenticated",
+		},
+		"test/pkg/http/handler.go": {
+			"return service.KindOf(err).HTTPStatus()",
+		},
+	} {
+		src, err := f.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range want {
+			if !strings.Contains(src, v) {
+				t.Errorf("GenerateAuthMiddleware.Generate() %s does not contain %q", file, v)
+			}
+		}
+	}
+}
+
+func TestGenerateClient_GenerateWithAuth(t *testing.T) {
+	f := newAuthTestFs()
+	if err := NewGenerateAuthMiddleware("test", AuthJWT).Generate(); err != nil {
+		t.Fatalf("GenerateAuthMiddleware.Generate() error = %v", err)
+	}
+	if err := NewGenerateClient("test", "http", "", false, "", 0, false, ClientLanguageGo).Generate(); err != nil {
+		t.Fatalf("GenerateClient.Generate() error = %v", err)
+	}
+	src, err := f.ReadFile("test/client/http/http.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(src, "http.ClientBefore(auth.ContextToHTTP)") {
+		t.Error("GenerateClient.Generate() http client does not inject the auth token")
+	}
+}
@@ -2,6 +2,7 @@ package generator
 
 import (
 	"fmt"
+	"math"
 	"path"
 
 	"strings"
@@ -28,16 +29,29 @@ type GenerateClient struct {
 	serviceFilePath  string
 	serviceFile      *parser.File
 	serviceInterface parser.Interface
+	loadBalanced     bool
+	options          clientOptions
+	cli              bool
+	language         string
 }
 
-// NewGenerateClient returns a client generator.
-func NewGenerateClient(name string, transport, pbImportPath string) Gen {
+// NewGenerateClient returns a client generator, if `loadBalanced` is set the http
+// client also gets a constructor that load balances between the instances of a sd.Instancer.
+// The method endpoints are wrapped with the `breaker` circuit breaker if it is not empty
+// and with a rate limiter allowing `rateLimit` requests per second if it is positive.
+// If `cli` is set a command line client is generated as well. If `language` is
+// typescript a fetch client of the http transport is generated instead of the go client.
+func NewGenerateClient(name string, transport, pbImportPath string, loadBalanced bool, breaker string, rateLimit float64, cli bool, language string) Gen {
 	i := &GenerateClient{
 		name:            name,
 		interfaceName:   utils.ToCamelCase(name + "Service"),
 		destPath:        fmt.Sprintf(viper.GetString("gk_client_cmd_path_format"), utils.ToLowerSnakeCase(name)),
 		serviceDestPath: fmt.Sprintf(viper.GetString("gk_service_path_format"), utils.ToLowerSnakeCase(name)),
 		transport:       transport,
+		loadBalanced:    loadBalanced,
+		options:         clientOptions{breaker: breaker, rateLimit: rateLimit},
+		cli:             cli,
+		language:        language,
 	}
 	i.serviceFilePath = path.Join(i.serviceDestPath, viper.GetString("gk_service_file_name"))
 	i.filePath = path.Join(i.destPath, viper.GetString("gk_service_file_name"))
@@ -69,6 +83,9 @@ func (g *GenerateClient) Generate() (err error) {
 		return err
 	}
 	g.serviceFile, err = parser.NewFileParser().Parse([]byte(svcSrc))
+	if err != nil {
+		return err
+	}
 	if !g.serviceFound() {
 		return
 	}
@@ -77,15 +94,22 @@ func (g *GenerateClient) Generate() (err error) {
 		logrus.Error("The service has no suitable methods please implement the interface methods")
 		return
 	}
+	if g.language == ClientLanguageTypeScript {
+		return newGenerateTypeScriptClient(g.name, g.withoutStreamingMethods(), g.serviceFile).Generate()
+	}
+	err = newGeneratePropagation(g.name).Generate()
+	if err != nil {
+		return err
+	}
 	switch g.transport {
 	case "http":
-		cg := newGenerateHTTPClient(g.name, g.serviceInterface, g.serviceFile)
+		cg := newGenerateHTTPClient(g.name, g.loadBalanced, g.options, g.withoutStreamingMethods(), g.serviceFile)
 		err = cg.Generate()
 		if err != nil {
 			return err
 		}
 	case "grpc":
-		cg := newGenerateGRPCClient(g.name, g.pbImportPath, g.serviceInterface, g.serviceFile)
+		cg := newGenerateGRPCClient(g.name, g.pbImportPath, g.options, g.serviceInterface, g.serviceFile)
 		err = cg.Generate()
 		if err != nil {
 			return err
@@ -93,7 +117,9 @@ func (g *GenerateClient) Generate() (err error) {
 	default:
 		logrus.Warn("This transport type is not yet implemented")
 	}
-
+	if g.cli {
+		return newGenerateClientCLI(g.name, g.transport, g.serviceInterface, g.serviceFile).Generate()
+	}
 	return
 }
 func (g *GenerateClient) serviceFound() bool {
@@ -132,21 +158,42 @@ func (g *GenerateClient) removeBadMethods() {
 	g.serviceInterface.Methods = keepMethods
 }
 
+// withoutStreamingMethods returns the service interface without the methods that stream
+// over channels, only the grpc client knows how to call them.
+func (g *GenerateClient) withoutStreamingMethods() parser.Interface {
+	svc := g.serviceInterface
+	svc.Methods = []parser.Method{}
+	for _, v := range g.serviceInterface.Methods {
+		if isStreamingMethod(v) {
+			logrus.Warnf("The method '%s' is streaming, only the grpc client supports it so it will be ignored", v.Name)
+			continue
+		}
+		svc.Methods = append(svc.Methods, v)
+	}
+	return svc
+}
+
 type generateHTTPClient struct {
 	BaseGenerator
 	name             string
 	interfaceName    string
 	destPath         string
 	filePath         string
+	loadBalanced     bool
+	options          clientOptions
 	serviceInterface parser.Interface
 	serviceFile      *parser.File
+	// routes are the routes of the methods annotated with kit:http.
+	routes map[string]*httpRoute
 }
 
-func newGenerateHTTPClient(name string, serviceInterface parser.Interface, serviceFile *parser.File) Gen {
+func newGenerateHTTPClient(name string, loadBalanced bool, options clientOptions, serviceInterface parser.Interface, serviceFile *parser.File) Gen {
 	i := &generateHTTPClient{
 		name:             name,
 		interfaceName:    utils.ToCamelCase(name + "Service"),
 		destPath:         fmt.Sprintf(viper.GetString("gk_http_client_path_format"), utils.ToLowerSnakeCase(name)),
+		loadBalanced:     loadBalanced,
+		options:          options,
 		serviceInterface: serviceInterface,
 		serviceFile:      serviceFile,
 	}
@@ -157,6 +204,12 @@ func newGenerateHTTPClient(name string, serviceInterface parser.Interface, servi
 	return i
 }
 func (g *generateHTTPClient) Generate() (err error) {
+	g.routes = map[string]*httpRoute{}
+	for _, m := range g.serviceInterface.Methods {
+		if g.routes[m.Name], err = newHTTPRoute(m); err != nil {
+			return err
+		}
+	}
 	g.CreateFolderStructure(g.destPath)
 	endpointImport, err := utils.GetEndpointImportPath(g.name)
 	if err != nil {
@@ -166,6 +219,10 @@ func (g *generateHTTPClient) Generate() (err error) {
 	if err != nil {
 		return err
 	}
+	propagationImport, err := utils.GetPropagationImportPath(g.name)
+	if err != nil {
+		return err
+	}
 	g.code.appendMultilineComment([]string{
 		"New returns an AddService backed by an HTTP server living at the remote",
 		"instance. We expect instance to come from a service discovery system, so",
@@ -183,20 +240,12 @@ func (g *generateHTTPClient) Generate() (err error) {
 				"github.com/go-kit/kit/endpoint",
 				"Endpoint",
 			).Line().Block(
-				jen.Id(utils.ToLowerFirstCamelCase(m.Name)+"Endpoint").Op("=").Qual(
-					"github.com/go-kit/kit/transport/http",
-					"NewClient",
-				).Call(
-					jen.Lit("POST"),
-					jen.Id("copyURL").Call(
-						jen.Id("u"), jen.Lit(
-							"/"+strings.Replace(utils.ToLowerSnakeCase(m.Name), "_", "-", -1),
-						),
+				jen.Id(utils.ToLowerFirstCamelCase(m.Name)+"Endpoint").Op("=").Add(
+					g.options.wrap(
+						jen.Id("options").Dot(m.Name),
+						g.clientEndpoint(m, jen.Id("options").Dot(m.Name).Dot("Transport").Op("...")),
 					),
-					jen.Id("encodeHTTPGenericRequest"),
-					jen.Id(fmt.Sprintf("decode%sResponse", m.Name)),
-					jen.Id(fmt.Sprintf("options[\"%s\"]...", m.Name)),
-				).Dot("Endpoint").Call(),
+				),
 			).Line(),
 		)
 	}
@@ -233,7 +282,7 @@ func (g *generateHTTPClient) Generate() (err error) {
 		nil,
 		[]jen.Code{
 			jen.Id("instance").String(),
-			jen.Id("options").Map(jen.String()).Index().Qual("github.com/go-kit/kit/transport/http", "ClientOption"),
+			jen.Id("options").Id("ClientOptions"),
 		},
 		[]jen.Code{
 			jen.Qual(serviceImport, g.serviceInterface.Name),
@@ -263,8 +312,308 @@ func (g *generateHTTPClient) Generate() (err error) {
 		jen.Return(),
 	)
 	g.code.NewLine()
+	if g.loadBalanced {
+		g.generateLoadBalanced(endpointImport, serviceImport)
+	}
+	defaultTransport := []jen.Code{
+		jen.Qual("github.com/go-kit/kit/transport/http", "ClientBefore").Call(
+			jen.Qual(propagationImport, "ContextToHTTP"),
+		),
+	}
+	if b, err := authExists(g.fs, g.name); err != nil {
+		return err
+	} else if b {
+		authImport, err := utils.GetAuthImportPath(g.name)
+		if err != nil {
+			return err
+		}
+		defaultTransport = append(
+			defaultTransport,
+			jen.Qual("github.com/go-kit/kit/transport/http", "ClientBefore").Call(
+				jen.Qual(authImport, "ContextToHTTP"),
+			),
+		)
+	}
+	if b, err := tracingExists(g.fs, g.name); err != nil {
+		return err
+	} else if b {
+		tracingImport, err := utils.GetTracingImportPath(g.name)
+		if err != nil {
+			return err
+		}
+		defaultTransport = append(
+			defaultTransport,
+			jen.Qual(tracingImport, "HTTPClientTrace").Call(jen.Id("name")),
+		)
+	}
+	g.options.generate(
+		g.code,
+		g.serviceInterface.Methods,
+		jen.Qual("github.com/go-kit/kit/transport/http", "ClientOption"),
+		defaultTransport...,
+	)
 	return g.fs.WriteFile(g.filePath, g.srcFile.GoString(), false)
 }
+
+// clientEndpoint returns the http client endpoint of the method for the instance URL `u`.
+func (g *generateHTTPClient) clientEndpoint(m parser.Method, options jen.Code) *jen.Statement {
+	if route := g.routes[m.Name]; route != nil {
+		return jen.Qual("github.com/go-kit/kit/transport/http", "NewClient").Call(
+			jen.Lit(route.method),
+			jen.Id("copyURL").Call(jen.Id("u"), jen.Lit(route.path)),
+			jen.Id(fmt.Sprintf("encode%sRequest", m.Name)),
+			jen.Id(fmt.Sprintf("decode%sResponse", m.Name)),
+			options,
+		).Dot("Endpoint").Call()
+	}
+	return jen.Qual("github.com/go-kit/kit/transport/http", "NewClient").Call(
+		jen.Lit("POST"),
+		jen.Id("copyURL").Call(
+			jen.Id("u"), jen.Lit(
+				"/"+strings.Replace(utils.ToLowerSnakeCase(m.Name), "_", "-", -1),
+			),
+		),
+		jen.Id("encodeHTTPGenericRequest"),
+		jen.Id(fmt.Sprintf("decode%sResponse", m.Name)),
+		options,
+	).Dot("Endpoint").Call()
+}
+
+// generateLoadBalanced generates NewLoadBalanced, a client that finds the instances
+// through a sd.Instancer and load balances and retries every method, and the
+// helpers to create static, DNS SRV and Consul instancers.
+func (g *generateHTTPClient) generateLoadBalanced(endpointImport, serviceImport string) {
+	g.code.appendMultilineComment([]string{
+		fmt.Sprintf("NewLoadBalanced returns a %s backed by the HTTP instances found by the", g.serviceInterface.Name),
+		"instancer. Each method is load balanced round robin between the instances and",
+		"retried up to retryMax times as long as retryTimeout has not passed, the circuit",
+		"breaker and the rate limit of the method apply to all the instances.",
+	})
+	g.code.NewLine()
+	handles := []jen.Code{}
+	respS := jen.Dict{}
+	for _, m := range g.serviceInterface.Methods {
+		respS[jen.Id(m.Name+"Endpoint")] = jen.Id(utils.ToLowerFirstCamelCase(m.Name) + "Endpoint")
+		handles = append(
+			handles,
+			jen.Var().Id(utils.ToLowerFirstCamelCase(m.Name)+"Endpoint").Qual(
+				"github.com/go-kit/kit/endpoint",
+				"Endpoint",
+			).Line().Block(
+				jen.Id("endpointer").Op(":=").Qual("github.com/go-kit/kit/sd", "NewEndpointer").Call(
+					jen.Id("instancer"),
+					jen.Id(fmt.Sprintf("make%sFactory", m.Name)).Call(jen.Id("options").Dot(m.Name)),
+					jen.Id("logger"),
+				),
+				jen.Id(utils.ToLowerFirstCamelCase(m.Name)+"Endpoint").Op("=").Add(
+					g.options.wrap(
+						jen.Id("options").Dot(m.Name),
+						jen.Id("retry").Call(
+							jen.Id("retryMax"),
+							jen.Id("retryTimeout"),
+							jen.Qual("github.com/go-kit/kit/sd/lb", "NewRoundRobin").Call(jen.Id("endpointer")),
+						),
+					),
+				),
+			).Line(),
+		)
+	}
+	handles = append(
+		handles,
+		jen.Return(
+			jen.Qual(endpointImport, "Endpoints").Values(respS),
+			jen.Nil(),
+		),
+	)
+	g.code.appendFunction(
+		"NewLoadBalanced",
+		nil,
+		[]jen.Code{
+			jen.Id("instancer").Qual("github.com/go-kit/kit/sd", "Instancer"),
+			jen.Id("retryMax").Int(),
+			jen.Id("retryTimeout").Qual("time", "Duration"),
+			jen.Id("logger").Qual("github.com/go-kit/kit/log", "Logger"),
+			jen.Id("options").Id("ClientOptions"),
+		},
+		[]jen.Code{
+			jen.Qual(serviceImport, g.serviceInterface.Name),
+			jen.Error(),
+		},
+		"",
+		handles...,
+	)
+	g.code.NewLine()
+	for _, m := range g.serviceInterface.Methods {
+		g.code.appendMultilineComment([]string{
+			fmt.Sprintf("make%sFactory returns a sd.Factory that creates the %s endpoint of an instance.", m.Name, m.Name),
+		})
+		g.code.NewLine()
+		g.code.appendFunction(
+			fmt.Sprintf("make%sFactory", m.Name),
+			nil,
+			[]jen.Code{
+				jen.Id("options").Id("MethodOptions"),
+			},
+			[]jen.Code{},
+			"sd.Factory",
+			jen.Return(
+				jen.Func().Params(jen.Id("instance").String()).Params(
+					jen.Qual("github.com/go-kit/kit/endpoint", "Endpoint"),
+					jen.Qual("io", "Closer"),
+					jen.Error(),
+				).Block(
+					jen.List(jen.Id("u"), jen.Err()).Op(":=").Id("parseInstance").Call(jen.Id("instance")),
+					jen.If(jen.Err().Op("!=").Nil()).Block(
+						jen.Return(jen.Nil(), jen.Nil(), jen.Err()),
+					),
+					jen.Return(
+						g.clientEndpoint(m, jen.Id("options").Dot("Transport").Op("...")),
+						jen.Nil(),
+						jen.Nil(),
+					),
+				),
+			),
+		)
+		g.code.NewLine()
+	}
+	g.code.appendMultilineComment([]string{
+		"retry returns an endpoint that retries the calls of the balancer up to retryMax",
+		"times as long as retryTimeout has not passed. The errors caused by the request",
+		"(4xx) are not retried and the last error is returned as is so the callers get",
+		"the error of the service.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"retry",
+		nil,
+		[]jen.Code{
+			jen.Id("retryMax").Int(),
+			jen.Id("retryTimeout").Qual("time", "Duration"),
+			jen.Id("balancer").Qual("github.com/go-kit/kit/sd/lb", "Balancer"),
+		},
+		[]jen.Code{},
+		"endpoint.Endpoint",
+		jen.Id("e").Op(":=").Qual("github.com/go-kit/kit/sd/lb", "RetryWithCallback").Call(
+			jen.Id("retryTimeout"),
+			jen.Id("balancer"),
+			jen.Func().Params(jen.Id("n").Int(), jen.Err().Error()).Params(jen.Bool(), jen.Error()).Block(
+				jen.Return(
+					jen.Id("n").Op("<").Id("retryMax").Op("&&").Qual(serviceImport, "KindOf").Call(jen.Err()).Dot("HTTPStatus").Call().Op(">=").Qual("net/http", "StatusInternalServerError"),
+					jen.Nil(),
+				),
+			),
+		),
+		jen.Return(jen.Func().Params(
+			jen.Id("ctx").Qual("context", "Context"),
+			jen.Id("request").Interface(),
+		).Params(jen.Interface(), jen.Error()).Block(
+			jen.List(jen.Id("response"), jen.Err()).Op(":=").Id("e").Call(jen.Id("ctx"), jen.Id("request")),
+			jen.If(
+				jen.List(jen.Id("r"), jen.Id("ok")).Op(":=").Err().Assert(jen.Qual("github.com/go-kit/kit/sd/lb", "RetryError")),
+				jen.Id("ok"),
+			).Block(
+				jen.Return(jen.Nil(), jen.Id("r").Dot("Final")),
+			),
+			jen.Return(jen.Id("response"), jen.Err()),
+		)),
+	)
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"parseInstance returns the URL of the instance, instances without a scheme use http.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"parseInstance",
+		nil,
+		[]jen.Code{
+			jen.Id("instance").String(),
+		},
+		[]jen.Code{
+			jen.Id("*").Qual("net/url", "URL"),
+			jen.Error(),
+		},
+		"",
+		jen.If(
+			jen.Id("!").Qual("strings", "HasPrefix").Call(jen.Id("instance"), jen.Lit("http")),
+		).Block(
+			jen.Id("instance").Op("=").Lit("http://").Op("+").Id("instance"),
+		),
+		jen.Return(jen.Qual("net/url", "Parse").Call(jen.Id("instance"))),
+	)
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"NewStaticInstancer returns an instancer with a fixed list of instances.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"NewStaticInstancer",
+		nil,
+		[]jen.Code{
+			jen.Id("instances").Op("...").String(),
+		},
+		[]jen.Code{},
+		"sd.Instancer",
+		jen.Return(jen.Qual("github.com/go-kit/kit/sd", "FixedInstancer").Call(jen.Id("instances"))),
+	)
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"NewDNSSRVInstancer returns an instancer that looks up the instances in the",
+		"DNS SRV records of name every ttl.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"NewDNSSRVInstancer",
+		nil,
+		[]jen.Code{
+			jen.Id("name").String(),
+			jen.Id("ttl").Qual("time", "Duration"),
+			jen.Id("logger").Qual("github.com/go-kit/kit/log", "Logger"),
+		},
+		[]jen.Code{},
+		"sd.Instancer",
+		jen.Return(jen.Qual("github.com/go-kit/kit/sd/dnssrv", "NewInstancer").Call(
+			jen.Id("name"), jen.Id("ttl"), jen.Id("logger"),
+		)),
+	)
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"NewConsulInstancer returns an instancer with the healthy instances of the",
+		"service name registered in the Consul agent at addr.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"NewConsulInstancer",
+		nil,
+		[]jen.Code{
+			jen.List(jen.Id("addr"), jen.Id("name")).String(),
+			jen.Id("tags").Index().String(),
+			jen.Id("logger").Qual("github.com/go-kit/kit/log", "Logger"),
+		},
+		[]jen.Code{
+			jen.Qual("github.com/go-kit/kit/sd", "Instancer"),
+			jen.Error(),
+		},
+		"",
+		jen.Id("config").Op(":=").Qual("github.com/hashicorp/consul/api", "DefaultConfig").Call(),
+		jen.Id("config").Dot("Address").Op("=").Id("addr"),
+		jen.List(jen.Id("client"), jen.Err()).Op(":=").Qual("github.com/hashicorp/consul/api", "NewClient").Call(jen.Id("config")),
+		jen.If(jen.Err().Op("!=").Nil()).Block(
+			jen.Return(jen.Nil(), jen.Err()),
+		),
+		jen.Return(
+			jen.Qual("github.com/go-kit/kit/sd/consul", "NewInstancer").Call(
+				jen.Qual("github.com/go-kit/kit/sd/consul", "NewClient").Call(jen.Id("client")),
+				jen.Id("logger"),
+				jen.Id("name"),
+				jen.Id("tags"),
+				jen.True(),
+			),
+			jen.Nil(),
+		),
+	)
+	g.code.NewLine()
+}
+
 func (g *generateHTTPClient) generateDecodeEncodeMethods(endpointImport string) (err error) {
 	httpImport, err := utils.GetHTTPTransportImportPath(g.name)
 	if err != nil {
@@ -301,6 +650,40 @@ func (g *generateHTTPClient) generateDecodeEncodeMethods(endpointImport string)
 	)
 	g.code.NewLine()
 	for _, m := range g.serviceInterface.Methods {
+		route := g.routes[m.Name]
+		var status jen.Code = jen.Qual("net/http", "StatusOK")
+		decode := []jen.Code{
+			jen.Var().Id("resp").Qual(endpointImport, m.Name+"Response"),
+			jen.Err().Op(":=").Qual("encoding/json", "NewDecoder").Call(
+				jen.Id("r").Dot("Body"),
+			).Dot("Decode").Call(jen.Id("&resp")),
+			jen.Return(jen.Id("resp"), jen.Err()),
+		}
+		if route != nil {
+			g.code.appendMultilineComment([]string{
+				fmt.Sprintf("encode%sRequest is a transport/http.EncodeRequestFunc that sets the", m.Name),
+				"path parameters and the query of the request and JSON-encodes the body.",
+			})
+			g.code.NewLine()
+			g.code.appendFunction(
+				fmt.Sprintf("encode%sRequest", m.Name),
+				nil,
+				[]jen.Code{
+					jen.Id("ctx").Qual("context", "Context"),
+					jen.Id("r").Id("*").Qual("net/http", "Request"),
+					jen.Id("request").Interface(),
+				},
+				[]jen.Code{},
+				"error",
+				route.encodeRequest(jen.Qual(endpointImport, m.Name+"Request"))...,
+			)
+			g.code.NewLine()
+			g.code.NewLine()
+			status = route.statusCode()
+			if route.noContent() {
+				decode = []jen.Code{jen.Return(jen.Qual(endpointImport, m.Name+"Response").Values(), jen.Nil())}
+			}
+		}
 		g.code.appendMultilineComment([]string{
 			fmt.Sprintf("decode%sResponse is a transport/http.DecodeResponseFunc that decodes", m.Name),
 			"a JSON-encoded concat response from the HTTP response body. If the response",
@@ -320,16 +703,13 @@ func (g *generateHTTPClient) generateDecodeEncodeMethods(endpointImport string)
 				jen.Error(),
 			},
 			"",
-			jen.If(
-				jen.Id("r").Dot("StatusCode").Op("!=").Qual("net/http", "StatusOK"),
-			).Block(
-				jen.Return(jen.Nil(), jen.Qual(httpImport, "ErrorDecoder").Call(jen.Id("r"))),
-			),
-			jen.Var().Id("resp").Qual(endpointImport, m.Name+"Response"),
-			jen.Err().Op(":=").Qual("encoding/json", "NewDecoder").Call(
-				jen.Id("r").Dot("Body"),
-			).Dot("Decode").Call(jen.Id("&resp")),
-			jen.Return(jen.Id("resp"), jen.Err()),
+			append([]jen.Code{
+				jen.If(
+					jen.Id("r").Dot("StatusCode").Op("!=").Add(status),
+				).Block(
+					jen.Return(jen.Nil(), jen.Qual(httpImport, "ErrorDecoder").Call(jen.Id("r"))),
+				),
+			}, decode...)...,
 		)
 		g.code.NewLine()
 	}
@@ -343,13 +723,15 @@ type generateGRPCClient struct {
 	destPath         string
 	pbImportPath     string
 	filePath         string
+	options          clientOptions
 	serviceInterface parser.Interface
 	serviceFile      *parser.File
 }
 
-func newGenerateGRPCClient(name, pbImportPath string, serviceInterface parser.Interface, serviceFile *parser.File) Gen {
+func newGenerateGRPCClient(name, pbImportPath string, options clientOptions, serviceInterface parser.Interface, serviceFile *parser.File) Gen {
 	i := &generateGRPCClient{
 		name:             name,
+		options:          options,
 		interfaceName:    utils.ToCamelCase(name + "Service"),
 		destPath:         fmt.Sprintf(viper.GetString("gk_grpc_client_path_format"), utils.ToLowerSnakeCase(name)),
 		serviceInterface: serviceInterface,
@@ -376,6 +758,10 @@ func (g *generateGRPCClient) Generate() (err error) {
 	if err != nil {
 		return err
 	}
+	propagationImport, err := utils.GetPropagationImportPath(g.name)
+	if err != nil {
+		return err
+	}
 	g.code.appendMultilineComment([]string{
 		"New returns an AddService backed by a gRPC server at the other end",
 		" of the conn. The caller is responsible for constructing the conn, and",
@@ -388,24 +774,48 @@ func (g *generateGRPCClient) Generate() (err error) {
 	respS := jen.Dict{}
 	for _, m := range g.serviceInterface.Methods {
 		respS[jen.Id(m.Name+"Endpoint")] = jen.Id(utils.ToLowerFirstCamelCase(m.Name) + "Endpoint")
+		if isStreamingMethod(m) {
+			handles = append(
+				handles,
+				jen.Var().Id(utils.ToLowerFirstCamelCase(m.Name)+"Endpoint").Qual(
+					"github.com/go-kit/kit/endpoint",
+					"Endpoint",
+				).Line().Block(
+					jen.Id(utils.ToLowerFirstCamelCase(m.Name)+"Endpoint").Op("=").Add(
+						g.options.wrap(
+							jen.Id("options").Dot(m.Name),
+							jen.Id(fmt.Sprintf("make%sStreamEndpoint", m.Name)).Call(
+								jen.Qual(pbImport, fmt.Sprintf("New%sClient", utils.ToCamelCase(g.name))).Call(jen.Id("conn")),
+							),
+						),
+					),
+				).Line(),
+			)
+			continue
+		}
 		handles = append(
 			handles,
 			jen.Var().Id(utils.ToLowerFirstCamelCase(m.Name)+"Endpoint").Qual(
 				"github.com/go-kit/kit/endpoint",
 				"Endpoint",
 			).Line().Block(
-				jen.Id(utils.ToLowerFirstCamelCase(m.Name)+"Endpoint").Op("=").Qual(
-					"github.com/go-kit/kit/transport/grpc",
-					"NewClient",
-				).Call(
-					jen.Id("conn"),
-					jen.Lit("pb."+utils.ToCamelCase(g.name)),
-					jen.Lit(m.Name),
-					jen.Id(fmt.Sprintf("encode%sRequest", m.Name)),
-					jen.Id(fmt.Sprintf("decode%sResponse", m.Name)),
-					jen.Qual(pbImport, m.Name+"Reply").Block(),
-					jen.Id(fmt.Sprintf("options[\"%s\"]...", m.Name)),
-				).Dot("Endpoint").Call(),
+				jen.Id(utils.ToLowerFirstCamelCase(m.Name)+"Endpoint").Op("=").Add(
+					g.options.wrap(
+						jen.Id("options").Dot(m.Name),
+						jen.Id("translateErrors").Call(jen.Qual(
+							"github.com/go-kit/kit/transport/grpc",
+							"NewClient",
+						).Call(
+							jen.Id("conn"),
+							jen.Lit("pb."+utils.ToCamelCase(g.name)),
+							jen.Lit(m.Name),
+							jen.Id(fmt.Sprintf("encode%sRequest", m.Name)),
+							jen.Id(fmt.Sprintf("decode%sResponse", m.Name)),
+							jen.Qual(pbImport, m.Name+"Reply").Block(),
+							jen.Id("options").Dot(m.Name).Dot("Transport").Op("..."),
+						).Dot("Endpoint").Call()),
+					),
+				),
 			).Line(),
 		)
 	}
@@ -426,7 +836,7 @@ func (g *generateGRPCClient) Generate() (err error) {
 		nil,
 		[]jen.Code{
 			jen.Id("conn").Id("*").Qual("google.golang.org/grpc", "ClientConn"),
-			jen.Id("options").Map(jen.String()).Index().Qual("github.com/go-kit/kit/transport/grpc", "ClientOption"),
+			jen.Id("options").Id("ClientOptions"),
 		},
 		[]jen.Code{
 			jen.Qual(serviceImport, g.serviceInterface.Name),
@@ -435,63 +845,472 @@ func (g *generateGRPCClient) Generate() (err error) {
 		"",
 		body...,
 	)
-	err = g.generateDecodeEncodeMethods(endpointImport)
+	err = g.generateDecodeEncodeMethods(serviceImport, pbImport)
 	if err != nil {
 		return err
 	}
+	g.code.appendMultilineComment([]string{
+		"translateErrors translates the errors of the gRPC calls back to the service",
+		"errors, so the callers get the same kind of error the service returned.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"translateErrors",
+		nil,
+		[]jen.Code{
+			jen.Id("next").Qual("github.com/go-kit/kit/endpoint", "Endpoint"),
+		},
+		[]jen.Code{},
+		"endpoint.Endpoint",
+		jen.Return(jen.Func().Params(
+			jen.Id("ctx").Qual("context", "Context"),
+			jen.Id("request").Interface(),
+		).Params(jen.Interface(), jen.Error()).Block(
+			jen.List(jen.Id("response"), jen.Err()).Op(":=").Id("next").Call(jen.Id("ctx"), jen.Id("request")),
+			jen.If(jen.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Nil(), jen.Qual(serviceImport, "FromGRPCError").Call(jen.Err())),
+			),
+			jen.Return(jen.Id("response"), jen.Nil()),
+		)),
+	)
+	g.code.NewLine()
+	g.generateStreamEndpoints(endpointImport, serviceImport, pbImport)
+	defaultTransport := []jen.Code{
+		jen.Qual("github.com/go-kit/kit/transport/grpc", "ClientBefore").Call(
+			jen.Qual(propagationImport, "ContextToGRPC"),
+		),
+	}
+	if b, err := authExists(g.fs, g.name); err != nil {
+		return err
+	} else if b {
+		authImport, err := utils.GetAuthImportPath(g.name)
+		if err != nil {
+			return err
+		}
+		defaultTransport = append(
+			defaultTransport,
+			jen.Qual("github.com/go-kit/kit/transport/grpc", "ClientBefore").Call(
+				jen.Qual(authImport, "ContextToGRPC"),
+			),
+		)
+	}
+	if b, err := tracingExists(g.fs, g.name); err != nil {
+		return err
+	} else if b {
+		tracingImport, err := utils.GetTracingImportPath(g.name)
+		if err != nil {
+			return err
+		}
+		defaultTransport = append(
+			defaultTransport,
+			jen.Qual(tracingImport, "GRPCClientTrace").Call(jen.Id("name")),
+		)
+	}
+	g.options.generate(
+		g.code,
+		g.serviceInterface.Methods,
+		jen.Qual("github.com/go-kit/kit/transport/grpc", "ClientOption"),
+		defaultTransport...,
+	)
 	return g.fs.WriteFile(g.filePath, g.srcFile.GoString(), false)
 }
-func (g *generateGRPCClient) generateDecodeEncodeMethods(endpointImport string) (err error) {
+func (g *generateGRPCClient) generateDecodeEncodeMethods(serviceImport, pbImport string) (err error) {
 	for _, m := range g.serviceInterface.Methods {
+		in, streamsRequest := streamParameter(m)
+		out, streamsReturns := streamResult(m)
 		g.code.NewLine()
-		g.code.appendMultilineComment([]string{
-			fmt.Sprintf("encode%sRequest is a transport/grpc.EncodeRequestFunc that converts a", m.Name),
-			fmt.Sprintf(" user-domain %s request to a gRPC request.", m.Name),
-		})
+		if streamsRequest {
+			g.code.appendMultilineComment([]string{
+				fmt.Sprintf("encode%sStreamRequest converts each user-domain value of the streamed", m.Name),
+				fmt.Sprintf("channel to a gRPC request of the %s stream.", m.Name),
+			})
+			g.code.NewLine()
+			g.code.appendFunction(
+				fmt.Sprintf("encode%sStreamRequest", m.Name),
+				nil,
+				[]jen.Code{
+					jen.Id("_").Qual("context", "Context"),
+					jen.Id("v").Add(streamElemType(streamElem(m, in), serviceImport)),
+				},
+				[]jen.Code{
+					jen.Id("*").Qual(pbImport, m.Name+"Request"),
+					jen.Error(),
+				},
+				"",
+				jen.Return(
+					jen.Nil(), jen.Qual("errors", "New").Call(
+						jen.Lit(fmt.Sprintf("'%s' Encoder is not impelemented", utils.ToCamelCase(g.name))),
+					),
+				),
+			)
+		} else {
+			g.code.appendMultilineComment([]string{
+				fmt.Sprintf("encode%sRequest is a transport/grpc.EncodeRequestFunc that converts a", m.Name),
+				fmt.Sprintf(" user-domain %s request to a gRPC request.", m.Name),
+			})
+			g.code.NewLine()
+			g.code.appendFunction(
+				fmt.Sprintf("encode%sRequest", m.Name),
+				nil,
+				[]jen.Code{
+					jen.Id("_").Qual("context", "Context"),
+					jen.Id("request").Interface(),
+				},
+				[]jen.Code{
+					jen.Interface(),
+					jen.Error(),
+				},
+				"",
+				jen.Return(
+					jen.Nil(), jen.Qual("errors", "New").Call(
+						jen.Lit(fmt.Sprintf("'%s' Encoder is not impelemented", utils.ToCamelCase(g.name))),
+					),
+				),
+			)
+		}
 		g.code.NewLine()
-		g.code.appendFunction(
-			fmt.Sprintf("encode%sRequest", m.Name),
-			nil,
-			[]jen.Code{
-				jen.Id("_").Qual("context", "Context"),
-				jen.Id("request").Interface(),
-			},
-			[]jen.Code{
-				jen.Interface(),
-				jen.Error(),
-			},
-			"",
-			jen.Return(
-				jen.Nil(), jen.Qual("errors", "New").Call(
-					jen.Lit(fmt.Sprintf("'%s' Encoder is not impelemented", utils.ToCamelCase(g.name))),
+		if streamsReturns {
+			g.code.appendMultilineComment([]string{
+				fmt.Sprintf("decode%sStreamResponse converts each gRPC reply of the %s stream", m.Name, m.Name),
+				"to a user-domain value of the streamed channel.",
+			})
+			g.code.NewLine()
+			g.code.appendFunction(
+				fmt.Sprintf("decode%sStreamResponse", m.Name),
+				nil,
+				[]jen.Code{
+					jen.Id("_").Qual("context", "Context"),
+					jen.Id("reply").Id("*").Qual(pbImport, m.Name+"Reply"),
+				},
+				[]jen.Code{
+					jen.Id("v").Add(streamElemType(streamElem(m, out), serviceImport)),
+					jen.Err().Error(),
+				},
+				"",
+				jen.Return(
+					jen.Id("v"), jen.Qual("errors", "New").Call(
+						jen.Lit(fmt.Sprintf("'%s' Decoder is not impelemented", utils.ToCamelCase(g.name))),
+					),
 				),
-			),
-		)
+			)
+		} else {
+			g.code.appendMultilineComment([]string{
+				fmt.Sprintf("decode%sResponse is a transport/grpc.DecodeResponseFunc that converts", m.Name),
+				"a gRPC concat reply to a user-domain concat response.",
+			})
+			g.code.NewLine()
+			g.code.appendFunction(
+				fmt.Sprintf("decode%sResponse", m.Name),
+				nil,
+				[]jen.Code{
+					jen.Id("_").Qual("context", "Context"),
+					jen.Id("reply").Interface(),
+				},
+				[]jen.Code{
+					jen.Interface(),
+					jen.Error(),
+				},
+				"",
+				jen.Return(
+					jen.Nil(), jen.Qual("errors", "New").Call(
+						jen.Lit(fmt.Sprintf("'%s' Decoder is not impelemented", utils.ToCamelCase(g.name))),
+					),
+				),
+			)
+		}
+		g.code.NewLine()
+	}
+	return
+}
+
+// generateStreamEndpoints generates the client endpoints of the streaming rpcs, they adapt
+// the gRPC stream to the channels of the endpoint request and response.
+func (g *generateGRPCClient) generateStreamEndpoints(endpointImport, serviceImport, pbImport string) {
+	for _, m := range g.serviceInterface.Methods {
+		if !isStreamingMethod(m) {
+			continue
+		}
+		in, streamsRequest := streamParameter(m)
+		out, streamsReturns := streamResult(m)
+		body := []jen.Code{}
+		if streamsRequest {
+			body = append(
+				body,
+				jen.List(jen.Id("stream"), jen.Err()).Op(":=").Id("client").Dot(m.Name).Call(jen.Id("ctx")),
+				jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Nil(), jen.Err())),
+			)
+			body = append(
+				body,
+				jen.Id("in").Op(":=").Id("request").Assert(jen.Qual(endpointImport, m.Name+"Request")).Dot(
+					utils.ToCamelCase(in.Name),
+				),
+			)
+			send := func(failed ...jen.Code) jen.Code {
+				return jen.For(jen.Id("v").Op(":=").Range().Id("in")).Block(
+					jen.List(jen.Id("req"), jen.Err()).Op(":=").Id(fmt.Sprintf("encode%sStreamRequest", m.Name)).Call(
+						jen.Id("ctx"), jen.Id("v"),
+					),
+					jen.If(jen.Err().Op("==").Nil()).Block(
+						jen.Err().Op("=").Id("stream").Dot("Send").Call(jen.Id("req")),
+					),
+					jen.If(jen.Err().Op("!=").Nil()).Block(
+						append([]jen.Code{drainStream(jen.Id("in"))}, failed...)...,
+					),
+				)
+			}
+			if !streamsReturns {
+				body = append(
+					body,
+					send(jen.Return(jen.Nil(), jen.Err())),
+					jen.List(jen.Id("reply"), jen.Err()).Op(":=").Id("stream").Dot("CloseAndRecv").Call(),
+					jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Nil(), jen.Err())),
+					jen.Return(jen.Id(fmt.Sprintf("decode%sResponse", m.Name)).Call(jen.Id("ctx"), jen.Id("reply"))),
+				)
+			} else {
+				body = append(
+					body,
+					jen.Go().Func().Params().Block(
+						send(jen.Break()),
+						jen.Id("stream").Dot("CloseSend").Call(),
+					).Call(),
+				)
+			}
+		} else {
+			body = append(
+				body,
+				jen.List(jen.Id("req"), jen.Err()).Op(":=").Id(fmt.Sprintf("encode%sRequest", m.Name)).Call(
+					jen.Id("ctx"), jen.Id("request"),
+				),
+				jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Nil(), jen.Err())),
+				jen.List(jen.Id("stream"), jen.Err()).Op(":=").Id("client").Dot(m.Name).Call(
+					jen.Id("ctx"),
+					jen.Id("req").Assert(jen.Id("*").Qual(pbImport, m.Name+"Request")),
+				),
+				jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Nil(), jen.Err())),
+			)
+		}
+		if streamsReturns {
+			elem := streamElemType(streamElem(m, out), serviceImport)
+			body = append(
+				body,
+				jen.Id("out").Op(":=").Make(jen.Chan().Add(elem)),
+				jen.Go().Func().Params().Block(
+					jen.Defer().Close(jen.Id("out")),
+					jen.For().Block(
+						jen.List(jen.Id("reply"), jen.Err()).Op(":=").Id("stream").Dot("Recv").Call(),
+						jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return()),
+						jen.List(jen.Id("v"), jen.Err()).Op(":=").Id(fmt.Sprintf("decode%sStreamResponse", m.Name)).Call(
+							jen.Id("ctx"), jen.Id("reply"),
+						),
+						jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return()),
+						jen.Select().Block(
+							jen.Case(jen.Id("out").Op("<-").Id("v")),
+							jen.Case(jen.Op("<-").Id("ctx").Dot("Done").Call()).Block(jen.Return()),
+						),
+					),
+				).Call(),
+				jen.Return(
+					jen.Qual(endpointImport, m.Name+"Response").Values(jen.Dict{
+						jen.Id(utils.ToCamelCase(out.Name)): jen.Id("out"),
+					}),
+					jen.Nil(),
+				),
+			)
+		}
 		g.code.NewLine()
 		g.code.appendMultilineComment([]string{
-			fmt.Sprintf("decode%sResponse is a transport/grpc.DecodeResponseFunc that converts", m.Name),
-			"a gRPC concat reply to a user-domain concat response.",
+			fmt.Sprintf("make%sStreamEndpoint adapts the %s streaming rpc to an endpoint, stream", m.Name, m.Name),
+			"messages are converted with the stream encoders and decoders.",
 		})
 		g.code.NewLine()
 		g.code.appendFunction(
-			fmt.Sprintf("decode%sResponse", m.Name),
+			fmt.Sprintf("make%sStreamEndpoint", m.Name),
 			nil,
 			[]jen.Code{
-				jen.Id("_").Qual("context", "Context"),
-				jen.Id("reply").Interface(),
+				jen.Id("client").Qual(pbImport, utils.ToCamelCase(g.name)+"Client"),
 			},
-			[]jen.Code{
-				jen.Interface(),
-				jen.Error(),
-			},
-			"",
+			[]jen.Code{},
+			"endpoint.Endpoint",
 			jen.Return(
-				jen.Nil(), jen.Qual("errors", "New").Call(
-					jen.Lit(fmt.Sprintf("'%s' Decoder is not impelemented", utils.ToCamelCase(g.name))),
-				),
+				jen.Func().Params(
+					jen.Id("ctx").Qual("context", "Context"),
+					jen.Id("request").Interface(),
+				).Params(jen.Interface(), jen.Error()).Block(body...),
 			),
 		)
 		g.code.NewLine()
 	}
-	return
+}
+
+// Circuit breakers supported by the generated clients.
+const (
+	BreakerGobreaker = "gobreaker"
+	BreakerHystrix   = "hystrix"
+)
+
+// SupportedBreakers are the circuit breakers the generated clients can use.
+var SupportedBreakers = []string{BreakerGobreaker, BreakerHystrix}
+
+// clientOptions generates the ClientOptions struct of a client, it holds the
+// transport options, the circuit breaker and the rate limit of every method.
+type clientOptions struct {
+	breaker   string
+	rateLimit float64
+}
+
+// resilient returns true if the method endpoints are wrapped by middlewares.
+func (c clientOptions) resilient() bool {
+	return c.breaker != "" || c.rateLimit > 0
+}
+
+// wrap wraps the endpoint `e` with the middlewares of the method `options`.
+func (c clientOptions) wrap(options *jen.Statement, e jen.Code) jen.Code {
+	if !c.resilient() {
+		return e
+	}
+	return options.Dot("wrap").Call(e)
+}
+
+func (c clientOptions) generate(code *PartialGenerator, methods []parser.Method, transportOption jen.Code, defaultTransport ...jen.Code) {
+	fields := []jen.Code{
+		jen.Comment("Transport are the go-kit transport options of the method."),
+		jen.Id("Transport").Index().Add(transportOption),
+	}
+	defaults := jen.Dict{
+		jen.Id("Transport"): jen.Index().Add(transportOption).Values(defaultTransport...),
+	}
+	switch c.breaker {
+	case BreakerGobreaker:
+		fields = append(
+			fields,
+			jen.Comment("Breaker configures the circuit breaker, nil disables it."),
+			jen.Id("Breaker").Id("*").Qual("github.com/sony/gobreaker", "Settings"),
+		)
+		defaults[jen.Id("Breaker")] = jen.Op("&").Qual("github.com/sony/gobreaker", "Settings").Values(
+			jen.Dict{jen.Id("Name"): jen.Id("name")},
+		)
+	case BreakerHystrix:
+		fields = append(
+			fields,
+			jen.Comment("Breaker is the hystrix command of the circuit breaker, empty disables it."),
+			jen.Id("Breaker").String(),
+			jen.Comment("BreakerConfig configures the hystrix command."),
+			jen.Id("BreakerConfig").Qual("github.com/afex/hystrix-go/hystrix", "CommandConfig"),
+		)
+		defaults[jen.Id("Breaker")] = jen.Id("name")
+	}
+	if c.rateLimit > 0 {
+		fields = append(
+			fields,
+			jen.Comment("RateLimit is the number of requests per second allowed, 0 disables the limiter."),
+			jen.Id("RateLimit").Float64(),
+			jen.Comment("Burst is the number of requests allowed at once."),
+			jen.Id("Burst").Int(),
+		)
+		defaults[jen.Id("RateLimit")] = jen.Lit(c.rateLimit)
+		defaults[jen.Id("Burst")] = jen.Lit(int(math.Max(1, math.Ceil(c.rateLimit))))
+	}
+	code.appendMultilineComment([]string{
+		"MethodOptions configures the client endpoint of a method.",
+	})
+	code.NewLine()
+	code.appendStruct("MethodOptions", fields...)
+	code.NewLine()
+	code.appendMultilineComment([]string{
+		"ClientOptions configures the client endpoints of every method.",
+	})
+	code.NewLine()
+	optionFields := []jen.Code{}
+	defaultValues := jen.Dict{}
+	for _, m := range methods {
+		optionFields = append(optionFields, jen.Id(m.Name).Id("MethodOptions"))
+		defaultValues[jen.Id(m.Name)] = jen.Id("defaultMethodOptions").Call(jen.Lit(m.Name))
+	}
+	code.appendStruct("ClientOptions", optionFields...)
+	code.NewLine()
+	code.appendMultilineComment([]string{
+		"DefaultClientOptions returns the options the client was generated with.",
+	})
+	code.NewLine()
+	code.appendFunction(
+		"DefaultClientOptions",
+		nil,
+		[]jen.Code{},
+		[]jen.Code{},
+		"ClientOptions",
+		jen.Return(jen.Id("ClientOptions").Values(defaultValues)),
+	)
+	code.NewLine()
+	code.appendMultilineComment([]string{
+		"defaultMethodOptions returns the options of the method with the given name.",
+	})
+	code.NewLine()
+	code.appendFunction(
+		"defaultMethodOptions",
+		nil,
+		[]jen.Code{
+			jen.Id("name").String(),
+		},
+		[]jen.Code{},
+		"MethodOptions",
+		jen.Return(jen.Id("MethodOptions").Values(defaults)),
+	)
+	code.NewLine()
+	if !c.resilient() {
+		return
+	}
+	body := []jen.Code{}
+	if c.rateLimit > 0 {
+		body = append(
+			body,
+			jen.If(jen.Id("o").Dot("RateLimit").Op(">").Lit(0)).Block(
+				jen.Id("e").Op("=").Qual("github.com/go-kit/kit/ratelimit", "NewErroringLimiter").Call(
+					jen.Qual("golang.org/x/time/rate", "NewLimiter").Call(
+						jen.Qual("golang.org/x/time/rate", "Limit").Call(jen.Id("o").Dot("RateLimit")),
+						jen.Id("o").Dot("Burst"),
+					),
+				).Call(jen.Id("e")),
+			),
+		)
+	}
+	switch c.breaker {
+	case BreakerGobreaker:
+		body = append(
+			body,
+			jen.If(jen.Id("o").Dot("Breaker").Op("!=").Nil()).Block(
+				jen.Id("e").Op("=").Qual("github.com/go-kit/kit/circuitbreaker", "Gobreaker").Call(
+					jen.Qual("github.com/sony/gobreaker", "NewCircuitBreaker").Call(jen.Id("*o").Dot("Breaker")),
+				).Call(jen.Id("e")),
+			),
+		)
+	case BreakerHystrix:
+		body = append(
+			body,
+			jen.If(jen.Id("o").Dot("Breaker").Op("!=").Lit("")).Block(
+				jen.Qual("github.com/afex/hystrix-go/hystrix", "ConfigureCommand").Call(
+					jen.Id("o").Dot("Breaker"),
+					jen.Id("o").Dot("BreakerConfig"),
+				),
+				jen.Id("e").Op("=").Qual("github.com/go-kit/kit/circuitbreaker", "Hystrix").Call(
+					jen.Id("o").Dot("Breaker"),
+				).Call(jen.Id("e")),
+			),
+		)
+	}
+	body = append(body, jen.Return(jen.Id("e")))
+	code.appendMultilineComment([]string{
+		"wrap wraps the endpoint with the rate limiter and the circuit breaker of the method.",
+	})
+	code.NewLine()
+	code.appendFunction(
+		"wrap",
+		jen.Id("o").Id("MethodOptions"),
+		[]jen.Code{
+			jen.Id("e").Qual("github.com/go-kit/kit/endpoint", "Endpoint"),
+		},
+		[]jen.Code{},
+		"endpoint.Endpoint",
+		body...,
+	)
+	code.NewLine()
 }
@@ -0,0 +1,418 @@
+package generator
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/kujtimiihoxha/kit/fs"
+	"github.com/kujtimiihoxha/kit/parser"
+	"github.com/kujtimiihoxha/kit/utils"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// cliFlagFuncs are the cobra flag functions used for the parameter types that
+// have a flag of their own, parameters of other types are read as JSON.
+var cliFlagFuncs = map[string]string{
+	"string":        "StringVar",
+	"bool":          "BoolVar",
+	"int":           "IntVar",
+	"int32":         "Int32Var",
+	"int64":         "Int64Var",
+	"uint":          "UintVar",
+	"uint32":        "Uint32Var",
+	"uint64":        "Uint64Var",
+	"float32":       "Float32Var",
+	"float64":       "Float64Var",
+	"[]string":      "StringSliceVar",
+	"[]int":         "IntSliceVar",
+	"time.Duration": "DurationVar",
+}
+
+// cliReservedNames are the names used by the generated command code.
+var cliReservedNames = map[string]bool{
+	"cmd": true, "args": true, "svc": true, "closeFn": true, "err": true, "ctx": true,
+}
+
+type generateClientCLI struct {
+	BaseGenerator
+	name             string
+	transport        string
+	destPath         string
+	filePath         string
+	serviceInterface parser.Interface
+	serviceFile      *parser.File
+}
+
+func newGenerateClientCLI(name, transport string, serviceInterface parser.Interface, serviceFile *parser.File) Gen {
+	i := &generateClientCLI{
+		name:             name,
+		transport:        transport,
+		destPath:         fmt.Sprintf(viper.GetString("gk_client_cmd_path_format"), utils.ToLowerSnakeCase(name)),
+		serviceInterface: serviceInterface,
+		serviceFile:      serviceFile,
+	}
+	i.filePath = path.Join(i.destPath, viper.GetString("gk_client_cmd_file_name"))
+	i.srcFile = jen.NewFilePathName(i.destPath, "main")
+	i.InitPg()
+	i.fs = fs.Get()
+	return i
+}
+
+// Generate generates a cobra command with one subcommand per service method that
+// calls the service through the generated http or grpc client.
+func (g *generateClientCLI) Generate() (err error) {
+	g.CreateFolderStructure(g.destPath)
+	serviceImport, err := utils.GetServiceImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	transports := []string{}
+	for _, v := range []struct {
+		transport string
+		path      string
+	}{
+		{"http", path.Join(
+			fmt.Sprintf(viper.GetString("gk_http_client_path_format"), utils.ToLowerSnakeCase(g.name)),
+			viper.GetString("gk_http_client_file_name"),
+		)},
+		{"grpc", path.Join(
+			fmt.Sprintf(viper.GetString("gk_grpc_client_path_format"), utils.ToLowerSnakeCase(g.name)),
+			viper.GetString("gk_grpc_client_file_name"),
+		)},
+	} {
+		if b, err := g.fs.Exists(v.path); err != nil {
+			return err
+		} else if b {
+			transports = append(transports, v.transport)
+		}
+	}
+	if len(transports) == 0 {
+		logrus.Error("The service has no client, generate the http or grpc client first")
+		return nil
+	}
+	defaultTransport := transports[0]
+	for _, v := range transports {
+		if v == g.transport {
+			defaultTransport = v
+		}
+	}
+	defaultAddr := map[string]string{"http": "localhost:8081", "grpc": "localhost:8082"}
+	g.code.appendMultilineComment([]string{
+		fmt.Sprintf("rootCmd calls the methods of the %s service from the terminal.", g.name),
+	})
+	g.code.NewLine()
+	g.code.Raw().Var().Id("rootCmd").Op("=").Op("&").Qual("github.com/spf13/cobra", "Command").Values(jen.Dict{
+		jen.Id("Use"):   jen.Lit(strings.Replace(utils.ToLowerSnakeCase(g.name), "_", "-", -1) + "-client"),
+		jen.Id("Short"): jen.Lit(fmt.Sprintf("Call the methods of the %s service", g.name)),
+	}).Line()
+	g.code.NewLine()
+	g.code.Raw().Var().Defs(
+		jen.Id("transport").String(),
+		jen.Id("addr").String(),
+	).Line()
+	g.code.NewLine()
+	initBody := []jen.Code{
+		jen.Id("rootCmd").Dot("PersistentFlags").Call().Dot("StringVar").Call(
+			jen.Op("&").Id("transport"),
+			jen.Lit("transport"),
+			jen.Lit(defaultTransport),
+			jen.Lit("The transport used to call the service ("+strings.Join(transports, "|")+")"),
+		),
+		jen.Id("rootCmd").Dot("PersistentFlags").Call().Dot("StringVar").Call(
+			jen.Op("&").Id("addr"),
+			jen.Lit("addr"),
+			jen.Lit(defaultAddr[defaultTransport]),
+			jen.Lit("The address of the service"),
+		),
+	}
+	methods := []parser.Method{}
+	for _, m := range g.serviceInterface.Methods {
+		if isStreamingMethod(m) {
+			logrus.Warnf("The method '%s' is streaming and will not be added to the cli", m.Name)
+			continue
+		}
+		methods = append(methods, m)
+		initBody = append(
+			initBody,
+			jen.Id("rootCmd").Dot("AddCommand").Call(jen.Id(utils.ToLowerFirstCamelCase(m.Name)+"Cmd").Call()),
+		)
+	}
+	g.code.appendFunction("init", nil, []jen.Code{}, []jen.Code{}, "", initBody...)
+	g.code.NewLine()
+	g.code.appendFunction(
+		"main",
+		nil,
+		[]jen.Code{},
+		[]jen.Code{},
+		"",
+		jen.If(
+			jen.Err().Op(":=").Id("rootCmd").Dot("Execute").Call(),
+			jen.Err().Op("!=").Nil(),
+		).Block(
+			jen.Qual("os", "Exit").Call(jen.Lit(1)),
+		),
+	)
+	g.code.NewLine()
+	if err = g.generateNewService(serviceImport, transports); err != nil {
+		return err
+	}
+	for _, m := range methods {
+		g.generateMethodCmd(m, serviceImport)
+	}
+	g.code.appendMultilineComment([]string{
+		"printJSON prints the value as indented JSON.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"printJSON",
+		nil,
+		[]jen.Code{jen.Id("v").Interface()},
+		[]jen.Code{},
+		"error",
+		jen.List(jen.Id("b"), jen.Err()).Op(":=").Qual("encoding/json", "MarshalIndent").Call(
+			jen.Id("v"), jen.Lit(""), jen.Lit("  "),
+		),
+		jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Err())),
+		jen.Qual("fmt", "Println").Call(jen.String().Call(jen.Id("b"))),
+		jen.Return(jen.Nil()),
+	)
+	g.code.NewLine()
+	src, err := utils.GoImportsSource(g.destPath, g.srcFile.GoString())
+	if err != nil {
+		return err
+	}
+	return g.fs.WriteFile(g.filePath, src, false)
+}
+
+// generateNewService generates newService, it dials the service with the
+// transport selected by the `--transport` flag.
+func (g *generateClientCLI) generateNewService(serviceImport string, transports []string) error {
+	cases := []jen.Code{}
+	for _, v := range transports {
+		switch v {
+		case "http":
+			httpImport, err := utils.GetHTTPClientImportPath(g.name)
+			if err != nil {
+				return err
+			}
+			cases = append(
+				cases,
+				jen.Case(jen.Lit("http")).Block(
+					jen.List(jen.Id("svc"), jen.Err()).Op(":=").Qual(httpImport, "New").Call(
+						jen.Id("addr"),
+						jen.Qual(httpImport, "DefaultClientOptions").Call(),
+					),
+					jen.Return(jen.Id("svc"), jen.Func().Params().Block(), jen.Err()),
+				),
+			)
+		case "grpc":
+			grpcImport, err := utils.GetGRPCClientImportPath(g.name)
+			if err != nil {
+				return err
+			}
+			cases = append(
+				cases,
+				jen.Case(jen.Lit("grpc")).Block(
+					jen.List(jen.Id("conn"), jen.Err()).Op(":=").Qual("google.golang.org/grpc", "Dial").Call(
+						jen.Id("addr"),
+						jen.Qual("google.golang.org/grpc", "WithInsecure").Call(),
+					),
+					jen.If(jen.Err().Op("!=").Nil()).Block(
+						jen.Return(jen.Nil(), jen.Nil(), jen.Err()),
+					),
+					jen.List(jen.Id("svc"), jen.Err()).Op(":=").Qual(grpcImport, "New").Call(
+						jen.Id("conn"),
+						jen.Qual(grpcImport, "DefaultClientOptions").Call(),
+					),
+					jen.Return(
+						jen.Id("svc"),
+						jen.Func().Params().Block(jen.Id("conn").Dot("Close").Call()),
+						jen.Err(),
+					),
+				),
+			)
+		}
+	}
+	g.code.appendMultilineComment([]string{
+		"newService dials the service with the selected transport, the returned",
+		"function closes the connection.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"newService",
+		nil,
+		[]jen.Code{},
+		[]jen.Code{
+			jen.Qual(serviceImport, g.serviceInterface.Name),
+			jen.Func().Params(),
+			jen.Error(),
+		},
+		"",
+		jen.Switch(jen.Id("transport")).Block(cases...),
+		jen.Return(
+			jen.Nil(),
+			jen.Nil(),
+			jen.Qual("fmt", "Errorf").Call(jen.Lit("transport `%s` not supported"), jen.Id("transport")),
+		),
+	)
+	g.code.NewLine()
+	return nil
+}
+
+// generateMethodCmd generates the subcommand of the method, parameters of basic
+// types get a flag of their own and the others are read from a JSON flag.
+func (g *generateClientCLI) generateMethodCmd(m parser.Method, serviceImport string) {
+	cmdName := strings.Replace(utils.ToLowerSnakeCase(m.Name), "_", "-", -1)
+	vars := []jen.Code{}
+	flags := []jen.Code{}
+	decode := []jen.Code{}
+	callParams := []jen.Code{}
+	for _, p := range m.Parameters {
+		if p.Type == "context.Context" {
+			callParams = append(callParams, jen.Qual("context", "Background").Call())
+			continue
+		}
+		name := cliVarName(p.Name)
+		flagName := strings.Replace(utils.ToLowerSnakeCase(p.Name), "_", "-", -1)
+		tp := p.Type
+		variadic := strings.HasPrefix(tp, "...")
+		if variadic {
+			tp = "[]" + strings.TrimPrefix(tp, "...")
+		}
+		call := jen.Id(name)
+		if variadic {
+			call = jen.Id(name).Op("...")
+		}
+		callParams = append(callParams, call)
+		if fn, ok := cliFlagFuncs[tp]; ok {
+			vars = append(vars, jen.Var().Id(name).Add(g.typeCode(tp, serviceImport)))
+			flags = append(flags, jen.Id("cmd").Dot("Flags").Call().Dot(fn).Call(
+				jen.Op("&").Id(name),
+				jen.Lit(flagName),
+				cliZeroValue(tp),
+				jen.Lit(fmt.Sprintf("The %s parameter of %s", p.Name, m.Name)),
+			))
+			continue
+		}
+		vars = append(
+			vars,
+			jen.Var().Id(name).Add(g.typeCode(tp, serviceImport)),
+			jen.Var().Id(name+"JSON").String(),
+		)
+		flags = append(flags, jen.Id("cmd").Dot("Flags").Call().Dot("StringVar").Call(
+			jen.Op("&").Id(name+"JSON"),
+			jen.Lit(flagName),
+			jen.Lit(""),
+			jen.Lit(fmt.Sprintf("The %s parameter of %s as JSON", p.Name, m.Name)),
+		))
+		decode = append(
+			decode,
+			jen.If(jen.Id(name+"JSON").Op("!=").Lit("")).Block(
+				jen.If(
+					jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(
+						jen.Index().Byte().Call(jen.Id(name+"JSON")),
+						jen.Op("&").Id(name),
+					),
+					jen.Err().Op("!=").Nil(),
+				).Block(
+					jen.Return(jen.Qual("fmt", "Errorf").Call(
+						jen.Lit(fmt.Sprintf("invalid --%s: %%v", flagName)),
+						jen.Err(),
+					)),
+				),
+			),
+		)
+	}
+	results := []jen.Code{}
+	output := jen.Dict{}
+	for _, r := range m.Results {
+		if r.Type == "error" {
+			results = append(results, jen.Err())
+			continue
+		}
+		name := cliVarName(r.Name)
+		results = append(results, jen.Id(name))
+		output[jen.Lit(utils.ToLowerSnakeCase(r.Name))] = jen.Id(name)
+	}
+	assign := ":="
+	if len(output) == 0 {
+		assign = "="
+	}
+	run := append(
+		decode,
+		jen.List(jen.Id("svc"), jen.Id("closeFn"), jen.Err()).Op(":=").Id("newService").Call(),
+		jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Err())),
+		jen.Defer().Id("closeFn").Call(),
+		jen.List(results...).Op(assign).Id("svc").Dot(m.Name).Call(callParams...),
+	)
+	if len(output) == len(results) {
+		run = append(run, jen.Return(jen.Id("printJSON").Call(jen.Map(jen.String()).Interface().Values(output))))
+	} else {
+		run = append(
+			run,
+			jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Err())),
+			jen.Return(jen.Id("printJSON").Call(jen.Map(jen.String()).Interface().Values(output))),
+		)
+	}
+	body := append(
+		vars,
+		jen.Id("cmd").Op(":=").Op("&").Qual("github.com/spf13/cobra", "Command").Values(jen.Dict{
+			jen.Id("Use"):   jen.Lit(cmdName),
+			jen.Id("Short"): jen.Lit(fmt.Sprintf("Call the %s method", m.Name)),
+			jen.Id("RunE"): jen.Func().Params(
+				jen.Id("cmd").Op("*").Qual("github.com/spf13/cobra", "Command"),
+				jen.Id("args").Index().String(),
+			).Error().Block(run...),
+		}),
+	)
+	body = append(body, flags...)
+	body = append(body, jen.Return(jen.Id("cmd")))
+	g.code.appendMultilineComment([]string{
+		fmt.Sprintf("%sCmd returns the command that calls %s.", utils.ToLowerFirstCamelCase(m.Name), m.Name),
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		utils.ToLowerFirstCamelCase(m.Name)+"Cmd",
+		nil,
+		[]jen.Code{},
+		[]jen.Code{},
+		"*cobra.Command",
+		body...,
+	)
+	g.code.NewLine()
+}
+
+// typeCode returns the type `tp` qualified with the package it comes from.
+func (g *generateClientCLI) typeCode(tp, serviceImport string) jen.Code {
+	if pth := g.EnsureThatWeUseQualifierIfNeeded(tp, g.serviceFile.Imports); pth != "" {
+		return jen.Qual(pth, strings.Split(tp, ".")[1])
+	}
+	if q := serviceQualifiedType(tp); q != tp {
+		return jen.Qual(serviceImport, strings.TrimPrefix(q, "service."))
+	}
+	return jen.Id(tp)
+}
+
+func cliVarName(name string) string {
+	name = utils.ToLowerFirstCamelCase(name)
+	if cliReservedNames[name] {
+		return name + "Arg"
+	}
+	return name
+}
+
+func cliZeroValue(tp string) jen.Code {
+	switch {
+	case tp == "string":
+		return jen.Lit("")
+	case tp == "bool":
+		return jen.False()
+	case strings.HasPrefix(tp, "[]"):
+		return jen.Nil()
+	default:
+		return jen.Lit(0)
+	}
+}
@@ -1 +1,294 @@
 package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kujtimiihoxha/kit/fs"
+	"github.com/kujtimiihoxha/kit/utils"
+)
+
+func newClientTestFs() *fs.KitFs {
+	setDefaults()
+	f := fs.NewDefaultFs("")
+	f.MkdirAll("test/pkg/service")
+	f.WriteFile("test/pkg/service/service.go", `package service
+import "context"
+type TestService interface{
+		Foo(ctx context.Context, a string)(b int, err error)
+		Watch(ctx context.Context, a string)(b <-chan int, err error)
+}`, true)
+	f.MkdirAll("test/pkg/db/postgres")
+	f.WriteFile("test/pkg/db/postgres/db.go", "package postgres", true)
+	return f
+}
+
+func TestGenerateClient_GenerateHTTP(t *testing.T) {
+	tests := []struct {
+		name         string
+		loadBalanced bool
+		breaker      string
+		rateLimit    float64
+		want         []string
+		notWant      []string
+	}{
+		{
+			name: "Test if the http client skips streaming methods",
+			want: []string{
+				"func New(instance string, options ClientOptions)",
+				"options.Foo.Transport...",
+				"type ClientOptions struct",
+				"decodeFooResponse",
+				"Transport: []http.ClientOption{http.ClientBefore(propagation.ContextToHTTP)}",
+			},
+			notWant: []string{"NewLoadBalanced", "decodeWatchResponse", "wrap("},
+		},
+		{
+			name:         "Test if the load balanced http client is generated",
+			loadBalanced: true,
+			want: []string{
+				"func NewLoadBalanced(instancer sd.Instancer, retryMax int, retryTimeout time.Duration",
+				"fooEndpoint = retry(retryMax, retryTimeout, lb.NewRoundRobin(endpointer))",
+				"func makeFooFactory(options MethodOptions) sd.Factory",
+				// The errors of the requests are not retried and the last error is not wrapped.
+				"lb.RetryWithCallback(retryTimeout, balancer, func(n int, err error) (bool, error) {",
+				"return n < retryMax && service.KindOf(err).HTTPStatus() >= http1.StatusInternalServerError, nil",
+				"return nil, r.Final",
+				"func NewStaticInstancer(",
+				"func NewDNSSRVInstancer(",
+				"func NewConsulInstancer(",
+			},
+		},
+		{
+			name:      "Test if the endpoints are wrapped with the circuit breaker and rate limiter",
+			breaker:   BreakerGobreaker,
+			rateLimit: 10,
+			want: []string{
+				"fooEndpoint = options.Foo.wrap(http.NewClient(",
+				"Breaker *gobreaker.Settings",
+				"Breaker:   &gobreaker.Settings{Name: name}",
+				"RateLimit: 10",
+				"func (o MethodOptions) wrap(e endpoint.Endpoint) endpoint.Endpoint",
+				"circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(*o.Breaker))(e)",
+				"ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Limit(o.RateLimit), o.Burst))(e)",
+			},
+		},
+		{
+			name:         "Test if the load balanced endpoints are wrapped instead of the endpoints of the instances",
+			loadBalanced: true,
+			breaker:      BreakerGobreaker,
+			rateLimit:    10,
+			want: []string{
+				"fooEndpoint = options.Foo.wrap(retry(retryMax, retryTimeout, lb.NewRoundRobin(endpointer)))",
+				"return http.NewClient(\"POST\", copyURL(u, \"/foo\"), encodeHTTPGenericRequest, decodeFooResponse, options.Transport...).Endpoint(), nil, nil",
+			},
+			notWant: []string{"options.wrap("},
+		},
+		{
+			name:    "Test if the endpoints can use a hystrix circuit breaker",
+			breaker: BreakerHystrix,
+			want: []string{
+				"BreakerConfig hystrix.CommandConfig",
+				"hystrix.ConfigureCommand(o.Breaker, o.BreakerConfig)",
+				"circuitbreaker.Hystrix(o.Breaker)(e)",
+			},
+			notWant: []string{"RateLimit"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newClientTestFs()
+			if err := NewGenerateClient("test", "http", "", tt.loadBalanced, tt.breaker, tt.rateLimit, false, ClientLanguageGo).Generate(); err != nil {
+				t.Fatalf("GenerateClient.Generate() error = %v", err)
+			}
+			src, err := f.ReadFile("test/client/http/http.go")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, v := range tt.want {
+				if !strings.Contains(src, v) {
+					t.Errorf("GenerateClient.Generate() http client does not contain %q", v)
+				}
+			}
+			for _, v := range tt.notWant {
+				if strings.Contains(src, v) {
+					t.Errorf("GenerateClient.Generate() http client should not contain %q", v)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateClient_GenerateCLI(t *testing.T) {
+	f := newClientTestFs()
+	if err := NewGenerateClient("test", "http", "", false, "", 0, true, ClientLanguageGo).Generate(); err != nil {
+		t.Fatalf("GenerateClient.Generate() error = %v", err)
+	}
+	src, err := f.ReadFile("test/cmd/client/main.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		"package main",
+		"rootCmd.AddCommand(fooCmd())",
+		"func newService() (service.TestService, func(), error)",
+		"http.New(addr, http.DefaultClientOptions())",
+		"func fooCmd() *cobra.Command",
+		`cmd.Flags().StringVar(&a, "a", "", "The a parameter of Foo")`,
+		`printJSON(map[string]interface{}{"b": b})`,
+	} {
+		if !strings.Contains(src, v) {
+			t.Errorf("GenerateClient.Generate() cli does not contain %q", v)
+		}
+	}
+	if strings.Contains(src, "watchCmd") {
+		t.Error("GenerateClient.Generate() cli should not contain the streaming method")
+	}
+}
+
+func TestGenerateClient_GenerateGRPCStreaming(t *testing.T) {
+	f := newClientTestFs()
+	f.WriteFile("test/pkg/service/service.go", `package service
+import "context"
+type Item struct{ Name string }
+type Events <-chan Item
+type TestService interface{
+		Foo(ctx context.Context, a string)(b int, err error)
+		Watch(ctx context.Context, a string)(items <-chan Item, err error)
+		Upload(ctx context.Context, items <-chan Item)(n int, err error)
+		Chat(ctx context.Context, in <-chan string)(out <-chan string, err error)
+		// kit:stream events:"Item"
+		Subscribe(ctx context.Context, topic string)(events Events, err error)
+}`, true)
+	if err := NewGenerateClient("test", "grpc", "", false, "", 0, false, ClientLanguageGo).Generate(); err != nil {
+		t.Fatalf("GenerateClient.Generate() error = %v", err)
+	}
+	src, err := f.ReadFile("test/client/grpc/grpc.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		`grpc1.NewClient(conn, "pb.Test", "Foo", encodeFooRequest, decodeFooResponse`,
+		"watchEndpoint = makeWatchStreamEndpoint(pb.NewTestClient(conn))",
+		// Server streaming encodes the request like unary rpcs and decodes each item.
+		"func encodeWatchRequest(_ context.Context, request interface{}) (interface{}, error)",
+		"func decodeWatchStreamResponse(_ context.Context, reply *pb.WatchReply) (v service.Item, err error)",
+		"stream, err := client.Watch(ctx, req.(*pb.WatchRequest))",
+		"v, err := decodeWatchStreamResponse(ctx, reply)",
+		"case out <- v:",
+		"return endpoint1.WatchResponse{Items: out}, nil",
+		// Client streaming encodes each item and decodes the reply like unary rpcs.
+		"func encodeUploadStreamRequest(_ context.Context, v service.Item) (*pb.UploadRequest, error)",
+		"func decodeUploadResponse(_ context.Context, reply interface{}) (interface{}, error)",
+		"req, err := encodeUploadStreamRequest(ctx, v)",
+		"stream.Send(req)",
+		"reply, err := stream.CloseAndRecv()",
+		"return decodeUploadResponse(ctx, reply)",
+		// Bidirectional streaming only uses the stream codecs.
+		"func encodeChatStreamRequest(_ context.Context, v string) (*pb.ChatRequest, error)",
+		"func decodeChatStreamResponse(_ context.Context, reply *pb.ChatReply) (v string, err error)",
+		"stream.CloseSend()",
+		// Annotated streams are streamed like channels.
+		"subscribeEndpoint = makeSubscribeStreamEndpoint(pb.NewTestClient(conn))",
+		"func decodeSubscribeStreamResponse(_ context.Context, reply *pb.SubscribeReply) (v service.Item, err error)",
+		"return endpoint1.SubscribeResponse{Events: out}, nil",
+		// A failed stream drains the channel of the request.
+		"in := request.(endpoint1.UploadRequest).Items",
+		"err = stream.Send(req)",
+		"for range in {",
+	} {
+		if !strings.Contains(src, v) {
+			t.Errorf("GenerateClient.Generate() grpc client does not contain %q", v)
+		}
+	}
+	for _, v := range []string{
+		"decodeWatchResponse",
+		"encodeUploadRequest",
+		"encodeChatRequest",
+		"decodeChatResponse",
+		".(service.Item)",
+		".(string)",
+	} {
+		if strings.Contains(src, v) {
+			t.Errorf("GenerateClient.Generate() grpc client should not contain %q", v)
+		}
+	}
+}
+
+func TestGenerateClient_GenerateTypeScript(t *testing.T) {
+	f := newClientTestFs()
+	f.WriteFile("test/pkg/service/service.go", "package service\n"+
+		"import \"context\"\n"+
+		"type Todo struct{\n\tTitle string `json:\"title\"`\n\tDone *bool `json:\"done,omitempty\"`\n\tTags []string\n\tsecret string\n}\n"+
+		"type TestService interface{\n"+
+		"\tAddTodo(ctx context.Context, todo Todo, labels ...string)(todos []Todo, err error)\n"+
+		"\tWatch(ctx context.Context, a string)(b <-chan int, err error)\n"+
+		"}", true)
+	if err := NewGenerateClient("test", "http", "", false, "", 0, false, ClientLanguageTypeScript).Generate(); err != nil {
+		t.Fatalf("GenerateClient.Generate() error = %v", err)
+	}
+	src, err := f.ReadFile("test/client/typescript/client.ts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		"export interface Todo {\n  title: string;\n  done?: boolean | null;\n  Tags: string[];\n}",
+		"export interface AddTodoRequest {\n  todo: Todo;\n  labels: string[];\n}",
+		"export interface AddTodoResponse {\n  todos: Todo[];\n}",
+		"export interface ErrorWrapper {\n  error: string;\n  // kind is the kind of the service error, e.x \"not_found\".\n  kind?: string;\n}",
+		"readonly kind: string;",
+		"throw new ServiceError(response.status, message, kind);",
+		"async addTodo(request: AddTodoRequest): Promise<AddTodoResponse> {",
+		`this.call<AddTodoResponse>("/add-todo", request)`,
+		"export class TestClient {",
+	} {
+		if !strings.Contains(src, v) {
+			t.Errorf("GenerateClient.Generate() typescript client does not contain %q", v)
+		}
+	}
+	if strings.Contains(src, "Watch") {
+		t.Error("GenerateClient.Generate() typescript client should not contain the streaming method")
+	}
+}
+
+func TestGenerateClient_GenerateTypeScriptModels(t *testing.T) {
+	f := newClientTestFs()
+	modelImport, err := utils.GetModelImportPath("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteFile("test/pkg/service/service.go", "package service\n"+
+		"import (\n\t\"context\"\n\n\t\"github.com/google/uuid\"\n\tmodel \""+modelImport+"\"\n)\n"+
+		"type TestService interface{\n"+
+		"\tGetTodo(ctx context.Context, id uuid.UUID)(todo model.Todo, err error)\n"+
+		"\tListTodos(ctx context.Context)(todos []model.Todo, err error)\n"+
+		"}", true)
+	f.MkdirAll("test/pkg/model")
+	f.WriteFile("test/pkg/model/base.go", "package model\n"+
+		"import (\n\t\"time\"\n\n\t\"github.com/google/uuid\"\n)\n"+
+		"type BaseModel struct{\n\tID uuid.UUID `json:\"id\"`\n\tCreatedAt time.Time\n\tDeletedAt *time.Time `json:\"deleted_at\"`\n}\n", true)
+	f.WriteFile("test/pkg/model/todo.go", "package model\n"+
+		"type Todo struct{\n\tBaseModel\n\tTitle string `json:\"title\"`\n}\n"+
+		"type TodoFilter struct{\n\tTitle *string\n}\n", true)
+	if err := NewGenerateClient("test", "http", "", false, "", 0, false, ClientLanguageTypeScript).Generate(); err != nil {
+		t.Fatalf("GenerateClient.Generate() error = %v", err)
+	}
+	src, err := f.ReadFile("test/client/typescript/client.ts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		"export interface Todo extends BaseModel {\n  title: string;\n}",
+		"export interface BaseModel {\n  id: string;\n  CreatedAt: string;\n  deleted_at?: string | null;\n}",
+		"export interface GetTodoRequest {\n  id: string;\n}",
+		"export interface GetTodoResponse {\n  todo: Todo;\n}",
+		"export interface ListTodosResponse {\n  todos: Todo[];\n}",
+	} {
+		if !strings.Contains(src, v) {
+			t.Errorf("GenerateClient.Generate() typescript client does not contain %q, got %s", v, src)
+		}
+	}
+	if strings.Contains(src, "any") || strings.Contains(src, "TodoFilter") || strings.Count(src, "export interface Todo ") != 1 {
+		t.Errorf("GenerateClient.Generate() typescript client should only type the used models once, got %s", src)
+	}
+}
@@ -0,0 +1,464 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/kujtimiihoxha/kit/fs"
+	"github.com/kujtimiihoxha/kit/parser"
+	"github.com/kujtimiihoxha/kit/utils"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+)
+
+// The languages the client can be generated in.
+const (
+	ClientLanguageGo         = "go"
+	ClientLanguageTypeScript = "typescript"
+)
+
+// SupportedClientLanguages are the languages the client can be generated in.
+var SupportedClientLanguages = []string{ClientLanguageGo, ClientLanguageTypeScript}
+
+// tsBasicTypes maps the go basic types to their TypeScript type.
+var tsBasicTypes = map[string]string{
+	"string":        "string",
+	"bool":          "boolean",
+	"int":           "number",
+	"int8":          "number",
+	"int16":         "number",
+	"int32":         "number",
+	"int64":         "number",
+	"uint":          "number",
+	"uint8":         "number",
+	"uint16":        "number",
+	"uint32":        "number",
+	"uint64":        "number",
+	"float32":       "number",
+	"float64":       "number",
+	"byte":          "number",
+	"rune":          "number",
+	"interface{}":   "any",
+	"time.Time":     "string",
+	"time.Duration": "number",
+	"uuid.UUID":     "string",
+}
+
+type generateTypeScriptClient struct {
+	BaseGenerator
+	name             string
+	destPath         string
+	filePath         string
+	serviceInterface parser.Interface
+	serviceFile      *parser.File
+	// structs are the TypeScript names of the structs of the service file.
+	structs map[string]string
+	// models are the structs of the model package the service file imports as modelAlias.
+	models     map[string]parser.Struct
+	modelAlias string
+	// modelNames are the TypeScript names of the models used by the service, in
+	// the order of usedModels.
+	modelNames map[string]string
+	usedModels []string
+	// scope is the package the types are resolved in, the service file if it is empty.
+	scope string
+	taken map[string]bool
+}
+
+// tsInterface is a TypeScript interface generated from a go struct.
+type tsInterface struct {
+	name    string
+	extends []string
+	fields  []string
+}
+
+func newGenerateTypeScriptClient(name string, serviceInterface parser.Interface, serviceFile *parser.File) Gen {
+	i := &generateTypeScriptClient{
+		name:             name,
+		destPath:         fmt.Sprintf(viper.GetString("gk_ts_client_path_format"), utils.ToLowerSnakeCase(name)),
+		serviceInterface: serviceInterface,
+		serviceFile:      serviceFile,
+		structs:          map[string]string{},
+		models:           map[string]parser.Struct{},
+		modelNames:       map[string]string{},
+		taken:            map[string]bool{},
+	}
+	i.filePath = path.Join(i.destPath, viper.GetString("gk_ts_client_file_name"))
+	i.fs = fs.Get()
+	return i
+}
+
+// Generate generates the TypeScript interfaces of the request and response
+// structs and a fetch client that calls the routes of the http transport.
+func (g *generateTypeScriptClient) Generate() (err error) {
+	if err = g.CreateFolderStructure(g.destPath); err != nil {
+		return err
+	}
+	clientName := utils.ToCamelCase(g.name) + "Client"
+	for _, v := range []string{"ErrorWrapper", "ServiceError", "ClientOptions", clientName} {
+		g.taken[v] = true
+	}
+	structs := []parser.Struct{}
+	for _, v := range g.serviceFile.Structures {
+		if isExported(v.Name) {
+			g.structs[v.Name] = v.Name
+			g.taken[v.Name] = true
+			structs = append(structs, v)
+		}
+	}
+	for _, m := range g.serviceInterface.Methods {
+		g.taken[m.Name+"Request"] = true
+		g.taken[m.Name+"Response"] = true
+	}
+	if err = g.loadModels(); err != nil {
+		return err
+	}
+	interfaces := []tsInterface{}
+	for _, v := range structs {
+		interfaces = append(interfaces, g.structInterface(v.Name, v))
+	}
+	methods := []tsInterface{}
+	for _, m := range g.serviceInterface.Methods {
+		req, res := tsInterface{name: m.Name + "Request"}, tsInterface{name: m.Name + "Response"}
+		for _, p := range m.Parameters {
+			if p.Type == "context.Context" {
+				continue
+			}
+			req.fields = append(req.fields, g.tsField(jsonFieldTag(p), p.Type, false))
+		}
+		for _, p := range m.Results {
+			if p.Type == "error" {
+				continue
+			}
+			res.fields = append(res.fields, g.tsField(jsonFieldTag(p), p.Type, false))
+		}
+		methods = append(methods, req, res)
+	}
+	// The models can use other models, usedModels grows until they are all resolved.
+	g.scope = g.modelAlias
+	for i := 0; i < len(g.usedModels); i++ {
+		name := g.usedModels[i]
+		interfaces = append(interfaces, g.structInterface(g.modelNames[name], g.models[name]))
+	}
+	g.scope = ""
+	buf := &bytes.Buffer{}
+	buf.WriteString("// THIS FILE IS AUTO GENERATED BY GK-CLI DO NOT EDIT!!\n")
+	for _, v := range append(interfaces, methods...) {
+		g.writeInterface(buf, v)
+	}
+	buf.WriteString(`
+// ErrorWrapper is the body the service responds with when a method fails.
+export interface ErrorWrapper {
+  error: string;
+  // kind is the kind of the service error, e.x "not_found".
+  kind?: string;
+}
+
+// ServiceError is thrown when the service responds with an error, kind is
+// "unknown" if the response does not say the kind of the error.
+export class ServiceError extends Error {
+  readonly status: number;
+  readonly kind: string;
+
+  constructor(status: number, message: string, kind = "unknown") {
+    super(message);
+    this.name = "ServiceError";
+    this.status = status;
+    this.kind = kind;
+  }
+}
+`)
+	fmt.Fprintf(buf, `
+// ClientOptions are the options of the %s.
+export interface ClientOptions {
+  // headers are sent with every request.
+  headers?: Record<string, string>;
+  // fetch is used instead of the global fetch if it is set.
+  fetch?: typeof fetch;
+}
+
+// %s calls the methods of the %s service through its http transport.
+export class %s {
+  private readonly baseUrl: string;
+  private readonly options: ClientOptions;
+
+  constructor(baseUrl: string, options: ClientOptions = {}) {
+    this.baseUrl = baseUrl.replace(/\/+$/, "");
+    this.options = options;
+  }
+`, clientName, clientName, g.name, clientName)
+	for _, m := range g.serviceInterface.Methods {
+		route, err := newHTTPRoute(m)
+		if err != nil {
+			return err
+		}
+		args := fmt.Sprintf("%q, request", "/"+strings.Replace(utils.ToLowerSnakeCase(m.Name), "_", "-", -1))
+		if route != nil {
+			args = fmt.Sprintf("%s, %s, %q", route.tsPath(), route.tsBody(), route.method)
+			if query := route.tsQuery(); query != "" {
+				args += ", " + query
+			}
+		}
+		param := fmt.Sprintf("request: %sRequest", m.Name)
+		if len(m.Parameters) == 1 {
+			param += " = {}"
+		}
+		fmt.Fprintf(
+			buf,
+			"\n  // %s calls the %s method.\n  async %s(%s): Promise<%sResponse> {\n    return this.call<%sResponse>(%s);\n  }\n",
+			utils.ToLowerFirstCamelCase(m.Name),
+			m.Name,
+			utils.ToLowerFirstCamelCase(m.Name),
+			param,
+			m.Name,
+			m.Name,
+			args,
+		)
+	}
+	buf.WriteString(`
+  // call sends the body JSON-encoded and the query to the route of the method.
+  private async call<T>(
+    path: string,
+    body: unknown,
+    method = "POST",
+    query: Record<string, unknown> = {},
+  ): Promise<T> {
+    const doFetch = this.options.fetch || fetch;
+    const search = new URLSearchParams();
+    for (const [key, value] of Object.entries(query)) {
+      if (value !== undefined && value !== null) {
+        search.set(key, String(value));
+      }
+    }
+    const url = this.baseUrl + path + (search.toString() ? "?" + search.toString() : "");
+    const response = await doFetch(url, {
+      method,
+      headers: { "Content-Type": "application/json", ...this.options.headers },
+      body: body === undefined ? undefined : JSON.stringify(body),
+    });
+    if (!response.ok) {
+      let message = response.statusText;
+      let kind: string | undefined;
+      try {
+        const body: ErrorWrapper = await response.json();
+        message = body.error || message;
+        kind = body.kind;
+      } catch (e) {
+        // The body is not an ErrorWrapper, keep the status text.
+      }
+      throw new ServiceError(response.status, message, kind);
+    }
+    if (response.status === 204) {
+      return {} as T;
+    }
+    return (await response.json()) as T;
+  }
+}
+`)
+	return g.fs.WriteFile(g.filePath, buf.String(), true)
+}
+
+func (g *generateTypeScriptClient) writeInterface(buf *bytes.Buffer, v tsInterface) {
+	extends := ""
+	if len(v.extends) > 0 {
+		extends = " extends " + strings.Join(v.extends, ", ")
+	}
+	if len(v.fields) == 0 {
+		fmt.Fprintf(buf, "\nexport interface %s%s {}\n", v.name, extends)
+		return
+	}
+	fmt.Fprintf(buf, "\nexport interface %s%s {\n", v.name, extends)
+	for _, f := range v.fields {
+		fmt.Fprintf(buf, "  %s;\n", f)
+	}
+	buf.WriteString("}\n")
+}
+
+// structInterface returns the interface of the JSON encoding of the struct, the
+// embedded structs are extended as their fields are encoded inline.
+func (g *generateTypeScriptClient) structInterface(name string, v parser.Struct) tsInterface {
+	i := tsInterface{name: name}
+	for _, f := range v.Vars {
+		tag := strings.Split(f.TagValue("json"), ",")
+		if tag[0] == "-" && len(tag) == 1 {
+			continue
+		}
+		if f.Embedded && tag[0] == "" {
+			if t := g.tsType(strings.TrimPrefix(f.Type, "*")); g.isInterface(t) {
+				i.extends = append(i.extends, t)
+			}
+			continue
+		}
+		fieldName := f.Name
+		if tag[0] != "" {
+			fieldName = tag[0]
+		} else if !isExported(f.Name) {
+			continue
+		}
+		optional := strings.HasPrefix(f.Type, "*")
+		for _, o := range tag[1:] {
+			optional = optional || o == "omitempty"
+		}
+		i.fields = append(i.fields, g.tsField(fieldName, f.Type, optional))
+	}
+	return i
+}
+
+func (g *generateTypeScriptClient) tsField(name, tp string, optional bool) string {
+	if optional {
+		return fmt.Sprintf("%s?: %s", tsPropertyName(name), g.tsType(tp))
+	}
+	return fmt.Sprintf("%s: %s", tsPropertyName(name), g.tsType(tp))
+}
+
+// tsType returns the TypeScript type of the go type, pointers can be null and
+// the types that are not declared in the service file or the model package are
+// typed as `any`.
+func (g *generateTypeScriptClient) tsType(tp string) string {
+	switch {
+	case strings.HasPrefix(tp, "..."):
+		return g.tsArray(tp[3:])
+	case strings.HasPrefix(tp, "*"):
+		return g.tsType(tp[1:]) + " | null"
+	case tp == "[]byte":
+		return "string"
+	case strings.HasPrefix(tp, "[]"):
+		return g.tsArray(tp[2:])
+	case strings.HasPrefix(tp, "map["):
+		depth := 0
+		for i := 3; i < len(tp); i++ {
+			switch tp[i] {
+			case '[':
+				depth++
+			case ']':
+				depth--
+			}
+			if depth == 0 {
+				return fmt.Sprintf("{ [key: string]: %s }", g.tsType(tp[i+1:]))
+			}
+		}
+	}
+	if t, ok := tsBasicTypes[tp]; ok {
+		return t
+	}
+	if g.scope != "" && !strings.Contains(tp, ".") {
+		tp = g.scope + "." + tp
+	}
+	if t, ok := g.structs[tp]; ok {
+		return t
+	}
+	if t := g.modelType(tp); t != "" {
+		return t
+	}
+	return "any"
+}
+
+// modelType returns the TypeScript name of the model if the type is a struct of
+// the model package, the model is generated with the interfaces of the client.
+func (g *generateTypeScriptClient) modelType(tp string) string {
+	if g.modelAlias == "" || !strings.HasPrefix(tp, g.modelAlias+".") {
+		return ""
+	}
+	name := strings.TrimPrefix(tp, g.modelAlias+".")
+	if _, ok := g.models[name]; !ok {
+		return ""
+	}
+	if t, ok := g.modelNames[name]; ok {
+		return t
+	}
+	t := name
+	if g.taken[t] {
+		t = utils.ToCamelCase(g.modelAlias) + name
+	}
+	g.taken[t] = true
+	g.modelNames[name] = t
+	g.usedModels = append(g.usedModels, name)
+	return t
+}
+
+// isInterface returns true if the TypeScript type is one of the generated interfaces.
+func (g *generateTypeScriptClient) isInterface(t string) bool {
+	for _, v := range g.structs {
+		if v == t {
+			return true
+		}
+	}
+	for _, v := range g.modelNames {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+// loadModels parses the structs of the model package if the service file imports it.
+func (g *generateTypeScriptClient) loadModels() error {
+	modelImport, err := utils.GetModelImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	for _, v := range g.serviceFile.Imports {
+		if strings.Trim(v.Type, `"`) != modelImport {
+			continue
+		}
+		g.modelAlias = v.Name
+		if g.modelAlias == "" {
+			g.modelAlias = path.Base(modelImport)
+		}
+	}
+	if g.modelAlias == "" {
+		return nil
+	}
+	modelPath := fmt.Sprintf(viper.GetString("gk_model_path_format"), utils.ToLowerSnakeCase(g.name))
+	if b, err := afero.DirExists(g.fs.Fs, modelPath); err != nil || !b {
+		return err
+	}
+	infos, err := afero.ReadDir(g.fs.Fs, modelPath)
+	if err != nil {
+		return err
+	}
+	for _, v := range infos {
+		if v.IsDir() || !strings.HasSuffix(v.Name(), ".go") || strings.HasSuffix(v.Name(), "_test.go") {
+			continue
+		}
+		src, err := g.fs.ReadFile(path.Join(modelPath, v.Name()))
+		if err != nil {
+			return err
+		}
+		f, err := parser.NewFileParser().Parse([]byte(src))
+		if err != nil {
+			return err
+		}
+		for _, s := range f.Structures {
+			if isExported(s.Name) {
+				g.models[s.Name] = s
+			}
+		}
+	}
+	return nil
+}
+
+func (g *generateTypeScriptClient) tsArray(tp string) string {
+	t := g.tsType(tp)
+	if strings.Contains(t, " ") {
+		return "Array<" + t + ">"
+	}
+	return t + "[]"
+}
+
+// tsPropertyName quotes the property names that are not valid identifiers.
+func tsPropertyName(name string) string {
+	for i, c := range name {
+		if c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9') {
+			continue
+		}
+		return fmt.Sprintf("%q", name)
+	}
+	return name
+}
+
+func isExported(name string) bool {
+	return name != "" && strings.ToUpper(name[:1]) == name[:1] && strings.ToLower(name[:1]) != name[:1]
+}
@@ -0,0 +1,399 @@
+package generator
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/kujtimiihoxha/kit/fs"
+	"github.com/kujtimiihoxha/kit/parser"
+	"github.com/kujtimiihoxha/kit/utils"
+	"github.com/spf13/viper"
+)
+
+const envImport = "github.com/caarlos0/env/v6"
+
+// configField is a field of a section of the service configuration.
+type configField struct {
+	name  string
+	tp    jen.Code
+	yaml  string
+	env   string
+	flag  string
+	usage string
+	value jen.Code
+}
+
+// configSection is a section of the service configuration, a field of AppConfig.
+type configSection struct {
+	name   string
+	field  string
+	yaml   string
+	doc    string
+	fields []configField
+}
+
+// configSections returns the sections of AppConfig, the DB section is left out
+// if the service does not have a database.
+func configSections(name string, db *dbDriver) []configSection {
+	sections := []configSection{
+		{
+			name:  "ListenersConfig",
+			field: "Listeners",
+			yaml:  "listeners",
+			doc:   "ListenersConfig are the listen addresses of the transports.",
+			fields: []configField{
+				{"Debug", jen.String(), "debug", "DEBUG_ADDR", "debug-addr", "Debug and metrics listen address", jen.Lit(":8080")},
+				{"HTTP", jen.String(), "http", "HTTP_ADDR", "http-addr", "HTTP listen address", jen.Lit(":8081")},
+				{"GRPC", jen.String(), "grpc", "GRPC_ADDR", "grpc-addr", "gRPC listen address", jen.Lit(":8082")},
+			},
+		},
+		{
+			name:  "TracingConfig",
+			field: "Tracing",
+			yaml:  "tracing",
+			doc:   "TracingConfig configures the OpenTelemetry or the OpenTracing tracer.",
+			fields: []configField{
+				{"Exporter", jen.String(), "exporter", "TRACING_EXPORTER", "otel-exporter", "Export the OpenTelemetry spans with otlp, stdout or none", jen.Lit("otlp")},
+				{"Endpoint", jen.String(), "endpoint", "TRACING_ENDPOINT", "otel-endpoint", "OTLP gRPC collector endpoint the otlp exporter sends the spans to", jen.Lit("localhost:4317")},
+				{"ZipkinURL", jen.String(), "zipkin_url", "ZIPKIN_URL", "zipkin-url", "Enable Zipkin tracing via a collector URL e.g. http://localhost:9411/api/v1/spans", nil},
+				{"LightstepToken", jen.String(), "lightstep_token", "LIGHTSTEP_TOKEN", "lightstep-token", "Enable LightStep tracing via a LightStep access token", nil},
+				{"AppdashAddr", jen.String(), "appdash_addr", "APPDASH_ADDR", "appdash-addr", "Enable Appdash tracing via an Appdash server host:port", nil},
+			},
+		},
+		{
+			name:  "LogConfig",
+			field: "Log",
+			yaml:  "log",
+			doc:   "LogConfig configures the logger.",
+			fields: []configField{
+				{"Format", jen.String(), "format", "LOG_FORMAT", "log-format", "Log format, logfmt or json", jen.Lit("logfmt")},
+				{"Level", jen.String(), "level", "LOG_LEVEL", "log-level", "Log level, debug, info, warn or error", jen.Lit("debug")},
+				{"Output", jen.String(), "output", "LOG_OUTPUT", "log-output", "Log output, stderr, stdout or file://path", jen.Lit("stderr")},
+			},
+		},
+	}
+	if db == nil {
+		return sections
+	}
+	dbSection := configSection{
+		name:  "DBConfig",
+		field: "DB",
+		yaml:  "db",
+		doc:   "DBConfig is the connection of the database.",
+		fields: append(
+			db.configFields(name),
+			configField{"AutoMigrate", jen.Bool(), "auto_migrate", "DB_AUTO_MIGRATE", "", "", nil},
+		),
+	}
+	return append(sections[:2], append([]configSection{dbSection}, sections[2:]...)...)
+}
+
+// generateAppConfig generates the configuration of the service, the sections of
+// AppConfig with their defaults, the loading from the YAML file, the environment
+// and the flags, and the validation.
+func (g *NewConfig) generateAppConfig() {
+	sections := configSections(g.name, g.db)
+	fields := []jen.Code{}
+	defaults := jen.Dict{}
+	flags := []jen.Code{
+		jen.Id("file").Op(":=").Id("fs").Dot("String").Call(
+			jen.Lit("config"),
+			jen.Qual("os", "Getenv").Call(jen.Lit("CONFIG_FILE")),
+			jen.Lit("YAML config file, the environment and the flags override its values"),
+		),
+	}
+	for _, s := range sections {
+		fields = append(fields, jen.Id(s.field).Id(s.name).Tag(map[string]string{"yaml": s.yaml}))
+		values := jen.Dict{}
+		for _, f := range s.fields {
+			if f.value != nil {
+				values[jen.Id(f.name)] = f.value
+			}
+			if f.flag == "" {
+				continue
+			}
+			flags = append(flags, jen.Id("fs").Dot("StringVar").Call(
+				jen.Op("&").Id("c").Dot(s.field).Dot(f.name),
+				jen.Lit(f.flag),
+				jen.Id("c").Dot(s.field).Dot(f.name),
+				jen.Lit(f.usage),
+			))
+		}
+		defaults[jen.Id(s.field)] = jen.Id(s.name).Values(values)
+	}
+	fields = append(
+		fields,
+		jen.Id("ShutdownTimeout").Qual("time", "Duration").Tag(map[string]string{
+			"yaml": "shutdown_timeout",
+			"env":  "SHUTDOWN_TIMEOUT",
+		}),
+	)
+	defaults[jen.Id("ShutdownTimeout")] = jen.Lit(15).Op("*").Qual("time", "Second")
+	flags = append(flags, jen.Id("fs").Dot("DurationVar").Call(
+		jen.Op("&").Id("c").Dot("ShutdownTimeout"),
+		jen.Lit("shutdown-timeout"),
+		jen.Id("c").Dot("ShutdownTimeout"),
+		jen.Lit("Time to drain the in-flight requests before the servers are stopped"),
+	))
+
+	g.code.appendMultilineComment([]string{
+		"AppConfig is the configuration of the service. It is loaded from the YAML config",
+		"file, the environment and the flags, each of them overriding the previous one.",
+	})
+	g.code.NewLine()
+	g.code.appendStruct("AppConfig", fields...)
+	for _, s := range sections {
+		g.code.NewLine()
+		g.code.appendMultilineComment([]string{s.doc})
+		g.code.NewLine()
+		sf := []jen.Code{}
+		for _, f := range s.fields {
+			sf = append(sf, jen.Id(f.name).Add(f.tp).Tag(map[string]string{
+				"yaml": f.yaml,
+				"env":  f.env,
+			}))
+		}
+		g.code.appendStruct(s.name, sf...)
+	}
+	g.code.NewLine()
+	g.code.Raw().Var().Defs(
+		jen.Id("mtx").Qual("sync", "RWMutex"),
+		jen.Id("loaded").Op("*").Id("AppConfig"),
+	).Line()
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{"Default returns the configuration used when nothing overrides it."})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"Default",
+		nil,
+		[]jen.Code{},
+		[]jen.Code{},
+		"AppConfig",
+		jen.Return(jen.Id("AppConfig").Values(defaults)),
+	)
+	g.code.NewLine()
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"Load loads and validates the configuration of the service from the args. The",
+		"config file is read from the -config flag or the CONFIG_FILE environment variable.",
+	})
+	g.code.NewLine()
+	body := []jen.Code{
+		jen.Id("c").Op("=").Id("Default").Call(),
+		jen.Id("fs").Op(":=").Qual("flag", "NewFlagSet").Call(jen.Id("name"), jen.Qual("flag", "ExitOnError")),
+	}
+	body = append(body, flags...)
+	body = append(
+		body,
+		jen.If(
+			jen.Err().Op("=").Id("fs").Dot("Parse").Call(jen.Id("args")),
+			jen.Err().Op("!=").Nil(),
+		).Block(jen.Return()),
+		jen.Comment("Keep the flags set in the args, they override the file and the environment."),
+		jen.Id("set").Op(":=").Map(jen.String()).String().Values(),
+		jen.Id("fs").Dot("Visit").Call(
+			jen.Func().Params(jen.Id("f").Op("*").Qual("flag", "Flag")).Block(
+				jen.Id("set").Index(jen.Id("f").Dot("Name")).Op("=").Id("f").Dot("Value").Dot("String").Call(),
+			),
+		),
+		jen.If(jen.Id("*file").Op("!=").Lit("")).Block(
+			jen.List(jen.Id("b"), jen.Err()).Op(":=").Qual("io/ioutil", "ReadFile").Call(jen.Id("*file")),
+			jen.If(jen.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Id("c"), jen.Err()),
+			),
+			jen.If(
+				jen.Err().Op("=").Qual("gopkg.in/yaml.v3", "Unmarshal").Call(jen.Id("b"), jen.Op("&").Id("c")),
+				jen.Err().Op("!=").Nil(),
+			).Block(
+				jen.Return(
+					jen.Id("c"),
+					jen.Qual("fmt", "Errorf").Call(jen.Lit("config file %s: %v"), jen.Id("*file"), jen.Err()),
+				),
+			),
+		),
+		jen.If(
+			jen.Err().Op("=").Qual(envImport, "Parse").Call(jen.Op("&").Id("c")),
+			jen.Err().Op("!=").Nil(),
+		).Block(jen.Return()),
+		jen.For(jen.List(jen.Id("name"), jen.Id("value")).Op(":=").Range().Id("set")).Block(
+			jen.If(
+				jen.Err().Op("=").Id("fs").Dot("Set").Call(jen.Id("name"), jen.Id("value")),
+				jen.Err().Op("!=").Nil(),
+			).Block(jen.Return()),
+		),
+		jen.If(
+			jen.Err().Op("=").Id("c").Dot("Validate").Call(),
+			jen.Err().Op("!=").Nil(),
+		).Block(jen.Return()),
+		jen.Id("mtx").Dot("Lock").Call(),
+		jen.Id("loaded").Op("=").Op("&").Id("c"),
+		jen.Id("mtx").Dot("Unlock").Call(),
+		jen.Return(),
+	)
+	g.code.appendFunction(
+		"Load",
+		nil,
+		[]jen.Code{
+			jen.Id("name").String(),
+			jen.Id("args").Index().String(),
+		},
+		[]jen.Code{
+			jen.Id("c").Id("AppConfig"),
+			jen.Err().Error(),
+		},
+		"",
+		body...,
+	)
+	g.code.NewLine()
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"Get returns the configuration loaded by Load, or the defaults overridden by the",
+		"environment when the configuration was not loaded.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"Get",
+		nil,
+		[]jen.Code{},
+		[]jen.Code{},
+		"AppConfig",
+		jen.Id("mtx").Dot("RLock").Call(),
+		jen.Defer().Id("mtx").Dot("RUnlock").Call(),
+		jen.If(jen.Id("loaded").Op("!=").Nil()).Block(
+			jen.Return(jen.Id("*loaded")),
+		),
+		jen.Id("c").Op(":=").Id("Default").Call(),
+		jen.Id("_").Op("=").Qual(envImport, "Parse").Call(jen.Op("&").Id("c")),
+		jen.Return(jen.Id("c")),
+	)
+	g.code.NewLine()
+	g.code.NewLine()
+	g.generateValidate()
+}
+
+func (g *NewConfig) generateValidate() {
+	dbCheck := jen.Null()
+	if g.db != nil {
+		dbCheck = g.db.validate()
+	}
+	g.code.appendMultilineComment([]string{"Validate returns an error if the service can not start with the configuration."})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"Validate",
+		jen.Id("c").Id("AppConfig"),
+		[]jen.Code{},
+		[]jen.Code{},
+		"error",
+		jen.For(
+			jen.List(jen.Id("_"), jen.Id("l")).Op(":=").Range().Index().Index(jen.Lit(2)).String().Values(
+				jen.Values(jen.Lit("debug"), jen.Id("c").Dot("Listeners").Dot("Debug")),
+				jen.Values(jen.Lit("http"), jen.Id("c").Dot("Listeners").Dot("HTTP")),
+				jen.Values(jen.Lit("grpc"), jen.Id("c").Dot("Listeners").Dot("GRPC")),
+			),
+		).Block(
+			jen.If(
+				jen.List(jen.Id("_"), jen.Id("_"), jen.Err()).Op(":=").Qual("net", "SplitHostPort").Call(jen.Id("l").Index(jen.Lit(1))),
+				jen.Err().Op("!=").Nil(),
+			).Block(
+				jen.Return(jen.Qual("fmt", "Errorf").Call(
+					jen.Lit("invalid %s listen address: %v"),
+					jen.Id("l").Index(jen.Lit(0)),
+					jen.Err(),
+				)),
+			),
+		),
+		jen.Switch(jen.Id("c").Dot("Tracing").Dot("Exporter")).Block(
+			jen.Case(jen.Lit("otlp"), jen.Lit("stdout"), jen.Lit("none")).Block(),
+			jen.Default().Block(
+				jen.Return(jen.Qual("fmt", "Errorf").Call(
+					jen.Lit("invalid tracing exporter %q, use otlp, stdout or none"),
+					jen.Id("c").Dot("Tracing").Dot("Exporter"),
+				)),
+			),
+		),
+		dbCheck,
+		jen.Switch(jen.Id("c").Dot("Log").Dot("Format")).Block(
+			jen.Case(jen.Lit("logfmt"), jen.Lit("json")).Block(),
+			jen.Default().Block(
+				jen.Return(jen.Qual("fmt", "Errorf").Call(
+					jen.Lit("invalid log format %q, use logfmt or json"),
+					jen.Id("c").Dot("Log").Dot("Format"),
+				)),
+			),
+		),
+		jen.Switch(jen.Id("c").Dot("Log").Dot("Level")).Block(
+			jen.Case(jen.Lit("debug"), jen.Lit("info"), jen.Lit("warn"), jen.Lit("error")).Block(),
+			jen.Default().Block(
+				jen.Return(jen.Qual("fmt", "Errorf").Call(
+					jen.Lit("invalid log level %q, use debug, info, warn or error"),
+					jen.Id("c").Dot("Log").Dot("Level"),
+				)),
+			),
+		),
+		jen.If(
+			jen.Id("c").Dot("Log").Dot("Output").Op("!=").Lit("stderr").Op("&&").
+				Id("c").Dot("Log").Dot("Output").Op("!=").Lit("stdout").Op("&&").
+				Op("!").Qual("strings", "HasPrefix").Call(jen.Id("c").Dot("Log").Dot("Output"), jen.Lit("file://")),
+		).Block(
+			jen.Return(jen.Qual("fmt", "Errorf").Call(
+				jen.Lit("invalid log output %q, use stderr, stdout or file://path"),
+				jen.Id("c").Dot("Log").Dot("Output"),
+			)),
+		),
+		jen.If(jen.Id("c").Dot("ShutdownTimeout").Op("<=").Lit(0)).Block(
+			jen.Return(jen.Qual("errors", "New").Call(jen.Lit("the shutdown timeout must be positive"))),
+		),
+		jen.Return(jen.Nil()),
+	)
+}
+
+// configExists returns true if the service loads the structured configuration of
+// the config package, the services generated before it keep the flags of the cmd.
+func configExists(f *fs.KitFs, name string) (bool, error) {
+	configPath := path.Join(
+		fmt.Sprintf(viper.GetString("gk_config_path_format"), utils.ToLowerSnakeCase(name)),
+		viper.GetString("gk_config_file_name"),
+	)
+	if b, err := f.Exists(configPath); err != nil || !b {
+		return false, err
+	}
+	src, err := f.ReadFile(configPath)
+	if err != nil {
+		return false, err
+	}
+	file, err := parser.NewFileParser().Parse([]byte(src))
+	if err != nil {
+		return false, err
+	}
+	load := false
+	for _, v := range file.Methods {
+		if v.Name == "Load" && v.Struct.Name == "" {
+			load = true
+		}
+	}
+	if !load {
+		return false, nil
+	}
+	cmdPath := path.Join(
+		fmt.Sprintf(viper.GetString("gk_cmd_service_path_format"), utils.ToLowerSnakeCase(name)),
+		viper.GetString("gk_cmd_svc_file_name"),
+	)
+	if b, err := f.Exists(cmdPath); err != nil || !b {
+		return err == nil, err
+	}
+	src, err = f.ReadFile(cmdPath)
+	if err != nil {
+		return false, err
+	}
+	file, err = parser.NewFileParser().Parse([]byte(src))
+	if err != nil {
+		return false, err
+	}
+	for _, v := range file.Vars {
+		if v.Name == "debugAddr" {
+			return false, nil
+		}
+	}
+	return true, nil
+}
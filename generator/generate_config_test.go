@@ -0,0 +1,95 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kujtimiihoxha/kit/parser"
+)
+
+func TestNewConfig_Generate(t *testing.T) {
+	f := newClientTestFs()
+	if err := NewNewConfig("test", DBPostgres).Generate(); err != nil {
+		t.Fatalf("NewConfig.Generate() error = %v", err)
+	}
+	src, err := f.ReadFile("test/config/config.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		"Listeners       ListenersConfig `yaml:\"listeners\"`",
+		"Host        string `env:\"DB_HOST\" yaml:\"host\"`",
+		"AutoMigrate bool   `env:\"DB_AUTO_MIGRATE\" yaml:\"auto_migrate\"`",
+		"ShutdownTimeout time.Duration   `env:\"SHUTDOWN_TIMEOUT\" yaml:\"shutdown_timeout\"`",
+		"func Load(name string, args []string) (c AppConfig, err error)",
+		`file := fs.String("config", os.Getenv("CONFIG_FILE")`,
+		`fs.StringVar(&c.Listeners.HTTP, "http-addr", c.Listeners.HTTP, "HTTP listen address")`,
+		"if err = yaml.Unmarshal(b, &c); err != nil",
+		"if err = env.Parse(&c); err != nil",
+		"if err = fs.Set(name, value); err != nil",
+		"func (c AppConfig) Validate() error",
+		"func Get() AppConfig",
+	} {
+		if !strings.Contains(src, v) {
+			t.Errorf("NewConfig.Generate() config.go does not contain %q", v)
+		}
+	}
+	if strings.Contains(src, "envDefault") || strings.Contains(src, `"db-pass"`) {
+		t.Error("NewConfig.Generate() should default the configuration in Default and not take the password from the flags")
+	}
+}
+
+func TestGenerateCmd_GenerateConfig(t *testing.T) {
+	f := newClientTestFs()
+	f.MkdirAll("test/pkg/http")
+	f.WriteFile("test/pkg/http/handler.go", `package http
+func makeFooHandler() {}`, true)
+	if err := NewNewConfig("test", DBPostgres).Generate(); err != nil {
+		t.Fatalf("NewConfig.Generate() error = %v", err)
+	}
+	svcSrc, err := f.ReadFile("test/pkg/service/service.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	svcFile, err := parser.NewFileParser().Parse([]byte(svcSrc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := newGenerateCmdBase("test", svcFile.Interfaces[0], false, false, nil).Generate(); err != nil {
+		t.Fatalf("generateCmdBase.Generate() error = %v", err)
+	}
+	src, err := f.ReadFile("test/cmd/service/service_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		"func createService(endpoints endpoint.Endpoints, conf config.AppConfig) (g *group.Group)",
+		"initHttpHandler(endpoints, conf, g)",
+	} {
+		if !strings.Contains(src, v) {
+			t.Errorf("generateCmdBase.Generate() service_gen.go does not contain %q", v)
+		}
+	}
+	if err := newGenerateCmd("test", "", svcFile.Interfaces[0], false, false, nil).Generate(); err != nil {
+		t.Fatalf("generateCmd.Generate() error = %v", err)
+	}
+	src, err = f.ReadFile("test/cmd/service/service.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		`conf, err := config.Load("test", os.Args[1:])`,
+		"g := createService(eps, conf)",
+		"initMetricsEndpoint(conf, g)",
+		"func initHttpHandler(endpoints endpoint.Endpoints, conf config.AppConfig, g *group.Group)",
+		`net.Listen("tcp", conf.Listeners.HTTP)`,
+		"context.WithTimeout(context.Background(), conf.ShutdownTimeout)",
+	} {
+		if !strings.Contains(src, v) {
+			t.Errorf("generateCmd.Generate() service.go does not contain %q", v)
+		}
+	}
+	if strings.Contains(src, "flag.NewFlagSet") || strings.Contains(src, "shutdownTimeout") {
+		t.Errorf("generateCmd.Generate() should not generate the flags with the structured configuration, got %s", src)
+	}
+}
@@ -0,0 +1,435 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	ps "go/parser"
+	"go/token"
+	"path"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/kujtimiihoxha/kit/fs"
+	"github.com/kujtimiihoxha/kit/parser"
+	"github.com/kujtimiihoxha/kit/utils"
+	"github.com/spf13/viper"
+)
+
+// crudMethod is a method GenerateCRUD adds to the service.
+type crudMethod struct {
+	name string
+	// route is the kit:http annotation of the method.
+	route  string
+	params []parser.NamedTypeValue
+	result []parser.NamedTypeValue
+	body   []jen.Code
+}
+
+// GenerateCRUD implements Gen and is used to add the create, get, list, update
+// and delete methods of a model to the service, the methods are implemented with
+// the repository of the model and annotated with their REST routes.
+type GenerateCRUD struct {
+	BaseGenerator
+	name              string
+	resource          string
+	fields            string
+	interfaceName     string
+	serviceStructName string
+	destPath          string
+	filePath          string
+	modelPath         string
+	// events is true if the methods that change the model publish events.
+	events bool
+}
+
+// NewGenerateCRUD returns a initialized and ready generator.
+//
+// The name parameter is the name of the service, resource is the name of the model
+// and fields are the fields of the model if it does not exist yet, e.x "Title:string".
+func NewGenerateCRUD(name, resource, fields string) Gen {
+	i := &GenerateCRUD{
+		name:          name,
+		resource:      utils.ToCamelCase(resource),
+		fields:        fields,
+		interfaceName: utils.ToCamelCase(name + "Service"),
+		destPath:      fmt.Sprintf(viper.GetString("gk_service_path_format"), utils.ToLowerSnakeCase(name)),
+	}
+	i.serviceStructName = utils.ToLowerFirstCamelCase(viper.GetString("gk_service_struct_prefix") + "-" + i.interfaceName)
+	i.filePath = path.Join(i.destPath, viper.GetString("gk_service_file_name"))
+	i.modelPath = path.Join(
+		fmt.Sprintf(viper.GetString("gk_model_path_format"), utils.ToLowerSnakeCase(name)),
+		utils.ToLowerSnakeCase(resource)+".go",
+	)
+	i.srcFile = jen.NewFilePath(i.destPath)
+	i.InitPg()
+	i.fs = fs.Get()
+	return i
+}
+
+// Generate generates the model if it does not exist and adds the methods of the
+// resource to the service interface and to the basic service.
+func (g *GenerateCRUD) Generate() (err error) {
+	if g.resource == "" {
+		return fmt.Errorf("you must provide the name of the resource")
+	}
+	if b, err := g.fs.Exists(g.filePath); err != nil {
+		return err
+	} else if !b {
+		return fmt.Errorf("service `%s` was not found", g.name)
+	}
+	if b, err := g.fs.Exists(g.modelPath); err != nil {
+		return err
+	} else if !b && g.fields == "" {
+		return fmt.Errorf(
+			"model `%s` was not found, generate it with `kit g model` or set the fields of the model",
+			g.resource,
+		)
+	}
+	src, err := g.fs.ReadFile(g.filePath)
+	if err != nil {
+		return err
+	}
+	if src, err = g.addToInterface(src); err != nil {
+		return err
+	}
+	if src, err = g.addBasicService(src); err != nil {
+		return err
+	}
+	if err = g.fs.WriteFile(g.filePath, src, true); err != nil {
+		return err
+	}
+	// The model generator only generates what is missing and adds the repository
+	// of the model to the basic service.
+	if err = NewGenerateModel(g.name, g.resource, g.fields).Generate(); err != nil {
+		return err
+	}
+	if src, err = g.fs.ReadFile(g.filePath); err != nil {
+		return err
+	}
+	if g.events, err = eventsTransactionExists(g.fs, g.name); err != nil {
+		return err
+	}
+	if src, err = g.implement(src); err != nil {
+		return err
+	}
+	return g.fs.WriteFile(g.filePath, src, true)
+}
+
+// addBasicService generates the basic service struct and its constructor like
+// GenerateService if the service was not generated yet, so the repository of the
+// model can be added to them.
+func (g *GenerateCRUD) addBasicService(src string) (string, error) {
+	db, err := dbExists(g.fs, g.name)
+	if err != nil {
+		return "", err
+	}
+	if db == nil {
+		return "", fmt.Errorf("service `%s` does not have a database, create it with `kit new service --db`", g.name)
+	}
+	file, err := parser.NewFileParser().Parse([]byte(src))
+	if err != nil {
+		return "", err
+	}
+	svc := &GenerateService{
+		pg:                NewPartialGenerator(nil),
+		name:              g.name,
+		interfaceName:     g.interfaceName,
+		serviceStructName: g.serviceStructName,
+		file:              file,
+		db:                db,
+	}
+	svc.generateServiceStruct()
+	svc.generateNewBasicStructMethod()
+	if svc.pg.String() == "" {
+		return src, nil
+	}
+	if src, err = insertSource(src + "\n" + svc.pg.String()); err != nil {
+		return "", err
+	}
+	return g.addImports(src)
+}
+
+// methods returns the methods of the resource, the receiver of the basic service is stp.
+func (g *GenerateCRUD) methods(stp string) []crudMethod {
+	param := utils.ToLowerFirstCamelCase(g.resource)
+	plural := pluralize(utils.ToLowerSnakeCase(g.resource))
+	route := "/" + strings.Replace(plural, "_", "-", -1)
+	model := "model." + g.resource
+	repository := jen.Id(stp).Dot(utils.ToLowerFirstCamelCase(g.resource) + "Repository")
+	ctx := parser.NewNameType("ctx", "context.Context")
+	id := parser.NewNameType("id", "uuid.UUID")
+	rs := parser.NewNameType("rs", model)
+	e := parser.NewNameType("err", "error")
+	notFound := jen.Id("NotFound").Call(jen.Lit(utils.ToLowerSnakeCase(g.resource)+" %s not found"), jen.Id("id"))
+	return []crudMethod{
+		{
+			name:   "Create" + g.resource,
+			route:  fmt.Sprintf(`POST %s status:"201" body:"%s"`, route, param),
+			params: []parser.NamedTypeValue{ctx, parser.NewNameType(param, model)},
+			result: []parser.NamedTypeValue{rs, e},
+			body: append(
+				g.save(
+					repository.Clone().Dot("Create").Call(jen.Id("ctx"), jen.Op("&").Id(param)),
+					"created",
+					jen.Id(param).Dot("ID").Dot("String").Call(),
+					jen.Id(param),
+					func(init jen.Code) jen.Code {
+						return jen.If(init, jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Id("rs"), jen.Err()))
+					},
+				),
+				jen.Return(jen.Id(param), jen.Nil()),
+			),
+		},
+		{
+			name:   "Get" + g.resource,
+			route:  fmt.Sprintf("GET %s/{id}", route),
+			params: []parser.NamedTypeValue{ctx, id},
+			result: []parser.NamedTypeValue{rs, e},
+			body: []jen.Code{
+				jen.List(jen.Id("m"), jen.Err()).Op(":=").Add(repository.Clone()).Dot("GetByID").Call(jen.Id("ctx"), jen.Id("id")),
+				jen.If(jen.Id("utils").Dot("IsErrNotFound").Call(jen.Err())).Block(
+					jen.Return(jen.Id("rs"), notFound),
+				).Else().If(jen.Err().Op("!=").Nil()).Block(
+					jen.Return(jen.Id("rs"), jen.Err()),
+				),
+				jen.Return(jen.Op("*").Id("m"), jen.Nil()),
+			},
+		},
+		{
+			name:   "List" + utils.ToCamelCase(plural),
+			route:  "GET " + route,
+			params: []parser.NamedTypeValue{ctx, parser.NewNameType("offset", "int"), parser.NewNameType("limit", "int")},
+			result: []parser.NamedTypeValue{parser.NewNameType("rs", "[]"+model), parser.NewNameType("total", "int"), e},
+			body: []jen.Code{
+				jen.Return(repository.Clone().Dot("List").Call(
+					jen.Id("ctx"),
+					jen.Id(model+"Filter").Values(),
+					jen.Id("offset"),
+					jen.Id("limit"),
+				)),
+			},
+		},
+		{
+			name:   "Update" + g.resource,
+			route:  fmt.Sprintf(`PUT %s/{id} body:"%s"`, route, param),
+			params: []parser.NamedTypeValue{ctx, id, parser.NewNameType(param, model)},
+			result: []parser.NamedTypeValue{rs, e},
+			body: append(
+				append(
+					[]jen.Code{jen.Id(param).Dot("ID").Op("=").Id("id")},
+					g.save(
+						repository.Clone().Dot("Update").Call(jen.Id("ctx"), jen.Op("&").Id(param)),
+						"updated",
+						jen.Id("id").Dot("String").Call(),
+						jen.Id(param),
+						func(init jen.Code) jen.Code {
+							return jen.If(init, jen.Id("utils").Dot("IsErrNotFound").Call(jen.Err())).Block(
+								jen.Return(jen.Id("rs"), notFound),
+							).Else().If(jen.Err().Op("!=").Nil()).Block(
+								jen.Return(jen.Id("rs"), jen.Err()),
+							)
+						},
+					)...,
+				),
+				jen.Return(jen.Id(stp).Dot("Get"+g.resource).Call(jen.Id("ctx"), jen.Id("id"))),
+			),
+		},
+		{
+			name:   "Delete" + g.resource,
+			route:  fmt.Sprintf(`DELETE %s/{id} status:"204"`, route),
+			params: []parser.NamedTypeValue{ctx, id},
+			result: []parser.NamedTypeValue{e},
+			body: append(
+				g.save(
+					repository.Clone().Dot("Delete").Call(jen.Id("ctx"), jen.Id("id")),
+					"deleted",
+					jen.Id("id").Dot("String").Call(),
+					jen.Map(jen.String()).Id("uuid.UUID").Values(jen.Dict{jen.Lit("id"): jen.Id("id")}),
+					func(init jen.Code) jen.Code {
+						return jen.If(init, jen.Id("utils").Dot("IsErrNotFound").Call(jen.Err())).Block(jen.Return(notFound))
+					},
+				),
+				jen.Return(jen.Err()),
+			),
+		},
+	}
+}
+
+// save returns the statements that assign the error of the repository call to err
+// and check it with the statement of check. If the service has events the call runs
+// in a transaction of the events that publishes the event of the action.
+func (g *GenerateCRUD) save(call *jen.Statement, action string, key, event jen.Code, check func(init jen.Code) jen.Code) []jen.Code {
+	if !g.events {
+		return []jen.Code{check(jen.Err().Op("=").Add(call))}
+	}
+	return []jen.Code{
+		jen.Err().Op("=").Id("events").Dot("Transaction").Call(
+			jen.Id("ctx"),
+			jen.Func().Params(
+				jen.Id("ctx").Id("context.Context"),
+				jen.Id("tx").Op("*").Id("gorm.DB"),
+			).Error().Block(
+				jen.If(jen.Err().Op(":=").Add(call), jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Err())),
+				jen.Return(jen.Id("events").Dot("Publish").Call(
+					jen.Id("tx"),
+					jen.Lit(utils.ToLowerSnakeCase(g.resource)+"."+action),
+					key,
+					event,
+				)),
+			),
+		),
+		check(jen.Null()),
+	}
+}
+
+// addToInterface adds the annotated methods that are missing to the service interface.
+func (g *GenerateCRUD) addToInterface(src string) (string, error) {
+	fset := token.NewFileSet()
+	f, err := ps.ParseFile(fset, "", src, ps.ParseComments)
+	if err != nil {
+		return "", err
+	}
+	var it *ast.InterfaceType
+	for _, d := range f.Decls {
+		if gd, ok := d.(*ast.GenDecl); ok {
+			for _, s := range gd.Specs {
+				if ts, ok := s.(*ast.TypeSpec); ok && ts.Name.Name == g.interfaceName {
+					it, _ = ts.Type.(*ast.InterfaceType)
+				}
+			}
+		}
+	}
+	if it == nil {
+		return "", fmt.Errorf("could not find the service interface `%s`", g.interfaceName)
+	}
+	found := map[string]bool{}
+	for _, v := range it.Methods.List {
+		for _, n := range v.Names {
+			found[n.Name] = true
+		}
+	}
+	text := ""
+	for _, m := range g.methods("") {
+		if found[m.name] {
+			continue
+		}
+		text += fmt.Sprintf("// kit:%s %s\n%s%s\n", HTTPAnnotation, m.route, m.name, crudSignature(m))
+	}
+	if text == "" {
+		return src, nil
+	}
+	closing := fset.Position(it.Methods.Closing).Offset
+	if src[closing-1] != '\n' {
+		text = "\n" + text
+	}
+	if src, err = insertSource(src, sourceInsert{closing, text}); err != nil {
+		return "", err
+	}
+	return g.addImports(src, "context", uuidImport)
+}
+
+// implement implements the methods of the resource the basic service does not implement yet.
+func (g *GenerateCRUD) implement(src string) (string, error) {
+	file, err := parser.NewFileParser().Parse([]byte(src))
+	if err != nil {
+		return "", err
+	}
+	var stp string
+	exists := map[string]bool{}
+	for _, v := range file.Methods {
+		if v.Struct.Type == "*"+g.serviceStructName {
+			stp = v.Struct.Name
+			exists[v.Name] = true
+		}
+	}
+	if stp == "" {
+		params := []parser.NamedTypeValue{}
+		for _, v := range file.Interfaces {
+			if v.Name != g.interfaceName {
+				continue
+			}
+			for _, m := range v.Methods {
+				params = append(params, m.Parameters...)
+				params = append(params, m.Results...)
+			}
+		}
+		stp = g.GenerateNameBySample(g.serviceStructName, params)
+	}
+	pg := NewPartialGenerator(nil)
+	missing := 0
+	for _, m := range g.methods(stp) {
+		if exists[m.name] {
+			continue
+		}
+		missing++
+		sp := []jen.Code{}
+		for _, p := range m.params {
+			sp = append(sp, jen.Id(p.Name).Id(p.Type))
+		}
+		rs := []jen.Code{}
+		for _, p := range m.result {
+			rs = append(rs, jen.Id(p.Name).Id(p.Type))
+		}
+		pg.appendFunction(m.name, jen.Id(stp).Id("*"+g.serviceStructName), sp, rs, "", m.body...)
+		pg.NewLine()
+		pg.NewLine()
+	}
+	if missing == 0 {
+		return src, nil
+	}
+	if src, err = insertSource(src + "\n" + pg.String()); err != nil {
+		return "", err
+	}
+	utilsImport, err := utils.GetUtilsImportPath(g.name)
+	if err != nil {
+		return "", err
+	}
+	imports := []string{uuidImport, utilsImport}
+	if g.events {
+		eventsImport, err := utils.GetEventsImportPath(g.name)
+		if err != nil {
+			return "", err
+		}
+		imports = append(imports, "context", gormImport, eventsImport)
+	}
+	return g.addImports(src, imports...)
+}
+
+// addImports adds the imports and the model import to the service.
+func (g *GenerateCRUD) addImports(src string, imports ...string) (string, error) {
+	var err error
+	for _, v := range imports {
+		if src, err = addSourceImport(src, "", v); err != nil {
+			return "", err
+		}
+	}
+	modelImport, err := utils.GetModelImportPath(g.name)
+	if err != nil {
+		return "", err
+	}
+	return addSourceImport(src, "model", modelImport)
+}
+
+// crudSignature returns the parameters and the results of the method.
+func crudSignature(m crudMethod) string {
+	join := func(v []parser.NamedTypeValue) string {
+		s := []string{}
+		for _, p := range v {
+			s = append(s, p.Name+" "+p.Type)
+		}
+		return strings.Join(s, ", ")
+	}
+	return fmt.Sprintf("(%s) (%s)", join(m.params), join(m.result))
+}
+
+// pluralize returns the plural of the snake case name, e.x category becomes categories.
+func pluralize(name string) string {
+	switch {
+	case len(name) > 1 && strings.HasSuffix(name, "y") && !strings.ContainsAny(name[len(name)-2:len(name)-1], "aeiou"):
+		return name[:len(name)-1] + "ies"
+	case strings.HasSuffix(name, "s"), strings.HasSuffix(name, "x"),
+		strings.HasSuffix(name, "ch"), strings.HasSuffix(name, "sh"):
+		return name + "es"
+	}
+	return name + "s"
+}
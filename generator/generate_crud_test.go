@@ -0,0 +1,122 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kujtimiihoxha/kit/parser"
+)
+
+func Test_pluralize(t *testing.T) {
+	for k, v := range map[string]string{
+		"todo":      "todos",
+		"category":  "categories",
+		"day":       "days",
+		"box":       "boxes",
+		"address":   "addresses",
+		"todo_item": "todo_items",
+	} {
+		if got := pluralize(k); got != v {
+			t.Errorf("pluralize(%q) = %q, want %q", k, got, v)
+		}
+	}
+}
+
+func TestGenerateCRUD_Generate(t *testing.T) {
+	f := newClientTestFs()
+	f.WriteFile("test/pkg/service/service.go", `package service
+
+// TestService describes the service.
+type TestService interface {
+	// Add your methods here
+}
+`, true)
+	for i := 0; i < 2; i++ {
+		if err := NewGenerateCRUD("test", "todo", "Title:string,Done:bool").Generate(); err != nil {
+			t.Fatalf("GenerateCRUD.Generate() error = %v", err)
+		}
+	}
+	src, err := f.ReadFile("test/pkg/service/service.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		"// kit:http POST /todos status:\"201\" body:\"todo\"\n\tCreateTodo(ctx context.Context, todo model.Todo) (rs model.Todo, err error)",
+		"// kit:http GET /todos/{id}\n\tGetTodo(ctx context.Context, id uuid.UUID) (rs model.Todo, err error)",
+		"ListTodos(ctx context.Context, offset int, limit int) (rs []model.Todo, total int, err error)",
+		"// kit:http DELETE /todos/{id} status:\"204\"",
+		"todoRepository model.TodoRepository",
+		"return &basicTestService{pgDB: db, todoRepository: model.NewTodoRepository()}",
+		"func (b *basicTestService) CreateTodo(ctx context.Context, todo model.Todo) (rs model.Todo, err error) {\n\tif err = b.todoRepository.Create(ctx, &todo); err != nil {",
+		"return rs, NotFound(\"todo %s not found\", id)",
+		"return b.todoRepository.List(ctx, model.TodoFilter{}, offset, limit)",
+		"return b.GetTodo(ctx, id)",
+		`"github.com/google/uuid"`,
+		`test/pkg/model"`,
+	} {
+		if !strings.Contains(src, v) {
+			t.Errorf("GenerateCRUD.Generate() service.go does not contain %q, got %s", v, src)
+		}
+	}
+	if strings.Count(src, "CreateTodo(") != 2 {
+		t.Errorf("GenerateCRUD.Generate() should add the methods once, got %s", src)
+	}
+	file, err := parser.NewFileParser().Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range file.Interfaces[0].Methods {
+		if r, err := newHTTPRoute(m); r == nil || err != nil {
+			t.Errorf("GenerateCRUD.Generate() the route of %s is not valid, %v", m.Name, err)
+		}
+	}
+	if b, _ := f.Exists("test/pkg/model/todo.go"); !b {
+		t.Error("GenerateCRUD.Generate() should generate the model")
+	}
+}
+
+func TestGenerateCRUD_GenerateWithoutModel(t *testing.T) {
+	newClientTestFs()
+	if err := NewGenerateCRUD("test", "Todo", "").Generate(); err == nil {
+		t.Error("GenerateCRUD.Generate() should return an error if the model does not exist and has no fields")
+	}
+}
+
+func TestGenerateCRUD_GenerateWithEvents(t *testing.T) {
+	f := newClientTestFs()
+	f.WriteFile("test/pkg/service/service.go", `package service
+
+// TestService describes the service.
+type TestService interface {
+	// Add your methods here
+}
+`, true)
+	if err := NewGenerateEvents("test", PublisherMemory).Generate(); err != nil {
+		t.Fatalf("GenerateEvents.Generate() error = %v", err)
+	}
+	if err := NewGenerateCRUD("test", "todo", "Title:string,Done:bool").Generate(); err != nil {
+		t.Fatalf("GenerateCRUD.Generate() error = %v", err)
+	}
+	src, err := f.ReadFile("test/pkg/service/service.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		"err = events.Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {\n\t\tif err := b.todoRepository.Create(ctx, &todo); err != nil {",
+		`return events.Publish(tx, "todo.created", todo.ID.String(), todo)`,
+		`return events.Publish(tx, "todo.updated", id.String(), todo)`,
+		`return events.Publish(tx, "todo.deleted", id.String(), map[string]uuid.UUID{"id": id})`,
+		"})\n\tif utils.IsErrNotFound(err) {",
+		`"github.com/jinzhu/gorm"`,
+		`test/pkg/events"`,
+	} {
+		if !strings.Contains(src, v) {
+			t.Errorf("GenerateCRUD.Generate() service.go does not contain %q, got %s", v, src)
+		}
+	}
+	// The events of the old Transaction can not share the transaction.
+	f.WriteFile("test/pkg/events/events.go", "package events\n\nfunc Transaction(fn func(tx *gorm.DB) error) error {\n\treturn nil\n}\n", true)
+	if b, err := eventsTransactionExists(f, "test"); err != nil || b {
+		t.Errorf("eventsTransactionExists() = %v, %v, want false", b, err)
+	}
+}
@@ -0,0 +1,337 @@
+package generator
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/kujtimiihoxha/kit/fs"
+	"github.com/kujtimiihoxha/kit/utils"
+	"github.com/spf13/viper"
+)
+
+// The databases the generated services can use.
+const (
+	DBPostgres = "postgres"
+	DBMySQL    = "mysql"
+	DBSQLite   = "sqlite"
+	DBNone     = "none"
+)
+
+// SupportedDatabases are the databases the generated services can use.
+var SupportedDatabases = []string{DBPostgres, DBMySQL, DBSQLite, DBNone}
+
+const (
+	gormImport = "github.com/jinzhu/gorm"
+	uuidImport = "github.com/google/uuid"
+)
+
+// dbDriver describes how a generated service connects to its database.
+type dbDriver struct {
+	// name is the --db value, the package of the connection registry and the
+	// file of the model database.
+	name string
+	// dialect is the driver name gorm.Open takes.
+	dialect string
+	// title prefixes the model database, e.x PostgresDatabase.
+	title string
+	// field is the field of the model database in the service struct.
+	field string
+	// dsnImports are the imports of the DSN builder.
+	dsnImports []string
+	// dsn is the body of the DSN builder.
+	dsn string
+}
+
+// newDBDriver returns the driver of the database, nil if the service does not
+// have a database.
+func newDBDriver(db string) *dbDriver {
+	switch db {
+	case DBPostgres, "":
+		return &dbDriver{
+			name:       DBPostgres,
+			dialect:    "postgres",
+			title:      "Postgres",
+			field:      "pgDB",
+			dsnImports: []string{"net", "net/url"},
+			dsn: `	q := url.Values{}
+	q.Set("sslmode", "disable")
+	if info.SearchPath != "" {
+		q.Set("search_path", info.SearchPath)
+	}
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(info.User, info.Pass),
+		Host:     net.JoinHostPort(info.Host, info.Port),
+		Path:     info.Name,
+		RawQuery: q.Encode(),
+	}
+	return u.String()`,
+		}
+	case DBMySQL:
+		return &dbDriver{
+			name:       DBMySQL,
+			dialect:    "mysql",
+			title:      "MySQL",
+			field:      "mysqlDB",
+			dsnImports: []string{"net"},
+			dsn: `	return fmt.Sprintf(
+		"%s:%s@tcp(%s)/%s?charset=utf8mb4&parseTime=True&loc=UTC&multiStatements=true",
+		info.User, info.Pass, net.JoinHostPort(info.Host, info.Port), info.Name,
+	)`,
+		}
+	case DBSQLite:
+		return &dbDriver{
+			name:    DBSQLite,
+			dialect: "sqlite3",
+			title:   "SQLite",
+			field:   "sqliteDB",
+			dsn:     `	return info.Name + "?_foreign_keys=1"`,
+		}
+	}
+	return nil
+}
+
+// configFields returns the fields of the DB section of AppConfig.
+func (d *dbDriver) configFields(name string) []configField {
+	switch d.name {
+	case DBMySQL:
+		return []configField{
+			{"Host", jen.String(), "host", "DB_HOST", "db-host", "Database host", jen.Lit("localhost")},
+			{"Port", jen.String(), "port", "DB_PORT", "db-port", "Database port", jen.Lit("3306")},
+			{"User", jen.String(), "user", "DB_USER", "db-user", "Database user", jen.Lit("root")},
+			{"Pass", jen.String(), "pass", "DB_PASS", "", "", jen.Lit("123456")},
+			{"Name", jen.String(), "name", "DB_NAME", "db-name", "Database name", jen.Lit(utils.ToLowerSnakeCase(name))},
+		}
+	case DBSQLite:
+		return []configField{
+			{"Name", jen.String(), "name", "DB_NAME", "db-name", "Database file", jen.Lit(utils.ToLowerSnakeCase(name) + ".db")},
+		}
+	}
+	return []configField{
+		{"Host", jen.String(), "host", "DB_HOST", "db-host", "Database host", jen.Lit("localhost")},
+		{"Port", jen.String(), "port", "DB_PORT", "db-port", "Database port", jen.Lit("5432")},
+		{"User", jen.String(), "user", "DB_USER", "db-user", "Database user", jen.Lit("postgres")},
+		{"Pass", jen.String(), "pass", "DB_PASS", "", "", jen.Lit("123456")},
+		{"Name", jen.String(), "name", "DB_NAME", "db-name", "Database name", jen.Lit("postgres")},
+		{"Schema", jen.String(), "schema", "DB_SCHEMA", "db-schema", "Database schema", jen.Lit("public")},
+	}
+}
+
+// validate returns the check of the DB section in AppConfig.Validate.
+func (d *dbDriver) validate() *jen.Statement {
+	name := jen.Id("c").Dot("DB").Dot("Name").Op("==").Lit("")
+	if d.name == DBSQLite {
+		return jen.If(name).Block(
+			jen.Return(jen.Qual("errors", "New").Call(jen.Lit("the database file is required"))),
+		)
+	}
+	return jen.If(jen.Id("c").Dot("DB").Dot("Host").Op("==").Lit("").Op("||").Add(name)).Block(
+		jen.Return(jen.Qual("errors", "New").Call(jen.Lit("the database host and name are required"))),
+	)
+}
+
+// modelDatabase is the name of the database interface of the model package.
+func (d *dbDriver) modelDatabase() string {
+	return d.title + "Database"
+}
+
+// registry returns the source of the connection registry of the database.
+func (d *dbDriver) registry() string {
+	imports := append([]string{"fmt", "sync"}, d.dsnImports...)
+	sort.Strings(imports)
+	for i, v := range imports {
+		imports[i] = fmt.Sprintf("\t%q", v)
+	}
+	return fmt.Sprintf(`package %s
+
+import (
+%s
+
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/%s"
+)
+
+// Dialect is the driver gorm opens the database with.
+const Dialect = %q
+%s
+// DSN returns the data source name of the database.
+func DSN(info DBInfo) string {
+%s
+}
+`, d.name, strings.Join(imports, "\n"), d.name, d.dialect, dbRegistry, d.dsn)
+}
+
+// dbExists returns the driver of the connection registry of the service, nil if
+// the service was created without a database.
+func dbExists(f *fs.KitFs, name string) (*dbDriver, error) {
+	for _, v := range SupportedDatabases {
+		d := newDBDriver(v)
+		if d == nil {
+			continue
+		}
+		b, err := f.Exists(path.Join(
+			fmt.Sprintf(viper.GetString("gk_db_path_format"), utils.ToLowerSnakeCase(name)),
+			d.name,
+			viper.GetString("gk_db_postgre_file_name"),
+		))
+		if err != nil {
+			return nil, err
+		}
+		if b {
+			return d, nil
+		}
+	}
+	return nil, nil
+}
+
+const dbRegistry = `
+type DBInfo struct {
+	Host       string
+	Port       string
+	Name       string
+	User       string
+	Pass       string
+	SearchPath string
+}
+
+var (
+	dataBaseCache = &_dbCache{cache: make(map[string]*alias)}
+)
+
+type DB struct {
+	*sync.RWMutex
+	DB *gorm.DB
+}
+
+type alias struct {
+	Name         string
+	MaxIdleConns int
+	MaxOpenConns int
+	DB           *DB
+}
+
+type _dbCache struct {
+	mux   sync.RWMutex
+	cache map[string]*alias
+}
+
+func (ac *_dbCache) add(name string, al *alias) (added bool) {
+	ac.mux.Lock()
+	defer ac.mux.Unlock()
+	if _, ok := ac.cache[name]; !ok {
+		ac.cache[name] = al
+		added = true
+	}
+	return
+}
+
+// get db alias if cached.
+func (ac *_dbCache) get(name string) (al *alias, ok bool) {
+	ac.mux.RLock()
+	defer ac.mux.RUnlock()
+	al, ok = ac.cache[name]
+	return
+}
+
+// get default alias.
+func (ac *_dbCache) getDefault() (al *alias) {
+	al, _ = ac.get("default")
+	return
+}
+
+func GetDB(aliasNames ...string) (*gorm.DB, error) {
+	var name string
+	if len(aliasNames) > 0 {
+		name = aliasNames[0]
+	} else {
+		name = "default"
+	}
+	al, ok := dataBaseCache.get(name)
+	if ok {
+		return al.DB.DB.Debug(), nil
+	}
+	return &gorm.DB{}, fmt.Errorf("DataBase of alias name %s not found", name)
+}
+
+func RegisterDataBase(aliasName, driverName, dataSource string, params ...int) error {
+	var (
+		err error
+		db  *gorm.DB
+		al  *alias
+	)
+
+	db, err = gorm.Open(driverName, dataSource)
+	if err != nil {
+		err = fmt.Errorf("register db %s, %s", aliasName, err.Error())
+		goto end
+	}
+
+	al, err = addAliasWthDB(aliasName, driverName, db)
+	if err != nil {
+		goto end
+	}
+
+	for i, v := range params {
+		switch i {
+		case 0:
+			SetMaxIdleConns(al.Name, v)
+		case 1:
+			SetMaxOpenConns(al.Name, v)
+		}
+	}
+
+end:
+	if err != nil {
+		if db != nil {
+			//_ = db.Close()
+		}
+	}
+
+	return err
+}
+
+func addAliasWthDB(aliasName, driverName string, db *gorm.DB) (*alias, error) {
+	al := new(alias)
+	al.Name = aliasName
+	al.DB = &DB{
+		RWMutex: new(sync.RWMutex),
+		DB:      db,
+	}
+
+	err := db.DB().Ping()
+	if err != nil {
+		return nil, fmt.Errorf("register db Ping %s, %s", aliasName, err.Error())
+	}
+
+	if !dataBaseCache.add(aliasName, al) {
+		return nil, fmt.Errorf("DataBase alias name %s already registered, cannot reuse", aliasName)
+	}
+
+	return al, nil
+}
+
+// get table alias.
+func getDbAlias(name string) *alias {
+	if al, ok := dataBaseCache.get(name); ok {
+		return al
+	}
+	panic(fmt.Errorf("unknown DataBase alias name %s", name))
+}
+
+// SetMaxIdleConns ChangeNumber the max idle conns for *sql.DB, use specify db alias name
+func SetMaxIdleConns(aliasName string, maxIdleConns int) {
+	al := getDbAlias(aliasName)
+	al.MaxIdleConns = maxIdleConns
+	al.DB.DB.DB().SetMaxIdleConns(maxIdleConns)
+}
+
+// SetMaxOpenConns ChangeNumber the max open conns for *sql.DB, use specify db alias name
+func SetMaxOpenConns(aliasName string, maxOpenConns int) {
+	al := getDbAlias(aliasName)
+	al.MaxOpenConns = maxOpenConns
+	al.DB.DB.DB().SetMaxOpenConns(maxOpenConns)
+}
+`
@@ -0,0 +1,152 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kujtimiihoxha/kit/parser"
+)
+
+func TestNewDatabase_Generate(t *testing.T) {
+	tests := []struct {
+		db      string
+		path    string
+		want    []string
+		notWant []string
+	}{
+		{
+			db:   DBPostgres,
+			path: "test/pkg/db/postgres",
+			want: []string{
+				`_ "github.com/jinzhu/gorm/dialects/postgres"`,
+				`const Dialect = "postgres"`,
+				"Host:     net.JoinHostPort(info.Host, info.Port),",
+				"SearchPath: conf.Schema,",
+				"RegisterDataBase(customerSchema, Dialect, DSN(dbInfo))",
+			},
+		},
+		{
+			db:   DBMySQL,
+			path: "test/pkg/db/mysql",
+			want: []string{
+				`_ "github.com/jinzhu/gorm/dialects/mysql"`,
+				`const Dialect = "mysql"`,
+				"parseTime=True",
+			},
+			notWant: []string{"SearchPath: conf."},
+		},
+		{
+			db:   DBSQLite,
+			path: "test/pkg/db/sqlite",
+			want: []string{
+				`_ "github.com/jinzhu/gorm/dialects/sqlite"`,
+				`const Dialect = "sqlite3"`,
+				"Name: conf.Name",
+			},
+			notWant: []string{"Host: conf.Host"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.db, func(t *testing.T) {
+			f := newClientTestFs()
+			if err := NewNewDatabase("test", tt.db).Generate(); err != nil {
+				t.Fatalf("NewDatabase.Generate() error = %v", err)
+			}
+			db, err := f.ReadFile(tt.path + "/db.go")
+			if err != nil {
+				t.Fatal(err)
+			}
+			conf, err := f.ReadFile(tt.path + "/config.go")
+			if err != nil {
+				t.Fatal(err)
+			}
+			src := db + conf
+			for _, v := range tt.want {
+				if !strings.Contains(src, v) {
+					t.Errorf("NewDatabase.Generate() does not contain %q", v)
+				}
+			}
+			for _, v := range tt.notWant {
+				if strings.Contains(src, v) {
+					t.Errorf("NewDatabase.Generate() should not contain %q", v)
+				}
+			}
+			if strings.Contains(src, "CreateDBConnectionString") {
+				t.Error("NewDatabase.Generate() should build the DSN with DSN")
+			}
+		})
+	}
+}
+
+func TestNewModel_Generate(t *testing.T) {
+	f := newClientTestFs()
+	if err := NewNewModel("test", DBMySQL).Generate(); err != nil {
+		t.Fatalf("NewModel.Generate() error = %v", err)
+	}
+	src, err := f.ReadFile("test/pkg/model/base.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		`gorm:"primary_key;type:char(36)"`,
+		"func (m *BaseModel) BeforeCreate(scope *gorm.Scope) error",
+		`dbPublic, err := mysql.GetDatabase("default")`,
+	} {
+		if !strings.Contains(src, v) {
+			t.Errorf("NewModel.Generate() base.go does not contain %q", v)
+		}
+	}
+	if strings.Contains(src, "uuid-ossp") {
+		t.Error("NewModel.Generate() should only create the uuid-ossp extension on Postgres")
+	}
+	src, err = f.ReadFile("test/pkg/model/mysql.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(src, "func NewBasicMySQLDatabase(db *gorm.DB) MySQLDatabase") {
+		t.Errorf("NewModel.Generate() mysql.go does not contain the MySQL database, got %s", src)
+	}
+}
+
+func TestNewService_GenerateWithoutDatabase(t *testing.T) {
+	f := newClientTestFs()
+	f.Fs.RemoveAll("test/pkg/db")
+	for _, g := range []Gen{NewNewModel("test", DBNone), NewNewDatabase("test", DBNone), NewNewConfig("test", DBNone)} {
+		if err := g.Generate(); err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+	}
+	if b, _ := f.Exists("test/pkg/model"); b {
+		t.Error("NewModel.Generate() should not generate the model without a database")
+	}
+	src, err := f.ReadFile("test/config/config.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(src, "DBConfig") {
+		t.Error("NewConfig.Generate() should not generate the database settings without a database")
+	}
+	svcSrc, err := f.ReadFile("test/pkg/service/service.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	svcFile, err := parser.NewFileParser().Parse([]byte(svcSrc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := newGenerateCmd("test", "", svcFile.Interfaces[0], false, false, nil).Generate(); err != nil {
+		t.Fatalf("generateCmd.Generate() error = %v", err)
+	}
+	src, err = f.ReadFile("test/cmd/service/service.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(src, "svc := service.New(getServiceMiddleware(logger))") {
+		t.Errorf("generateCmd.Generate() should create the service without a database, got %s", src)
+	}
+	for _, v := range []string{"GetDatabase", "AutoMigration", "d.Close()"} {
+		if strings.Contains(src, v) {
+			t.Errorf("generateCmd.Generate() should not contain %q without a database", v)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package generator
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/kujtimiihoxha/kit/fs"
+	"github.com/kujtimiihoxha/kit/utils"
+	"github.com/spf13/viper"
+)
+
+// errorKind is a kind of the service errors and the HTTP status and gRPC code
+// the transports map it to.
+type errorKind struct {
+	name       string
+	value      string
+	httpStatus string
+	grpcCode   string
+	// statusKey is the key of the kind in the status catalog.
+	statusKey string
+}
+
+// errorKinds are the kinds of the generated service errors, the order is the
+// order used to map a HTTP status or a gRPC code back to a kind.
+var errorKinds = []errorKind{
+	{"InvalidArgument", "invalid_argument", "StatusBadRequest", "InvalidArgument", "bad_request"},
+	{"Unauthorized", "unauthorized", "StatusUnauthorized", "Unauthenticated", "unauthorized"},
+	{"Forbidden", "forbidden", "StatusForbidden", "PermissionDenied", "forbidden"},
+	{"NotFound", "not_found", "StatusNotFound", "NotFound", "not_found"},
+	{"Conflict", "conflict", "StatusConflict", "AlreadyExists", "conflict"},
+	{"Unavailable", "unavailable", "StatusServiceUnavailable", "Unavailable", "unavailable"},
+	{"Internal", "internal", "StatusInternalServerError", "Internal", "internal"},
+}
+
+type generateServiceErrors struct {
+	BaseGenerator
+	name     string
+	destPath string
+	filePath string
+}
+
+func newGenerateServiceErrors(name string) Gen {
+	i := &generateServiceErrors{
+		name:     name,
+		destPath: fmt.Sprintf(viper.GetString("gk_service_path_format"), utils.ToLowerSnakeCase(name)),
+	}
+	i.filePath = path.Join(i.destPath, viper.GetString("gk_service_errors_file_name"))
+	i.srcFile = jen.NewFilePath(i.destPath)
+	i.InitPg()
+	i.fs = fs.Get()
+	return i
+}
+
+// Generate generates the typed errors of the service, the file is only generated
+// once so the users can add their own kinds.
+func (g *generateServiceErrors) Generate() (err error) {
+	if b, err := g.fs.Exists(g.filePath); err != nil || b {
+		return err
+	}
+	consts := []jen.Code{
+		jen.Id("KindUnknown").Id("Kind").Op("=").Lit("unknown"),
+	}
+	kinds := []jen.Code{}
+	httpCases := []jen.Code{}
+	grpcCases := []jen.Code{}
+	for _, v := range errorKinds {
+		consts = append(consts, jen.Id("Kind"+v.name).Id("Kind").Op("=").Lit(v.value))
+		kinds = append(kinds, jen.Id("Kind"+v.name))
+		httpCases = append(httpCases, jen.Case(jen.Id("Kind"+v.name)).Block(
+			jen.Return(jen.Qual("net/http", v.httpStatus)),
+		))
+		grpcCases = append(grpcCases, jen.Case(jen.Id("Kind"+v.name)).Block(
+			jen.Return(jen.Qual("google.golang.org/grpc/codes", v.grpcCode)),
+		))
+	}
+	g.code.appendMultilineComment([]string{
+		"Kind is the kind of a service error, the transports map the kinds to HTTP",
+		"statuses and gRPC codes so the callers get back an error of the same kind.",
+	})
+	g.code.NewLine()
+	g.code.Raw().Type().Id("Kind").String().Line()
+	g.code.NewLine()
+	g.code.Raw().Comment("The kinds of the service errors.").Line()
+	g.code.Raw().Const().Defs(consts...).Line()
+	g.code.NewLine()
+	g.code.Raw().Var().Id("kinds").Op("=").Index().Id("Kind").Values(kinds...).Line()
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"Error is an error of the service with a kind.",
+	})
+	g.code.NewLine()
+	g.code.appendStruct(
+		"Error",
+		jen.Id("Kind").Id("Kind"),
+		jen.Id("Message").String(),
+		jen.Comment("Status is the key of the status of the status catalog the error is"),
+		jen.Comment("encoded with, the status of the kind is used if it is empty."),
+		jen.Id("Status").String(),
+	)
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"Error implements the error interface.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"Error",
+		jen.Id("e").Id("*Error"),
+		[]jen.Code{},
+		[]jen.Code{},
+		"string",
+		jen.Return(jen.Id("e").Dot("Message")),
+	)
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"StatusKey returns the key of the status of the status catalog the error is",
+		"encoded with.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"StatusKey",
+		jen.Id("e").Id("*Error"),
+		[]jen.Code{},
+		[]jen.Code{},
+		"string",
+		jen.Return(jen.Id("e").Dot("Status")),
+	)
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"GRPCStatus makes the grpc server respond with the code of the kind.",
+	})
+	g.code.NewLine()
+	g.code.Raw().Func().Params(jen.Id("e").Id("*Error")).Id("GRPCStatus").Params().Op("*").Qual(
+		"google.golang.org/grpc/status", "Status",
+	).Block(
+		jen.Return(jen.Qual("google.golang.org/grpc/status", "New").Call(
+			jen.Id("e").Dot("Kind").Dot("GRPCCode").Call(),
+			jen.Id("e").Dot("Message"),
+		)),
+	).Line()
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"NewError returns an error of the kind.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"NewError",
+		nil,
+		[]jen.Code{
+			jen.Id("kind").Id("Kind"),
+			jen.Id("format").String(),
+			jen.Id("args").Op("...").Interface(),
+		},
+		[]jen.Code{},
+		"error",
+		jen.Return(jen.Op("&").Id("Error").Values(jen.Dict{
+			jen.Id("Kind"):    jen.Id("kind"),
+			jen.Id("Message"): jen.Qual("fmt", "Sprintf").Call(jen.Id("format"), jen.Id("args").Op("...")),
+		})),
+	)
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"NewStatusError returns an error of the kind that is encoded with the status of",
+		"the key, use it for the statuses added with `kit g status add`.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"NewStatusError",
+		nil,
+		[]jen.Code{
+			jen.Id("key").String(),
+			jen.Id("kind").Id("Kind"),
+			jen.Id("format").String(),
+			jen.Id("args").Op("...").Interface(),
+		},
+		[]jen.Code{},
+		"error",
+		jen.Return(jen.Op("&").Id("Error").Values(jen.Dict{
+			jen.Id("Kind"):    jen.Id("kind"),
+			jen.Id("Message"): jen.Qual("fmt", "Sprintf").Call(jen.Id("format"), jen.Id("args").Op("...")),
+			jen.Id("Status"):  jen.Id("key"),
+		})),
+	)
+	g.code.NewLine()
+	for _, v := range errorKinds {
+		g.code.appendMultilineComment([]string{
+			fmt.Sprintf("%s returns a Kind%s error.", v.name, v.name),
+		})
+		g.code.NewLine()
+		g.code.appendFunction(
+			v.name,
+			nil,
+			[]jen.Code{
+				jen.Id("format").String(),
+				jen.Id("args").Op("...").Interface(),
+			},
+			[]jen.Code{},
+			"error",
+			jen.Return(jen.Id("NewError").Call(jen.Id("Kind"+v.name), jen.Id("format"), jen.Id("args").Op("..."))),
+		)
+		g.code.NewLine()
+	}
+	g.code.appendMultilineComment([]string{
+		"KindOf returns the kind of the error, KindUnknown if it is not a service error.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"KindOf",
+		nil,
+		[]jen.Code{
+			jen.Err().Error(),
+		},
+		[]jen.Code{},
+		"Kind",
+		jen.Var().Id("e").Id("*Error"),
+		jen.If(jen.Qual("errors", "As").Call(jen.Err(), jen.Op("&").Id("e"))).Block(
+			jen.Return(jen.Id("e").Dot("Kind")),
+		),
+		jen.Return(jen.Id("KindUnknown")),
+	)
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"HTTPStatus returns the HTTP status of the kind.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"HTTPStatus",
+		jen.Id("k").Id("Kind"),
+		[]jen.Code{},
+		[]jen.Code{},
+		"int",
+		jen.Switch(jen.Id("k")).Block(httpCases...),
+		jen.Return(jen.Qual("net/http", "StatusInternalServerError")),
+	)
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"GRPCCode returns the gRPC code of the kind.",
+	})
+	g.code.NewLine()
+	g.code.Raw().Func().Params(jen.Id("k").Id("Kind")).Id("GRPCCode").Params().Qual(
+		"google.golang.org/grpc/codes", "Code",
+	).Block(
+		jen.Switch(jen.Id("k")).Block(grpcCases...),
+		jen.Return(jen.Qual("google.golang.org/grpc/codes", "Unknown")),
+	).Line()
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"FromHTTPStatus returns an error of the kind of the HTTP status, it is used by",
+		"the clients when the response does not say the kind of the error.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"FromHTTPStatus",
+		nil,
+		[]jen.Code{
+			jen.Id("code").Int(),
+			jen.Id("message").String(),
+		},
+		[]jen.Code{},
+		"error",
+		jen.For(jen.List(jen.Id("_"), jen.Id("k")).Op(":=").Range().Id("kinds")).Block(
+			jen.If(jen.Id("k").Dot("HTTPStatus").Call().Op("==").Id("code")).Block(
+				jen.Return(jen.Op("&").Id("Error").Values(jen.Dict{
+					jen.Id("Kind"):    jen.Id("k"),
+					jen.Id("Message"): jen.Id("message"),
+				})),
+			),
+		),
+		jen.Return(jen.Op("&").Id("Error").Values(jen.Dict{
+			jen.Id("Kind"):    jen.Id("KindUnknown"),
+			jen.Id("Message"): jen.Id("message"),
+		})),
+	)
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"FromGRPCError translates the error of a gRPC call back to a service error.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"FromGRPCError",
+		nil,
+		[]jen.Code{
+			jen.Err().Error(),
+		},
+		[]jen.Code{},
+		"error",
+		jen.List(jen.Id("s"), jen.Id("ok")).Op(":=").Qual("google.golang.org/grpc/status", "FromError").Call(jen.Err()),
+		jen.If(jen.Op("!").Id("ok")).Block(
+			jen.Return(jen.Err()),
+		),
+		jen.For(jen.List(jen.Id("_"), jen.Id("k")).Op(":=").Range().Id("kinds")).Block(
+			jen.If(jen.Id("k").Dot("GRPCCode").Call().Op("==").Id("s").Dot("Code").Call()).Block(
+				jen.Return(jen.Op("&").Id("Error").Values(jen.Dict{
+					jen.Id("Kind"):    jen.Id("k"),
+					jen.Id("Message"): jen.Id("s").Dot("Message").Call(),
+				})),
+			),
+		),
+		jen.Return(jen.Op("&").Id("Error").Values(jen.Dict{
+			jen.Id("Kind"):    jen.Id("KindUnknown"),
+			jen.Id("Message"): jen.Id("s").Dot("Message").Call(),
+		})),
+	)
+	g.code.NewLine()
+	return g.fs.WriteFile(g.filePath, g.srcFile.GoString(), false)
+}
@@ -0,0 +1,40 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateServiceErrors_Generate(t *testing.T) {
+	f := newClientTestFs()
+	if err := newGenerateServiceErrors("test").Generate(); err != nil {
+		t.Fatalf("generateServiceErrors.Generate() error = %v", err)
+	}
+	src, err := f.ReadFile("test/pkg/service/errors.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		`KindNotFound        Kind = "not_found"`,
+		"func NotFound(format string, args ...interface{}) error",
+		"func KindOf(err error) Kind",
+		"func NewStatusError(key string, kind Kind, format string, args ...interface{}) error",
+		"func (e *Error) StatusKey() string",
+		"func (e *Error) GRPCStatus() *status.Status",
+		"return http.StatusNotFound",
+		"return codes.NotFound",
+		"func FromHTTPStatus(code int, message string) error",
+		"func FromGRPCError(err error) error",
+	} {
+		if !strings.Contains(src, v) {
+			t.Errorf("generateServiceErrors.Generate() does not contain %q", v)
+		}
+	}
+	f.WriteFile("test/pkg/service/errors.go", "package service\n", true)
+	if err := newGenerateServiceErrors("test").Generate(); err != nil {
+		t.Fatalf("generateServiceErrors.Generate() error = %v", err)
+	}
+	if src, _ = f.ReadFile("test/pkg/service/errors.go"); src != "package service\n" {
+		t.Error("generateServiceErrors.Generate() should not override the existing errors")
+	}
+}
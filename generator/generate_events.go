@@ -0,0 +1,841 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	ps "go/parser"
+	"go/token"
+	"path"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/kujtimiihoxha/kit/fs"
+	"github.com/kujtimiihoxha/kit/parser"
+	"github.com/kujtimiihoxha/kit/utils"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// The publishers the relay of the outbox can publish the events with.
+const (
+	PublisherNATS   = "nats"
+	PublisherKafka  = "kafka"
+	PublisherMemory = "memory"
+)
+
+// SupportedPublishers are the publishers the relay of the outbox can use.
+var SupportedPublishers = []string{PublisherNATS, PublisherKafka, PublisherMemory}
+
+const (
+	natsImport  = "github.com/nats-io/nats.go"
+	kafkaImport = "github.com/segmentio/kafka-go"
+	outboxModel = "OutboxEvent"
+	outboxTable = "outbox_events"
+)
+
+// outboxMigrations are the statements that create the outbox table, by database.
+var outboxMigrations = map[string]string{
+	DBPostgres: `CREATE TABLE IF NOT EXISTS outbox_events (
+	id BIGSERIAL PRIMARY KEY,
+	topic VARCHAR(255) NOT NULL,
+	event_key VARCHAR(255) NOT NULL,
+	payload BYTEA NOT NULL,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	published_at TIMESTAMP WITH TIME ZONE NULL
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_published_at ON outbox_events (published_at);
+`,
+	DBMySQL: `CREATE TABLE IF NOT EXISTS outbox_events (
+	id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+	topic VARCHAR(255) NOT NULL,
+	event_key VARCHAR(255) NOT NULL,
+	payload LONGBLOB NOT NULL,
+	created_at DATETIME NOT NULL,
+	published_at DATETIME NULL,
+	INDEX idx_outbox_events_published_at (published_at)
+);
+`,
+	DBSQLite: `CREATE TABLE IF NOT EXISTS outbox_events (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	topic VARCHAR(255) NOT NULL,
+	event_key VARCHAR(255) NOT NULL,
+	payload BLOB NOT NULL,
+	created_at DATETIME NOT NULL,
+	published_at DATETIME NULL
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_published_at ON outbox_events (published_at);
+`,
+}
+
+// GenerateEvents implements Gen and is used to generate the outbox of a service,
+// the events are added to the outbox in the transaction of the changes they
+// describe and a relay publishes them to NATS, Kafka or in memory.
+type GenerateEvents struct {
+	BaseGenerator
+	name              string
+	publisher         string
+	destPath          string
+	filePath          string
+	genFilePath       string
+	modelPath         string
+	db                *dbDriver
+	dbImport          string
+	modelImport       string
+	file              *parser.File
+	generateFirstTime bool
+}
+
+// NewGenerateEvents returns a initialized and ready generator.
+//
+// The name parameter is the name of the service, publisher is the publisher the
+// relay uses by default (nats, kafka or memory).
+func NewGenerateEvents(name, publisher string) Gen {
+	i := &GenerateEvents{
+		name:      name,
+		publisher: publisher,
+		destPath:  fmt.Sprintf(viper.GetString("gk_events_path_format"), utils.ToLowerSnakeCase(name)),
+		modelPath: fmt.Sprintf(viper.GetString("gk_model_path_format"), utils.ToLowerSnakeCase(name)),
+	}
+	i.filePath = path.Join(i.destPath, viper.GetString("gk_events_file_name"))
+	i.genFilePath = path.Join(i.destPath, viper.GetString("gk_events_base_file_name"))
+	i.srcFile = jen.NewFilePath(i.destPath)
+	i.InitPg()
+	i.fs = fs.Get()
+	return i
+}
+
+// Generate generates the outbox model, the events package with the publisher
+// and wires the relay in the service.
+func (g *GenerateEvents) Generate() (err error) {
+	supported := false
+	for _, v := range SupportedPublishers {
+		supported = supported || v == g.publisher
+	}
+	if !supported {
+		return fmt.Errorf("publisher `%s` not supported, use %s", g.publisher, strings.Join(SupportedPublishers, ", "))
+	}
+	g.db, err = dbExists(g.fs, g.name)
+	if err != nil {
+		return err
+	}
+	if g.db == nil {
+		return fmt.Errorf("service `%s` does not have a database, create it with `kit new service --db`", g.name)
+	}
+	if g.dbImport, err = utils.GetDBImportPath(g.name, g.db.name); err != nil {
+		return err
+	}
+	if g.modelImport, err = utils.GetModelImportPath(g.name); err != nil {
+		return err
+	}
+	if err = g.generateOutbox(); err != nil {
+		return err
+	}
+	// Transaction passes the transaction to the repositories in the context.
+	tx := &GenerateModel{name: g.name, destPath: g.modelPath}
+	tx.fs = g.fs
+	if err = tx.generateTx(); err != nil {
+		return err
+	}
+	if err = g.generateEvents(); err != nil {
+		return err
+	}
+	if err = g.generatePublisher(); err != nil {
+		return err
+	}
+	if err = g.generateNewPublisher(); err != nil {
+		return err
+	}
+	return g.addToCmd()
+}
+
+// generateOutbox generates the outbox model, registers it in AutoMigration and adds
+// the migration of the outbox table if the service has migrations.
+func (g *GenerateEvents) generateOutbox() error {
+	filePath := path.Join(g.modelPath, "outbox.go")
+	if b, err := g.fs.Exists(filePath); err != nil || b {
+		return err
+	}
+	if err := g.CreateFolderStructure(g.modelPath); err != nil {
+		return err
+	}
+	f := jen.NewFilePath(g.modelPath)
+	f.Commentf(
+		"%s is an event of the outbox, it is added in the transaction of the changes it",
+		outboxModel,
+	)
+	f.Comment("describes and published by the relay of the events package once it is committed.")
+	f.Type().Id(outboxModel).Struct(
+		jen.Id("ID").Uint64().Tag(map[string]string{"gorm": "primary_key"}),
+		jen.Id("Topic").String().Tag(map[string]string{"gorm": "not null"}),
+		jen.Id("Key").String().Tag(map[string]string{"gorm": "column:event_key;not null"}),
+		jen.Id("Payload").Index().Byte().Tag(map[string]string{"gorm": "not null"}),
+		jen.Id("CreatedAt").Qual("time", "Time"),
+		jen.Id("PublishedAt").Op("*").Qual("time", "Time").Tag(map[string]string{"gorm": "index"}),
+	)
+	f.Line()
+	f.Comment("TableName returns the table of the outbox.")
+	f.Func().Params(jen.Id(outboxModel)).Id("TableName").Params().String().Block(
+		jen.Return(jen.Lit(outboxTable)),
+	)
+	if err := g.fs.WriteFile(filePath, f.GoString(), false); err != nil {
+		return err
+	}
+	m := &GenerateModel{name: g.name, modelName: outboxModel, destPath: g.modelPath}
+	m.fs = g.fs
+	if err := m.registerMigration(); err != nil {
+		return err
+	}
+	if b, err := migrationExists(g.fs, g.name); err != nil || !b {
+		return err
+	}
+	migration := NewGenerateMigration(g.name, "create_"+outboxTable).(*GenerateMigration)
+	migration.up = outboxMigrations[g.db.name]
+	migration.down = "DROP TABLE IF EXISTS " + outboxTable + ";\n"
+	return migration.Generate()
+}
+
+// generateEvents generates the declarations that are missing from the events package.
+func (g *GenerateEvents) generateEvents() (err error) {
+	if err = g.CreateFolderStructure(g.destPath); err != nil {
+		return err
+	}
+	if b, err := g.fs.Exists(g.filePath); err != nil {
+		return err
+	} else if !b {
+		g.generateFirstTime = true
+		f := jen.NewFile("events")
+		g.fs.WriteFile(g.filePath, f.GoString(), false)
+	}
+	src, err := g.fs.ReadFile(g.filePath)
+	if err != nil {
+		return err
+	}
+	g.file, err = parser.NewFileParser().Parse([]byte(src))
+	if err != nil {
+		return err
+	}
+	found := map[string]bool{}
+	for _, v := range g.file.Methods {
+		if v.Struct.Type == "" {
+			found[v.Name] = true
+		}
+	}
+	for _, v := range g.file.Interfaces {
+		found[v.Name] = true
+	}
+	for _, v := range g.file.Structures {
+		found[v.Name] = true
+	}
+	if !found["Publisher"] {
+		g.generatePublisherInterface()
+	}
+	if !found["Publish"] {
+		g.generatePublish()
+	}
+	if !found["Transaction"] {
+		g.generateTransaction()
+	}
+	if !found["Relay"] {
+		g.generateRelay()
+	}
+	if !found["MemoryPublisher"] {
+		g.generateMemoryPublisher()
+	}
+	if g.generateFirstTime {
+		return g.fs.WriteFile(g.filePath, g.srcFile.GoString(), true)
+	}
+	pSrc, err := g.partialSource()
+	if err != nil {
+		return err
+	}
+	src, err = utils.GoImportsSource(g.destPath, src+"\n"+pSrc)
+	if err != nil {
+		return err
+	}
+	return g.fs.WriteFile(g.filePath, src, true)
+}
+
+func (g *GenerateEvents) generatePublisherInterface() {
+	g.code.appendMultilineComment([]string{
+		"Publisher publishes the events of the outbox to a broker, the topic of an event",
+		"is the subject or the topic it is published to.",
+	})
+	g.code.NewLine()
+	g.code.appendInterface("Publisher", []jen.Code{
+		jen.Id("Publish").Params(
+			jen.Id("ctx").Qual("context", "Context"),
+			jen.List(jen.Id("topic"), jen.Id("key")).String(),
+			jen.Id("payload").Index().Byte(),
+		).Error(),
+		jen.Id("Close").Params().Error(),
+	})
+	g.code.NewLine()
+}
+
+func (g *GenerateEvents) generatePublish() {
+	g.code.appendMultilineComment([]string{
+		"Publish adds the JSON-encoded event to the outbox in the transaction tx, the relay",
+		"publishes it once tx is committed so the event is only published if the changes",
+		"it describes are saved, e.x:",
+		"",
+		"	err := events.Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {",
+		"		if err := todos.Create(ctx, &todo); err != nil {",
+		"			return err",
+		"		}",
+		"		return events.Publish(tx, \"todo.created\", todo.ID.String(), todo)",
+		"	})",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"Publish",
+		nil,
+		[]jen.Code{
+			jen.Id("tx").Op("*").Qual(gormImport, "DB"),
+			jen.List(jen.Id("topic"), jen.Id("key")).String(),
+			jen.Id("event").Interface(),
+		},
+		[]jen.Code{},
+		"error",
+		jen.List(jen.Id("payload"), jen.Err()).Op(":=").Qual("encoding/json", "Marshal").Call(jen.Id("event")),
+		jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Err())),
+		jen.Return(jen.Id("tx").Dot("Create").Call(
+			jen.Op("&").Qual(g.modelImport, outboxModel).Values(jen.Dict{
+				jen.Id("Topic"):   jen.Id("topic"),
+				jen.Id("Key"):     jen.Id("key"),
+				jen.Id("Payload"): jen.Id("payload"),
+			}),
+		).Dot("Error")),
+	)
+	g.code.NewLine()
+	g.code.NewLine()
+}
+
+func (g *GenerateEvents) generateTransaction() {
+	g.code.appendMultilineComment([]string{
+		"Transaction runs fn in a transaction of the default connection, the transaction",
+		"is committed if fn succeeds and rolled back otherwise. The context of fn carries",
+		"the transaction so the repositories called with it run their queries in it.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"Transaction",
+		nil,
+		[]jen.Code{
+			jen.Id("ctx").Qual("context", "Context"),
+			jen.Id("fn").Func().Params(
+				jen.Id("ctx").Qual("context", "Context"),
+				jen.Id("tx").Op("*").Qual(gormImport, "DB"),
+			).Error(),
+		},
+		[]jen.Code{},
+		"error",
+		jen.List(jen.Id("db"), jen.Err()).Op(":=").Qual(g.dbImport, "GetDatabase").Call(
+			jen.Qual(g.dbImport, "DefaultConnName"),
+		),
+		jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Err())),
+		jen.Id("tx").Op(":=").Id("db").Dot("BeginTx").Call(jen.Id("ctx"), jen.Nil()),
+		jen.If(jen.Id("tx").Dot("Error").Op("!=").Nil()).Block(jen.Return(jen.Id("tx").Dot("Error"))),
+		jen.Defer().Func().Params().Block(
+			jen.If(jen.Id("r").Op(":=").Recover(), jen.Id("r").Op("!=").Nil()).Block(
+				jen.Id("tx").Dot("Rollback").Call(),
+				jen.Panic(jen.Id("r")),
+			),
+		).Call(),
+		jen.If(
+			jen.Err().Op("=").Id("fn").Call(jen.Qual(g.modelImport, "ContextWithTx").Call(jen.Id("ctx"), jen.Id("tx")), jen.Id("tx")),
+			jen.Err().Op("!=").Nil(),
+		).Block(
+			jen.Id("tx").Dot("Rollback").Call(),
+			jen.Return(jen.Err()),
+		),
+		jen.Return(jen.Id("tx").Dot("Commit").Call().Dot("Error")),
+	)
+	g.code.NewLine()
+	g.code.NewLine()
+}
+
+func (g *GenerateEvents) generateRelay() {
+	g.code.appendMultilineComment([]string{
+		"Relay publishes the events of the outbox in the order they were added. The events",
+		"are published at least once, an event is published again if the relay stops",
+		"before it is marked as published.",
+	})
+	g.code.NewLine()
+	g.code.appendStruct(
+		"Relay",
+		jen.Id("db").Op("*").Qual(gormImport, "DB"),
+		jen.Id("publisher").Id("Publisher"),
+		jen.Id("logger").Qual(kitLogImport, "Logger"),
+		jen.Comment("Interval is the time between two reads of the outbox."),
+		jen.Id("Interval").Qual("time", "Duration"),
+		jen.Comment("BatchSize is the maximum number of events published by a read of the outbox."),
+		jen.Id("BatchSize").Int(),
+	)
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"NewRelay returns a relay that publishes the events of the outbox of db with the",
+		"publisher every second.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"NewRelay",
+		nil,
+		[]jen.Code{
+			jen.Id("db").Op("*").Qual(gormImport, "DB"),
+			jen.Id("publisher").Id("Publisher"),
+			jen.Id("logger").Qual(kitLogImport, "Logger"),
+		},
+		[]jen.Code{},
+		"*Relay",
+		jen.Return(jen.Op("&").Id("Relay").Values(jen.Dict{
+			jen.Id("db"):        jen.Id("db"),
+			jen.Id("publisher"): jen.Id("publisher"),
+			jen.Id("logger"):    jen.Id("logger"),
+			jen.Id("Interval"):  jen.Qual("time", "Second"),
+			jen.Id("BatchSize"): jen.Lit(100),
+		})),
+	)
+	g.code.NewLine()
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"Run publishes the events of the outbox every interval until ctx is done.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"Run",
+		jen.Id("r").Op("*").Id("Relay"),
+		[]jen.Code{jen.Id("ctx").Qual("context", "Context")},
+		[]jen.Code{},
+		"error",
+		jen.Id("ticker").Op(":=").Qual("time", "NewTicker").Call(jen.Id("r").Dot("Interval")),
+		jen.Defer().Id("ticker").Dot("Stop").Call(),
+		jen.For().Block(
+			jen.If(
+				jen.List(jen.Id("n"), jen.Err()).Op(":=").Id("r").Dot("publish").Call(jen.Id("ctx")),
+				jen.Err().Op("!=").Nil(),
+			).Block(
+				logLevel("Error", jen.Id("r").Dot("logger")).Dot("Log").Call(
+					jen.Lit("component"), jen.Lit("relay"),
+					jen.Lit("published"), jen.Id("n"),
+					jen.Lit("err"), jen.Err(),
+				),
+			),
+			jen.Select().Block(
+				jen.Case(jen.Op("<-").Id("ctx").Dot("Done").Call()).Block(jen.Return(jen.Nil())),
+				jen.Case(jen.Op("<-").Id("ticker").Dot("C")),
+			),
+		),
+	)
+	g.code.NewLine()
+	g.code.NewLine()
+	comment := []string{
+		"publish publishes a batch of the events that are not published yet and marks them",
+		"as published.",
+	}
+	query := jen.Id("tx")
+	if g.db.name != DBSQLite {
+		comment[1] = "as published, the rows are locked so the instances of the service share the outbox."
+		query = query.Dot("Set").Call(jen.Lit("gorm:query_option"), jen.Lit("FOR UPDATE SKIP LOCKED"))
+	}
+	g.code.appendMultilineComment(comment)
+	g.code.NewLine()
+	g.code.Raw().Func().Params(jen.Id("r").Op("*").Id("Relay")).Id("publish").Params(
+		jen.Id("ctx").Qual("context", "Context"),
+	).Params(jen.Int(), jen.Error()).Block(
+		jen.Id("tx").Op(":=").Id("r").Dot("db").Dot("Begin").Call(),
+		jen.If(jen.Id("tx").Dot("Error").Op("!=").Nil()).Block(jen.Return(jen.Lit(0), jen.Id("tx").Dot("Error"))),
+		jen.Comment("Rollback does nothing once the transaction is committed."),
+		jen.Defer().Id("tx").Dot("Rollback").Call(),
+		jen.Var().Id("events").Index().Qual(g.modelImport, outboxModel),
+		jen.Err().Op(":=").Add(query).Dot("Where").Call(jen.Lit("published_at IS NULL")).
+			Dot("Order").Call(jen.Lit("id")).
+			Dot("Limit").Call(jen.Id("r").Dot("BatchSize")).
+			Dot("Find").Call(jen.Op("&").Id("events")).Dot("Error"),
+		jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Lit(0), jen.Err())),
+		jen.Id("ids").Op(":=").Index().Uint64().Values(),
+		jen.For(jen.List(jen.Id("_"), jen.Id("e")).Op(":=").Range().Id("events")).Block(
+			jen.If(
+				jen.Err().Op("=").Id("r").Dot("publisher").Dot("Publish").Call(
+					jen.Id("ctx"),
+					jen.Id("e").Dot("Topic"),
+					jen.Id("e").Dot("Key"),
+					jen.Id("e").Dot("Payload"),
+				),
+				jen.Err().Op("!=").Nil(),
+			).Block(jen.Break()),
+			jen.Id("ids").Op("=").Append(jen.Id("ids"), jen.Id("e").Dot("ID")),
+		),
+		jen.If(jen.Len(jen.Id("ids")).Op(">").Lit(0)).Block(
+			jen.Id("res").Op(":=").Id("tx").Dot("Model").Call(jen.Op("&").Qual(g.modelImport, outboxModel).Values()).
+				Dot("Where").Call(jen.Lit("id IN (?)"), jen.Id("ids")).
+				Dot("Update").Call(jen.Lit("published_at"), jen.Qual("time", "Now").Call()),
+			jen.If(jen.Id("res").Dot("Error").Op("!=").Nil()).Block(jen.Return(jen.Lit(0), jen.Id("res").Dot("Error"))),
+		),
+		jen.If(
+			jen.Id("cerr").Op(":=").Id("tx").Dot("Commit").Call().Dot("Error"),
+			jen.Id("cerr").Op("!=").Nil(),
+		).Block(jen.Return(jen.Lit(0), jen.Id("cerr"))),
+		jen.Return(jen.Len(jen.Id("ids")), jen.Err()),
+	).Line()
+	g.code.NewLine()
+}
+
+func (g *GenerateEvents) generateMemoryPublisher() {
+	g.code.appendMultilineComment([]string{
+		"Event is an event published by the MemoryPublisher.",
+	})
+	g.code.NewLine()
+	g.code.appendStruct(
+		"Event",
+		jen.Id("Topic").String(),
+		jen.Id("Key").String(),
+		jen.Id("Payload").Index().Byte(),
+	)
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"MemoryPublisher keeps the published events in memory, use it in the tests.",
+	})
+	g.code.NewLine()
+	g.code.appendStruct(
+		"MemoryPublisher",
+		jen.Id("mu").Qual("sync", "Mutex"),
+		jen.Id("events").Index().Id("Event"),
+	)
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"NewMemoryPublisher returns a MemoryPublisher without events.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"NewMemoryPublisher",
+		nil,
+		[]jen.Code{},
+		[]jen.Code{},
+		"*MemoryPublisher",
+		jen.Return(jen.Op("&").Id("MemoryPublisher").Values()),
+	)
+	g.code.NewLine()
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"Publish keeps the event.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"Publish",
+		jen.Id("p").Op("*").Id("MemoryPublisher"),
+		[]jen.Code{
+			jen.Id("_").Qual("context", "Context"),
+			jen.List(jen.Id("topic"), jen.Id("key")).String(),
+			jen.Id("payload").Index().Byte(),
+		},
+		[]jen.Code{},
+		"error",
+		jen.Id("p").Dot("mu").Dot("Lock").Call(),
+		jen.Defer().Id("p").Dot("mu").Dot("Unlock").Call(),
+		jen.Id("p").Dot("events").Op("=").Append(jen.Id("p").Dot("events"), jen.Id("Event").Values(jen.Dict{
+			jen.Id("Topic"):   jen.Id("topic"),
+			jen.Id("Key"):     jen.Id("key"),
+			jen.Id("Payload"): jen.Id("payload"),
+		})),
+		jen.Return(jen.Nil()),
+	)
+	g.code.NewLine()
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"Events returns the published events in the order they were published.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"Events",
+		jen.Id("p").Op("*").Id("MemoryPublisher"),
+		[]jen.Code{},
+		[]jen.Code{},
+		"[]Event",
+		jen.Id("p").Dot("mu").Dot("Lock").Call(),
+		jen.Defer().Id("p").Dot("mu").Dot("Unlock").Call(),
+		jen.Return(jen.Append(jen.Index().Id("Event").Values(), jen.Id("p").Dot("events").Op("..."))),
+	)
+	g.code.NewLine()
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"Close does nothing, the events are kept.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"Close",
+		jen.Id("p").Op("*").Id("MemoryPublisher"),
+		[]jen.Code{},
+		[]jen.Code{},
+		"error",
+		jen.Return(jen.Nil()),
+	)
+	g.code.NewLine()
+}
+
+// generatePublisher generates the NATS or the Kafka publisher if it does not exist.
+func (g *GenerateEvents) generatePublisher() error {
+	if g.publisher == PublisherMemory {
+		return nil
+	}
+	filePath := path.Join(g.destPath, g.publisher+".go")
+	if b, err := g.fs.Exists(filePath); err != nil || b {
+		return err
+	}
+	f := jen.NewFilePath(g.destPath)
+	method := func(name string, params []jen.Code, body ...jen.Code) {
+		f.Func().Params(jen.Id("p").Op("*").Id(g.publisher + "Publisher")).Id(name).Params(params...).Error().Block(body...)
+		f.Line()
+	}
+	publishParams := []jen.Code{
+		jen.Id("ctx").Qual("context", "Context"),
+		jen.List(jen.Id("topic"), jen.Id("key")).String(),
+		jen.Id("payload").Index().Byte(),
+	}
+	switch g.publisher {
+	case PublisherNATS:
+		f.Comment("natsPublisher publishes the events to the NATS subjects named by their topic.")
+		f.Type().Id("natsPublisher").Struct(jen.Id("conn").Op("*").Qual(natsImport, "Conn"))
+		f.Line()
+		f.Comment("NewNATSPublisher returns a publisher connected to the NATS server at url, the key")
+		f.Comment("of the events is sent in the Key header.")
+		f.Func().Id("NewNATSPublisher").Params(jen.Id("url").String()).Params(jen.Id("Publisher"), jen.Error()).Block(
+			jen.List(jen.Id("conn"), jen.Err()).Op(":=").Qual(natsImport, "Connect").Call(jen.Id("url")),
+			jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Nil(), jen.Err())),
+			jen.Return(jen.Op("&").Id("natsPublisher").Values(jen.Dict{jen.Id("conn"): jen.Id("conn")}), jen.Nil()),
+		)
+		f.Line()
+		f.Comment("Publish publishes the event and waits for the server to receive it.")
+		method(
+			"Publish",
+			publishParams,
+			jen.Id("msg").Op(":=").Qual(natsImport, "NewMsg").Call(jen.Id("topic")),
+			jen.Id("msg").Dot("Data").Op("=").Id("payload"),
+			jen.If(jen.Id("key").Op("!=").Lit("")).Block(
+				jen.Id("msg").Dot("Header").Dot("Set").Call(jen.Lit("Key"), jen.Id("key")),
+			),
+			jen.If(
+				jen.Err().Op(":=").Id("p").Dot("conn").Dot("PublishMsg").Call(jen.Id("msg")),
+				jen.Err().Op("!=").Nil(),
+			).Block(jen.Return(jen.Err())),
+			jen.List(jen.Id("ctx"), jen.Id("cancel")).Op(":=").Qual("context", "WithTimeout").Call(
+				jen.Id("ctx"),
+				jen.Lit(5).Op("*").Qual("time", "Second"),
+			),
+			jen.Defer().Id("cancel").Call(),
+			jen.Return(jen.Id("p").Dot("conn").Dot("FlushWithContext").Call(jen.Id("ctx"))),
+		)
+		f.Comment("Close drains the connection.")
+		method("Close", nil, jen.Return(jen.Id("p").Dot("conn").Dot("Drain").Call()))
+	case PublisherKafka:
+		f.Comment("kafkaPublisher writes the events to the Kafka topics named by their topic, the")
+		f.Comment("events with the same key are written to the same partition.")
+		f.Type().Id("kafkaPublisher").Struct(jen.Id("writer").Op("*").Qual(kafkaImport, "Writer"))
+		f.Line()
+		f.Comment("NewKafkaPublisher returns a publisher writing to the Kafka brokers.")
+		f.Func().Id("NewKafkaPublisher").Params(jen.Id("brokers").Index().String()).Id("Publisher").Block(
+			jen.Return(jen.Op("&").Id("kafkaPublisher").Values(jen.Dict{
+				jen.Id("writer"): jen.Op("&").Qual(kafkaImport, "Writer").Values(jen.Dict{
+					jen.Id("Addr"):         jen.Qual(kafkaImport, "TCP").Call(jen.Id("brokers").Op("...")),
+					jen.Id("Balancer"):     jen.Op("&").Qual(kafkaImport, "Hash").Values(),
+					jen.Id("RequiredAcks"): jen.Qual(kafkaImport, "RequireAll"),
+				}),
+			})),
+		)
+		f.Line()
+		f.Comment("Publish writes the event and waits for the brokers to acknowledge it.")
+		method(
+			"Publish",
+			publishParams,
+			jen.Return(jen.Id("p").Dot("writer").Dot("WriteMessages").Call(
+				jen.Id("ctx"),
+				jen.Qual(kafkaImport, "Message").Values(jen.Dict{
+					jen.Id("Topic"): jen.Id("topic"),
+					jen.Id("Key"):   jen.Index().Byte().Parens(jen.Id("key")),
+					jen.Id("Value"): jen.Id("payload"),
+				}),
+			)),
+		)
+		f.Comment("Close flushes the pending messages and closes the writer.")
+		method("Close", nil, jen.Return(jen.Id("p").Dot("writer").Dot("Close").Call()))
+	}
+	return g.fs.WriteFile(filePath, f.GoString(), false)
+}
+
+// generateNewPublisher generates NewPublisher, it returns the publisher set by the
+// environment among the publishers the events package has.
+func (g *GenerateEvents) generateNewPublisher() error {
+	publishers := []string{}
+	for _, v := range SupportedPublishers {
+		b, err := g.fs.Exists(path.Join(g.destPath, v+".go"))
+		if err != nil {
+			return err
+		}
+		if b || v == PublisherMemory {
+			publishers = append(publishers, v)
+		}
+	}
+	getenv := func(v, key string, fallback jen.Code) []jen.Code {
+		return []jen.Code{
+			jen.Id(v).Op(":=").Qual("os", "Getenv").Call(jen.Lit(key)),
+			jen.If(jen.Id(v).Op("==").Lit("")).Block(jen.Id(v).Op("=").Add(fallback)),
+		}
+	}
+	cases := []jen.Code{}
+	doc := []string{}
+	for _, v := range publishers {
+		switch v {
+		case PublisherNATS:
+			doc = append(doc, "NATS_URL is the url of the NATS server.")
+			cases = append(cases, jen.Case(jen.Lit(v)).Block(
+				append(
+					getenv("natsURL", "NATS_URL", jen.Qual(natsImport, "DefaultURL")),
+					jen.Return(jen.Id("NewNATSPublisher").Call(jen.Id("natsURL"))),
+				)...,
+			))
+		case PublisherKafka:
+			doc = append(doc, "KAFKA_BROKERS are the comma separated addresses of the Kafka brokers.")
+			cases = append(cases, jen.Case(jen.Lit(v)).Block(
+				append(
+					getenv("kafkaBrokers", "KAFKA_BROKERS", jen.Lit("localhost:9092")),
+					jen.Return(
+						jen.Id("NewKafkaPublisher").Call(jen.Qual("strings", "Split").Call(jen.Id("kafkaBrokers"), jen.Lit(","))),
+						jen.Nil(),
+					),
+				)...,
+			))
+		case PublisherMemory:
+			cases = append(cases, jen.Case(jen.Lit(v)).Block(jen.Return(jen.Id("NewMemoryPublisher").Call(), jen.Nil())))
+		}
+	}
+	f := jen.NewFilePath(g.destPath)
+	f.PackageComment("THIS FILE IS AUTO GENERATED BY GK-CLI DO NOT EDIT!!")
+	f.Commentf(
+		"NewPublisher returns the publisher set by EVENTS_PUBLISHER (%s), %s by default.",
+		strings.Join(publishers, ", "),
+		g.publisher,
+	)
+	for _, v := range doc {
+		f.Comment(v)
+	}
+	body := append(getenv("eventsPublisher", "EVENTS_PUBLISHER", jen.Lit(g.publisher)), jen.Switch(jen.Id("eventsPublisher")).Block(cases...))
+	body = append(body, jen.Return(jen.Nil(), jen.Qual("fmt", "Errorf").Call(
+		jen.Lit(fmt.Sprintf("unknown events publisher %%q, use %s", strings.Join(publishers, ", "))),
+		jen.Id("eventsPublisher"),
+	)))
+	f.Func().Id("NewPublisher").Params().Params(jen.Id("Publisher"), jen.Error()).Block(body...)
+	return g.fs.WriteFile(g.genFilePath, f.GoString(), true)
+}
+
+// addToCmd adds the relay to the Run of the service if the service was generated,
+// the services generated later add it themselves.
+func (g *GenerateEvents) addToCmd() error {
+	cmdPath := path.Join(
+		fmt.Sprintf(viper.GetString("gk_cmd_service_path_format"), utils.ToLowerSnakeCase(g.name)),
+		viper.GetString("gk_cmd_svc_file_name"),
+	)
+	if b, err := g.fs.Exists(cmdPath); err != nil || !b {
+		return err
+	}
+	src, err := g.fs.ReadFile(cmdPath)
+	if err != nil {
+		return err
+	}
+	fset := token.NewFileSet()
+	f, err := ps.ParseFile(fset, "", src, ps.ParseComments)
+	if err != nil {
+		return err
+	}
+	var run *ast.FuncDecl
+	for _, d := range f.Decls {
+		if fd, ok := d.(*ast.FuncDecl); ok && fd.Name.Name == "Run" && fd.Recv == nil && fd.Body != nil {
+			run = fd
+		}
+	}
+	hint := "Call initEventsRelay(d, g) in Run before the group runs to relay the outbox"
+	if run == nil {
+		logrus.Warn(hint)
+		return nil
+	}
+	var interrupt *ast.ExprStmt
+	relay := false
+	for _, s := range run.Body.List {
+		es, ok := s.(*ast.ExprStmt)
+		if !ok {
+			continue
+		}
+		if c, ok := es.X.(*ast.CallExpr); ok {
+			if id, ok := c.Fun.(*ast.Ident); ok {
+				relay = relay || id.Name == "initEventsRelay"
+				if id.Name == "initCancelInterrupt" {
+					interrupt = es
+				}
+			}
+		}
+	}
+	if !relay {
+		if interrupt == nil {
+			logrus.Warn(hint)
+			return nil
+		}
+		src, err = insertSource(src, sourceInsert{fset.Position(interrupt.Pos()).Offset, "initEventsRelay(d, g)\n"})
+		if err != nil {
+			return err
+		}
+		if err = g.fs.WriteFile(cmdPath, src, true); err != nil {
+			return err
+		}
+	}
+	svcPath := path.Join(
+		fmt.Sprintf(viper.GetString("gk_service_path_format"), utils.ToLowerSnakeCase(g.name)),
+		viper.GetString("gk_service_file_name"),
+	)
+	svcSrc, err := g.fs.ReadFile(svcPath)
+	if err != nil {
+		return err
+	}
+	svcFile, err := parser.NewFileParser().Parse([]byte(svcSrc))
+	if err != nil {
+		return err
+	}
+	for _, v := range svcFile.Interfaces {
+		if v.Name == utils.ToCamelCase(g.name+"Service") {
+			// The cmd generator adds initEventsRelay, it only adds what is missing.
+			return newGenerateCmd(g.name, "", v, false, false, nil).Generate()
+		}
+	}
+	logrus.Warn(hint)
+	return nil
+}
+
+// eventsExists returns true if the service has the events package.
+func eventsExists(f *fs.KitFs, name string) (bool, error) {
+	return f.Exists(path.Join(
+		fmt.Sprintf(viper.GetString("gk_events_path_format"), utils.ToLowerSnakeCase(name)),
+		viper.GetString("gk_events_file_name"),
+	))
+}
+
+// eventsTransactionExists returns true if the service has events and their
+// Transaction passes the transaction to the repositories in the context.
+func eventsTransactionExists(f *fs.KitFs, name string) (bool, error) {
+	if b, err := eventsExists(f, name); err != nil || !b {
+		return false, err
+	}
+	src, err := f.ReadFile(path.Join(
+		fmt.Sprintf(viper.GetString("gk_events_path_format"), utils.ToLowerSnakeCase(name)),
+		viper.GetString("gk_events_file_name"),
+	))
+	if err != nil {
+		return false, err
+	}
+	file, err := parser.NewFileParser().Parse([]byte(src))
+	if err != nil {
+		return false, err
+	}
+	for _, v := range file.Methods {
+		if v.Struct.Type == "" && v.Name == "Transaction" {
+			return len(v.Parameters) == 2 && v.Parameters[0].Type == "context.Context", nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,144 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kujtimiihoxha/kit/parser"
+	"github.com/spf13/afero"
+)
+
+func TestGenerateEvents_Generate(t *testing.T) {
+	f := newClientTestFs()
+	f.MkdirAll("test/pkg/model")
+	f.WriteFile("test/pkg/model/base.go", `package model
+
+// AutoMigration migrates the models.
+func AutoMigration() (err error) {
+	dbPublic, err := postgres.GetDatabase("default")
+	if err != nil {
+		return
+	}
+	t := dbPublic.AutoMigrate()
+	return t.Error
+}
+`, true)
+	f.MkdirAll("test/pkg/migration")
+	f.WriteFile("test/pkg/migration/migration.go", "package migration", true)
+	for i := 0; i < 2; i++ {
+		if err := NewGenerateEvents("test", PublisherNATS).Generate(); err != nil {
+			t.Fatalf("GenerateEvents.Generate() error = %v", err)
+		}
+	}
+	files := map[string][]string{
+		"test/pkg/events/events.go": {
+			"func Publish(tx *gorm.DB, topic, key string, event interface{}) error",
+			"return tx.Create(&model.OutboxEvent{",
+			"func Transaction(ctx context.Context, fn func(ctx context.Context, tx *gorm.DB) error) error",
+			"tx := db.BeginTx(ctx, nil)",
+			// The repositories called with the context of fn join the transaction.
+			"err = fn(model.ContextWithTx(ctx, tx), tx)",
+			"postgres.GetDatabase(postgres.DefaultConnName)",
+			"func NewRelay(db *gorm.DB, publisher Publisher, logger log.Logger) *Relay",
+			`tx.Set("gorm:query_option", "FOR UPDATE SKIP LOCKED").Where("published_at IS NULL")`,
+			`Update("published_at", time.Now())`,
+			"func (p *MemoryPublisher) Events() []Event",
+		},
+		"test/pkg/events/nats.go": {
+			"func NewNATSPublisher(url string) (Publisher, error)",
+			"return p.conn.FlushWithContext(ctx)",
+		},
+		"test/pkg/events/publisher_gen.go": {
+			"THIS FILE IS AUTO GENERATED BY GK-CLI DO NOT EDIT!!",
+			`eventsPublisher = "nats"`,
+			`case "memory":`,
+		},
+		"test/pkg/model/outbox.go": {
+			"type OutboxEvent struct",
+			`gorm:"column:event_key;not null"`,
+			`return "outbox_events"`,
+		},
+		"test/pkg/model/base.go": {"AutoMigrate(&OutboxEvent{})"},
+		"test/pkg/model/tx.go":   {"func ContextWithTx(ctx context.Context, tx *gorm.DB) context.Context"},
+	}
+	for k, want := range files {
+		src, err := f.ReadFile(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range want {
+			if !strings.Contains(src, v) {
+				t.Errorf("GenerateEvents.Generate() %s does not contain %q, got %s", k, v, src)
+			}
+		}
+	}
+	src, _ := f.ReadFile("test/pkg/events/events.go")
+	if strings.Count(src, "func Publish(") != 1 {
+		t.Errorf("GenerateEvents.Generate() should add the declarations once, got %s", src)
+	}
+	m, err := afero.Glob(f.Fs, "test/pkg/migration/sql/*_create_outbox_events.up.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m) != 1 {
+		t.Fatalf("GenerateEvents.Generate() should write the migration of the outbox once, got %v", m)
+	}
+	if src, _ = f.ReadFile(m[0]); !strings.Contains(src, "id BIGSERIAL PRIMARY KEY") {
+		t.Errorf("GenerateEvents.Generate() should write the postgres migration, got %s", src)
+	}
+}
+
+func TestGenerateEvents_GenerateWithoutDB(t *testing.T) {
+	f := newClientTestFs()
+	f.Fs.Remove("test/pkg/db/postgres/db.go")
+	if err := NewGenerateEvents("test", PublisherNATS).Generate(); err == nil {
+		t.Error("GenerateEvents.Generate() should return an error if the service has no database")
+	}
+	newClientTestFs()
+	if err := NewGenerateEvents("test", "rabbitmq").Generate(); err == nil {
+		t.Error("GenerateEvents.Generate() should return an error if the publisher is not supported")
+	}
+}
+
+func TestGenerateCmd_GenerateEventsRelay(t *testing.T) {
+	f := newClientTestFs()
+	if err := NewNewConfig("test", DBPostgres).Generate(); err != nil {
+		t.Fatalf("NewConfig.Generate() error = %v", err)
+	}
+	svcSrc, err := f.ReadFile("test/pkg/service/service.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	svcFile, err := parser.NewFileParser().Parse([]byte(svcSrc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := newGenerateCmd("test", "", svcFile.Interfaces[0], false, false, nil).Generate(); err != nil {
+		t.Fatalf("generateCmd.Generate() error = %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := NewGenerateEvents("test", PublisherKafka).Generate(); err != nil {
+			t.Fatalf("GenerateEvents.Generate() error = %v", err)
+		}
+	}
+	src, err := f.ReadFile("test/cmd/service/service.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		"initEventsRelay(d, g)\n\tinitCancelInterrupt(g)",
+		"func initEventsRelay(d *gorm.DB, g *group.Group) {",
+		"relay := events.NewRelay(d, publisher, logger)",
+		"return relay.Run(ctx)",
+	} {
+		if !strings.Contains(src, v) {
+			t.Errorf("GenerateEvents.Generate() cmd service does not contain %q, got %s", v, src)
+		}
+	}
+	if strings.Count(src, "initEventsRelay(") != 2 {
+		t.Errorf("GenerateEvents.Generate() should add the relay once, got %s", src)
+	}
+	if b, _ := f.Exists("test/pkg/events/kafka.go"); !b {
+		t.Error("GenerateEvents.Generate() should generate the kafka publisher")
+	}
+}
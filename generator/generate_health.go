@@ -0,0 +1,419 @@
+package generator
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/kujtimiihoxha/kit/fs"
+	"github.com/kujtimiihoxha/kit/parser"
+	"github.com/kujtimiihoxha/kit/utils"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+const grpcHealthImport = "google.golang.org/grpc/health/grpc_health_v1"
+
+// generateHealth generates the health package of the service, the liveness,
+// readiness and version handlers of the debug listener, the readiness registry
+// and the gRPC health server.
+type generateHealth struct {
+	BaseGenerator
+	name              string
+	grpc              bool
+	destPath          string
+	filePath          string
+	file              *parser.File
+	generateFirstTime bool
+}
+
+func newGenerateHealth(name string, grpc bool) Gen {
+	i := &generateHealth{
+		name:     name,
+		grpc:     grpc,
+		destPath: fmt.Sprintf(viper.GetString("gk_health_path_format"), utils.ToLowerSnakeCase(name)),
+	}
+	i.filePath = path.Join(i.destPath, viper.GetString("gk_health_file_name"))
+	i.srcFile = jen.NewFilePath(i.destPath)
+	i.srcFile.ImportAlias(grpcHealthImport, "grpc_health_v1")
+	i.InitPg()
+	i.fs = fs.Get()
+	return i
+}
+
+// Generate generates the parts of the health package that are missing, the gRPC
+// health server is only generated if the service has the gRPC transport.
+func (g *generateHealth) Generate() (err error) {
+	err = g.CreateFolderStructure(g.destPath)
+	if err != nil {
+		return err
+	}
+	if b, err := g.fs.Exists(g.filePath); err != nil {
+		return err
+	} else if !b {
+		g.generateFirstTime = true
+		f := jen.NewFile("health")
+		g.fs.WriteFile(g.filePath, f.GoString(), false)
+	}
+	src, err := g.fs.ReadFile(g.filePath)
+	if err != nil {
+		return err
+	}
+	g.file, err = parser.NewFileParser().Parse([]byte(src))
+	if err != nil {
+		return err
+	}
+	found := map[string]bool{
+		g.file.FuncType.Name: true,
+	}
+	for _, v := range g.file.Methods {
+		if v.Struct.Type != "" {
+			found[v.Struct.Type+"."+v.Name] = true
+			continue
+		}
+		found[v.Name] = true
+	}
+	for _, v := range g.file.Structures {
+		found[v.Name] = true
+	}
+	for _, v := range g.file.Vars {
+		found[v.Name] = true
+	}
+	if !found["Version"] {
+		healthImport, err := utils.GetHealthImportPath(g.name)
+		if err != nil {
+			return err
+		}
+		g.code.appendMultilineComment([]string{
+			"The build information of the service served on /version, set them when",
+			"building the service (e.x go build -ldflags \"-X " + healthImport + ".Version=v1.0.0\").",
+		})
+		g.code.NewLine()
+		g.code.Raw().Var().Defs(
+			jen.Id("Version").Op("=").Lit("dev"),
+			jen.Id("Commit").Op("=").Lit(""),
+			jen.Id("BuildTime").Op("=").Lit(""),
+		).Line()
+		g.code.NewLine()
+	}
+	if !found["Checker"] {
+		g.code.appendMultilineComment([]string{
+			"Checker checks a dependency of the service, the service is not ready while",
+			"a checker returns an error.",
+		})
+		g.code.NewLine()
+		g.code.Raw().Type().Id("Checker").Func().Params(
+			jen.Id("ctx").Qual("context", "Context"),
+		).Error().Line()
+		g.code.NewLine()
+	}
+	g.generateRegistry(found)
+	if !found["LivenessHandler"] {
+		g.code.appendMultilineComment([]string{
+			"LivenessHandler reports that the service is up.",
+		})
+		g.code.NewLine()
+		g.code.Raw().Func().Id("LivenessHandler").Params().Qual("net/http", "Handler").Block(
+			jen.Return(jen.Qual("net/http", "HandlerFunc").Call(jen.Func().Params(
+				jen.Id("w").Qual("net/http", "ResponseWriter"),
+				jen.Id("_").Op("*").Qual("net/http", "Request"),
+			).Block(
+				jen.Id("writeJSON").Call(
+					jen.Id("w"),
+					jen.Qual("net/http", "StatusOK"),
+					jen.Map(jen.String()).String().Values(jen.Dict{jen.Lit("status"): jen.Lit("ok")}),
+				),
+			))),
+		).Line()
+		g.code.NewLine()
+	}
+	if !found["ReadinessHandler"] {
+		g.code.appendMultilineComment([]string{
+			"ReadinessHandler reports if the checks of the registry pass, it responds",
+			"with 503 Service Unavailable and the errors of the checks otherwise.",
+		})
+		g.code.NewLine()
+		g.code.Raw().Func().Id("ReadinessHandler").Params(
+			jen.Id("registry").Op("*").Id("Registry"),
+		).Qual("net/http", "Handler").Block(
+			jen.Return(jen.Qual("net/http", "HandlerFunc").Call(jen.Func().Params(
+				jen.Id("w").Qual("net/http", "ResponseWriter"),
+				jen.Id("r").Op("*").Qual("net/http", "Request"),
+			).Block(
+				jen.List(jen.Id("status"), jen.Id("code")).Op(":=").List(jen.Lit("ok"), jen.Qual("net/http", "StatusOK")),
+				jen.Id("checks").Op(":=").Map(jen.String()).String().Values(),
+				jen.For(jen.List(jen.Id("name"), jen.Err()).Op(":=").Range().Id("registry").Dot("Check").Call(
+					jen.Id("r").Dot("Context").Call(),
+				)).Block(
+					jen.If(jen.Err().Op("!=").Nil()).Block(
+						jen.List(jen.Id("status"), jen.Id("code")).Op("=").List(jen.Lit("unavailable"), jen.Qual("net/http", "StatusServiceUnavailable")),
+						jen.Id("checks").Index(jen.Id("name")).Op("=").Err().Dot("Error").Call(),
+						jen.Continue(),
+					),
+					jen.Id("checks").Index(jen.Id("name")).Op("=").Lit("ok"),
+				),
+				jen.Id("writeJSON").Call(
+					jen.Id("w"),
+					jen.Id("code"),
+					jen.Map(jen.String()).Interface().Values(jen.Dict{
+						jen.Lit("status"): jen.Id("status"),
+						jen.Lit("checks"): jen.Id("checks"),
+					}),
+				),
+			))),
+		).Line()
+		g.code.NewLine()
+	}
+	if !found["VersionHandler"] {
+		g.code.appendMultilineComment([]string{
+			"VersionHandler responds with the build information of the service.",
+		})
+		g.code.NewLine()
+		g.code.Raw().Func().Id("VersionHandler").Params().Qual("net/http", "Handler").Block(
+			jen.Return(jen.Qual("net/http", "HandlerFunc").Call(jen.Func().Params(
+				jen.Id("w").Qual("net/http", "ResponseWriter"),
+				jen.Id("_").Op("*").Qual("net/http", "Request"),
+			).Block(
+				jen.Id("writeJSON").Call(
+					jen.Id("w"),
+					jen.Qual("net/http", "StatusOK"),
+					jen.Map(jen.String()).String().Values(jen.Dict{
+						jen.Lit("version"):    jen.Id("Version"),
+						jen.Lit("commit"):     jen.Id("Commit"),
+						jen.Lit("build_time"): jen.Id("BuildTime"),
+						jen.Lit("go_version"): jen.Qual("runtime", "Version").Call(),
+					}),
+				),
+			))),
+		).Line()
+		g.code.NewLine()
+	}
+	if !found["writeJSON"] {
+		g.code.appendFunction(
+			"writeJSON",
+			nil,
+			[]jen.Code{
+				jen.Id("w").Qual("net/http", "ResponseWriter"),
+				jen.Id("code").Int(),
+				jen.Id("v").Interface(),
+			},
+			[]jen.Code{},
+			"",
+			jen.Id("w").Dot("Header").Call().Dot("Set").Call(
+				jen.Lit("Content-Type"),
+				jen.Lit("application/json; charset=utf-8"),
+			),
+			jen.Id("w").Dot("WriteHeader").Call(jen.Id("code")),
+			jen.Qual("encoding/json", "NewEncoder").Call(jen.Id("w")).Dot("Encode").Call(jen.Id("v")),
+		)
+		g.code.NewLine()
+	}
+	if g.grpc && !found["GRPCServer"] {
+		g.generateGRPCServer()
+	}
+	if g.generateFirstTime {
+		cmdFilePath := path.Join(
+			fmt.Sprintf(viper.GetString("gk_cmd_service_path_format"), utils.ToLowerSnakeCase(g.name)),
+			viper.GetString("gk_cmd_svc_file_name"),
+		)
+		if b, err := g.fs.Exists(cmdFilePath); err != nil {
+			return err
+		} else if b {
+			logrus.Infof("Serve the health package on the debug listener in %s#initMetricsEndpoint()", cmdFilePath)
+			logrus.Info("with health.LivenessHandler(), health.ReadinessHandler(health.DefaultRegistry) and health.VersionHandler()")
+		}
+		return g.fs.WriteFile(g.filePath, g.srcFile.GoString(), true)
+	}
+	pSrc, err := g.partialSource()
+	if err != nil {
+		return err
+	}
+	src += "\n" + pSrc
+	tmpSrc := g.srcFile.GoString()
+	f, err := parser.NewFileParser().Parse([]byte(tmpSrc))
+	if err != nil {
+		return err
+	}
+	imp, err := g.getMissingImports(f.Imports, g.file)
+	if err != nil {
+		return err
+	}
+	if len(imp) > 0 {
+		src, err = g.AddImportsToFile(imp, src)
+		if err != nil {
+			return err
+		}
+	}
+	s, err := utils.GoImportsSource(g.destPath, src)
+	if err != nil {
+		return err
+	}
+	return g.fs.WriteFile(g.filePath, s, true)
+}
+
+func (g *generateHealth) generateRegistry(found map[string]bool) {
+	if !found["Registry"] {
+		g.code.appendMultilineComment([]string{
+			"Registry holds the checks the readiness of the service depends on.",
+		})
+		g.code.NewLine()
+		g.code.appendStruct(
+			"Registry",
+			jen.Comment("Timeout is the time the checks have to complete."),
+			jen.Id("Timeout").Qual("time", "Duration"),
+			jen.Id("mtx").Qual("sync", "RWMutex"),
+			jen.Id("checks").Map(jen.String()).Id("Checker"),
+		)
+		g.code.NewLine()
+	}
+	if !found["NewRegistry"] {
+		g.code.appendMultilineComment([]string{
+			"NewRegistry returns an empty registry whose checks time out after 5 seconds.",
+		})
+		g.code.NewLine()
+		g.code.appendFunction(
+			"NewRegistry",
+			nil,
+			[]jen.Code{},
+			[]jen.Code{},
+			"*Registry",
+			jen.Return(jen.Op("&").Id("Registry").Values(jen.Dict{
+				jen.Id("Timeout"): jen.Lit(5).Op("*").Qual("time", "Second"),
+				jen.Id("checks"):  jen.Map(jen.String()).Id("Checker").Values(),
+			})),
+		)
+		g.code.NewLine()
+	}
+	if !found["*Registry.Register"] {
+		g.code.appendMultilineComment([]string{
+			"Register adds the check of the named dependency, it replaces the check",
+			"registered with the same name.",
+		})
+		g.code.NewLine()
+		g.code.appendFunction(
+			"Register",
+			jen.Id("r").Op("*").Id("Registry"),
+			[]jen.Code{
+				jen.Id("name").String(),
+				jen.Id("check").Id("Checker"),
+			},
+			[]jen.Code{},
+			"",
+			jen.Id("r").Dot("mtx").Dot("Lock").Call(),
+			jen.Defer().Id("r").Dot("mtx").Dot("Unlock").Call(),
+			jen.Id("r").Dot("checks").Index(jen.Id("name")).Op("=").Id("check"),
+		)
+		g.code.NewLine()
+	}
+	if !found["*Registry.Check"] {
+		g.code.appendMultilineComment([]string{
+			"Check runs the checks concurrently and returns the result of every check.",
+		})
+		g.code.NewLine()
+		g.code.appendFunction(
+			"Check",
+			jen.Id("r").Op("*").Id("Registry"),
+			[]jen.Code{
+				jen.Id("ctx").Qual("context", "Context"),
+			},
+			[]jen.Code{},
+			"map[string]error",
+			jen.Id("r").Dot("mtx").Dot("RLock").Call(),
+			jen.Defer().Id("r").Dot("mtx").Dot("RUnlock").Call(),
+			jen.List(jen.Id("ctx"), jen.Id("cancel")).Op(":=").Qual("context", "WithTimeout").Call(
+				jen.Id("ctx"),
+				jen.Id("r").Dot("Timeout"),
+			),
+			jen.Defer().Id("cancel").Call(),
+			jen.Var().Id("mtx").Qual("sync", "Mutex"),
+			jen.Var().Id("wg").Qual("sync", "WaitGroup"),
+			jen.Id("results").Op(":=").Make(jen.Map(jen.String()).Error(), jen.Len(jen.Id("r").Dot("checks"))),
+			jen.For(jen.List(jen.Id("name"), jen.Id("check")).Op(":=").Range().Id("r").Dot("checks")).Block(
+				jen.Id("wg").Dot("Add").Call(jen.Lit(1)),
+				jen.Go().Func().Params(
+					jen.Id("name").String(),
+					jen.Id("check").Id("Checker"),
+				).Block(
+					jen.Defer().Id("wg").Dot("Done").Call(),
+					jen.Err().Op(":=").Id("check").Call(jen.Id("ctx")),
+					jen.Id("mtx").Dot("Lock").Call(),
+					jen.Id("results").Index(jen.Id("name")).Op("=").Err(),
+					jen.Id("mtx").Dot("Unlock").Call(),
+				).Call(jen.Id("name"), jen.Id("check")),
+			),
+			jen.Id("wg").Dot("Wait").Call(),
+			jen.Return(jen.Id("results")),
+		)
+		g.code.NewLine()
+	}
+	if !found["DefaultRegistry"] {
+		g.code.appendMultilineComment([]string{
+			"DefaultRegistry is the registry served on /readyz.",
+		})
+		g.code.NewLine()
+		g.code.Raw().Var().Id("DefaultRegistry").Op("=").Id("NewRegistry").Call().Line()
+		g.code.NewLine()
+	}
+	if !found["Register"] {
+		g.code.appendMultilineComment([]string{
+			"Register adds the check of the named dependency to the DefaultRegistry.",
+		})
+		g.code.NewLine()
+		g.code.appendFunction(
+			"Register",
+			nil,
+			[]jen.Code{
+				jen.Id("name").String(),
+				jen.Id("check").Id("Checker"),
+			},
+			[]jen.Code{},
+			"",
+			jen.Id("DefaultRegistry").Dot("Register").Call(jen.Id("name"), jen.Id("check")),
+		)
+		g.code.NewLine()
+	}
+}
+
+func (g *generateHealth) generateGRPCServer() {
+	g.code.Raw().Type().Id("grpcServer").Struct(
+		jen.Qual(grpcHealthImport, "UnimplementedHealthServer"),
+		jen.Id("registry").Op("*").Id("Registry"),
+	).Line()
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"GRPCServer returns the grpc.health.v1 server of the service, it reports the",
+		"service as serving while the checks of the registry pass.",
+	})
+	g.code.NewLine()
+	g.code.Raw().Func().Id("GRPCServer").Params(
+		jen.Id("registry").Op("*").Id("Registry"),
+	).Qual(grpcHealthImport, "HealthServer").Block(
+		jen.Return(jen.Op("&").Id("grpcServer").Values(jen.Dict{
+			jen.Id("registry"): jen.Id("registry"),
+		})),
+	).Line()
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"Check implements grpc_health_v1.HealthServer.",
+	})
+	g.code.NewLine()
+	g.code.Raw().Func().Params(jen.Id("s").Op("*").Id("grpcServer")).Id("Check").Params(
+		jen.Id("ctx").Qual("context", "Context"),
+		jen.Id("_").Op("*").Qual(grpcHealthImport, "HealthCheckRequest"),
+	).Params(
+		jen.Op("*").Qual(grpcHealthImport, "HealthCheckResponse"),
+		jen.Error(),
+	).Block(
+		jen.For(jen.List(jen.Id("_"), jen.Err()).Op(":=").Range().Id("s").Dot("registry").Dot("Check").Call(jen.Id("ctx"))).Block(
+			jen.If(jen.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Op("&").Qual(grpcHealthImport, "HealthCheckResponse").Values(jen.Dict{
+					jen.Id("Status"): jen.Qual(grpcHealthImport, "HealthCheckResponse_NOT_SERVING"),
+				}), jen.Nil()),
+			),
+		),
+		jen.Return(jen.Op("&").Qual(grpcHealthImport, "HealthCheckResponse").Values(jen.Dict{
+			jen.Id("Status"): jen.Qual(grpcHealthImport, "HealthCheckResponse_SERVING"),
+		}), jen.Nil()),
+	).Line()
+	g.code.NewLine()
+}
@@ -0,0 +1,83 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kujtimiihoxha/kit/parser"
+)
+
+func TestGenerateHealth_Generate(t *testing.T) {
+	f := newClientTestFs()
+	for i := 0; i < 2; i++ {
+		if err := newGenerateHealth("test", false).Generate(); err != nil {
+			t.Fatalf("generateHealth.Generate() error = %v", err)
+		}
+	}
+	src, err := f.ReadFile("test/pkg/health/health.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		`Version   = "dev"`,
+		"type Checker func(ctx context.Context) error",
+		"func (r *Registry) Check(ctx context.Context) map[string]error",
+		"var DefaultRegistry = NewRegistry()",
+		"func Register(name string, check Checker)",
+		"func ReadinessHandler(registry *Registry) http.Handler",
+		`status, code = "unavailable", http.StatusServiceUnavailable`,
+		`"go_version": runtime.Version()`,
+	} {
+		if !strings.Contains(src, v) {
+			t.Errorf("generateHealth.Generate() health.go does not contain %q", v)
+		}
+	}
+	for _, v := range []string{"func (r *Registry) Register(", "func (r *Registry) Check("} {
+		if strings.Count(src, v) != 1 {
+			t.Errorf("generateHealth.Generate() should add %q once, got %s", v, src)
+		}
+	}
+	if strings.Contains(src, "grpc_health_v1") {
+		t.Error("generateHealth.Generate() should not generate the gRPC health server without the gRPC transport")
+	}
+	if err := newGenerateHealth("test", true).Generate(); err != nil {
+		t.Fatalf("generateHealth.Generate() error = %v", err)
+	}
+	src, err = f.ReadFile("test/pkg/health/health.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(src, "func GRPCServer(registry *Registry) grpc_health_v1.HealthServer") ||
+		strings.Count(src, "func NewRegistry()") != 1 {
+		t.Errorf("generateHealth.Generate() should only add the gRPC health server, got %s", src)
+	}
+}
+
+func TestGenerateCmd_GenerateHealthEndpoints(t *testing.T) {
+	f := newClientTestFs()
+	svcSrc, err := f.ReadFile("test/pkg/service/service.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	svcFile, err := parser.NewFileParser().Parse([]byte(svcSrc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := newGenerateCmd("test", "", svcFile.Interfaces[0], false, false, nil).Generate(); err != nil {
+		t.Fatalf("generateCmd.Generate() error = %v", err)
+	}
+	src, err := f.ReadFile("test/cmd/service/service.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		`health.Register("postgres", func(ctx context.Context) error`,
+		`.DefaultServeMux.Handle("/healthz", health.LivenessHandler())`,
+		`.DefaultServeMux.Handle("/readyz", health.ReadinessHandler(health.DefaultRegistry))`,
+		`.DefaultServeMux.Handle("/version", health.VersionHandler())`,
+	} {
+		if !strings.Contains(src, v) {
+			t.Errorf("generateCmd.Generate() service.go does not contain %q", v)
+		}
+	}
+}
@@ -0,0 +1,362 @@
+package generator
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/kujtimiihoxha/kit/fs"
+	"github.com/kujtimiihoxha/kit/parser"
+	"github.com/kujtimiihoxha/kit/utils"
+	"github.com/spf13/viper"
+)
+
+const (
+	kitLogImport     = "github.com/go-kit/kit/log"
+	kitLevelImport   = "github.com/go-kit/kit/log/level"
+	lumberjackImport = "gopkg.in/natefinch/lumberjack.v2"
+)
+
+// generateLogging generates the logging package of the service, the logger built
+// from the log settings of the configuration and the logger of the request that
+// the logging middleware stores in the context.
+type generateLogging struct {
+	BaseGenerator
+	name              string
+	destPath          string
+	filePath          string
+	file              *parser.File
+	generateFirstTime bool
+}
+
+func newGenerateLogging(name string) Gen {
+	i := &generateLogging{
+		name:     name,
+		destPath: fmt.Sprintf(viper.GetString("gk_logging_path_format"), utils.ToLowerSnakeCase(name)),
+	}
+	i.filePath = path.Join(i.destPath, viper.GetString("gk_logging_file_name"))
+	i.srcFile = jen.NewFilePath(i.destPath)
+	i.srcFile.ImportAlias(lumberjackImport, "lumberjack")
+	i.InitPg()
+	i.fs = fs.Get()
+	return i
+}
+
+// Generate generates the parts of the logging package that are missing.
+func (g *generateLogging) Generate() (err error) {
+	err = g.CreateFolderStructure(g.destPath)
+	if err != nil {
+		return err
+	}
+	if b, err := g.fs.Exists(g.filePath); err != nil {
+		return err
+	} else if !b {
+		g.generateFirstTime = true
+		f := jen.NewFile("logging")
+		g.fs.WriteFile(g.filePath, f.GoString(), false)
+	}
+	src, err := g.fs.ReadFile(g.filePath)
+	if err != nil {
+		return err
+	}
+	g.file, err = parser.NewFileParser().Parse([]byte(src))
+	if err != nil {
+		return err
+	}
+	found := map[string]bool{}
+	for _, v := range g.file.Methods {
+		if v.Struct.Type != "" {
+			found[v.Struct.Type+"."+v.Name] = true
+			continue
+		}
+		found[v.Name] = true
+	}
+	for _, v := range g.file.Structures {
+		found[v.Name] = true
+	}
+	if !found["New"] {
+		g.code.appendMultilineComment([]string{
+			"New returns the logger of the service. It encodes the lines with the format,",
+			"logfmt or json, drops the lines below the level and writes them to the output.",
+			"The returned closer closes the output when the service stops.",
+		})
+		g.code.NewLine()
+		g.code.Raw().Func().Id("New").Params(
+			jen.List(jen.Id("format"), jen.Id("lvl"), jen.Id("output")).String(),
+		).Params(
+			jen.Qual(kitLogImport, "Logger"),
+			jen.Qual("io", "Closer"),
+			jen.Error(),
+		).Block(
+			jen.List(jen.Id("w"), jen.Err()).Op(":=").Id("Output").Call(jen.Id("output")),
+			jen.If(jen.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Nil(), jen.Nil(), jen.Err()),
+			),
+			jen.Var().Id("logger").Qual(kitLogImport, "Logger"),
+			jen.Switch(jen.Id("format")).Block(
+				jen.Case(jen.Lit("json")).Block(
+					jen.Id("logger").Op("=").Qual(kitLogImport, "NewJSONLogger").Call(
+						jen.Qual(kitLogImport, "NewSyncWriter").Call(jen.Id("w")),
+					),
+				),
+				jen.Case(jen.Lit("logfmt"), jen.Lit("")).Block(
+					jen.Id("logger").Op("=").Qual(kitLogImport, "NewLogfmtLogger").Call(
+						jen.Qual(kitLogImport, "NewSyncWriter").Call(jen.Id("w")),
+					),
+				),
+				jen.Default().Block(
+					jen.Id("w").Dot("Close").Call(),
+					jen.Return(
+						jen.Nil(),
+						jen.Nil(),
+						jen.Qual("fmt", "Errorf").Call(jen.Lit("unsupported log format %q, use logfmt or json"), jen.Id("format")),
+					),
+				),
+			),
+			jen.List(jen.Id("allow"), jen.Err()).Op(":=").Id("Allow").Call(jen.Id("lvl")),
+			jen.If(jen.Err().Op("!=").Nil()).Block(
+				jen.Id("w").Dot("Close").Call(),
+				jen.Return(jen.Nil(), jen.Nil(), jen.Err()),
+			),
+			jen.Id("logger").Op("=").Qual(kitLevelImport, "NewFilter").Call(jen.Id("logger"), jen.Id("allow")),
+			jen.Id("logger").Op("=").Qual(kitLogImport, "With").Call(
+				jen.Id("logger"),
+				jen.Lit("ts"),
+				jen.Qual(kitLogImport, "DefaultTimestampUTC"),
+				jen.Lit("caller"),
+				jen.Qual(kitLogImport, "DefaultCaller"),
+			),
+			jen.Return(jen.Id("logger"), jen.Id("w"), jen.Nil()),
+		).Line()
+		g.code.NewLine()
+	}
+	if !found["Allow"] {
+		g.code.appendMultilineComment([]string{
+			"Allow returns the filter of the level, the lines without a level are kept.",
+		})
+		g.code.NewLine()
+		g.code.Raw().Func().Id("Allow").Params(jen.Id("lvl").String()).Params(
+			jen.Qual(kitLevelImport, "Option"),
+			jen.Error(),
+		).Block(
+			jen.Switch(jen.Id("lvl")).Block(
+				jen.Case(jen.Lit("debug"), jen.Lit("")).Block(
+					jen.Return(jen.Qual(kitLevelImport, "AllowDebug").Call(), jen.Nil()),
+				),
+				jen.Case(jen.Lit("info")).Block(
+					jen.Return(jen.Qual(kitLevelImport, "AllowInfo").Call(), jen.Nil()),
+				),
+				jen.Case(jen.Lit("warn")).Block(
+					jen.Return(jen.Qual(kitLevelImport, "AllowWarn").Call(), jen.Nil()),
+				),
+				jen.Case(jen.Lit("error")).Block(
+					jen.Return(jen.Qual(kitLevelImport, "AllowError").Call(), jen.Nil()),
+				),
+			),
+			jen.Return(
+				jen.Nil(),
+				jen.Qual("fmt", "Errorf").Call(jen.Lit("unsupported log level %q, use debug, info, warn or error"), jen.Id("lvl")),
+			),
+		).Line()
+		g.code.NewLine()
+	}
+	if !found["Result"] {
+		g.code.appendMultilineComment([]string{
+			"Result returns the logger at the level of the result of a call, error if err",
+			"is not nil and info otherwise.",
+		})
+		g.code.NewLine()
+		g.code.appendFunction(
+			"Result",
+			nil,
+			[]jen.Code{
+				jen.Id("logger").Qual(kitLogImport, "Logger"),
+				jen.Err().Error(),
+			},
+			[]jen.Code{},
+			"log.Logger",
+			jen.If(jen.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Qual(kitLevelImport, "Error").Call(jen.Id("logger"))),
+			),
+			jen.Return(jen.Qual(kitLevelImport, "Info").Call(jen.Id("logger"))),
+		)
+		g.code.NewLine()
+	}
+	if !found["Output"] {
+		g.generateOutput()
+	}
+	if !found["nopCloser"] {
+		g.code.appendStruct("nopCloser", jen.Qual("io", "Writer"))
+		g.code.NewLine()
+		g.code.appendFunction(
+			"Close",
+			jen.Id("nopCloser"),
+			[]jen.Code{},
+			[]jen.Code{},
+			"error",
+			jen.Return(jen.Nil()),
+		)
+		g.code.NewLine()
+	}
+	g.generateContext(found)
+	if g.generateFirstTime {
+		return g.fs.WriteFile(g.filePath, g.srcFile.GoString(), true)
+	}
+	pSrc, err := g.partialSource()
+	if err != nil {
+		return err
+	}
+	src += "\n" + pSrc
+	tmpSrc := g.srcFile.GoString()
+	f, err := parser.NewFileParser().Parse([]byte(tmpSrc))
+	if err != nil {
+		return err
+	}
+	imp, err := g.getMissingImports(f.Imports, g.file)
+	if err != nil {
+		return err
+	}
+	if len(imp) > 0 {
+		src, err = g.AddImportsToFile(imp, src)
+		if err != nil {
+			return err
+		}
+	}
+	s, err := utils.GoImportsSource(g.destPath, src)
+	if err != nil {
+		return err
+	}
+	return g.fs.WriteFile(g.filePath, s, true)
+}
+
+// logLevel returns the logger at the level of go-kit, e.x level.Error(logger), the
+// logger of the service drops the lines below the level of the configuration.
+func logLevel(lvl string, logger jen.Code) *jen.Statement {
+	return jen.Qual(kitLevelImport, lvl).Call(logger)
+}
+
+func (g *generateLogging) generateOutput() {
+	g.code.appendMultilineComment([]string{
+		"Output opens the output of the logger, stderr, stdout or a file URI. The file",
+		"is rotated by size, the rotation is set with the query of the URI",
+		"(e.x file://logs/service.log?max_size=100&max_backups=3&max_age=28&compress=true).",
+	})
+	g.code.NewLine()
+	g.code.Raw().Func().Id("Output").Params(jen.Id("output").String()).Params(
+		jen.Qual("io", "WriteCloser"),
+		jen.Error(),
+	).Block(
+		jen.Switch().Block(
+			jen.Case(jen.Id("output").Op("==").Lit("").Op("||").Id("output").Op("==").Lit("stderr")).Block(
+				jen.Return(jen.Id("nopCloser").Values(jen.Qual("os", "Stderr")), jen.Nil()),
+			),
+			jen.Case(jen.Id("output").Op("==").Lit("stdout")).Block(
+				jen.Return(jen.Id("nopCloser").Values(jen.Qual("os", "Stdout")), jen.Nil()),
+			),
+			jen.Case(jen.Op("!").Qual("strings", "HasPrefix").Call(jen.Id("output"), jen.Lit("file://"))).Block(
+				jen.Return(
+					jen.Nil(),
+					jen.Qual("fmt", "Errorf").Call(jen.Lit("unsupported log output %q, use stderr, stdout or file://path"), jen.Id("output")),
+				),
+			),
+		),
+		jen.List(jen.Id("file"), jen.Id("query")).Op(":=").List(
+			jen.Qual("strings", "TrimPrefix").Call(jen.Id("output"), jen.Lit("file://")),
+			jen.Lit(""),
+		),
+		jen.If(
+			jen.Id("i").Op(":=").Qual("strings", "Index").Call(jen.Id("file"), jen.Lit("?")),
+			jen.Id("i").Op(">=").Lit(0),
+		).Block(
+			jen.List(jen.Id("file"), jen.Id("query")).Op("=").List(
+				jen.Id("file").Index(jen.Empty(), jen.Id("i")),
+				jen.Id("file").Index(jen.Id("i").Op("+").Lit(1), jen.Empty()),
+			),
+		),
+		jen.List(jen.Id("q"), jen.Err()).Op(":=").Qual("net/url", "ParseQuery").Call(jen.Id("query")),
+		jen.If(jen.Err().Op("!=").Nil()).Block(
+			jen.Return(jen.Nil(), jen.Qual("fmt", "Errorf").Call(jen.Lit("log output %s: %v"), jen.Id("output"), jen.Err())),
+		),
+		jen.Id("w").Op(":=").Op("&").Qual(lumberjackImport, "Logger").Values(jen.Dict{
+			jen.Id("Filename"):   jen.Id("file"),
+			jen.Id("MaxSize"):    jen.Lit(100),
+			jen.Id("MaxBackups"): jen.Lit(3),
+			jen.Id("MaxAge"):     jen.Lit(28),
+			jen.Id("Compress"):   jen.Id("q").Dot("Get").Call(jen.Lit("compress")).Op("==").Lit("true"),
+		}),
+		jen.For(jen.List(jen.Id("key"), jen.Id("v")).Op(":=").Range().Map(jen.String()).Op("*").Int().Values(jen.Dict{
+			jen.Lit("max_size"):    jen.Op("&").Id("w").Dot("MaxSize"),
+			jen.Lit("max_backups"): jen.Op("&").Id("w").Dot("MaxBackups"),
+			jen.Lit("max_age"):     jen.Op("&").Id("w").Dot("MaxAge"),
+		})).Block(
+			jen.If(jen.Id("s").Op(":=").Id("q").Dot("Get").Call(jen.Id("key")), jen.Id("s").Op("!=").Lit("")).Block(
+				jen.If(
+					jen.List(jen.Op("*").Id("v"), jen.Err()).Op("=").Qual("strconv", "Atoi").Call(jen.Id("s")),
+					jen.Err().Op("!=").Nil(),
+				).Block(
+					jen.Return(jen.Nil(), jen.Qual("fmt", "Errorf").Call(
+						jen.Lit("log output %s: %s: %v"),
+						jen.Id("output"),
+						jen.Id("key"),
+						jen.Err(),
+					)),
+				),
+			),
+		),
+		jen.Return(jen.Id("w"), jen.Nil()),
+	).Line()
+	g.code.NewLine()
+}
+
+// generateContext generates the logger of the request stored in the context.
+func (g *generateLogging) generateContext(found map[string]bool) {
+	if !found["contextKey"] {
+		g.code.appendStruct("contextKey")
+		g.code.NewLine()
+	}
+	if !found["NewContext"] {
+		g.code.appendMultilineComment([]string{
+			"NewContext returns a copy of the context that stores the logger, the logging",
+			"middleware stores the logger of the request with its method and request ID.",
+		})
+		g.code.NewLine()
+		g.code.appendFunction(
+			"NewContext",
+			nil,
+			[]jen.Code{
+				jen.Id("ctx").Qual("context", "Context"),
+				jen.Id("logger").Qual(kitLogImport, "Logger"),
+			},
+			[]jen.Code{},
+			"context.Context",
+			jen.Return(jen.Qual("context", "WithValue").Call(
+				jen.Id("ctx"),
+				jen.Id("contextKey").Values(),
+				jen.Id("logger"),
+			)),
+		)
+		g.code.NewLine()
+	}
+	if !found["FromContext"] {
+		g.code.appendMultilineComment([]string{
+			"FromContext returns the logger stored in the context, use it to log with the",
+			"method and the request ID of the request. It returns a nop logger if the",
+			"context does not have a logger.",
+		})
+		g.code.NewLine()
+		g.code.Raw().Func().Id("FromContext").Params(
+			jen.Id("ctx").Qual("context", "Context"),
+		).Qual(kitLogImport, "Logger").Block(
+			jen.If(
+				jen.List(jen.Id("logger"), jen.Id("ok")).Op(":=").Id("ctx").Dot("Value").Call(
+					jen.Id("contextKey").Values(),
+				).Assert(jen.Qual(kitLogImport, "Logger")),
+				jen.Id("ok"),
+			).Block(
+				jen.Return(jen.Id("logger")),
+			),
+			jen.Return(jen.Qual(kitLogImport, "NewNopLogger").Call()),
+		).Line()
+		g.code.NewLine()
+	}
+}
@@ -0,0 +1,109 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kujtimiihoxha/kit/parser"
+)
+
+func TestGenerateLogging_Generate(t *testing.T) {
+	f := newClientTestFs()
+	if err := newGenerateLogging("test").Generate(); err != nil {
+		t.Fatalf("generateLogging.Generate() error = %v", err)
+	}
+	src, err := f.ReadFile("test/pkg/logging/logging.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		"func New(format, lvl, output string) (log.Logger, io.Closer, error)",
+		"logger = log.NewJSONLogger(log.NewSyncWriter(w))",
+		"logger = level.NewFilter(logger, allow)",
+		"func Allow(lvl string) (level.Option, error)",
+		"func Output(output string) (io.WriteCloser, error)",
+		"w := &lumberjack.Logger{",
+		"func NewContext(ctx context.Context, logger log.Logger) context.Context",
+		"func FromContext(ctx context.Context) log.Logger",
+		"func Result(logger log.Logger, err error) log.Logger",
+	} {
+		if !strings.Contains(src, v) {
+			t.Errorf("generateLogging.Generate() logging.go does not contain %q", v)
+		}
+	}
+	if err := newGenerateLogging("test").Generate(); err != nil {
+		t.Fatalf("generateLogging.Generate() error = %v", err)
+	}
+	src, err = f.ReadFile("test/pkg/logging/logging.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(src, "func New(") != 1 || strings.Count(src, "type nopCloser struct") != 1 {
+		t.Errorf("generateLogging.Generate() should not generate the existing code again, got %s", src)
+	}
+}
+
+func TestGenerateServiceMiddleware_GenerateRequestLogger(t *testing.T) {
+	f := newClientTestFs()
+	svcSrc, err := f.ReadFile("test/pkg/service/service.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	svcFile, err := parser.NewFileParser().Parse([]byte(svcSrc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := newGenerateServiceMiddleware("test", svcFile, svcFile.Interfaces[0], true).Generate(); err != nil {
+		t.Fatalf("generateServiceMiddleware.Generate() error = %v", err)
+	}
+	src, err := f.ReadFile("test/pkg/service/middleware.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		`logger := log.With(l.logger, "method", "Foo", "request_id", propagation.RequestID(ctx))`,
+		"ctx = logging.NewContext(ctx, logger)",
+		// The failed calls are logged at the error level.
+		`logging.Result(logger, err).Log("a", a, "b", b, "err", err)`,
+	} {
+		if !strings.Contains(src, v) {
+			t.Errorf("generateServiceMiddleware.Generate() middleware.go does not contain %q", v)
+		}
+	}
+}
+
+func TestGenerateCmd_GenerateLogger(t *testing.T) {
+	f := newClientTestFs()
+	if err := NewNewConfig("test", DBPostgres).Generate(); err != nil {
+		t.Fatalf("NewConfig.Generate() error = %v", err)
+	}
+	svcSrc, err := f.ReadFile("test/pkg/service/service.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	svcFile, err := parser.NewFileParser().Parse([]byte(svcSrc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := newGenerateCmd("test", "", svcFile.Interfaces[0], false, false, nil).Generate(); err != nil {
+		t.Fatalf("generateCmd.Generate() error = %v", err)
+	}
+	src, err := f.ReadFile("test/cmd/service/service.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		"logger, logOutput, err = logging.New(conf.Log.Format, conf.Log.Level, conf.Log.Output)",
+		"defer logOutput.Close()",
+		`level.Error(logger).Log("during", "AutoMigration", "err", err)`,
+		`level.Info(logger).Log("exit", g.Run())`,
+		`level.Error(logger).Log("transport", "debug/HTTP", "during", "Listen", "err", err)`,
+	} {
+		if !strings.Contains(src, v) {
+			t.Errorf("generateCmd.Generate() service.go does not contain %q", v)
+		}
+	}
+	if strings.Contains(src, "NewLogfmtLogger") {
+		t.Error("generateCmd.Generate() should build the logger from the log settings")
+	}
+}
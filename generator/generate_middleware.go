@@ -58,6 +58,9 @@ func (g *GenerateMiddleware) Generate() (err error) {
 		return err
 	}
 	g.file, err = parser.NewFileParser().Parse([]byte(svcSrc))
+	if err != nil {
+		return err
+	}
 	if !g.serviceFound() {
 		return
 	}
@@ -0,0 +1,590 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	ps "go/parser"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/kujtimiihoxha/kit/fs"
+	"github.com/kujtimiihoxha/kit/utils"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+)
+
+// migrationSQLDir is the folder of the SQL files in the migration package, the
+// runner embeds it.
+const migrationSQLDir = "sql"
+
+// GenerateMigration generates a versioned SQL migration of the service, the
+// runner that applies the embedded migrations and the migrate subcommand.
+type GenerateMigration struct {
+	BaseGenerator
+	name          string
+	migrationName string
+	destPath      string
+	filePath      string
+	sqlPath       string
+	cmdPath       string
+	mainPath      string
+	db            *dbDriver
+	config        bool
+	// up and down are the statements of the migration, the files only have a
+	// comment if they are not set.
+	up, down string
+}
+
+// NewGenerateMigration returns an initialized and ready generator.
+//
+// The name parameter is the name of the service, the migration files are named
+// <version>_<migrationName>.up.sql and <version>_<migrationName>.down.sql.
+func NewGenerateMigration(name, migrationName string) Gen {
+	i := &GenerateMigration{
+		name:          name,
+		migrationName: utils.ToLowerSnakeCase(migrationName),
+		destPath:      fmt.Sprintf(viper.GetString("gk_migration_path_format"), utils.ToLowerSnakeCase(name)),
+		cmdPath:       fmt.Sprintf(viper.GetString("gk_cmd_service_path_format"), utils.ToLowerSnakeCase(name)),
+		mainPath: path.Join(
+			fmt.Sprintf(viper.GetString("gk_cmd_path_format"), utils.ToLowerSnakeCase(name)),
+			"main.go",
+		),
+	}
+	i.filePath = path.Join(i.destPath, viper.GetString("gk_migration_file_name"))
+	i.sqlPath = path.Join(i.destPath, migrationSQLDir)
+	i.srcFile = jen.NewFilePath(i.destPath)
+	i.InitPg()
+	i.fs = fs.Get()
+	return i
+}
+
+// Generate generates the up and down files of the migration, the runner and the
+// migrate subcommand if the service does not have them yet.
+func (g *GenerateMigration) Generate() (err error) {
+	if g.migrationName == "" {
+		return fmt.Errorf("the migration name is required")
+	}
+	g.db, err = dbExists(g.fs, g.name)
+	if err != nil {
+		return err
+	}
+	if g.db == nil {
+		return fmt.Errorf("service `%s` does not have a database, create it with `kit new service --db`", g.name)
+	}
+	g.config, err = configExists(g.fs, g.name)
+	if err != nil {
+		return err
+	}
+	if err = g.generateSQL(); err != nil {
+		return err
+	}
+	if err = g.generateRunner(); err != nil {
+		return err
+	}
+	if err = g.generateMigrate(); err != nil {
+		return err
+	}
+	return g.addToMain()
+}
+
+// generateSQL writes the up and down files, the version is the UTC time of the
+// generation and is moved forward if another migration already has it.
+func (g *GenerateMigration) generateSQL() error {
+	if err := g.CreateFolderStructure(g.sqlPath); err != nil {
+		return err
+	}
+	version, err := strconv.ParseInt(time.Now().UTC().Format("20060102150405"), 10, 64)
+	if err != nil {
+		return err
+	}
+	for {
+		m, err := afero.Glob(g.fs.Fs, path.Join(g.sqlPath, fmt.Sprintf("%d_*", version)))
+		if err != nil {
+			return err
+		}
+		if len(m) == 0 {
+			break
+		}
+		version++
+	}
+	file := fmt.Sprintf("%d_%s", version, g.migrationName)
+	up, down := g.up, g.down
+	if up == "" {
+		up = fmt.Sprintf("-- Write the statements that apply %s here.\n", g.migrationName)
+	}
+	if down == "" {
+		down = fmt.Sprintf("-- Write the statements that revert %s here.\n", g.migrationName)
+	}
+	if err = g.fs.WriteFile(path.Join(g.sqlPath, file+".up.sql"), up, false); err != nil {
+		return err
+	}
+	return g.fs.WriteFile(path.Join(g.sqlPath, file+".down.sql"), down, false)
+}
+
+// generateRunner generates the migration package, it embeds the SQL files and
+// keeps the applied versions in the schema_migrations table.
+func (g *GenerateMigration) generateRunner() error {
+	if b, err := g.fs.Exists(g.filePath); err != nil || b {
+		return err
+	}
+	g.code.appendMultilineComment([]string{
+		"files are the SQL migrations, <version>_<name>.up.sql applies a migration and",
+		"<version>_<name>.down.sql reverts it.",
+		"",
+		"//go:embed " + migrationSQLDir + "/*.sql",
+	})
+	g.code.NewLine()
+	g.code.Raw().Var().Id("files").Qual("embed", "FS").Line()
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{"versionTable keeps the versions of the applied migrations."})
+	g.code.NewLine()
+	g.code.Raw().Const().Id("versionTable").Op("=").Lit("schema_migrations").Line()
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{"Migration is a versioned SQL migration of the service."})
+	g.code.NewLine()
+	g.code.appendStruct(
+		"Migration",
+		jen.Id("Version").Int64(),
+		jen.Id("Name").String(),
+		jen.Id("Up").String(),
+		jen.Id("Down").String(),
+	)
+	g.code.NewLine()
+	g.generateLoad()
+	g.generateUp()
+	g.generateDown()
+	g.generateVersion()
+	g.generateHelpers()
+	return g.fs.WriteFile(g.filePath, g.srcFile.GoString(), false)
+}
+
+func (g *GenerateMigration) generateLoad() {
+	g.code.appendMultilineComment([]string{"Load returns the embedded migrations sorted by version."})
+	g.code.NewLine()
+	g.code.Raw().Func().Id("Load").Params().Params(jen.Index().Id("Migration"), jen.Error()).Block(
+		jen.List(jen.Id("entries"), jen.Err()).Op(":=").Id("files").Dot("ReadDir").Call(jen.Lit(migrationSQLDir)),
+		jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Nil(), jen.Err())),
+		jen.Id("byVersion").Op(":=").Map(jen.Int64()).Op("*").Id("Migration").Values(),
+		jen.For(jen.List(jen.Id("_"), jen.Id("e")).Op(":=").Range().Id("entries")).Block(
+			jen.Id("base").Op(":=").Qual("strings", "TrimSuffix").Call(jen.Id("e").Dot("Name").Call(), jen.Lit(".sql")),
+			jen.Id("direction").Op(":=").Qual("path", "Ext").Call(jen.Id("base")),
+			jen.Id("base").Op("=").Qual("strings", "TrimSuffix").Call(jen.Id("base"), jen.Id("direction")),
+			jen.Id("i").Op(":=").Qual("strings", "Index").Call(jen.Id("base"), jen.Lit("_")),
+			jen.If(jen.Id("i").Op("<").Lit(0)).Block(
+				jen.Return(jen.Nil(), jen.Qual("fmt", "Errorf").Call(
+					jen.Lit("invalid migration %s, use <version>_<name>.up.sql"),
+					jen.Id("e").Dot("Name").Call(),
+				)),
+			),
+			jen.List(jen.Id("version"), jen.Err()).Op(":=").Qual("strconv", "ParseInt").Call(
+				jen.Id("base").Index(jen.Empty(), jen.Id("i")),
+				jen.Lit(10),
+				jen.Lit(64),
+			),
+			jen.If(jen.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Nil(), jen.Qual("fmt", "Errorf").Call(
+					jen.Lit("invalid migration version %s: %v"),
+					jen.Id("e").Dot("Name").Call(),
+					jen.Err(),
+				)),
+			),
+			jen.List(jen.Id("b"), jen.Err()).Op(":=").Id("files").Dot("ReadFile").Call(
+				jen.Lit(migrationSQLDir+"/").Op("+").Id("e").Dot("Name").Call(),
+			),
+			jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Nil(), jen.Err())),
+			jen.List(jen.Id("m"), jen.Id("ok")).Op(":=").Id("byVersion").Index(jen.Id("version")),
+			jen.If(jen.Op("!").Id("ok")).Block(
+				jen.Id("m").Op("=").Op("&").Id("Migration").Values(jen.Dict{
+					jen.Id("Version"): jen.Id("version"),
+					jen.Id("Name"):    jen.Id("base").Index(jen.Id("i").Op("+").Lit(1), jen.Empty()),
+				}),
+				jen.Id("byVersion").Index(jen.Id("version")).Op("=").Id("m"),
+			),
+			jen.Switch(jen.Id("direction")).Block(
+				jen.Case(jen.Lit(".up")).Block(
+					jen.Id("m").Dot("Up").Op("=").String().Call(jen.Id("b")),
+				),
+				jen.Case(jen.Lit(".down")).Block(
+					jen.Id("m").Dot("Down").Op("=").String().Call(jen.Id("b")),
+				),
+				jen.Default().Block(
+					jen.Return(jen.Nil(), jen.Qual("fmt", "Errorf").Call(
+						jen.Lit("invalid migration %s, use .up.sql or .down.sql"),
+						jen.Id("e").Dot("Name").Call(),
+					)),
+				),
+			),
+		),
+		jen.Id("migrations").Op(":=").Make(jen.Index().Id("Migration"), jen.Lit(0), jen.Len(jen.Id("byVersion"))),
+		jen.For(jen.List(jen.Id("_"), jen.Id("m")).Op(":=").Range().Id("byVersion")).Block(
+			jen.Id("migrations").Op("=").Append(jen.Id("migrations"), jen.Id("*m")),
+		),
+		jen.Qual("sort", "Slice").Call(
+			jen.Id("migrations"),
+			jen.Func().Params(jen.List(jen.Id("i"), jen.Id("j")).Int()).Bool().Block(
+				jen.Return(jen.Id("migrations").Index(jen.Id("i")).Dot("Version").Op("<").Id("migrations").Index(jen.Id("j")).Dot("Version")),
+			),
+		),
+		jen.Return(jen.Id("migrations"), jen.Nil()),
+	).Line()
+	g.code.NewLine()
+}
+
+func (g *GenerateMigration) generateUp() {
+	g.code.appendMultilineComment([]string{
+		"Up applies the migrations that were not applied yet in the order of their",
+		"versions, each migration is recorded in the transaction that applies it.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"Up",
+		nil,
+		[]jen.Code{jen.Id("db").Op("*").Qual("database/sql", "DB"), jen.Id("dialect").String()},
+		[]jen.Code{},
+		"error",
+		jen.List(jen.Id("migrations"), jen.Err()).Op(":=").Id("Load").Call(),
+		jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Err())),
+		jen.List(jen.Id("applied"), jen.Err()).Op(":=").Id("appliedVersions").Call(jen.Id("db")),
+		jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Err())),
+		jen.Id("record").Op(":=").Id("bind").Call(
+			jen.Id("dialect"),
+			jen.Lit("INSERT INTO ").Op("+").Id("versionTable").Op("+").Lit(" (version, applied_at) VALUES (?, ?)"),
+		),
+		jen.For(jen.List(jen.Id("_"), jen.Id("m")).Op(":=").Range().Id("migrations")).Block(
+			jen.If(jen.Id("applied").Index(jen.Id("m").Dot("Version"))).Block(jen.Continue()),
+			jen.If(
+				jen.Err().Op(":=").Id("run").Call(
+					jen.Id("db"),
+					jen.Id("m").Dot("Up"),
+					jen.Id("record"),
+					jen.Id("m").Dot("Version"),
+					jen.Qual("time", "Now").Call().Dot("UTC").Call(),
+				),
+				jen.Err().Op("!=").Nil(),
+			).Block(
+				jen.Return(jen.Qual("fmt", "Errorf").Call(
+					jen.Lit("migration %d_%s: %v"),
+					jen.Id("m").Dot("Version"),
+					jen.Id("m").Dot("Name"),
+					jen.Err(),
+				)),
+			),
+		),
+		jen.Return(jen.Nil()),
+	)
+	g.code.NewLine()
+	g.code.NewLine()
+}
+
+func (g *GenerateMigration) generateDown() {
+	g.code.appendMultilineComment([]string{"Down reverts the last applied migration."})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"Down",
+		nil,
+		[]jen.Code{jen.Id("db").Op("*").Qual("database/sql", "DB"), jen.Id("dialect").String()},
+		[]jen.Code{},
+		"error",
+		jen.List(jen.Id("version"), jen.Err()).Op(":=").Id("Version").Call(jen.Id("db")),
+		jen.If(jen.Err().Op("!=").Nil().Op("||").Id("version").Op("==").Lit(0)).Block(jen.Return(jen.Err())),
+		jen.List(jen.Id("migrations"), jen.Err()).Op(":=").Id("Load").Call(),
+		jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Err())),
+		jen.For(jen.List(jen.Id("_"), jen.Id("m")).Op(":=").Range().Id("migrations")).Block(
+			jen.If(jen.Id("m").Dot("Version").Op("!=").Id("version")).Block(jen.Continue()),
+			jen.If(
+				jen.Err().Op(":=").Id("run").Call(
+					jen.Id("db"),
+					jen.Id("m").Dot("Down"),
+					jen.Id("bind").Call(
+						jen.Id("dialect"),
+						jen.Lit("DELETE FROM ").Op("+").Id("versionTable").Op("+").Lit(" WHERE version = ?"),
+					),
+					jen.Id("m").Dot("Version"),
+				),
+				jen.Err().Op("!=").Nil(),
+			).Block(
+				jen.Return(jen.Qual("fmt", "Errorf").Call(
+					jen.Lit("migration %d_%s: %v"),
+					jen.Id("m").Dot("Version"),
+					jen.Id("m").Dot("Name"),
+					jen.Err(),
+				)),
+			),
+			jen.Return(jen.Nil()),
+		),
+		jen.Return(jen.Qual("fmt", "Errorf").Call(
+			jen.Lit("migration %d is applied but the service does not embed it"),
+			jen.Id("version"),
+		)),
+	)
+	g.code.NewLine()
+	g.code.NewLine()
+}
+
+func (g *GenerateMigration) generateVersion() {
+	g.code.appendMultilineComment([]string{"Version returns the version of the last applied migration, 0 if none was applied."})
+	g.code.NewLine()
+	g.code.Raw().Func().Id("Version").Params(
+		jen.Id("db").Op("*").Qual("database/sql", "DB"),
+	).Params(jen.Int64(), jen.Error()).Block(
+		jen.If(
+			jen.Err().Op(":=").Id("createVersionTable").Call(jen.Id("db")),
+			jen.Err().Op("!=").Nil(),
+		).Block(jen.Return(jen.Lit(0), jen.Err())),
+		jen.Var().Id("version").Qual("database/sql", "NullInt64"),
+		jen.Err().Op(":=").Id("db").Dot("QueryRow").Call(
+			jen.Lit("SELECT MAX(version) FROM ").Op("+").Id("versionTable"),
+		).Dot("Scan").Call(jen.Op("&").Id("version")),
+		jen.Return(jen.Id("version").Dot("Int64"), jen.Err()),
+	).Line()
+	g.code.NewLine()
+}
+
+func (g *GenerateMigration) generateHelpers() {
+	g.code.Raw().Func().Id("appliedVersions").Params(
+		jen.Id("db").Op("*").Qual("database/sql", "DB"),
+	).Params(jen.Map(jen.Int64()).Bool(), jen.Error()).Block(
+		jen.If(
+			jen.Err().Op(":=").Id("createVersionTable").Call(jen.Id("db")),
+			jen.Err().Op("!=").Nil(),
+		).Block(jen.Return(jen.Nil(), jen.Err())),
+		jen.List(jen.Id("rows"), jen.Err()).Op(":=").Id("db").Dot("Query").Call(
+			jen.Lit("SELECT version FROM ").Op("+").Id("versionTable"),
+		),
+		jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Nil(), jen.Err())),
+		jen.Defer().Id("rows").Dot("Close").Call(),
+		jen.Id("applied").Op(":=").Map(jen.Int64()).Bool().Values(),
+		jen.For(jen.Id("rows").Dot("Next").Call()).Block(
+			jen.Var().Id("version").Int64(),
+			jen.If(
+				jen.Err().Op(":=").Id("rows").Dot("Scan").Call(jen.Op("&").Id("version")),
+				jen.Err().Op("!=").Nil(),
+			).Block(jen.Return(jen.Nil(), jen.Err())),
+			jen.Id("applied").Index(jen.Id("version")).Op("=").True(),
+		),
+		jen.Return(jen.Id("applied"), jen.Id("rows").Dot("Err").Call()),
+	).Line()
+	g.code.NewLine()
+	g.code.appendFunction(
+		"createVersionTable",
+		nil,
+		[]jen.Code{jen.Id("db").Op("*").Qual("database/sql", "DB")},
+		[]jen.Code{},
+		"error",
+		jen.List(jen.Id("_"), jen.Err()).Op(":=").Id("db").Dot("Exec").Call(
+			jen.Lit("CREATE TABLE IF NOT EXISTS ").Op("+").Id("versionTable").Op("+").
+				Lit(" (version BIGINT NOT NULL PRIMARY KEY, applied_at TIMESTAMP NOT NULL)"),
+		),
+		jen.Return(jen.Err()),
+	)
+	g.code.NewLine()
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"run executes the statements of a migration and records it in one transaction,",
+		"the databases that commit the DDL statements implicitly only keep the record atomic.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"run",
+		nil,
+		[]jen.Code{
+			jen.Id("db").Op("*").Qual("database/sql", "DB"),
+			jen.List(jen.Id("statements"), jen.Id("record")).String(),
+			jen.Id("args").Op("...").Interface(),
+		},
+		[]jen.Code{},
+		"error",
+		jen.List(jen.Id("tx"), jen.Err()).Op(":=").Id("db").Dot("Begin").Call(),
+		jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Err())),
+		jen.If(jen.Qual("strings", "TrimSpace").Call(jen.Id("statements")).Op("!=").Lit("")).Block(
+			jen.If(
+				jen.List(jen.Id("_"), jen.Err()).Op(":=").Id("tx").Dot("Exec").Call(jen.Id("statements")),
+				jen.Err().Op("!=").Nil(),
+			).Block(
+				jen.Id("tx").Dot("Rollback").Call(),
+				jen.Return(jen.Err()),
+			),
+		),
+		jen.If(
+			jen.List(jen.Id("_"), jen.Err()).Op(":=").Id("tx").Dot("Exec").Call(jen.Id("record"), jen.Id("args").Op("...")),
+			jen.Err().Op("!=").Nil(),
+		).Block(
+			jen.Id("tx").Dot("Rollback").Call(),
+			jen.Return(jen.Err()),
+		),
+		jen.Return(jen.Id("tx").Dot("Commit").Call()),
+	)
+	g.code.NewLine()
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{"bind replaces the ? placeholders of the query with $1, $2... on Postgres."})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"bind",
+		nil,
+		[]jen.Code{jen.List(jen.Id("dialect"), jen.Id("query")).String()},
+		[]jen.Code{},
+		"string",
+		jen.If(jen.Id("dialect").Op("!=").Lit("postgres")).Block(jen.Return(jen.Id("query"))),
+		jen.For(
+			jen.Id("i").Op(":=").Lit(1),
+			jen.Qual("strings", "Contains").Call(jen.Id("query"), jen.Lit("?")),
+			jen.Id("i").Op("++"),
+		).Block(
+			jen.Id("query").Op("=").Qual("strings", "Replace").Call(
+				jen.Id("query"),
+				jen.Lit("?"),
+				jen.Lit("$").Op("+").Qual("strconv", "Itoa").Call(jen.Id("i")),
+				jen.Lit(1),
+			),
+		),
+		jen.Return(jen.Id("query")),
+	)
+	g.code.NewLine()
+}
+
+// generateMigrate generates the migrate subcommand in the cmd service package, it
+// connects to the database with the settings of the service.
+func (g *GenerateMigration) generateMigrate() error {
+	filePath := path.Join(g.cmdPath, viper.GetString("gk_cmd_migrate_file_name"))
+	if b, err := g.fs.Exists(filePath); err != nil || b {
+		return err
+	}
+	migrationImport, err := utils.GetMigrationImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	dbImport, err := utils.GetDBImportPath(g.name, g.db.name)
+	if err != nil {
+		return err
+	}
+	exit := []jen.Code{
+		jen.Qual("fmt", "Fprintln").Call(jen.Qual("os", "Stderr"), jen.Err()),
+		jen.Qual("os", "Exit").Call(jen.Lit(1)),
+	}
+	body := []jen.Code{
+		jen.Id("action").Op(":=").Lit("up"),
+		jen.If(
+			jen.Len(jen.Id("args")).Op(">").Lit(0).Op("&&").
+				Op("!").Qual("strings", "HasPrefix").Call(jen.Id("args").Index(jen.Lit(0)), jen.Lit("-")),
+		).Block(
+			jen.List(jen.Id("action"), jen.Id("args")).Op("=").List(
+				jen.Id("args").Index(jen.Lit(0)),
+				jen.Id("args").Index(jen.Lit(1), jen.Empty()),
+			),
+		),
+	}
+	if g.config {
+		configImport, err := utils.GetConfigImportPath(g.name)
+		if err != nil {
+			return err
+		}
+		body = append(body, jen.If(
+			jen.List(jen.Id("_"), jen.Err()).Op(":=").Qual(configImport, "Load").Call(jen.Lit(g.name), jen.Id("args")),
+			jen.Err().Op("!=").Nil(),
+		).Block(exit...))
+	}
+	body = append(
+		body,
+		jen.List(jen.Id("d"), jen.Err()).Op(":=").Qual(dbImport, "GetDatabase").Call(jen.Qual(dbImport, "DefaultConnName")),
+		jen.If(jen.Err().Op("!=").Nil()).Block(exit...),
+		jen.Defer().Id("d").Dot("Close").Call(),
+		jen.Switch(jen.Id("action")).Block(
+			jen.Case(jen.Lit("up")).Block(
+				jen.Err().Op("=").Qual(migrationImport, "Up").Call(jen.Id("d").Dot("DB").Call(), jen.Qual(dbImport, "Dialect")),
+			),
+			jen.Case(jen.Lit("down")).Block(
+				jen.Err().Op("=").Qual(migrationImport, "Down").Call(jen.Id("d").Dot("DB").Call(), jen.Qual(dbImport, "Dialect")),
+			),
+			jen.Case(jen.Lit("version")).Block(
+				jen.Var().Id("version").Int64(),
+				jen.If(
+					jen.List(jen.Id("version"), jen.Err()).Op("=").Qual(migrationImport, "Version").Call(jen.Id("d").Dot("DB").Call()),
+					jen.Err().Op("==").Nil(),
+				).Block(
+					jen.Qual("fmt", "Println").Call(jen.Id("version")),
+				),
+			),
+			jen.Default().Block(
+				jen.Err().Op("=").Qual("fmt", "Errorf").Call(
+					jen.Lit("unknown migrate command %q, use up, down or version"),
+					jen.Id("action"),
+				),
+			),
+		),
+		jen.If(jen.Err().Op("!=").Nil()).Block(exit...),
+	)
+	src := jen.NewFilePath(g.cmdPath)
+	src.Comment("Migrate runs the migrate subcommand, up applies the pending migrations, down")
+	src.Comment("reverts the last applied one and version prints the version of the schema.")
+	src.Func().Id("Migrate").Params(jen.Id("args").Index().String()).Block(body...)
+	return g.fs.WriteFile(filePath, src.GoString(), false)
+}
+
+// addToMain dispatches the migrate subcommand in the main of the service, the
+// main is generated with it when the service does not have one yet.
+func (g *GenerateMigration) addToMain() error {
+	if b, err := g.fs.Exists(g.mainPath); err != nil || !b {
+		return err
+	}
+	src, err := g.fs.ReadFile(g.mainPath)
+	if err != nil {
+		return err
+	}
+	if strings.Contains(src, "Migrate(") {
+		return nil
+	}
+	fset := token.NewFileSet()
+	f, err := ps.ParseFile(fset, "", src, ps.ParseComments)
+	if err != nil {
+		return err
+	}
+	// The subcommand is dispatched to the package of the Run the main calls.
+	qualifier, offset := "", -1
+	for _, v := range f.Decls {
+		fn, ok := v.(*ast.FuncDecl)
+		if !ok || fn.Name.Name != "main" || fn.Recv != nil || fn.Body == nil {
+			continue
+		}
+		offset = fset.Position(fn.Body.Lbrace).Offset + 1
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok && sel.Sel.Name == "Run" {
+				if id, ok := sel.X.(*ast.Ident); ok {
+					qualifier = id.Name
+				}
+			}
+			return qualifier == ""
+		})
+	}
+	if qualifier == "" {
+		return fmt.Errorf("the main of service `%s` does not run the service", g.name)
+	}
+	src, err = insertSource(src, sourceInsert{
+		offset: offset,
+		text: fmt.Sprintf(
+			"\nif len(os.Args) > 1 && os.Args[1] == \"migrate\" {\n%s.Migrate(os.Args[2:])\nreturn\n}",
+			qualifier,
+		),
+	})
+	if err != nil {
+		return err
+	}
+	src, err = addSourceImport(src, "", "os")
+	if err != nil {
+		return err
+	}
+	return g.fs.WriteFile(g.mainPath, src, true)
+}
+
+// migrationExists returns true if the service has the migration runner and the
+// migrate subcommand.
+func migrationExists(f *fs.KitFs, name string) (bool, error) {
+	return f.Exists(path.Join(
+		fmt.Sprintf(viper.GetString("gk_migration_path_format"), utils.ToLowerSnakeCase(name)),
+		viper.GetString("gk_migration_file_name"),
+	))
+}
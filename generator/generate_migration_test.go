@@ -0,0 +1,110 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kujtimiihoxha/kit/parser"
+	"github.com/spf13/afero"
+)
+
+func TestGenerateMigration_Generate(t *testing.T) {
+	f := newClientTestFs()
+	f.MkdirAll("test/cmd")
+	f.WriteFile("test/cmd/main.go", `package main
+
+import service "test/cmd/service"
+
+func main() {
+	service.Run()
+}
+`, true)
+	for i := 0; i < 2; i++ {
+		if err := NewGenerateMigration("test", "CreateTodos").Generate(); err != nil {
+			t.Fatalf("GenerateMigration.Generate() error = %v", err)
+		}
+	}
+	for _, v := range []string{"up", "down"} {
+		m, err := afero.Glob(f.Fs, "test/pkg/migration/sql/*_create_todos."+v+".sql")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(m) != 2 {
+			t.Errorf("GenerateMigration.Generate() should write a %s file with a new version each time, got %v", v, m)
+		}
+	}
+	src, err := f.ReadFile("test/pkg/migration/migration.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		"//go:embed sql/*.sql\nvar files embed.FS",
+		`const versionTable = "schema_migrations"`,
+		"func Load() ([]Migration, error)",
+		"func Up(db *sql.DB, dialect string) error",
+		"func Down(db *sql.DB, dialect string) error",
+		"func Version(db *sql.DB) (int64, error)",
+		"(version BIGINT NOT NULL PRIMARY KEY, applied_at TIMESTAMP NOT NULL)",
+	} {
+		if !strings.Contains(src, v) {
+			t.Errorf("GenerateMigration.Generate() migration.go does not contain %q", v)
+		}
+	}
+	src, err = f.ReadFile("test/cmd/service/migrate.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		"func Migrate(args []string)",
+		`d, err := postgres.GetDatabase(postgres.DefaultConnName)`,
+		"err = migration.Up(d.DB(), postgres.Dialect)",
+	} {
+		if !strings.Contains(src, v) {
+			t.Errorf("GenerateMigration.Generate() migrate.go does not contain %q", v)
+		}
+	}
+	src, err = f.ReadFile("test/cmd/main.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(src, "if len(os.Args) > 1 && os.Args[1] == \"migrate\" {\n\t\tservice.Migrate(os.Args[2:])") {
+		t.Errorf("GenerateMigration.Generate() should dispatch the migrate subcommand in main, got %s", src)
+	}
+	if strings.Count(src, "Migrate(") != 1 {
+		t.Errorf("GenerateMigration.Generate() should dispatch the migrate subcommand once, got %s", src)
+	}
+}
+
+func TestGenerateCmd_GenerateAutoMigrate(t *testing.T) {
+	f := newClientTestFs()
+	if err := NewNewConfig("test", DBPostgres).Generate(); err != nil {
+		t.Fatalf("NewConfig.Generate() error = %v", err)
+	}
+	f.MkdirAll("test/pkg/migration")
+	f.WriteFile("test/pkg/migration/migration.go", "package migration", true)
+	svcSrc, err := f.ReadFile("test/pkg/service/service.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	svcFile, err := parser.NewFileParser().Parse([]byte(svcSrc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := newGenerateCmd("test", "", svcFile.Interfaces[0], false, false, nil).Generate(); err != nil {
+		t.Fatalf("generateCmd.Generate() error = %v", err)
+	}
+	src, err := f.ReadFile("test/cmd/service/service.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(src, "if conf.DB.AutoMigrate {\n\t\tif err := model.AutoMigration(); err != nil {") {
+		t.Errorf("generateCmd.Generate() should only run AutoMigration in the development mode, got %s", src)
+	}
+	src, err = f.ReadFile("test/cmd/main.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(src, "service.Migrate(os.Args[2:])") {
+		t.Errorf("generateCmd.Generate() should dispatch the migrate subcommand in main, got %s", src)
+	}
+}
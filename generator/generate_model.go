@@ -0,0 +1,696 @@
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	ps "go/parser"
+	"go/token"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/kujtimiihoxha/kit/fs"
+	"github.com/kujtimiihoxha/kit/parser"
+	"github.com/kujtimiihoxha/kit/utils"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// modelField is a field of a generated model.
+type modelField struct {
+	name   string
+	column string
+	tp     string
+}
+
+// code returns the type of the field.
+func (f modelField) code() *jen.Statement {
+	tp := strings.TrimPrefix(f.tp, "*")
+	s := jen.Empty()
+	if tp != f.tp {
+		s = jen.Op("*")
+	}
+	switch tp {
+	case "time.Time":
+		return s.Qual("time", "Time")
+	case "uuid.UUID":
+		return s.Qual(uuidImport, "UUID")
+	}
+	return s.Id(tp)
+}
+
+// filter returns true if List can filter the models by the field.
+func (f modelField) filter() bool {
+	return f.tp != "[]byte"
+}
+
+// modelFieldTypes are the types the fields of the generated models can have.
+var modelFieldTypes = map[string]bool{
+	"string": true, "bool": true, "[]byte": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"float32": true, "float64": true, "time.Time": true, "uuid.UUID": true,
+}
+
+// parseModelFields parses the fields of a model, e.x "Title:string,Done:bool".
+func parseModelFields(fields string) ([]modelField, error) {
+	reserved := map[string]bool{
+		"BaseModel": true, "ID": true, "CreatorID": true, "UpdaterID": true,
+		"CreatedAt": true, "UpdatedAt": true, "DeletedAt": true,
+	}
+	seen := map[string]bool{}
+	mf := []modelField{}
+	for _, v := range strings.Split(fields, ",") {
+		if strings.TrimSpace(v) == "" {
+			continue
+		}
+		nt := strings.SplitN(v, ":", 2)
+		if len(nt) != 2 || strings.TrimSpace(nt[0]) == "" {
+			return nil, fmt.Errorf("invalid field `%s`, use Name:type", v)
+		}
+		f := modelField{
+			name: utils.ToCamelCase(strings.TrimSpace(nt[0])),
+			tp:   strings.TrimSpace(nt[1]),
+		}
+		f.column = utils.ToLowerSnakeCase(f.name)
+		if !modelFieldTypes[strings.TrimPrefix(f.tp, "*")] {
+			return nil, fmt.Errorf("field `%s` has the unsupported type `%s`", f.name, f.tp)
+		}
+		if reserved[f.name] {
+			return nil, fmt.Errorf("field `%s` is already a field of BaseModel", f.name)
+		}
+		if seen[f.name] {
+			return nil, fmt.Errorf("field `%s` is defined twice", f.name)
+		}
+		seen[f.name] = true
+		mf = append(mf, f)
+	}
+	return mf, nil
+}
+
+// GenerateModel implements Gen and is used to generate a model, its repository
+// and the gorm implementation of the repository.
+type GenerateModel struct {
+	BaseGenerator
+	name              string
+	modelName         string
+	fields            []modelField
+	fieldsErr         error
+	db                *dbDriver
+	dbImport          string
+	destPath          string
+	filePath          string
+	file              *parser.File
+	generateFirstTime bool
+}
+
+// NewGenerateModel returns a initialized and ready generator.
+//
+// The name parameter is the name of the service, the model is named modelName
+// and has the fields, e.x "Title:string,Done:bool".
+func NewGenerateModel(name, modelName, fields string) Gen {
+	i := &GenerateModel{
+		name:      name,
+		modelName: utils.ToCamelCase(modelName),
+		destPath:  fmt.Sprintf(viper.GetString("gk_model_path_format"), utils.ToLowerSnakeCase(name)),
+	}
+	i.fields, i.fieldsErr = parseModelFields(fields)
+	i.filePath = path.Join(i.destPath, utils.ToLowerSnakeCase(modelName)+".go")
+	i.srcFile = jen.NewFilePath(i.destPath)
+	i.InitPg()
+	i.fs = fs.Get()
+	return i
+}
+
+// Generate generates the model, registers it in AutoMigration and adds its
+// repository to the service.
+func (g *GenerateModel) Generate() (err error) {
+	if g.fieldsErr != nil {
+		return g.fieldsErr
+	}
+	g.db, err = dbExists(g.fs, g.name)
+	if err != nil {
+		return err
+	}
+	if g.db == nil {
+		return fmt.Errorf("service `%s` does not have a database, create it with `kit new service --db`", g.name)
+	}
+	g.dbImport, err = utils.GetDBImportPath(g.name, g.db.name)
+	if err != nil {
+		return err
+	}
+	if err = g.generateTx(); err != nil {
+		return err
+	}
+	if err = g.generateModel(); err != nil {
+		return err
+	}
+	if err = g.registerMigration(); err != nil {
+		return err
+	}
+	return g.addToService()
+}
+
+// generateTx generates the functions that carry a transaction in the context, the
+// repositories run their queries in it so the callers can group them.
+func (g *GenerateModel) generateTx() error {
+	filePath := path.Join(g.destPath, "tx.go")
+	if b, err := g.fs.Exists(filePath); err != nil || b {
+		return err
+	}
+	if err := g.CreateFolderStructure(g.destPath); err != nil {
+		return err
+	}
+	f := jen.NewFilePath(g.destPath)
+	f.Comment("txKey is the key of the transaction in the context.")
+	f.Type().Id("txKey").Struct()
+	f.Line()
+	f.Comment("ContextWithTx returns a copy of ctx that carries the transaction tx, the")
+	f.Comment("repositories called with the context run their queries in tx, e.x:")
+	f.Comment("")
+	f.Comment("	tx := db.BeginTx(ctx, nil)")
+	f.Comment("	err := todos.Create(model.ContextWithTx(ctx, tx), todo)")
+	f.Func().Id("ContextWithTx").Params(
+		jen.Id("ctx").Qual("context", "Context"),
+		jen.Id("tx").Op("*").Qual(gormImport, "DB"),
+	).Qual("context", "Context").Block(
+		jen.Return(jen.Qual("context", "WithValue").Call(jen.Id("ctx"), jen.Id("txKey").Values(), jen.Id("tx"))),
+	)
+	f.Line()
+	f.Comment("TxFromContext returns the transaction ctx carries, nil if it carries none.")
+	f.Func().Id("TxFromContext").Params(
+		jen.Id("ctx").Qual("context", "Context"),
+	).Op("*").Qual(gormImport, "DB").Block(
+		jen.List(jen.Id("tx"), jen.Id("_")).Op(":=").Id("ctx").Dot("Value").Call(jen.Id("txKey").Values()).Assert(jen.Op("*").Qual(gormImport, "DB")),
+		jen.Return(jen.Id("tx")),
+	)
+	return g.fs.WriteFile(filePath, f.GoString(), false)
+}
+
+func (g *GenerateModel) generateModel() (err error) {
+	err = g.CreateFolderStructure(g.destPath)
+	if err != nil {
+		return err
+	}
+	if b, err := g.fs.Exists(g.filePath); err != nil {
+		return err
+	} else if !b {
+		g.generateFirstTime = true
+		f := jen.NewFile("model")
+		g.fs.WriteFile(g.filePath, f.GoString(), false)
+	}
+	src, err := g.fs.ReadFile(g.filePath)
+	if err != nil {
+		return err
+	}
+	g.file, err = parser.NewFileParser().Parse([]byte(src))
+	if err != nil {
+		return err
+	}
+	found := map[string]bool{}
+	for _, v := range g.file.Methods {
+		if v.Struct.Type != "" {
+			found[v.Struct.Type+"."+v.Name] = true
+			continue
+		}
+		found[v.Name] = true
+	}
+	for _, v := range g.file.Structures {
+		found[v.Name] = true
+	}
+	for _, v := range g.file.Interfaces {
+		found[v.Name] = true
+	}
+	g.generateStructs(found)
+	g.generateRepository(found)
+	if g.generateFirstTime {
+		return g.fs.WriteFile(g.filePath, g.srcFile.GoString(), true)
+	}
+	pSrc, err := g.partialSource()
+	if err != nil {
+		return err
+	}
+	src += "\n" + pSrc
+	f, err := parser.NewFileParser().Parse([]byte(g.srcFile.GoString()))
+	if err != nil {
+		return err
+	}
+	imp, err := g.getMissingImports(f.Imports, g.file)
+	if err != nil {
+		return err
+	}
+	if len(imp) > 0 {
+		src, err = g.AddImportsToFile(imp, src)
+		if err != nil {
+			return err
+		}
+	}
+	s, err := utils.GoImportsSource(g.destPath, src)
+	if err != nil {
+		return err
+	}
+	return g.fs.WriteFile(g.filePath, s, true)
+}
+
+func (g *GenerateModel) generateStructs(found map[string]bool) {
+	if !found[g.modelName] {
+		fields := []jen.Code{jen.Id("BaseModel")}
+		for _, f := range g.fields {
+			fields = append(fields, jen.Id(f.name).Add(f.code()).Tag(map[string]string{
+				"json": f.column,
+				"gorm": "column:" + f.column,
+			}))
+		}
+		g.code.Raw().Commentf("%s is a model of the service, %sRepository stores it.", g.modelName, g.modelName).Line()
+		g.code.appendStruct(g.modelName, fields...)
+		g.code.NewLine()
+	}
+	filter := g.modelName + "Filter"
+	if !found[filter] {
+		g.code.appendMultilineComment([]string{
+			fmt.Sprintf("%s filters the %s models of List by equality, the nil fields are ignored.", filter, g.modelName),
+		})
+		g.code.NewLine()
+		g.code.appendStruct(filter, g.filterFields()...)
+		g.code.NewLine()
+	}
+}
+
+// filterFields returns the fields of the filter of List.
+func (g *GenerateModel) filterFields() []jen.Code {
+	fields := []jen.Code{}
+	for _, f := range g.fields {
+		if !f.filter() {
+			continue
+		}
+		fields = append(fields, jen.Id(f.name).Op("*").Add(modelField{tp: strings.TrimPrefix(f.tp, "*")}.code()))
+	}
+	return fields
+}
+
+func (g *GenerateModel) generateRepository(found map[string]bool) {
+	repo := g.modelName + "Repository"
+	impl := "gorm" + repo
+	m := jen.Op("*").Id(g.modelName)
+	if !found[repo] {
+		g.code.Raw().Commentf(
+			"%s stores the %s models, Delete soft deletes them so they are no longer returned.",
+			repo,
+			g.modelName,
+		).Line()
+		g.code.Raw().Comment("The methods run in the transaction of ContextWithTx if the context carries one.").Line()
+		g.code.appendInterface(repo, []jen.Code{
+			jen.Id("Create").Params(jen.Id("ctx").Qual("context", "Context"), jen.Id("m").Add(m)).Error(),
+			jen.Id("Update").Params(jen.Id("ctx").Qual("context", "Context"), jen.Id("m").Add(m)).Error(),
+			jen.Id("GetByID").Params(
+				jen.Id("ctx").Qual("context", "Context"),
+				jen.Id("id").Qual(uuidImport, "UUID"),
+			).Params(m, jen.Error()),
+			jen.Id("List").Params(
+				jen.Id("ctx").Qual("context", "Context"),
+				jen.Id("filter").Id(g.modelName+"Filter"),
+				jen.List(jen.Id("offset"), jen.Id("limit")).Int(),
+			).Params(jen.Index().Id(g.modelName), jen.Int(), jen.Error()),
+			jen.Id("Delete").Params(jen.Id("ctx").Qual("context", "Context"), jen.Id("id").Qual(uuidImport, "UUID")).Error(),
+		})
+		g.code.NewLine()
+	}
+	if !found["New"+repo] {
+		g.code.appendMultilineComment([]string{
+			fmt.Sprintf("New%s returns the gorm %s, it uses the default connection", repo, repo),
+			fmt.Sprintf("of the %s registry.", g.db.name),
+		})
+		g.code.NewLine()
+		g.code.appendFunction(
+			"New"+repo,
+			nil,
+			[]jen.Code{},
+			[]jen.Code{},
+			repo,
+			jen.Return(jen.Op("&").Id(impl).Values(jen.Dict{
+				jen.Id("alias"): jen.Qual(g.dbImport, "DefaultConnName"),
+			})),
+		)
+		g.code.NewLine()
+	}
+	if !found[impl] {
+		g.code.appendStruct(impl, jen.Id("alias").String())
+		g.code.NewLine()
+	}
+	stp := jen.Id("r").Op("*").Id(impl)
+	getDB := []jen.Code{
+		jen.List(jen.Id("db"), jen.Err()).Op(":=").Id("r").Dot("db").Call(jen.Id("ctx")),
+	}
+	ctx := jen.Id("ctx").Qual("context", "Context")
+	if !found["*"+impl+".db"] {
+		g.code.appendMultilineComment([]string{
+			"db returns the transaction the context carries, the connection of the alias",
+			"otherwise. The queries are not started once the context is done.",
+		})
+		g.code.NewLine()
+		g.code.Raw().Func().Params(stp).Id("db").Params(ctx).Params(
+			jen.Op("*").Qual(gormImport, "DB"),
+			jen.Error(),
+		).Block(
+			jen.If(jen.Err().Op(":=").Id("ctx").Dot("Err").Call(), jen.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Nil(), jen.Err()),
+			),
+			jen.If(jen.Id("tx").Op(":=").Id("TxFromContext").Call(jen.Id("ctx")), jen.Id("tx").Op("!=").Nil()).Block(
+				jen.Return(jen.Id("tx"), jen.Nil()),
+			),
+			jen.Return(jen.Qual(g.dbImport, "GetDatabase").Call(jen.Id("r").Dot("alias"))),
+		).Line()
+		g.code.NewLine()
+	}
+	if !found["*"+impl+".Create"] {
+		g.code.appendFunction(
+			"Create",
+			stp,
+			[]jen.Code{ctx, jen.Id("m").Add(m)},
+			[]jen.Code{},
+			"error",
+			append(
+				getDB,
+				jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Err())),
+				jen.Return(jen.Id("db").Dot("Create").Call(jen.Id("m")).Dot("Error")),
+			)...,
+		)
+		g.code.NewLine()
+		g.code.NewLine()
+	}
+	if !found["*"+impl+".Update"] {
+		values := jen.Dict{
+			jen.Lit("updater_id"): jen.Id("m").Dot("UpdaterID"),
+		}
+		for _, f := range g.fields {
+			values[jen.Lit(f.column)] = jen.Id("m").Dot(f.name)
+		}
+		g.code.appendFunction(
+			"Update",
+			stp,
+			[]jen.Code{ctx, jen.Id("m").Add(m)},
+			[]jen.Code{},
+			"error",
+			append(
+				getDB,
+				jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Err())),
+				jen.Comment("Updates the zero values too, unlike Updates with the struct."),
+				jen.Id("res").Op(":=").Id("db").Dot("Model").Call(jen.Op("&").Id(g.modelName).Values()).
+					Dot("Where").Call(jen.Lit("id = ?"), jen.Id("m").Dot("ID")).
+					Dot("Updates").Call(jen.Map(jen.String()).Interface().Values(values)),
+				g.notFound(),
+				jen.Return(jen.Id("res").Dot("Error")),
+			)...,
+		)
+		g.code.NewLine()
+		g.code.NewLine()
+	}
+	if !found["*"+impl+".GetByID"] {
+		g.code.Raw().Func().Params(stp).Id("GetByID").Params(
+			ctx,
+			jen.Id("id").Qual(uuidImport, "UUID"),
+		).Params(m, jen.Error()).Block(
+			append(
+				getDB,
+				jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Nil(), jen.Err())),
+				jen.Id("v").Op(":=").Op("&").Id(g.modelName).Values(),
+				jen.If(
+					jen.Err().Op(":=").Id("db").Dot("First").Call(jen.Id("v"), jen.Lit("id = ?"), jen.Id("id")).Dot("Error"),
+					jen.Err().Op("!=").Nil(),
+				).Block(jen.Return(jen.Nil(), jen.Err())),
+				jen.Return(jen.Id("v"), jen.Nil()),
+			)...,
+		).Line()
+		g.code.NewLine()
+	}
+	if !found["*"+impl+".List"] {
+		body := append(
+			getDB,
+			jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Nil(), jen.Lit(0), jen.Err())),
+			jen.Id("q").Op(":=").Id("db").Dot("Model").Call(jen.Op("&").Id(g.modelName).Values()),
+		)
+		for _, f := range g.fields {
+			if !f.filter() {
+				continue
+			}
+			body = append(body, jen.If(jen.Id("filter").Dot(f.name).Op("!=").Nil()).Block(
+				jen.Id("q").Op("=").Id("q").Dot("Where").Call(jen.Lit(f.column+" = ?"), jen.Op("*").Id("filter").Dot(f.name)),
+			))
+		}
+		body = append(
+			body,
+			jen.Var().Id("total").Int(),
+			jen.If(
+				jen.Err().Op(":=").Id("q").Dot("Count").Call(jen.Op("&").Id("total")).Dot("Error"),
+				jen.Err().Op("!=").Nil(),
+			).Block(jen.Return(jen.Nil(), jen.Lit(0), jen.Err())),
+			jen.Comment("A limit lower than 1 returns all the records after the offset."),
+			jen.If(jen.Id("limit").Op("<").Lit(1)).Block(jen.Id("limit").Op("=").Lit(-1)),
+			jen.Id("items").Op(":=").Index().Id(g.modelName).Values(),
+			jen.Err().Op("=").Id("q").Dot("Order").Call(jen.Lit("created_at desc")).
+				Dot("Offset").Call(jen.Id("offset")).
+				Dot("Limit").Call(jen.Id("limit")).
+				Dot("Find").Call(jen.Op("&").Id("items")).Dot("Error"),
+			jen.Return(jen.Id("items"), jen.Id("total"), jen.Err()),
+		)
+		g.code.Raw().Func().Params(stp).Id("List").Params(
+			ctx,
+			jen.Id("filter").Id(g.modelName+"Filter"),
+			jen.List(jen.Id("offset"), jen.Id("limit")).Int(),
+		).Params(jen.Index().Id(g.modelName), jen.Int(), jen.Error()).Block(body...).Line()
+		g.code.NewLine()
+	}
+	if !found["*"+impl+".Delete"] {
+		g.code.appendFunction(
+			"Delete",
+			stp,
+			[]jen.Code{ctx, jen.Id("id").Qual(uuidImport, "UUID")},
+			[]jen.Code{},
+			"error",
+			append(
+				getDB,
+				jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Err())),
+				jen.Comment("BaseModel has a DeletedAt field so gorm only sets it."),
+				jen.Id("res").Op(":=").Id("db").Dot("Delete").Call(
+					jen.Op("&").Id(g.modelName).Values(),
+					jen.Lit("id = ?"),
+					jen.Id("id"),
+				),
+				g.notFound(),
+				jen.Return(jen.Id("res").Dot("Error")),
+			)...,
+		)
+		g.code.NewLine()
+		g.code.NewLine()
+	}
+}
+
+// notFound returns the check that no record matched the update or the delete.
+func (g *GenerateModel) notFound() jen.Code {
+	return jen.If(
+		jen.Id("res").Dot("Error").Op("==").Nil().Op("&&").Id("res").Dot("RowsAffected").Op("==").Lit(0),
+	).Block(
+		jen.Return(jen.Qual(gormImport, "ErrRecordNotFound")),
+	)
+}
+
+// registerMigration adds the model to the models AutoMigration migrates.
+func (g *GenerateModel) registerMigration() error {
+	basePath := path.Join(g.destPath, viper.GetString("gk_model_file_name"))
+	if b, err := g.fs.Exists(basePath); err != nil || !b {
+		if err == nil {
+			logrus.Warnf("Add &model.%s{} to AutoMigration, %s was not found", g.modelName, basePath)
+		}
+		return err
+	}
+	src, err := g.fs.ReadFile(basePath)
+	if err != nil {
+		return err
+	}
+	fset := token.NewFileSet()
+	f, err := ps.ParseFile(fset, "", src, ps.ParseComments)
+	if err != nil {
+		return err
+	}
+	var call *ast.CallExpr
+	for _, d := range f.Decls {
+		fd, ok := d.(*ast.FuncDecl)
+		if !ok || fd.Name.Name != "AutoMigration" || fd.Recv != nil || fd.Body == nil {
+			continue
+		}
+		ast.Inspect(fd.Body, func(n ast.Node) bool {
+			if c, ok := n.(*ast.CallExpr); ok {
+				if s, ok := c.Fun.(*ast.SelectorExpr); ok && s.Sel.Name == "AutoMigrate" {
+					call = c
+				}
+			}
+			return call == nil
+		})
+	}
+	if call == nil {
+		logrus.Warnf("Add &%s{} to AutoMigration, the AutoMigrate call was not found", g.modelName)
+		return nil
+	}
+	for _, a := range call.Args {
+		if u, ok := a.(*ast.UnaryExpr); ok {
+			if c, ok := u.X.(*ast.CompositeLit); ok {
+				if id, ok := c.Type.(*ast.Ident); ok && id.Name == g.modelName {
+					return nil
+				}
+			}
+		}
+	}
+	arg := "&" + g.modelName + "{}"
+	if len(call.Args) > 0 {
+		arg = ", " + arg
+	}
+	src, err = insertSource(src, sourceInsert{fset.Position(call.Rparen).Offset, arg})
+	if err != nil {
+		return err
+	}
+	return g.fs.WriteFile(basePath, src, true)
+}
+
+// addToService adds the repository of the model to the service struct and sets
+// it in the constructor of the struct.
+func (g *GenerateModel) addToService() error {
+	interfaceName := utils.ToCamelCase(g.name + "Service")
+	structName := utils.ToLowerFirstCamelCase(viper.GetString("gk_service_struct_prefix") + "-" + interfaceName)
+	constructor := "New" + utils.ToCamelCase(structName)
+	field := utils.ToLowerFirstCamelCase(g.modelName) + "Repository"
+	svcPath := path.Join(
+		fmt.Sprintf(viper.GetString("gk_service_path_format"), utils.ToLowerSnakeCase(g.name)),
+		viper.GetString("gk_service_file_name"),
+	)
+	hint := fmt.Sprintf(
+		"Add the %s field to %s and set it to model.New%sRepository() in %s",
+		field, structName, g.modelName, constructor,
+	)
+	if b, err := g.fs.Exists(svcPath); err != nil || !b {
+		if err == nil {
+			logrus.Warn(hint)
+		}
+		return err
+	}
+	src, err := g.fs.ReadFile(svcPath)
+	if err != nil {
+		return err
+	}
+	fset := token.NewFileSet()
+	f, err := ps.ParseFile(fset, "", src, ps.ParseComments)
+	if err != nil {
+		return err
+	}
+	var st *ast.StructType
+	var lit *ast.CompositeLit
+	for _, d := range f.Decls {
+		switch d := d.(type) {
+		case *ast.GenDecl:
+			for _, s := range d.Specs {
+				if ts, ok := s.(*ast.TypeSpec); ok && ts.Name.Name == structName {
+					st, _ = ts.Type.(*ast.StructType)
+				}
+			}
+		case *ast.FuncDecl:
+			if d.Name.Name != constructor || d.Recv != nil || d.Body == nil {
+				continue
+			}
+			ast.Inspect(d.Body, func(n ast.Node) bool {
+				if c, ok := n.(*ast.CompositeLit); ok {
+					if id, ok := c.Type.(*ast.Ident); ok && id.Name == structName {
+						lit = c
+					}
+				}
+				return lit == nil
+			})
+		}
+	}
+	if st == nil || lit == nil {
+		logrus.Warn(hint)
+		return nil
+	}
+	for _, v := range st.Fields.List {
+		for _, n := range v.Names {
+			if n.Name == field {
+				return nil
+			}
+		}
+	}
+	closing := fset.Position(st.Fields.Closing).Offset
+	fieldText := fmt.Sprintf("%s model.%sRepository\n", field, g.modelName)
+	if src[closing-1] != '\n' {
+		fieldText = "\n" + fieldText
+	}
+	value := fmt.Sprintf("%s: model.New%sRepository()", field, g.modelName)
+	if len(lit.Elts) > 0 {
+		value = ", " + value
+	}
+	src, err = insertSource(
+		src,
+		sourceInsert{closing, fieldText},
+		sourceInsert{fset.Position(lit.Rbrace).Offset, value},
+	)
+	if err != nil {
+		return err
+	}
+	modelImport, err := utils.GetModelImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	src, err = addSourceImport(src, "model", modelImport)
+	if err != nil {
+		return err
+	}
+	return g.fs.WriteFile(svcPath, src, true)
+}
+
+// sourceInsert is a text inserted in a source at the offset.
+type sourceInsert struct {
+	offset int
+	text   string
+}
+
+// insertSource inserts the texts in the source and formats it, it is used to edit
+// the code of the files the user owns without rewriting the rest of it.
+func insertSource(src string, inserts ...sourceInsert) (string, error) {
+	sort.Slice(inserts, func(i, j int) bool {
+		return inserts[i].offset > inserts[j].offset
+	})
+	for _, v := range inserts {
+		if v.offset < 0 || v.offset > len(src) {
+			return "", errors.New("the insert is out of the source")
+		}
+		src = src[:v.offset] + v.text + src[v.offset:]
+	}
+	b, err := format.Source([]byte(src))
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// addSourceImport adds the import to the source if it does not import the path,
+// unlike AddImportsToFile it keeps the comments of the source.
+func addSourceImport(src, name, importPath string) (string, error) {
+	fset := token.NewFileSet()
+	f, err := ps.ParseFile(fset, "", src, ps.ParseComments)
+	if err != nil {
+		return "", err
+	}
+	for _, v := range f.Imports {
+		if strings.Trim(v.Path.Value, `"`) == importPath {
+			return src, nil
+		}
+	}
+	astutil.AddNamedImport(fset, f, name, importPath)
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, f); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
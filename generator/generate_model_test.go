@@ -0,0 +1,134 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_parseModelFields(t *testing.T) {
+	fields, err := parseModelFields("Title:string, done:bool,DueAt:*time.Time")
+	if err != nil {
+		t.Fatalf("parseModelFields() error = %v", err)
+	}
+	if len(fields) != 3 || fields[1].name != "Done" || fields[2].column != "due_at" || fields[2].tp != "*time.Time" {
+		t.Errorf("parseModelFields() = %v", fields)
+	}
+	for _, v := range []string{"Title", "Title:complex64", "ID:string", "Title:string,Title:bool"} {
+		if _, err := parseModelFields(v); err == nil {
+			t.Errorf("parseModelFields(%q) should return an error", v)
+		}
+	}
+}
+
+func TestGenerateModel_Generate(t *testing.T) {
+	f := newClientTestFs()
+	f.MkdirAll("test/pkg/model")
+	f.WriteFile("test/pkg/model/base.go", `package model
+
+// AutoMigration migrates the models.
+func AutoMigration() (err error) {
+	dbPublic, err := postgres.GetDatabase("default")
+	if err != nil {
+		return
+	}
+	t := dbPublic.AutoMigrate()
+	return t.Error
+}
+`, true)
+	f.WriteFile("test/pkg/service/service.go", `package service
+
+import (
+	"context"
+
+	model "test/pkg/model"
+)
+
+type TestService interface {
+	Foo(ctx context.Context, a string) (b int, err error)
+}
+
+// basicTestService keeps the dependencies of the service.
+type basicTestService struct {
+	pgDB model.PostgresDatabase
+}
+
+func NewBasicTestService(db model.PostgresDatabase) TestService {
+	return &basicTestService{pgDB: db}
+}
+`, true)
+	for i := 0; i < 2; i++ {
+		if err := NewGenerateModel("test", "todo", "Title:string,Done:bool").Generate(); err != nil {
+			t.Fatalf("GenerateModel.Generate() error = %v", err)
+		}
+	}
+	src, err := f.ReadFile("test/pkg/model/todo.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		"type Todo struct {\n\tBaseModel\n",
+		"Done  *bool",
+		"List(ctx context.Context, filter TodoFilter, offset, limit int) ([]Todo, int, error)",
+		"return &gormTodoRepository{alias: postgres.DefaultConnName}",
+		`q = q.Where("done = ?", *filter.Done)`,
+		`res := db.Delete(&Todo{}, "id = ?", id)`,
+		"return gorm.ErrRecordNotFound",
+		// The repository runs the queries in the transaction of the context.
+		"func (r *gormTodoRepository) db(ctx context.Context) (*gorm.DB, error)",
+		"if tx := TxFromContext(ctx); tx != nil {",
+		"db, err := r.db(ctx)",
+	} {
+		if !strings.Contains(src, v) {
+			t.Errorf("GenerateModel.Generate() todo.go does not contain %q", v)
+		}
+	}
+	if strings.Contains(src, "GetDatabase(r.alias)\n\tif err != nil") {
+		t.Errorf("GenerateModel.Generate() the methods should get the database with the context, got %s", src)
+	}
+	if strings.Count(src, "func (r *gormTodoRepository) Create(") != 1 || strings.Count(src, "func (r *gormTodoRepository) db(") != 1 {
+		t.Errorf("GenerateModel.Generate() should not generate the existing methods again, got %s", src)
+	}
+	src, err = f.ReadFile("test/pkg/model/tx.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		"func ContextWithTx(ctx context.Context, tx *gorm.DB) context.Context",
+		"tx, _ := ctx.Value(txKey{}).(*gorm.DB)",
+	} {
+		if !strings.Contains(src, v) {
+			t.Errorf("GenerateModel.Generate() tx.go does not contain %q", v)
+		}
+	}
+	src, err = f.ReadFile("test/pkg/model/base.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(src, "t := dbPublic.AutoMigrate(&Todo{})") {
+		t.Errorf("GenerateModel.Generate() should register the model in AutoMigration, got %s", src)
+	}
+	src, err = f.ReadFile("test/pkg/service/service.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		"todoRepository model.TodoRepository",
+		"return &basicTestService{pgDB: db, todoRepository: model.NewTodoRepository()}",
+		"// basicTestService keeps the dependencies of the service.",
+	} {
+		if !strings.Contains(src, v) {
+			t.Errorf("GenerateModel.Generate() service.go does not contain %q, got %s", v, src)
+		}
+	}
+	if strings.Count(src, "todoRepository model.TodoRepository") != 1 {
+		t.Errorf("GenerateModel.Generate() should add the repository once, got %s", src)
+	}
+}
+
+func TestGenerateModel_GenerateWithoutDatabase(t *testing.T) {
+	f := newClientTestFs()
+	f.Fs.RemoveAll("test/pkg/db")
+	if err := NewGenerateModel("test", "Todo", "Title:string").Generate(); err == nil {
+		t.Error("GenerateModel.Generate() should return an error if the service does not have a database")
+	}
+}
@@ -0,0 +1,326 @@
+package generator
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/kujtimiihoxha/kit/fs"
+	"github.com/kujtimiihoxha/kit/parser"
+	"github.com/kujtimiihoxha/kit/utils"
+	"github.com/spf13/viper"
+)
+
+// generatePropagation generates the propagation package of the service, it
+// copies an allow-list of headers between the HTTP headers, the gRPC metadata
+// and the context so they follow the request from service to service.
+type generatePropagation struct {
+	BaseGenerator
+	name              string
+	destPath          string
+	filePath          string
+	file              *parser.File
+	generateFirstTime bool
+}
+
+func newGeneratePropagation(name string) Gen {
+	i := &generatePropagation{
+		name:     name,
+		destPath: fmt.Sprintf(viper.GetString("gk_propagation_path_format"), utils.ToLowerSnakeCase(name)),
+	}
+	i.filePath = path.Join(i.destPath, viper.GetString("gk_propagation_file_name"))
+	i.srcFile = jen.NewFilePath(i.destPath)
+	i.InitPg()
+	i.fs = fs.Get()
+	return i
+}
+
+// Generate generates the propagation functions that are missing.
+func (g *generatePropagation) Generate() (err error) {
+	err = g.CreateFolderStructure(g.destPath)
+	if err != nil {
+		return err
+	}
+	if b, err := g.fs.Exists(g.filePath); err != nil {
+		return err
+	} else if !b {
+		g.generateFirstTime = true
+		f := jen.NewFile("propagation")
+		g.fs.WriteFile(g.filePath, f.GoString(), false)
+	}
+	src, err := g.fs.ReadFile(g.filePath)
+	if err != nil {
+		return err
+	}
+	g.file, err = parser.NewFileParser().Parse([]byte(src))
+	if err != nil {
+		return err
+	}
+	found := map[string]bool{}
+	for _, v := range g.file.Methods {
+		found[v.Name] = true
+	}
+	for _, v := range g.file.Vars {
+		found[v.Name] = true
+	}
+	for _, v := range g.file.Constants {
+		found[v.Name] = true
+	}
+	if !found["Headers"] {
+		headers := []jen.Code{}
+		for _, v := range viper.GetStringSlice("gk_propagation_headers") {
+			headers = append(headers, jen.Lit(v))
+		}
+		g.code.appendMultilineComment([]string{
+			"Headers are the headers copied between the HTTP headers, the gRPC metadata",
+			"and the context, add the headers your services need to forward.",
+		})
+		g.code.NewLine()
+		g.code.Raw().Var().Id("Headers").Op("=").Index().String().Values(headers...).Line()
+		g.code.NewLine()
+	}
+	if !found["RequestIDHeader"] {
+		g.code.appendMultilineComment([]string{
+			"RequestIDHeader is the header of the request ID, a new request ID is minted",
+			"when a request comes in without one.",
+		})
+		g.code.NewLine()
+		g.code.Raw().Const().Id("RequestIDHeader").Op("=").Lit("X-Request-ID").Line()
+		g.code.NewLine()
+		g.code.Raw().Type().Id("contextKey").String().Line()
+		g.code.NewLine()
+	}
+	if !found["FromContext"] {
+		g.code.appendMultilineComment([]string{
+			"FromContext returns the value of the header stored in the context.",
+		})
+		g.code.NewLine()
+		g.code.appendFunction(
+			"FromContext",
+			nil,
+			[]jen.Code{
+				jen.Id("ctx").Qual("context", "Context"),
+				jen.Id("header").String(),
+			},
+			[]jen.Code{},
+			"string",
+			jen.List(jen.Id("v"), jen.Id("_")).Op(":=").Id("ctx").Dot("Value").Call(
+				jen.Id("contextKey").Call(jen.Qual("net/http", "CanonicalHeaderKey").Call(jen.Id("header"))),
+			).Assert(jen.String()),
+			jen.Return(jen.Id("v")),
+		)
+		g.code.NewLine()
+	}
+	if !found["NewContext"] {
+		g.code.appendMultilineComment([]string{
+			"NewContext returns a copy of the context that stores the value of the header.",
+		})
+		g.code.NewLine()
+		g.code.appendFunction(
+			"NewContext",
+			nil,
+			[]jen.Code{
+				jen.Id("ctx").Qual("context", "Context"),
+				jen.Id("header"),
+				jen.Id("value").String(),
+			},
+			[]jen.Code{},
+			"context.Context",
+			jen.Return(jen.Qual("context", "WithValue").Call(
+				jen.Id("ctx"),
+				jen.Id("contextKey").Call(jen.Qual("net/http", "CanonicalHeaderKey").Call(jen.Id("header"))),
+				jen.Id("value"),
+			)),
+		)
+		g.code.NewLine()
+	}
+	if !found["RequestID"] {
+		g.code.appendMultilineComment([]string{
+			"RequestID returns the request ID stored in the context.",
+		})
+		g.code.NewLine()
+		g.code.appendFunction(
+			"RequestID",
+			nil,
+			[]jen.Code{
+				jen.Id("ctx").Qual("context", "Context"),
+			},
+			[]jen.Code{},
+			"string",
+			jen.Return(jen.Id("FromContext").Call(jen.Id("ctx"), jen.Id("RequestIDHeader"))),
+		)
+		g.code.NewLine()
+	}
+	if !found["HTTPToContext"] {
+		g.code.appendMultilineComment([]string{
+			"HTTPToContext copies the headers of the request to the context and mints a",
+			"request ID if the request has none, use it as a http.ServerBefore.",
+		})
+		g.code.NewLine()
+		g.code.appendFunction(
+			"HTTPToContext",
+			nil,
+			[]jen.Code{
+				jen.Id("ctx").Qual("context", "Context"),
+				jen.Id("r").Id("*").Qual("net/http", "Request"),
+			},
+			[]jen.Code{},
+			"context.Context",
+			jen.For(jen.List(jen.Id("_"), jen.Id("h")).Op(":=").Range().Id("Headers")).Block(
+				jen.If(
+					jen.Id("v").Op(":=").Id("r").Dot("Header").Dot("Get").Call(jen.Id("h")),
+					jen.Id("v").Op("!=").Lit(""),
+				).Block(
+					jen.Id("ctx").Op("=").Id("NewContext").Call(jen.Id("ctx"), jen.Id("h"), jen.Id("v")),
+				),
+			),
+			jen.Return(jen.Id("withRequestID").Call(jen.Id("ctx"))),
+		)
+		g.code.NewLine()
+	}
+	if !found["ContextToHTTP"] {
+		g.code.appendMultilineComment([]string{
+			"ContextToHTTP copies the headers stored in the context to the outgoing",
+			"request, use it as a http.ClientBefore.",
+		})
+		g.code.NewLine()
+		g.code.appendFunction(
+			"ContextToHTTP",
+			nil,
+			[]jen.Code{
+				jen.Id("ctx").Qual("context", "Context"),
+				jen.Id("r").Id("*").Qual("net/http", "Request"),
+			},
+			[]jen.Code{},
+			"context.Context",
+			jen.Id("ctx").Op("=").Id("withRequestID").Call(jen.Id("ctx")),
+			jen.For(jen.List(jen.Id("_"), jen.Id("h")).Op(":=").Range().Id("Headers")).Block(
+				jen.If(
+					jen.Id("v").Op(":=").Id("FromContext").Call(jen.Id("ctx"), jen.Id("h")),
+					jen.Id("v").Op("!=").Lit(""),
+				).Block(
+					jen.Id("r").Dot("Header").Dot("Set").Call(jen.Id("h"), jen.Id("v")),
+				),
+			),
+			jen.Return(jen.Id("ctx")),
+		)
+		g.code.NewLine()
+	}
+	if !found["GRPCToContext"] {
+		g.code.appendMultilineComment([]string{
+			"GRPCToContext copies the headers of the incoming metadata to the context and",
+			"mints a request ID if the metadata has none, use it as a grpc.ServerBefore.",
+		})
+		g.code.NewLine()
+		g.code.appendFunction(
+			"GRPCToContext",
+			nil,
+			[]jen.Code{
+				jen.Id("ctx").Qual("context", "Context"),
+				jen.Id("md").Qual("google.golang.org/grpc/metadata", "MD"),
+			},
+			[]jen.Code{},
+			"context.Context",
+			jen.For(jen.List(jen.Id("_"), jen.Id("h")).Op(":=").Range().Id("Headers")).Block(
+				jen.If(
+					jen.Id("v").Op(":=").Id("md").Dot("Get").Call(jen.Id("h")),
+					jen.Len(jen.Id("v")).Op(">").Lit(0),
+				).Block(
+					jen.Id("ctx").Op("=").Id("NewContext").Call(jen.Id("ctx"), jen.Id("h"), jen.Id("v").Index(jen.Lit(0))),
+				),
+			),
+			jen.Return(jen.Id("withRequestID").Call(jen.Id("ctx"))),
+		)
+		g.code.NewLine()
+	}
+	if !found["ContextToGRPC"] {
+		g.code.appendMultilineComment([]string{
+			"ContextToGRPC copies the headers stored in the context to the outgoing",
+			"metadata, use it as a grpc.ClientBefore.",
+		})
+		g.code.NewLine()
+		g.code.appendFunction(
+			"ContextToGRPC",
+			nil,
+			[]jen.Code{
+				jen.Id("ctx").Qual("context", "Context"),
+				jen.Id("md").Id("*").Qual("google.golang.org/grpc/metadata", "MD"),
+			},
+			[]jen.Code{},
+			"context.Context",
+			jen.Id("ctx").Op("=").Id("withRequestID").Call(jen.Id("ctx")),
+			jen.For(jen.List(jen.Id("_"), jen.Id("h")).Op(":=").Range().Id("Headers")).Block(
+				jen.If(
+					jen.Id("v").Op(":=").Id("FromContext").Call(jen.Id("ctx"), jen.Id("h")),
+					jen.Id("v").Op("!=").Lit(""),
+				).Block(
+					jen.Id("md").Dot("Set").Call(jen.Id("h"), jen.Id("v")),
+				),
+			),
+			jen.Return(jen.Id("ctx")),
+		)
+		g.code.NewLine()
+	}
+	if !found["withRequestID"] {
+		g.code.appendMultilineComment([]string{
+			"withRequestID mints a request ID if the context does not have one.",
+		})
+		g.code.NewLine()
+		g.code.appendFunction(
+			"withRequestID",
+			nil,
+			[]jen.Code{
+				jen.Id("ctx").Qual("context", "Context"),
+			},
+			[]jen.Code{},
+			"context.Context",
+			jen.If(jen.Id("RequestID").Call(jen.Id("ctx")).Op("!=").Lit("")).Block(
+				jen.Return(jen.Id("ctx")),
+			),
+			jen.Id("b").Op(":=").Make(jen.Index().Byte(), jen.Lit(16)),
+			jen.If(
+				jen.List(jen.Id("_"), jen.Err()).Op(":=").Qual("crypto/rand", "Read").Call(jen.Id("b")),
+				jen.Err().Op("!=").Nil(),
+			).Block(
+				jen.Return(jen.Id("NewContext").Call(
+					jen.Id("ctx"),
+					jen.Id("RequestIDHeader"),
+					jen.Qual("strconv", "FormatInt").Call(
+						jen.Qual("time", "Now").Call().Dot("UnixNano").Call(),
+						jen.Lit(36),
+					),
+				)),
+			),
+			jen.Return(jen.Id("NewContext").Call(
+				jen.Id("ctx"),
+				jen.Id("RequestIDHeader"),
+				jen.Qual("encoding/hex", "EncodeToString").Call(jen.Id("b")),
+			)),
+		)
+		g.code.NewLine()
+	}
+	if g.generateFirstTime {
+		return g.fs.WriteFile(g.filePath, g.srcFile.GoString(), true)
+	}
+	src += "\n" + g.code.Raw().GoString()
+	tmpSrc := g.srcFile.GoString()
+	f, err := parser.NewFileParser().Parse([]byte(tmpSrc))
+	if err != nil {
+		return err
+	}
+	imp, err := g.getMissingImports(f.Imports, g.file)
+	if err != nil {
+		return err
+	}
+	if len(imp) > 0 {
+		src, err = g.AddImportsToFile(imp, src)
+		if err != nil {
+			return err
+		}
+	}
+	s, err := utils.GoImportsSource(g.destPath, src)
+	if err != nil {
+		return err
+	}
+	return g.fs.WriteFile(g.filePath, s, true)
+}
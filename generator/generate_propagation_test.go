@@ -0,0 +1,39 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGeneratePropagation_Generate(t *testing.T) {
+	f := newClientTestFs()
+	f.MkdirAll("test/pkg/propagation")
+	f.WriteFile("test/pkg/propagation/propagation.go", `package propagation
+
+import "net/http"
+
+var Headers = []string{http.CanonicalHeaderKey("x-tenant-id")}
+`, true)
+	if err := newGeneratePropagation("test").Generate(); err != nil {
+		t.Fatalf("generatePropagation.Generate() error = %v", err)
+	}
+	src, err := f.ReadFile("test/pkg/propagation/propagation.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		`var Headers = []string{http.CanonicalHeaderKey("x-tenant-id")}`,
+		"func HTTPToContext(ctx context.Context, r *http.Request) context.Context",
+		"func ContextToHTTP(ctx context.Context, r *http.Request) context.Context",
+		"func GRPCToContext(ctx context.Context, md metadata.MD) context.Context",
+		"func ContextToGRPC(ctx context.Context, md *metadata.MD) context.Context",
+		"func withRequestID(ctx context.Context) context.Context",
+	} {
+		if !strings.Contains(src, v) {
+			t.Errorf("generatePropagation.Generate() does not contain %q", v)
+		}
+	}
+	if strings.Count(src, "var Headers") != 1 {
+		t.Error("generatePropagation.Generate() should keep the existing allow-list")
+	}
+}
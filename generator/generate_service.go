@@ -3,6 +3,8 @@ package generator
 import (
 	"fmt"
 	"path"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/dave/jennifer/jen"
@@ -32,6 +34,8 @@ type GenerateService struct {
 	file                                 *parser.File
 	serviceInterface                     parser.Interface
 	sMiddleware, gorillaMux, eMiddleware bool
+	// db is the database of the service, nil if it does not have one.
+	db *dbDriver
 }
 
 // NewGenerateService returns a initialized and ready generator.
@@ -90,6 +94,10 @@ func (g *GenerateService) Generate() (err error) {
 		logrus.Error("The service has no suitable methods please implement the interface methods")
 		return
 	}
+	g.db, err = dbExists(g.fs, g.name)
+	if err != nil {
+		return err
+	}
 	g.generateServiceStruct()
 	g.generateServiceMethods()
 	g.generateNewBasicStructMethod()
@@ -103,6 +111,14 @@ func (g *GenerateService) Generate() (err error) {
 	if err != nil {
 		return err
 	}
+	err = newGenerateServiceErrors(g.name).Generate()
+	if err != nil {
+		return err
+	}
+	err = newGenerateLogging(g.name).Generate()
+	if err != nil {
+		return err
+	}
 	mdwG := newGenerateServiceMiddleware(g.name, g.file, g.serviceInterface, g.sMiddleware)
 	err = mdwG.Generate()
 	if err != nil {
@@ -118,11 +134,34 @@ func (g *GenerateService) Generate() (err error) {
 	if err != nil {
 		return err
 	}
+	err = newGenerateServiceValidation(g.name, g.serviceInterface, g.file).Generate()
+	if err != nil {
+		return err
+	}
+	if b, err := authExists(g.fs, g.name); err != nil {
+		return err
+	} else if b {
+		err = newGenerateAuthBase(g.name, g.serviceInterface).Generate()
+		if err != nil {
+			return err
+		}
+	}
 	tp := NewGenerateTransport(g.name, g.gorillaMux, g.transport, g.pbPath, g.pbImportPath, g.methods)
 	err = tp.Generate()
 	if err != nil {
 		return err
 	}
+	grpcHandler, err := g.fs.Exists(path.Join(
+		fmt.Sprintf(viper.GetString("gk_grpc_path_format"), utils.ToLowerSnakeCase(g.name)),
+		viper.GetString("gk_grpc_file_name"),
+	))
+	if err != nil {
+		return err
+	}
+	err = newGenerateHealth(g.name, grpcHandler).Generate()
+	if err != nil {
+		return err
+	}
 	mbG := newGenerateCmdBase(g.name, g.serviceInterface, g.sMiddleware, g.eMiddleware, g.methods)
 	err = mbG.Generate()
 	if err != nil {
@@ -184,12 +223,16 @@ func (g *GenerateService) generateServiceStruct() {
 			return
 		}
 	}
+	if g.db == nil {
+		g.pg.appendStruct(g.serviceStructName)
+		return
+	}
 	modelImport, err := utils.GetModelImportPath(g.name)
 	if err != nil {
 		logrus.Debugf("Service `%s` structure cannot get import path.", err)
 		return
 	}
-	g.pg.appendStruct(g.serviceStructName, jen.Id("pgDB").Qual(modelImport,"PostgresDatabase"))
+	g.pg.appendStruct(g.serviceStructName, jen.Id(g.db.field).Qual(modelImport, g.db.modelDatabase()))
 }
 func (g *GenerateService) generateNewMethod() {
 	modelImport, err := utils.GetModelImportPath(g.name)
@@ -207,8 +250,14 @@ func (g *GenerateService) generateNewMethod() {
 		g.interfaceName,
 	).Line()
 	fn := fmt.Sprintf("New%s", utils.ToCamelCase(g.serviceStructName))
+	params := []jen.Code{jen.Id("middleware").Id("[]Middleware")}
+	args := []jen.Code{}
+	if g.db != nil {
+		params = append(params, jen.Id("db").Qual(modelImport, g.db.modelDatabase()))
+		args = append(args, jen.Id("db"))
+	}
 	body := []jen.Code{
-		jen.Var().Id("svc").Id(g.interfaceName).Op("=").Id(fn).Call(jen.Id("db")),
+		jen.Var().Id("svc").Id(g.interfaceName).Op("=").Id(fn).Call(args...),
 		jen.For(
 			jen.List(jen.Id("_"), jen.Id("m")).Op(":=").Range().Id("middleware"),
 		).Block(
@@ -219,10 +268,7 @@ func (g *GenerateService) generateNewMethod() {
 	g.pg.appendFunction(
 		"New",
 		nil,
-		[]jen.Code{
-			jen.Id("middleware").Id("[]Middleware"),
-			jen.Id("db").Qual(modelImport,"PostgresDatabase"),
-		},
+		params,
 		[]jen.Code{},
 		g.interfaceName,
 		body...)
@@ -245,11 +291,16 @@ func (g *GenerateService) generateNewBasicStructMethod() {
 		utils.ToCamelCase(g.serviceStructName),
 		g.interfaceName,
 	).Line()
+	if g.db == nil {
+		g.pg.appendFunction(fn, nil, []jen.Code{}, []jen.Code{}, g.interfaceName, jen.Return(jen.Id("&"+g.serviceStructName+"{}")))
+		g.pg.NewLine()
+		return
+	}
 	body := []jen.Code{
-		jen.Return(jen.Id(fmt.Sprintf("&%s{pgDB: db}", g.serviceStructName))),
+		jen.Return(jen.Id(fmt.Sprintf("&%s{%s: db}", g.serviceStructName, g.db.field))),
 	}
 
-	g.pg.appendFunction(fn, nil, []jen.Code{jen.Id("db").Qual(modelImport,"PostgresDatabase")}, []jen.Code{}, g.interfaceName, body...)
+	g.pg.appendFunction(fn, nil, []jen.Code{jen.Id("db").Qual(modelImport, g.db.modelDatabase())}, []jen.Code{}, g.interfaceName, body...)
 	g.pg.NewLine()
 }
 func (g *GenerateService) serviceFound() bool {
@@ -300,6 +351,8 @@ type generateServiceMiddleware struct {
 	file              *parser.File
 	serviceInterface  parser.Interface
 	generateDefaults  bool
+	propagationImport string
+	loggingImport     string
 }
 
 func newGenerateServiceMiddleware(name string, serviceFile *parser.File,
@@ -402,7 +455,16 @@ func (g *generateServiceMiddleware) Generate() error {
 			g.code.NewLine()
 			g.code.NewLine()
 		}
+		g.propagationImport, err = utils.GetPropagationImportPath(g.name)
+		if err != nil {
+			return err
+		}
+		g.loggingImport, err = utils.GetLoggingImportPath(g.name)
+		if err != nil {
+			return err
+		}
 		g.generateMethodMiddleware("loggingMiddleware", true)
+		g.generateInstrumentingMiddleware()
 	}
 	if g.generateFirstTime {
 		return g.fs.WriteFile(g.filePath, g.srcFile.GoString(), true)
@@ -433,6 +495,171 @@ func (g *generateServiceMiddleware) Generate() error {
 }
 
 func (g *generateServiceMiddleware) generateMethodMiddleware(mdw string, df bool) {
+	g.generateMethodMiddlewareBody(mdw, func(m parser.Method, stp string, loggerLog []jen.Code) *jen.Statement {
+		if df {
+			return g.generateRequestLogger(m, stp, loggerLog)
+		}
+		return jen.Comment("Implement your middleware logic here").Line().Line()
+	})
+}
+
+// generateRequestLogger generates the logging of a method, the logger of the request
+// with the method and the request ID is stored in the context for the next services.
+func (g *generateServiceMiddleware) generateRequestLogger(m parser.Method, stp string, loggerLog []jen.Code) *jen.Statement {
+	ctx := ""
+	logger := "logger"
+	for _, p := range append(m.Parameters, m.Results...) {
+		if p.Type == "context.Context" && ctx == "" {
+			ctx = p.Name
+		}
+		if p.Name == logger {
+			logger = "requestLogger"
+		}
+	}
+	if ctx == "" || g.loggingImport == "" {
+		return jen.Defer().Func().Call().Block(g.resultLogger(m, jen.Id(stp).Dot("logger")).Dot("Log").Call(
+			loggerLog...,
+		)).Call()
+	}
+	return jen.Id(logger).Op(":=").Qual("github.com/go-kit/kit/log", "With").Call(
+		jen.Id(stp).Dot("logger"),
+		jen.Lit("method"),
+		jen.Lit(m.Name),
+		jen.Lit("request_id"),
+		jen.Qual(g.propagationImport, "RequestID").Call(jen.Id(ctx)),
+	).Line().Id(ctx).Op("=").Qual(g.loggingImport, "NewContext").Call(
+		jen.Id(ctx),
+		jen.Id(logger),
+	).Line().Defer().Func().Call().Block(g.resultLogger(m, jen.Id(logger)).Dot("Log").Call(
+		loggerLog[2:]...,
+	)).Call()
+}
+
+// resultLogger returns the logger at the level of the result of the method, the
+// methods without an error log at the info level.
+func (g *generateServiceMiddleware) resultLogger(m parser.Method, logger jen.Code) *jen.Statement {
+	for _, p := range m.Results {
+		if p.Type == "error" && g.loggingImport != "" {
+			return jen.Qual(g.loggingImport, "Result").Call(logger, jen.Id(p.Name))
+		}
+	}
+	return logLevel("Info", logger)
+}
+
+// generateInstrumentingMiddleware generates the instrumenting middleware that
+// counts the requests and the errors and observes the latency of every method,
+// labeled by the method and the kind of the error.
+func (g *generateServiceMiddleware) generateInstrumentingMiddleware() {
+	found := map[string]bool{}
+	for _, v := range g.file.Structures {
+		found[v.Name] = true
+	}
+	for _, v := range g.file.Methods {
+		if v.Struct.Type == "" {
+			found[v.Name] = true
+		}
+	}
+	if !found["instrumentingMiddleware"] {
+		g.code.appendStruct(
+			"instrumentingMiddleware",
+			jen.Id("requests").Qual("github.com/go-kit/kit/metrics", "Counter"),
+			jen.Id("errors").Qual("github.com/go-kit/kit/metrics", "Counter"),
+			jen.Id("duration").Qual("github.com/go-kit/kit/metrics", "Histogram"),
+			jen.Id("next").Id(g.interfaceName),
+		)
+	}
+	if !found["InstrumentingMiddleware"] {
+		g.code.appendMultilineComment([]string{
+			"InstrumentingMiddleware takes the request and error counters and the latency",
+			fmt.Sprintf("histogram as dependencies and returns a %s Middleware, the", g.interfaceName),
+			"metrics are labeled by `method` and `error_kind`.",
+		})
+		g.code.NewLine()
+		pt := NewPartialGenerator(nil)
+		pt.appendFunction(
+			"",
+			nil,
+			[]jen.Code{
+				jen.Id("next").Id(g.interfaceName),
+			},
+			[]jen.Code{},
+			g.interfaceName,
+			jen.Return(jen.Id("&instrumentingMiddleware").Values(
+				jen.Id("requests"), jen.Id("errors"), jen.Id("duration"), jen.Id("next"),
+			)),
+		)
+		pt.NewLine()
+		g.code.appendFunction(
+			"InstrumentingMiddleware",
+			nil,
+			[]jen.Code{
+				jen.List(jen.Id("requests"), jen.Id("errors")).Qual("github.com/go-kit/kit/metrics", "Counter"),
+				jen.Id("duration").Qual("github.com/go-kit/kit/metrics", "Histogram"),
+			},
+			[]jen.Code{},
+			"Middleware",
+			jen.Return(pt.Raw()),
+		)
+		g.code.NewLine()
+		g.code.NewLine()
+	}
+	if !found["errorKind"] {
+		g.code.appendMultilineComment([]string{
+			"errorKind returns the label of the kind of the error, `none` if err is nil.",
+		})
+		g.code.NewLine()
+		g.code.appendFunction(
+			"errorKind",
+			nil,
+			[]jen.Code{
+				jen.Err().Error(),
+			},
+			[]jen.Code{},
+			"string",
+			jen.If(jen.Err().Op("==").Nil()).Block(
+				jen.Return(jen.Lit("none")),
+			),
+			jen.Return(jen.String().Call(jen.Id("KindOf").Call(jen.Err()))),
+		)
+		g.code.NewLine()
+	}
+	g.generateMethodMiddlewareBody("instrumentingMiddleware", func(m parser.Method, stp string, _ []jen.Code) *jen.Statement {
+		kind := jen.Lit("none")
+		body := []jen.Code{}
+		for _, p := range m.Results {
+			if p.Type == "error" {
+				kind = jen.Id("errorKind").Call(jen.Id(p.Name))
+				body = append(body, jen.If(jen.Id(p.Name).Op("!=").Nil()).Block(
+					jen.Id(stp).Dot("errors").Dot("With").Call(jen.Id("lvs").Op("...")).Dot("Add").Call(jen.Lit(1)),
+				))
+			}
+		}
+		body = append(
+			[]jen.Code{
+				jen.Id("lvs").Op(":=").Index().String().Values(
+					jen.Lit("method"), jen.Lit(m.Name), jen.Lit("error_kind"), kind,
+				),
+				jen.Id(stp).Dot("requests").Dot("With").Call(jen.Id("lvs").Op("...")).Dot("Add").Call(jen.Lit(1)),
+			},
+			body...,
+		)
+		body = append(
+			body,
+			jen.Id(stp).Dot("duration").Dot("With").Call(jen.Id("lvs").Op("...")).Dot("Observe").Call(
+				jen.Qual("time", "Since").Call(jen.Id("begin")).Dot("Seconds").Call(),
+			),
+		)
+		return jen.Defer().Func().Params(jen.Id("begin").Qual("time", "Time")).Block(body...).Call(
+			jen.Qual("time", "Now").Call(),
+		)
+	})
+}
+
+// generateMethodMiddlewareBody generates the methods of the `mdw` middleware that
+// are missing, body returns the statements that run before the next service is
+// called, loggerLog are the key values of the method parameters and results.
+func (g *generateServiceMiddleware) generateMethodMiddlewareBody(mdw string,
+	body func(m parser.Method, stp string, loggerLog []jen.Code) *jen.Statement) {
 	var stp string
 	methodParameterNames := []parser.NamedTypeValue{}
 	for _, v := range g.serviceInterface.Methods {
@@ -481,14 +708,7 @@ func (g *generateServiceMiddleware) generateMethodMiddleware(mdw string, df bool
 				loggerLog = append(loggerLog, jen.Lit(p.Name), jen.Id(p.Name))
 			}
 			loggerLog = append([]jen.Code{jen.Lit("method"), jen.Lit(m.Name)}, loggerLog...)
-			var deferBlock *jen.Statement
-			if df {
-				deferBlock = jen.Defer().Func().Call().Block(jen.Id(stp).Dot("logger").Dot("Log").Call(
-					loggerLog...,
-				)).Call()
-			} else {
-				deferBlock = jen.Comment("Implement your middleware logic here").Line().Line()
-			}
+			deferBlock := body(m, stp, loggerLog)
 			g.code.appendFunction(
 				m.Name,
 				jen.Id(stp).Id(mdw),
@@ -503,6 +723,70 @@ func (g *generateServiceMiddleware) generateMethodMiddleware(mdw string, df bool
 	}
 }
 
+// metricsNamespace returns the prometheus namespace of the service metrics.
+func metricsNamespace(name string) string {
+	return strings.Replace(utils.ToLowerSnakeCase(name), "-", "_", -1)
+}
+
+// metricsBuckets returns the buckets of the latency histograms, `gk_metrics_buckets`
+// is a list of numbers or comma separated numbers (e.x GK_METRICS_BUCKETS=0.1,0.5,1).
+func metricsBuckets() []float64 {
+	if v, ok := viper.Get("gk_metrics_buckets").([]float64); ok {
+		return v
+	}
+	buckets := []float64{}
+	for _, v := range viper.GetStringSlice("gk_metrics_buckets") {
+		for _, b := range strings.Split(v, ",") {
+			if b = strings.TrimSpace(b); b == "" {
+				continue
+			}
+			f, err := strconv.ParseFloat(b, 64)
+			if err != nil {
+				logrus.Warnf("Ignoring the metrics bucket `%s`: %v", b, err)
+				continue
+			}
+			buckets = append(buckets, f)
+		}
+	}
+	sort.Float64s(buckets)
+	return buckets
+}
+
+// serviceQualifiedType returns the type as it is seen from outside of the service package.
+//
+// If the type is not `something.MyType` and it starts with an uppercase than the type was
+// defined inside the service package, channel element types are handled the same way
+// e.x `<-chan Item` => `<-chan service.Item`.
+func serviceQualifiedType(tp string) string {
+	if elem := parser.ChanElemType(tp); elem != "" {
+		return strings.TrimSuffix(tp, elem) + serviceQualifiedType(elem)
+	}
+	if len(strings.Split(tp, ".")) == 1 {
+		if tp[:1] == strings.ToUpper(tp[:1]) && tp[0] != '[' && tp[0] != '*' {
+			return "service." + tp
+		}
+	}
+	return tp
+}
+
+// jsonFieldTag returns the json tag of a request/response field, channels are streamed
+// by the transport so they are never encoded.
+func jsonFieldTag(p parser.NamedTypeValue) string {
+	if parser.ChanElemType(p.Type) != "" {
+		return "-"
+	}
+	return utils.ToLowerSnakeCase(p.Name)
+}
+
+// endpointFieldTag returns the json tag of a request/response field of the method, the
+// parameters annotated as streams are never encoded either.
+func endpointFieldTag(m parser.Method, p parser.NamedTypeValue) string {
+	if m.ParamTag(StreamAnnotation, p.Name) != "" {
+		return "-"
+	}
+	return jsonFieldTag(p)
+}
+
 type generateServiceEndpoints struct {
 	BaseGenerator
 	name              string
@@ -622,15 +906,7 @@ func (g *generateServiceEndpoints) generateEndpointsClientMethods() {
 				rqName = rqName + fmt.Sprintf("%d", i)
 				i++
 			}
-			tp := p.Type
-			ts := strings.Split(tp, ".")
-			if len(ts) == 1 {
-				if tp[:1] == strings.ToUpper(tp[:1]) && tp[0] != '[' && tp[0] != '*' {
-					// If the type of the parameter is not `something.MyType` and it starts with an uppercase
-					// than the type was defined inside the service package.
-					tp = "service." + tp
-				}
-			}
+			tp := serviceQualifiedType(p.Type)
 			pth := g.EnsureThatWeUseQualifierIfNeeded(p.Type, g.serviceImports)
 			if pth != "" {
 				s := strings.Split(p.Type, ".")
@@ -655,15 +931,7 @@ func (g *generateServiceEndpoints) generateEndpointsClientMethods() {
 				rqName = rqName + fmt.Sprintf("%d", i)
 				i++
 			}
-			tp := p.Type
-			ts := strings.Split(tp, ".")
-			if len(ts) == 1 {
-				if tp[:1] == strings.ToUpper(tp[:1]) && tp[0] != '[' && tp[0] != '*' {
-					// If the type of the parameter is not `something.MyType` and it starts with an uppercase
-					// than the type was defined inside the service package.
-					tp = "service." + tp
-				}
-			}
+			tp := serviceQualifiedType(p.Type)
 			pth := g.EnsureThatWeUseQualifierIfNeeded(p.Type, g.serviceImports)
 			if pth != "" {
 				s := strings.Split(p.Type, ".")
@@ -725,15 +993,7 @@ func (g *generateServiceEndpoints) generateMethodEndpoint() (err error) {
 				mCallParam = append(mCallParam, jen.Id(p.Name))
 				continue
 			}
-			tp := p.Type
-			ts := strings.Split(tp, ".")
-			if len(ts) == 1 {
-				if tp[:1] == strings.ToUpper(tp[:1]) && tp[0] != '[' && tp[0] != '*' {
-					// If the type of the parameter is not `something.MyType` and it starts with an uppercase
-					// than the type was defined inside the service package.
-					tp = "service." + tp
-				}
-			}
+			tp := serviceQualifiedType(p.Type)
 			pth := g.EnsureThatWeUseQualifierIfNeeded(p.Type, g.serviceImports)
 			if pth != "" {
 				s := strings.Split(p.Type, ".")
@@ -742,7 +1002,7 @@ func (g *generateServiceEndpoints) generateMethodEndpoint() (err error) {
 				}))
 			} else {
 				reqFields = append(reqFields, jen.Id(utils.ToCamelCase(p.Name)).Id(strings.Replace(tp, "...", "[]", 1)).Tag(map[string]string{
-					"json": utils.ToLowerSnakeCase(p.Name),
+					"json": endpointFieldTag(m, p),
 				}))
 			}
 			mCallParam = append(mCallParam, jen.Id("req").Dot(utils.ToCamelCase(p.Name)))
@@ -756,15 +1016,7 @@ func (g *generateServiceEndpoints) generateMethodEndpoint() (err error) {
 				methodHasError = true
 				errName = utils.ToCamelCase(p.Name)
 			}
-			tp := p.Type
-			ts := strings.Split(tp, ".")
-			if len(ts) == 1 {
-				if tp[:1] == strings.ToUpper(tp[:1]) && tp[0] != '[' && tp[0] != '*' {
-					// If the type of the parameter is not `something.MyType` and it starts with an uppercase
-					// than the type was defined inside the service package.
-					tp = "service." + tp
-				}
-			}
+			tp := serviceQualifiedType(p.Type)
 			pth := g.EnsureThatWeUseQualifierIfNeeded(p.Type, g.serviceImports)
 			if pth != "" {
 				s := strings.Split(p.Type, ".")
@@ -773,7 +1025,7 @@ func (g *generateServiceEndpoints) generateMethodEndpoint() (err error) {
 				}))
 			} else {
 				resFields = append(resFields, jen.Id(utils.ToCamelCase(p.Name)).Id(tp).Tag(map[string]string{
-					"json": utils.ToLowerSnakeCase(p.Name),
+					"json": endpointFieldTag(m, p),
 				}))
 			}
 			respParam[jen.Id(utils.ToCamelCase(p.Name))] = jen.Id(p.Name)
@@ -949,7 +1201,9 @@ func (g *generateServiceEndpointsBase) Generate() (err error) {
 	eps := jen.Dict{}
 	loops := []jen.Code{}
 	for _, v := range g.serviceInterface.Methods {
-		eps[jen.Id(v.Name+"Endpoint")] = jen.Id("Make" + v.Name + "Endpoint").Call(jen.Id("s"))
+		eps[jen.Id(v.Name+"Endpoint")] = jen.Id("ValidationMiddleware").Call().Call(
+			jen.Id("Make" + v.Name + "Endpoint").Call(jen.Id("s")),
+		)
 		l := jen.For(jen.List(jen.Id("_"), jen.Id("m")).Op(":=").Range().Id("mdw").Index(jen.Lit(v.Name)))
 		l.Block(
 			jen.Id("eps").Dot(v.Name + "Endpoint").Op("=").Id("m").Call(jen.Id("eps").Dot(v.Name + "Endpoint")),
@@ -968,7 +1222,7 @@ func (g *generateServiceEndpointsBase) Generate() (err error) {
 	body = append(body, jen.Return(jen.Id("eps")))
 	g.code.appendMultilineComment([]string{
 		"New returns a Endpoints struct that wraps the provided service, and wires in all of the",
-		"expected endpoint middlewares",
+		"expected endpoint middlewares, the requests are validated before the service is called",
 	})
 	g.code.NewLine()
 	g.code.appendFunction(
@@ -1100,6 +1354,14 @@ func (g *generateEndpointMiddleware) Generate() (err error) {
 		g.code.NewLine()
 	}
 	if !defaultLoggingExists {
+		propagationImport, err := utils.GetPropagationImportPath(g.name)
+		if err != nil {
+			return err
+		}
+		loggingImport, err := utils.GetLoggingImportPath(g.name)
+		if err != nil {
+			return err
+		}
 		g.code.appendMultilineComment([]string{
 			"LoggingMiddleware returns an endpoint middleware that logs the",
 			"duration of each invocation, and the resulting error, if any.",
@@ -1115,7 +1377,9 @@ func (g *generateEndpointMiddleware) Generate() (err error) {
 			},
 			[]jen.Code{},
 			"",
-			jen.Id("logger").Dot("Log").Call(
+			jen.Qual(loggingImport, "Result").Call(jen.Id("logger"), jen.Id("err")).Dot("Log").Call(
+				jen.Lit("request_id"),
+				jen.Qual(propagationImport, "RequestID").Call(jen.Id("ctx")),
 				jen.Lit("transport_error"),
 				jen.Id("err"),
 				jen.Lit("took"),
@@ -1160,7 +1424,11 @@ func (g *generateEndpointMiddleware) Generate() (err error) {
 		return g.fs.WriteFile(g.filePath, g.srcFile.GoString(), true)
 	}
 
-	src += "\n" + g.code.Raw().GoString()
+	pSrc, err := g.partialSource()
+	if err != nil {
+		return err
+	}
+	src += "\n" + pSrc
 	tmpSrc := g.srcFile.GoString()
 	f, err := parser.NewFileParser().Parse([]byte(tmpSrc))
 	if err != nil {
@@ -1239,12 +1507,46 @@ func (g *generateCmdBase) Generate() (err error) {
 	if err != nil {
 		return err
 	}
+	propagationImport, err := utils.GetPropagationImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	authImport, err := utils.GetAuthImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	existingAuth, err := authExists(g.fs, g.name)
+	if err != nil {
+		return err
+	}
+	tracingImport, err := utils.GetTracingImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	existingTracing, err := tracingExists(g.fs, g.name)
+	if err != nil {
+		return err
+	}
+	configImport, err := utils.GetConfigImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	existingConfig, err := configExists(g.fs, g.name)
+	if err != nil {
+		return err
+	}
 	existingHTTP := false
 	if b, err := g.fs.Exists(g.httpFilePath); err != nil {
 		return err
 	} else if b {
 		existingHTTP = true
 	}
+	errorEncoder := "ErrorEncoder"
+	if b, err := g.fs.Exists(path.Join(g.httpDestPath, viper.GetString("gk_http_status_file_name"))); err != nil {
+		return err
+	} else if b {
+		errorEncoder = "StatusErrorEncoder"
+	}
 	existingGRPC := false
 	if b, err := g.fs.Exists(g.grpcFilePath); err != nil {
 		return err
@@ -1256,6 +1558,14 @@ func (g *generateCmdBase) Generate() (err error) {
 			"github.com/oklog/oklog/pkg/group", "Group",
 		).Block(),
 	}
+	params := []jen.Code{
+		jen.Id("endpoints").Qual(endpointImport, "Endpoints"),
+	}
+	initArgs := []jen.Code{jen.Id("endpoints"), jen.Id("g")}
+	if existingConfig {
+		params = append(params, jen.Id("conf").Qual(configImport, "AppConfig"))
+		initArgs = []jen.Code{jen.Id("endpoints"), jen.Id("conf"), jen.Id("g")}
+	}
 	if existingHTTP {
 		src, err := g.fs.ReadFile(g.httpFilePath)
 		if err != nil {
@@ -1265,7 +1575,7 @@ func (g *generateCmdBase) Generate() (err error) {
 		if err != nil {
 			return err
 		}
-		cd = append(cd, jen.Id("initHttpHandler").Call(jen.Id("endpoints"), jen.Id("g")))
+		cd = append(cd, jen.Id("initHttpHandler").Call(initArgs...))
 	}
 	if existingGRPC {
 		src, err := g.fs.ReadFile(g.grpcFilePath)
@@ -1276,15 +1586,13 @@ func (g *generateCmdBase) Generate() (err error) {
 		if err != nil {
 			return err
 		}
-		cd = append(cd, jen.Id("initGRPCHandler").Call(jen.Id("endpoints"), jen.Id("g")))
+		cd = append(cd, jen.Id("initGRPCHandler").Call(initArgs...))
 	}
 	cd = append(cd, jen.Return(jen.Id("g")))
 	g.code.appendFunction(
 		"createService",
 		nil,
-		[]jen.Code{
-			jen.Id("endpoints").Qual(endpointImport, "Endpoints"),
-		},
+		params,
 		[]jen.Code{
 			jen.Id("g").Id("*").Qual("github.com/oklog/oklog/pkg/group", "Group"),
 		},
@@ -1309,7 +1617,7 @@ func (g *generateCmdBase) Generate() (err error) {
 						pt = append(
 							pt,
 							jen.Qual("github.com/go-kit/kit/transport/http", "ServerErrorEncoder").Call(
-								jen.Qual(httpImport, "ErrorEncoder"),
+								jen.Qual(httpImport, errorEncoder),
 							),
 						)
 					}
@@ -1318,13 +1626,31 @@ func (g *generateCmdBase) Generate() (err error) {
 						pt,
 						jen.Qual("github.com/go-kit/kit/transport/http", "ServerErrorLogger").Call(jen.Id("logger")),
 						jen.Qual("github.com/go-kit/kit/transport/http", "ServerBefore").Call(
-							jen.Qual("github.com/go-kit/kit/tracing/opentracing", "HTTPToContext").Call(
-								jen.Id("tracer"),
-								jen.Lit(v.Name),
-								jen.Id("logger"),
-							),
+							jen.Qual(propagationImport, "HTTPToContext"),
 						),
 					)
+					if existingAuth {
+						pt = append(
+							pt,
+							jen.Qual("github.com/go-kit/kit/transport/http", "ServerBefore").Call(
+								jen.Qual(authImport, "HTTPToContext"),
+							),
+						)
+					}
+					if existingTracing {
+						pt = append(pt, jen.Qual(tracingImport, "HTTPServerTrace").Call(jen.Lit(v.Name)))
+					} else {
+						pt = append(
+							pt,
+							jen.Qual("github.com/go-kit/kit/transport/http", "ServerBefore").Call(
+								jen.Qual("github.com/go-kit/kit/tracing/opentracing", "HTTPToContext").Call(
+									jen.Id("tracer"),
+									jen.Lit(v.Name),
+									jen.Id("logger"),
+								),
+							),
+						)
+					}
 					opt[jen.Lit(v.Name)] =
 						jen.Values(
 							jen.List(
@@ -1342,13 +1668,16 @@ func (g *generateCmdBase) Generate() (err error) {
 			opt,
 		).Line()
 		pl.Raw().Return(jen.Id("options"))
+		params := []jen.Code{
+			jen.Id("logger").Qual("github.com/go-kit/kit/log", "Logger"),
+		}
+		if !existingTracing {
+			params = append(params, jen.Id("tracer").Qual("github.com/opentracing/opentracing-go", "Tracer"))
+		}
 		g.code.appendFunction(
 			"defaultHttpOptions",
 			nil,
-			[]jen.Code{
-				jen.Id("logger").Qual("github.com/go-kit/kit/log", "Logger"),
-				jen.Id("tracer").Qual("github.com/opentracing/opentracing-go", "Tracer"),
-			},
+			params,
 			[]jen.Code{
 				jen.Map(jen.String()).Index().Qual("github.com/go-kit/kit/transport/http", "ServerOption"),
 			},
@@ -1362,17 +1691,38 @@ func (g *generateCmdBase) Generate() (err error) {
 		for _, v := range g.serviceInterface.Methods {
 			for _, m := range g.grpcFile.Methods {
 				if m.Name == "make"+v.Name+"Handler" {
+					pt := []jen.Code{
+						jen.Qual("github.com/go-kit/kit/transport/grpc", "ServerErrorLogger").Call(jen.Id("logger")),
+						jen.Qual("github.com/go-kit/kit/transport/grpc", "ServerBefore").Call(
+							jen.Qual(propagationImport, "GRPCToContext"),
+						),
+					}
+					if existingAuth {
+						pt = append(
+							pt,
+							jen.Qual("github.com/go-kit/kit/transport/grpc", "ServerBefore").Call(
+								jen.Qual(authImport, "GRPCToContext"),
+							),
+						)
+					}
+					if existingTracing {
+						pt = append(pt, jen.Qual(tracingImport, "GRPCServerTrace").Call(jen.Lit(v.Name)))
+					} else {
+						pt = append(
+							pt,
+							jen.Qual("github.com/go-kit/kit/transport/grpc", "ServerBefore").Call(
+								jen.Qual("github.com/go-kit/kit/tracing/opentracing", "GRPCToContext").Call(
+									jen.Id("tracer"),
+									jen.Lit(v.Name),
+									jen.Id("logger"),
+								),
+							),
+						)
+					}
 					opt[jen.Lit(v.Name)] =
 						jen.Values(
 							jen.List(
-								jen.Qual("github.com/go-kit/kit/transport/grpc", "ServerErrorLogger").Call(jen.Id("logger")),
-								jen.Qual("github.com/go-kit/kit/transport/grpc", "ServerBefore").Call(
-									jen.Qual("github.com/go-kit/kit/tracing/opentracing", "GRPCToContext").Call(
-										jen.Id("tracer"),
-										jen.Lit(v.Name),
-										jen.Id("logger"),
-									),
-								),
+								pt...,
 							),
 						)
 				}
@@ -1386,13 +1736,16 @@ func (g *generateCmdBase) Generate() (err error) {
 			opt,
 		).Line()
 		pl.Raw().Return(jen.Id("options"))
+		params := []jen.Code{
+			jen.Id("logger").Qual("github.com/go-kit/kit/log", "Logger"),
+		}
+		if !existingTracing {
+			params = append(params, jen.Id("tracer").Qual("github.com/opentracing/opentracing-go", "Tracer"))
+		}
 		g.code.appendFunction(
 			"defaultGRPCOptions",
 			nil,
-			[]jen.Code{
-				jen.Id("logger").Qual("github.com/go-kit/kit/log", "Logger"),
-				jen.Id("tracer").Qual("github.com/opentracing/opentracing-go", "Tracer"),
-			},
+			params,
 			[]jen.Code{
 				jen.Map(jen.String()).Index().Qual("github.com/go-kit/kit/transport/grpc", "ServerOption"),
 			},
@@ -1449,6 +1802,19 @@ func (g *generateCmdBase) Generate() (err error) {
 		g.code.NewLine()
 	}
 	if g.generateSvcDefaultsMiddleware {
+		namespace := metricsNamespace(g.name)
+		labels := jen.Index().String().Values(jen.Lit("method"), jen.Lit("error_kind"))
+		buckets := []jen.Code{}
+		for _, v := range metricsBuckets() {
+			buckets = append(buckets, jen.Lit(v))
+		}
+		g.code.appendMultilineComment([]string{
+			"serviceDurationBuckets are the buckets of the latency histogram of the",
+			"service methods in seconds, set `gk_metrics_buckets` to change them.",
+		})
+		g.code.NewLine()
+		g.code.Raw().Var().Id("serviceDurationBuckets").Op("=").Index().Float64().Values(buckets...).Line()
+		g.code.NewLine()
 		g.code.appendFunction(
 			"addDefaultServiceMiddleware",
 			nil,
@@ -1460,8 +1826,42 @@ func (g *generateCmdBase) Generate() (err error) {
 				jen.Index().Qual(serviceImport, "Middleware"),
 			},
 			"",
+			jen.Id("requests").Op(":=").Qual("github.com/go-kit/kit/metrics/prometheus", "NewCounterFrom").Call(
+				jen.Qual("github.com/prometheus/client_golang/prometheus", "CounterOpts").Values(jen.Dict{
+					jen.Id("Help"):      jen.Lit("Number of requests received."),
+					jen.Id("Name"):      jen.Lit("requests_total"),
+					jen.Id("Namespace"): jen.Lit(namespace),
+					jen.Id("Subsystem"): jen.Lit("service"),
+				}),
+				labels,
+			),
+			jen.Id("errors").Op(":=").Qual("github.com/go-kit/kit/metrics/prometheus", "NewCounterFrom").Call(
+				jen.Qual("github.com/prometheus/client_golang/prometheus", "CounterOpts").Values(jen.Dict{
+					jen.Id("Help"):      jen.Lit("Number of requests that failed."),
+					jen.Id("Name"):      jen.Lit("errors_total"),
+					jen.Id("Namespace"): jen.Lit(namespace),
+					jen.Id("Subsystem"): jen.Lit("service"),
+				}),
+				labels,
+			),
+			jen.Id("duration").Op(":=").Qual("github.com/go-kit/kit/metrics/prometheus", "NewHistogramFrom").Call(
+				jen.Qual("github.com/prometheus/client_golang/prometheus", "HistogramOpts").Values(jen.Dict{
+					jen.Id("Help"):      jen.Lit("Request duration in seconds."),
+					jen.Id("Name"):      jen.Lit("request_duration_seconds"),
+					jen.Id("Namespace"): jen.Lit(namespace),
+					jen.Id("Subsystem"): jen.Lit("service"),
+					jen.Id("Buckets"):   jen.Id("serviceDurationBuckets"),
+				}),
+				labels,
+			),
 			jen.Return(
-				jen.Append(jen.Id("mw"), jen.Qual(serviceImport, "LoggingMiddleware").Call(jen.Id("logger"))),
+				jen.Append(
+					jen.Id("mw"),
+					jen.Qual(serviceImport, "LoggingMiddleware").Call(jen.Id("logger")),
+					jen.Qual(serviceImport, "InstrumentingMiddleware").Call(
+						jen.Id("requests"), jen.Id("errors"), jen.Id("duration"),
+					),
+				),
 			),
 		)
 		g.code.NewLine()
@@ -1470,6 +1870,25 @@ func (g *generateCmdBase) Generate() (err error) {
 	for _, v := range g.serviceInterface.Methods {
 		mth = append(mth, jen.Lit(v.Name))
 	}
+	if existingTracing {
+		g.code.appendFunction(
+			"addTracingEndpointMiddleware",
+			nil,
+			[]jen.Code{
+				jen.Id("mw").Map(jen.String()).Index().Qual("github.com/go-kit/kit/endpoint", "Middleware"),
+			},
+			[]jen.Code{},
+			"",
+			jen.Id("methods").Op(":=").Index().String().Values(mth...),
+			jen.For(jen.List(jen.Id("_"), jen.Id("v")).Op(":=").Range().Id("methods")).Block(
+				jen.Id("mw").Index(jen.Id("v")).Op("=").Append(
+					jen.Id("mw").Index(jen.Id("v")),
+					jen.Qual(tracingImport, "EndpointMiddleware").Call(jen.Id("v")),
+				),
+			),
+		)
+		g.code.NewLine()
+	}
 	g.code.appendFunction(
 		"addEndpointMiddlewareToAllMethods",
 		nil,
@@ -1505,6 +1924,15 @@ type generateCmd struct {
 	generateSvcDefaultsMiddleware      bool
 	generateEndpointDefaultsMiddleware bool
 	serviceInterface                   parser.Interface
+	// otel is true if the service traces with the OpenTelemetry tracing package.
+	otel bool
+	// config is true if the service loads the configuration of the config package.
+	config       bool
+	configImport string
+	// db is the database of the service, nil if it does not have one.
+	db *dbDriver
+	// events is true if the service relays the outbox of the events package.
+	events bool
 }
 
 func newGenerateCmd(name, pbImportPath string, serviceInterface parser.Interface,
@@ -1524,6 +1952,7 @@ func newGenerateCmd(name, pbImportPath string, serviceInterface parser.Interface
 	t.httpFilePath = path.Join(t.httpDestPath, viper.GetString("gk_http_file_name"))
 	t.grpcFilePath = path.Join(t.grpcDestPath, viper.GetString("gk_grpc_file_name"))
 	t.srcFile = jen.NewFile("service")
+	t.srcFile.ImportAlias(grpcHealthImport, "grpc_health_v1")
 	t.pbImportPath = pbImportPath
 	t.InitPg()
 	t.fs = fs.Get()
@@ -1551,18 +1980,40 @@ func (g *generateCmd) Generate() (err error) {
 	if err != nil {
 		return err
 	}
-	g.generateVars()
-	runFound := false
-	for _, v := range g.file.Methods {
-		if v.Name == "Run" {
-			runFound = true
-		}
+	g.otel, err = tracingExists(g.fs, g.name)
+	if err != nil {
+		return err
 	}
-	if !runFound {
-		p, err := g.generateRun()
-		if err != nil {
-			return err
-		}
+	g.config, err = configExists(g.fs, g.name)
+	if err != nil {
+		return err
+	}
+	g.db, err = dbExists(g.fs, g.name)
+	if err != nil {
+		return err
+	}
+	if g.db != nil {
+		g.events, err = eventsExists(g.fs, g.name)
+		if err != nil {
+			return err
+		}
+	}
+	g.configImport, err = utils.GetConfigImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	g.generateVars()
+	runFound := false
+	for _, v := range g.file.Methods {
+		if v.Name == "Run" {
+			runFound = true
+		}
+	}
+	if !runFound {
+		p, err := g.generateRun()
+		if err != nil {
+			return err
+		}
 		g.code.appendFunction(
 			"Run",
 			nil,
@@ -1592,7 +2043,13 @@ func (g *generateCmd) Generate() (err error) {
 	if err != nil {
 		return err
 	}
-	g.generateDefaultMetrics()
+	err = g.generateDefaultMetrics()
+	if err != nil {
+		return err
+	}
+	if err = g.generateInitEventsRelay(); err != nil {
+		return err
+	}
 	g.generateCancelInterrupt()
 	g.generateCmdMain()
 	if g.generateFirstTime {
@@ -1608,34 +2065,44 @@ func (g *generateCmd) Generate() (err error) {
 	if err != nil {
 		return err
 	}
-	if len(imp) > 0 {
-		pSrc := g.code.Raw().GoString()
+	pSrc, err := g.partialSource()
+	if err != nil {
+		return err
+	}
+	if len(imp) == 0 {
+		src += "\n" + pSrc
+	} else {
 		foundSameImport := false
 		inx := 0
 		// Small(stupid) workaround
 		txt := "abcdefghijkl"
 		mp := map[string]string{}
-		keep := imp
-		for a, i := range imp {
+		keep := []parser.NamedTypeValue{}
+		for _, i := range imp {
+			renamed := false
 			for _, v := range g.file.Imports {
-				if v.Type == i.Type && i.Name != v.Name {
+				if v.Type == i.Type && i.Name != v.Name && v.Name != "" && i.Name != "" {
 					mp[txt+i.Name] = v.Name
 					pSrc = strings.Replace(pSrc, i.Name+".", txt+i.Name+".", -1)
-					keep = append(imp[:a], imp[a+1:]...)
+					renamed = true
+					break
 				}
 			}
+			if !renamed {
+				keep = append(keep, i)
+			}
 		}
 
 		for a, i := range keep {
 			for _, v := range g.file.Imports {
-				if v.Name == i.Name {
+				if v.Name == i.Name && i.Name != "" {
 					foundSameImport = true
 					inx = a
 				}
 			}
 		}
-		oldName := keep[inx].Name
 		if foundSameImport {
+			oldName := keep[inx].Name
 			a := 1
 			for {
 				canUse := true
@@ -1675,21 +2142,243 @@ func (g *generateCmd) Generate() (err error) {
 }
 func (g *generateCmd) generateRun() (*PartialGenerator, error) {
 	pg := NewPartialGenerator(nil)
-	pg.Raw().Id("fs").Dot("Parse").Call(jen.Qual("os", "Args").Index(jen.Lit(1), jen.Empty()))
+	if g.config {
+		pg.Raw().List(jen.Id("conf"), jen.Err()).Op(":=").Qual(g.configImport, "Load").Call(
+			jen.Lit(g.name),
+			jen.Qual("os", "Args").Index(jen.Lit(1), jen.Empty()),
+		).Line()
+		pg.Raw().If(jen.Err().Op("!=").Nil()).Block(
+			jen.Qual("fmt", "Fprintln").Call(jen.Qual("os", "Stderr"), jen.Err()),
+			jen.Qual("os", "Exit").Call(jen.Lit(1)),
+		)
+	} else {
+		pg.Raw().Id("fs").Dot("Parse").Call(jen.Qual("os", "Args").Index(jen.Lit(1), jen.Empty()))
+	}
 	pg.Raw().Line().Line().Comment("Create a single logger, which we'll use and give to other components.").Line()
-	pg.Raw().Id("logger").Op("=").Qual("github.com/go-kit/kit/log", "NewLogfmtLogger").Call(
-		jen.Qual("os", "Stderr"),
+	if g.config {
+		loggingImport, err := utils.GetLoggingImportPath(g.name)
+		if err != nil {
+			return nil, err
+		}
+		pg.Raw().Var().Id("logOutput").Qual("io", "Closer").Line()
+		pg.Raw().List(jen.Id("logger"), jen.Id("logOutput"), jen.Err()).Op("=").Qual(loggingImport, "New").Call(
+			jen.Id("conf").Dot("Log").Dot("Format"),
+			jen.Id("conf").Dot("Log").Dot("Level"),
+			jen.Id("conf").Dot("Log").Dot("Output"),
+		).Line()
+		pg.Raw().If(jen.Err().Op("!=").Nil()).Block(
+			jen.Qual("fmt", "Fprintln").Call(jen.Qual("os", "Stderr"), jen.Err()),
+			jen.Qual("os", "Exit").Call(jen.Lit(1)),
+		).Line()
+		pg.Raw().Defer().Id("logOutput").Dot("Close").Call().Line().Line()
+	} else {
+		pg.Raw().Id("logger").Op("=").Qual("github.com/go-kit/kit/log", "NewLogfmtLogger").Call(
+			jen.Qual("os", "Stderr"),
+		).Line()
+		pg.Raw().Id("logger").Op("=").Qual("github.com/go-kit/kit/log", "With").Call(
+			jen.Id("logger"),
+			jen.Lit("ts"),
+			jen.Qual("github.com/go-kit/kit/log", "DefaultTimestampUTC"),
+		).Line()
+		pg.Raw().Id("logger").Op("=").Qual("github.com/go-kit/kit/log", "With").Call(
+			jen.Id("logger"),
+			jen.Lit("caller"),
+			jen.Qual("github.com/go-kit/kit/log", "DefaultCaller"),
+		).Line().Line()
+	}
+	if g.otel {
+		tracingImport, err := utils.GetTracingImportPath(g.name)
+		if err != nil {
+			return nil, err
+		}
+		pg.appendMultilineComment(
+			[]string{
+				"Set up the OpenTelemetry tracer provider, use -otel-exporter=stdout to",
+				"print the spans during local development.",
+			},
+		)
+		pg.NewLine()
+		pg.Raw().List(jen.Id("tp"), jen.Err()).Op(":=").Qual(tracingImport, "NewTracerProvider").Call(
+			jen.Qual("context", "Background").Call(),
+			g.setting("otelExporter", "Tracing", "Exporter"),
+			g.setting("otelEndpoint", "Tracing", "Endpoint"),
+		).Line()
+		pg.Raw().If(jen.Err().Op("!=").Nil()).Block(
+			logLevel("Error", jen.Id("logger")).Dot("Log").Call(
+				jen.Lit("err"),
+				jen.Id("err"),
+			),
+			jen.Qual("os", "Exit").Call(jen.Lit(1)),
+		).Line()
+		pg.Raw().Add(logLevel("Info", jen.Id("logger"))).Dot("Log").Call(
+			jen.Lit("tracer"),
+			jen.Lit("OpenTelemetry"),
+			jen.Lit("exporter"),
+			g.setting("otelExporter", "Tracing", "Exporter"),
+		).Line().Line()
+	} else {
+		g.generateOpenTracing(pg)
+	}
+
+	svcImport, err := utils.GetServiceImportPath(g.name)
+	if err != nil {
+		return nil, err
+	}
+	epImport, err := utils.GetEndpointImportPath(g.name)
+	if err != nil {
+		return nil, err
+	}
+
+	modelImport, err := utils.GetModelImportPath(g.name)
+	if err != nil {
+		return nil,err
+	}
+
+	healthImport, err := utils.GetHealthImportPath(g.name)
+	if err != nil {
+		return nil, err
+	}
+
+	svcArgs := []jen.Code{jen.Id("getServiceMiddleware").Call(jen.Id("logger"))}
+	if g.db != nil {
+		dbImport, err := utils.GetDBImportPath(g.name, g.db.name)
+		if err != nil {
+			return nil, err
+		}
+		pg.Raw().List(jen.Id("d"), jen.Err()).Op(":=").Qual(dbImport, "GetDatabase").Call(jen.Lit("default")).Line()
+		pg.Raw().If(jen.Err().Op("!=").Nil()).Block(jen.Return()).Line()
+		pg.Raw().Qual(healthImport, "Register").Call(
+			jen.Lit(g.db.name),
+			jen.Func().Params(jen.Id("ctx").Qual("context", "Context")).Error().Block(
+				jen.Return(jen.Id("d").Dot("DB").Call().Dot("PingContext").Call(jen.Id("ctx"))),
+			),
+		).Line()
+		pg.Raw().Id("db").Op(":=").Qual(modelImport, "NewBasic"+g.db.modelDatabase()).Call(jen.Id("d")).Line()
+		svcArgs = append(svcArgs, jen.Id("db"))
+	}
+	pg.Raw().Id("svc").Op(":=").Qual(svcImport, "New").Call(svcArgs...).Line()
+	pg.Raw().Id("eps").Op(":=").Qual(epImport, "New").Call(
+		jen.Id("svc"),
+		jen.Id("getEndpointMiddleware").Call(jen.Id("logger")),
 	).Line()
-	pg.Raw().Id("logger").Op("=").Qual("github.com/go-kit/kit/log", "With").Call(
-		jen.Id("logger"),
-		jen.Lit("ts"),
-		jen.Qual("github.com/go-kit/kit/log", "DefaultTimestampUTC"),
+	createArgs := []jen.Code{jen.Id("eps")}
+	if g.config {
+		createArgs = append(createArgs, jen.Id("conf"))
+	}
+	pg.Raw().Id("g").Op(":=").Id("createService").Call(createArgs...).Line()
+
+	if g.db != nil {
+		pg.Raw().Comment("AutoMigrate is a development mode, the schema is versioned by the migrate subcommand.").Line()
+		pg.Raw().If(g.setting("autoMigrate", "DB", "AutoMigrate")).Block(
+			jen.If(
+				jen.Err().Op(":=").Qual(modelImport, "AutoMigration").Call(),
+				jen.Err().Op("!=").Nil(),
+			).Block(
+				logLevel("Error", jen.Id("logger")).Dot("Log").Call(jen.Lit("during"), jen.Lit("AutoMigration"), jen.Lit("err"), jen.Err()),
+				jen.Qual("os", "Exit").Call(jen.Lit(1)),
+			),
+		).Line()
+	}
+
+	if g.config {
+		pg.Raw().Id("initMetricsEndpoint").Call(jen.Id("conf"), jen.Id("g")).Line()
+	} else {
+		pg.Raw().Id("initMetricsEndpoint").Call(jen.Id("g")).Line()
+	}
+	if g.events {
+		pg.Raw().Id("initEventsRelay").Call(jen.Id("d"), jen.Id("g")).Line()
+	}
+	pg.Raw().Id("initCancelInterrupt").Call(jen.Id("g")).Line()
+	g.generateResourcesShutdown(pg)
+	pg.Raw().Add(logLevel("Info", jen.Id("logger"))).Dot("Log").Call(
+		jen.Lit("exit"),
+		jen.Id("g").Dot("Run").Call(),
 	).Line()
-	pg.Raw().Id("logger").Op("=").Qual("github.com/go-kit/kit/log", "With").Call(
-		jen.Id("logger"),
-		jen.Lit("caller"),
-		jen.Qual("github.com/go-kit/kit/log", "DefaultCaller"),
-	).Line().Line()
+	return pg, nil
+}
+
+// generateResourcesShutdown generates the actor that closes the database pool and
+// flushes the tracer, the group interrupts it after the servers are drained.
+func (g *generateCmd) generateResourcesShutdown(pg *PartialGenerator) {
+	if !g.otel && g.db == nil {
+		return
+	}
+	interrupt := []jen.Code{
+		jen.Defer().Close(jen.Id("done")),
+	}
+	if g.otel {
+		interrupt = append(
+			interrupt,
+			jen.List(jen.Id("ctx"), jen.Id("cancel")).Op(":=").Qual("context", "WithTimeout").Call(
+				jen.Qual("context", "Background").Call(),
+				g.setting("shutdownTimeout", "", "ShutdownTimeout"),
+			),
+			jen.Defer().Id("cancel").Call(),
+			logLevel("Info", jen.Id("logger")).Dot("Log").Call(
+				jen.Lit("tracer"),
+				jen.Lit("OpenTelemetry"),
+				jen.Lit("during"),
+				jen.Lit("Shutdown"),
+			),
+			jen.If(
+				jen.Err().Op(":=").Id("tp").Dot("Shutdown").Call(jen.Id("ctx")),
+				jen.Err().Op("!=").Nil(),
+			).Block(
+				logLevel("Error", jen.Id("logger")).Dot("Log").Call(
+					jen.Lit("tracer"),
+					jen.Lit("OpenTelemetry"),
+					jen.Lit("during"),
+					jen.Lit("Shutdown"),
+					jen.Lit("err"),
+					jen.Err(),
+				),
+			),
+		)
+	}
+	if g.db != nil {
+		interrupt = append(
+			interrupt,
+			logLevel("Info", jen.Id("logger")).Dot("Log").Call(
+				jen.Lit("database"),
+				jen.Lit(g.db.name),
+				jen.Lit("during"),
+				jen.Lit("Close"),
+			),
+			jen.If(
+				jen.Err().Op(":=").Id("d").Dot("Close").Call(),
+				jen.Err().Op("!=").Nil(),
+			).Block(
+				logLevel("Error", jen.Id("logger")).Dot("Log").Call(
+					jen.Lit("database"),
+					jen.Lit(g.db.name),
+					jen.Lit("during"),
+					jen.Lit("Close"),
+					jen.Lit("err"),
+					jen.Err(),
+				),
+			),
+		)
+	}
+	pg.appendMultilineComment(
+		[]string{
+			"The interrupts run in the order the actors were added, this one is added last",
+			"so the resources are released after the servers drained the requests.",
+		},
+	)
+	pg.NewLine()
+	pg.Raw().Id("done").Op(":=").Make(jen.Chan().Struct()).Line()
+	pg.Raw().Id("g").Dot("Add").Call(
+		jen.Func().Params().Error().Block(
+			jen.Op("<-").Id("done"),
+			jen.Return(jen.Nil()),
+		),
+		jen.Func().Params(jen.Error()).Block(interrupt...),
+	).Line()
+}
+
+// generateOpenTracing generates the selection of the OpenTracing tracer from
+// the Zipkin, LightStep and Appdash flags.
+func (g *generateCmd) generateOpenTracing(pg *PartialGenerator) {
 	pg.appendMultilineComment(
 		[]string{
 			" Determine which tracer to use. We'll pass the tracer to all the",
@@ -1698,17 +2387,17 @@ func (g *generateCmd) generateRun() (*PartialGenerator, error) {
 	)
 	pg.NewLine()
 	pg.Raw().If(
-		jen.Id("*zipkinURL").Op("!=").Lit(""),
+		g.setting("zipkinURL", "Tracing", "ZipkinURL").Op("!=").Lit(""),
 	).Block(
-		jen.Id("logger").Dot("Log").Call(
+		logLevel("Info", jen.Id("logger")).Dot("Log").Call(
 			jen.Lit("tracer"),
 			jen.Lit("Zipkin"),
 			jen.Lit("URL"),
-			jen.Id("*zipkinURL"),
+			g.setting("zipkinURL", "Tracing", "ZipkinURL"),
 		),
 		jen.Id("reporter").Op(":=").Qual(
 			"github.com/openzipkin/zipkin-go/reporter/http", "NewReporter",
-		).Call(jen.Id("*zipkinURL")),
+		).Call(g.setting("zipkinURL", "Tracing", "ZipkinURL")),
 		jen.Defer().Id("reporter").Dot("Close").Call(),
 		jen.List(jen.Id("endpoint"), jen.Id("err")).Op(":=").Qual(
 			"github.com/openzipkin/zipkin-go", "NewEndpoint",
@@ -1717,7 +2406,7 @@ func (g *generateCmd) generateRun() (*PartialGenerator, error) {
 			jen.Lit("localhost:80"),
 		),
 		jen.If(jen.Err().Op("!=").Nil()).Block(
-			jen.Id("logger").Dot("Log").Call(
+			logLevel("Error", jen.Id("logger")).Dot("Log").Call(
 				jen.Lit("err"),
 				jen.Id("err"),
 			),
@@ -1728,7 +2417,7 @@ func (g *generateCmd) generateRun() (*PartialGenerator, error) {
 			"github.com/openzipkin/zipkin-go", "NewTracer",
 		).Call(jen.Id("reporter"), jen.Id("localEndpoint")),
 		jen.If(jen.Err().Op("!=").Nil()).Block(
-			jen.Id("logger").Dot("Log").Call(
+			logLevel("Error", jen.Id("logger")).Dot("Log").Call(
 				jen.Lit("err"),
 				jen.Id("err"),
 			),
@@ -1739,8 +2428,8 @@ func (g *generateCmd) generateRun() (*PartialGenerator, error) {
 		).Call(
 			jen.Id("nativeTracer"),
 		),
-	).Else().If(jen.Id("*lightstepToken").Op("!=").Lit("")).Block(
-		jen.Id("logger").Dot("Log").Call(
+	).Else().If(g.setting("lightstepToken", "Tracing", "LightstepToken").Op("!=").Lit("")).Block(
+		logLevel("Info", jen.Id("logger")).Dot("Log").Call(
 			jen.Lit("tracer"),
 			jen.Lit("LightStep"),
 		),
@@ -1750,29 +2439,29 @@ func (g *generateCmd) generateRun() (*PartialGenerator, error) {
 			"github.com/lightstep/lightstep-tracer-go", "Options",
 		).Values(
 			jen.Dict{
-				jen.Id("AccessToken"): jen.Id("*lightstepToken"),
+				jen.Id("AccessToken"): g.setting("lightstepToken", "Tracing", "LightstepToken"),
 			},
 		),
 		),
 		jen.Defer().Qual(
 			"github.com/lightstep/lightstep-tracer-go", "Flush",
 		).Call(jen.Qual("context", "Background").Call(), jen.Id("tracer")),
-	).Else().If(jen.Id("*appdashAddr").Op("!=").Lit("")).Block(
-		jen.Id("logger").Dot("Log").Call(
+	).Else().If(g.setting("appdashAddr", "Tracing", "AppdashAddr").Op("!=").Lit("")).Block(
+		logLevel("Info", jen.Id("logger")).Dot("Log").Call(
 			jen.Lit("tracer"),
 			jen.Lit("Appdash"),
 			jen.Lit("addr"),
-			jen.Id("*appdashAddr"),
+			g.setting("appdashAddr", "Tracing", "AppdashAddr"),
 		),
 		jen.Id("collector").Op(":=").Qual(
 			"sourcegraph.com/sourcegraph/appdash", "NewRemoteCollector",
-		).Call(jen.Id("*appdashAddr")),
+		).Call(g.setting("appdashAddr", "Tracing", "AppdashAddr")),
 		jen.Id("tracer").Op("=").Qual(
 			"sourcegraph.com/sourcegraph/appdash/opentracing", "NewTracer",
 		).Call(jen.Id("collector")),
 		jen.Defer().Id("collector").Dot("Close").Call(),
 	).Else().Block(
-		jen.Id("logger").Dot("Log").Call(
+		logLevel("Info", jen.Id("logger")).Dot("Log").Call(
 			jen.Lit("tracer"),
 			jen.Lit("none"),
 		),
@@ -1780,55 +2469,16 @@ func (g *generateCmd) generateRun() (*PartialGenerator, error) {
 			"github.com/opentracing/opentracing-go", "GlobalTracer",
 		).Call(),
 	).Line().Line()
-
-	svcImport, err := utils.GetServiceImportPath(g.name)
-	if err != nil {
-		return nil, err
-	}
-	epImport, err := utils.GetEndpointImportPath(g.name)
-	if err != nil {
-		return nil, err
-	}
-
-	modelImport, err := utils.GetModelImportPath(g.name)
-	if err != nil {
-		return nil,err
-	}
-
-	postgreImport,err := utils.GetPostgresImportPath(g.name)
-	if err != nil{
-		return nil, err
-	}
-
-	pg.Raw().List(jen.Id("d"),jen.Err()).Op(":=").Qual(postgreImport,"GetDatabase").Call(jen.Lit("default")).Line()
-	pg.Raw().If(jen.Err().Op("!=").Nil()).Block(jen.Return()).Line()
-	pg.Raw().Id("db").Op(":=").Qual(modelImport,"NewBasicPostgresDatabase").Call(jen.Id("d")).Line()
-	pg.Raw().Id("svc").Op(":=").Qual(svcImport, "New").Call(
-		jen.Id("getServiceMiddleware").Call(jen.Id("logger")),
-		jen.Id("db"),
-	).Line()
-	pg.Raw().Id("eps").Op(":=").Qual(epImport, "New").Call(
-		jen.Id("svc"),
-		jen.Id("getEndpointMiddleware").Call(jen.Id("logger")),
-	).Line()
-	pg.Raw().Id("g").Op(":=").Id("createService").Call(
-		jen.Id("eps"),
-	).Line()
-
-	pg.Raw().Id("_").Op("=").Qual(modelImport,"AutoMigration").Call().Line()
-
-	pg.Raw().Id("initMetricsEndpoint").Call(jen.Id("g")).Line()
-	pg.Raw().Id("initCancelInterrupt").Call(jen.Id("g")).Line()
-	pg.Raw().Id("logger").Dot("Log").Call(
-		jen.Lit("exit"),
-		jen.Id("g").Dot("Run").Call(),
-	).Line()
-	return pg, nil
 }
 func (g *generateCmd) generateVars() {
 	if g.generateFirstTime {
-		g.code.Raw().Var().Id("tracer").Qual("github.com/opentracing/opentracing-go", "Tracer").Line()
+		if !g.otel {
+			g.code.Raw().Var().Id("tracer").Qual("github.com/opentracing/opentracing-go", "Tracer").Line()
+		}
 		g.code.Raw().Var().Id("logger").Qual("github.com/go-kit/kit/log", "Logger").Line()
+		if g.config {
+			return
+		}
 		g.code.appendMultilineComment(
 			[]string{
 				"Define our flags. Your service probably won't need to bind listeners for",
@@ -1882,25 +2532,67 @@ func (g *generateCmd) generateVars() {
 			jen.Lit("true to enable framing"),
 		)
 		g.code.NewLine()
-		g.code.Raw().Var().Id("zipkinURL").Op("=").Id("fs").Dot("String").Call(
-			jen.Lit("zipkin-url"),
-			jen.Lit(""),
-			jen.Lit("Enable Zipkin tracing via a collector URL e.g. http://localhost:9411/api/v1/spans"),
-		)
-		g.code.NewLine()
-		g.code.Raw().Var().Id("lightstepToken").Op("=").Id("fs").Dot("String").Call(
-			jen.Lit("lightstep-token"),
-			jen.Lit(""),
-			jen.Lit("Enable LightStep tracing via a LightStep access token"),
-		)
-		g.code.NewLine()
-		g.code.Raw().Var().Id("appdashAddr").Op("=").Id("fs").Dot("String").Call(
-			jen.Lit("appdash-addr"),
-			jen.Lit(""),
-			jen.Lit("Enable Appdash tracing via an Appdash server host:port"),
-		)
-		g.code.NewLine()
+		if g.otel {
+			g.code.Raw().Var().Id("otelExporter").Op("=").Id("fs").Dot("String").Call(
+				jen.Lit("otel-exporter"),
+				jen.Lit("otlp"),
+				jen.Lit("Export the OpenTelemetry spans with otlp, stdout or none"),
+			)
+			g.code.NewLine()
+			g.code.Raw().Var().Id("otelEndpoint").Op("=").Id("fs").Dot("String").Call(
+				jen.Lit("otel-endpoint"),
+				jen.Lit("localhost:4317"),
+				jen.Lit("OTLP gRPC collector endpoint the otlp exporter sends the spans to"),
+			)
+			g.code.NewLine()
+		} else {
+			g.code.Raw().Var().Id("zipkinURL").Op("=").Id("fs").Dot("String").Call(
+				jen.Lit("zipkin-url"),
+				jen.Lit(""),
+				jen.Lit("Enable Zipkin tracing via a collector URL e.g. http://localhost:9411/api/v1/spans"),
+			)
+			g.code.NewLine()
+			g.code.Raw().Var().Id("lightstepToken").Op("=").Id("fs").Dot("String").Call(
+				jen.Lit("lightstep-token"),
+				jen.Lit(""),
+				jen.Lit("Enable LightStep tracing via a LightStep access token"),
+			)
+			g.code.NewLine()
+			g.code.Raw().Var().Id("appdashAddr").Op("=").Id("fs").Dot("String").Call(
+				jen.Lit("appdash-addr"),
+				jen.Lit(""),
+				jen.Lit("Enable Appdash tracing via an Appdash server host:port"),
+			)
+			g.code.NewLine()
+		}
+		if g.db != nil {
+			g.code.Raw().Var().Id("autoMigrate").Op("=").Id("fs").Dot("Bool").Call(
+				jen.Lit("auto-migrate"),
+				jen.Lit(false),
+				jen.Lit("Migrate the models with gorm AutoMigrate at startup, for development only"),
+			)
+			g.code.NewLine()
+		}
+	}
+	if !g.config {
+		g.generateShutdownTimeout()
+	}
+}
+
+// generateShutdownTimeout generates the drain timeout flag, it is also added to the
+// services generated before the servers were shut down gracefully.
+func (g *generateCmd) generateShutdownTimeout() {
+	for _, v := range g.file.Vars {
+		if v.Name == "shutdownTimeout" {
+			return
+		}
 	}
+	g.code.Raw().Var().Id("shutdownTimeout").Op("=").Id("fs").Dot("Duration").Call(
+		jen.Lit("shutdown-timeout"),
+		jen.Lit(15).Op("*").Qual("time", "Second"),
+		jen.Lit("Time to drain the in-flight requests before the servers are stopped"),
+	)
+	g.code.NewLine()
 }
 func (g *generateCmd) generateInitHTTP() (err error) {
 	for _, v := range g.file.Methods {
@@ -1919,10 +2611,11 @@ func (g *generateCmd) generateInitHTTP() (err error) {
 	}
 
 	pt := NewPartialGenerator(nil)
-	pt.Raw().Id("options").Op(":=").Id("defaultHttpOptions").Call(
-		jen.Id("logger"),
-		jen.Id("tracer"),
-	).Line().Comment("Add your http options here").Line().Line()
+	args := []jen.Code{jen.Id("logger")}
+	if !g.otel {
+		args = append(args, jen.Id("tracer"))
+	}
+	pt.Raw().Id("options").Op(":=").Id("defaultHttpOptions").Call(args...).Line().Comment("Add your http options here").Line().Line()
 	pt.Raw().Id("httpHandler").Op(":=").Qual(httpImport, "NewHTTPHandler").Call(
 		jen.Id("endpoints"),
 		jen.Id("options"),
@@ -1930,11 +2623,11 @@ func (g *generateCmd) generateInitHTTP() (err error) {
 
 	pt.Raw().List(jen.Id("httpListener"), jen.Err()).Op(":=").Qual("net", "Listen").Call(
 		jen.Lit("tcp"),
-		jen.Id("*httpAddr"),
+		g.setting("httpAddr", "Listeners", "HTTP"),
 	).Line()
 	pt.Raw().If(
 		jen.Err().Op("!=").Nil().Block(
-			jen.Id("logger").Dot("Log").Call(
+			logLevel("Error", jen.Id("logger")).Dot("Log").Call(
 				jen.Lit("transport"),
 				jen.Lit("HTTP"),
 				jen.Lit("during"),
@@ -1944,33 +2637,34 @@ func (g *generateCmd) generateInitHTTP() (err error) {
 			),
 		),
 	).Line()
+	pt.Raw().Id("httpServer").Op(":=").Op("&").Qual("net/http", "Server").Values(
+		jen.Dict{
+			jen.Id("Handler"): jen.Id("httpHandler"),
+		},
+	).Line()
 	pt.Raw().Id("g").Dot("Add").Call(
 		jen.Func().Params().Error().Block(
-			jen.Id("logger").Dot("Log").Call(
+			logLevel("Info", jen.Id("logger")).Dot("Log").Call(
 				jen.Lit("transport"),
 				jen.Lit("HTTP"),
 				jen.Lit("addr"),
-				jen.Id("*httpAddr"),
+				g.setting("httpAddr", "Listeners", "HTTP"),
 			),
 			jen.Return(
-				jen.Qual("net/http", "Serve").Call(
+				jen.Id("httpServer").Dot("Serve").Call(
 					jen.Id("httpListener"),
-					jen.Id("httpHandler"),
 				),
 			),
 		),
 		jen.Func().Params(jen.Error()).Block(
-			jen.Id("httpListener").Dot("Close").Call(),
+			g.shutdownHTTPServer("HTTP", "httpServer")...,
 		),
 	).Line()
 	g.code.NewLine()
 	g.code.appendFunction(
 		"initHttpHandler",
 		nil,
-		[]jen.Code{
-			jen.Id("endpoints").Qual(epImport, "Endpoints"),
-			jen.Id("g").Id("*").Qual("github.com/oklog/oklog/pkg/group", "Group"),
-		},
+		g.groupParams(jen.Id("endpoints").Qual(epImport, "Endpoints")),
 		[]jen.Code{},
 		"",
 		pt.Raw(),
@@ -1991,6 +2685,10 @@ func (g *generateCmd) generateInitGRPC() (err error) {
 	if err != nil {
 		return err
 	}
+	healthImport, err := utils.GetHealthImportPath(g.name)
+	if err != nil {
+		return err
+	}
 
 	epImport, err := utils.GetEndpointImportPath(g.name)
 	if err != nil {
@@ -1998,10 +2696,11 @@ func (g *generateCmd) generateInitGRPC() (err error) {
 	}
 
 	pt := NewPartialGenerator(nil)
-	pt.Raw().Id("options").Op(":=").Id("defaultGRPCOptions").Call(
-		jen.Id("logger"),
-		jen.Id("tracer"),
-	).Line().Comment("Add your GRPC options here").Line().Line()
+	args := []jen.Code{jen.Id("logger")}
+	if !g.otel {
+		args = append(args, jen.Id("tracer"))
+	}
+	pt.Raw().Id("options").Op(":=").Id("defaultGRPCOptions").Call(args...).Line().Comment("Add your GRPC options here").Line().Line()
 	pt.Raw().Id("grpcServer").Op(":=").Qual(grpcImport, "NewGRPCServer").Call(
 		jen.Id("endpoints"),
 		jen.Id("options"),
@@ -2009,11 +2708,11 @@ func (g *generateCmd) generateInitGRPC() (err error) {
 
 	pt.Raw().List(jen.Id("grpcListener"), jen.Err()).Op(":=").Qual("net", "Listen").Call(
 		jen.Lit("tcp"),
-		jen.Id("*grpcAddr"),
+		g.setting("grpcAddr", "Listeners", "GRPC"),
 	).Line()
 	pt.Raw().If(
 		jen.Err().Op("!=").Nil().Block(
-			jen.Id("logger").Dot("Log").Call(
+			logLevel("Error", jen.Id("logger")).Dot("Log").Call(
 				jen.Lit("transport"),
 				jen.Lit("gRPC"),
 				jen.Lit("during"),
@@ -2023,18 +2722,22 @@ func (g *generateCmd) generateInitGRPC() (err error) {
 			),
 		),
 	).Line()
+	pt.Raw().Id("baseServer").Op(":=").Qual("google.golang.org/grpc", "NewServer").Call().Line()
+	pt.Raw().Qual(pbImport, fmt.Sprintf("Register%sServer", utils.ToCamelCase(g.name))).Call(
+		jen.Id("baseServer"),
+		jen.Id("grpcServer"),
+	).Line()
+	pt.Raw().Qual(grpcHealthImport, "RegisterHealthServer").Call(
+		jen.Id("baseServer"),
+		jen.Qual(healthImport, "GRPCServer").Call(jen.Qual(healthImport, "DefaultRegistry")),
+	).Line()
 	pt.Raw().Id("g").Dot("Add").Call(
 		jen.Func().Params().Error().Block(
-			jen.Id("logger").Dot("Log").Call(
+			logLevel("Info", jen.Id("logger")).Dot("Log").Call(
 				jen.Lit("transport"),
 				jen.Lit("gRPC"),
 				jen.Lit("addr"),
-				jen.Id("*grpcAddr"),
-			),
-			jen.Id("baseServer").Op(":=").Qual("google.golang.org/grpc", "NewServer").Call(),
-			jen.Qual(pbImport, fmt.Sprintf("Register%sServer", utils.ToCamelCase(g.name))).Call(
-				jen.Id("baseServer"),
-				jen.Id("grpcServer"),
+				g.setting("grpcAddr", "Listeners", "GRPC"),
 			),
 			jen.Return(
 				jen.Id("baseServer").Dot("Serve").Call(
@@ -2043,17 +2746,47 @@ func (g *generateCmd) generateInitGRPC() (err error) {
 			),
 		),
 		jen.Func().Params(jen.Error()).Block(
-			jen.Id("grpcListener").Dot("Close").Call(),
+			logLevel("Info", jen.Id("logger")).Dot("Log").Call(
+				jen.Lit("transport"),
+				jen.Lit("gRPC"),
+				jen.Lit("during"),
+				jen.Lit("GracefulStop"),
+				jen.Lit("timeout"),
+				g.setting("shutdownTimeout", "", "ShutdownTimeout"),
+			),
+			jen.Id("stopped").Op(":=").Make(jen.Chan().Struct()),
+			jen.Go().Func().Params().Block(
+				jen.Id("baseServer").Dot("GracefulStop").Call(),
+				jen.Close(jen.Id("stopped")),
+			).Call(),
+			jen.Select().Block(
+				jen.Case(jen.Op("<-").Id("stopped")).Block(
+					logLevel("Info", jen.Id("logger")).Dot("Log").Call(
+						jen.Lit("transport"),
+						jen.Lit("gRPC"),
+						jen.Lit("status"),
+						jen.Lit("drained"),
+					),
+				),
+				jen.Case(jen.Op("<-").Qual("time", "After").Call(g.setting("shutdownTimeout", "", "ShutdownTimeout"))).Block(
+					logLevel("Warn", jen.Id("logger")).Dot("Log").Call(
+						jen.Lit("transport"),
+						jen.Lit("gRPC"),
+						jen.Lit("during"),
+						jen.Lit("GracefulStop"),
+						jen.Lit("err"),
+						jen.Lit("drain timeout exceeded"),
+					),
+					jen.Id("baseServer").Dot("Stop").Call(),
+				),
+			),
 		),
 	).Line()
 	g.code.NewLine()
 	g.code.appendFunction(
 		"initGRPCHandler",
 		nil,
-		[]jen.Code{
-			jen.Id("endpoints").Qual(epImport, "Endpoints"),
-			jen.Id("g").Id("*").Qual("github.com/oklog/oklog/pkg/group", "Group"),
-		},
+		g.groupParams(jen.Id("endpoints").Qual(epImport, "Endpoints")),
 		[]jen.Code{},
 		"",
 		pt.Raw(),
@@ -2104,7 +2837,6 @@ func (g *generateCmd) generateGetMiddleware() (err error) {
 		).Block(),
 	}
 	if g.generateEndpointDefaultsMiddleware {
-		name := strings.Replace(utils.ToLowerSnakeCase(g.name), "-", "_", -1)
 		c = append(
 			c,
 			jen.Id("duration").Op(":=").Qual("github.com/go-kit/kit/metrics/prometheus", "NewSummaryFrom").Call(
@@ -2112,8 +2844,8 @@ func (g *generateCmd) generateGetMiddleware() (err error) {
 					jen.Dict{
 						jen.Id("Help"):      jen.Lit("Request duration in seconds."),
 						jen.Id("Name"):      jen.Lit("request_duration_seconds"),
-						jen.Id("Namespace"): jen.Lit("example"),
-						jen.Id("Subsystem"): jen.Lit(name),
+						jen.Id("Namespace"): jen.Lit(metricsNamespace(g.name)),
+						jen.Id("Subsystem"): jen.Lit("endpoint"),
 					},
 				),
 				jen.Index().String().Values(jen.Lit("method"), jen.Lit("success")),
@@ -2123,6 +2855,9 @@ func (g *generateCmd) generateGetMiddleware() (err error) {
 			),
 		)
 	}
+	if g.otel {
+		c = append(c, jen.Id("addTracingEndpointMiddleware").Call(jen.Id("mw")))
+	}
 	c = append(
 		c,
 		jen.Comment("Add you endpoint middleware here").Line(),
@@ -2145,28 +2880,42 @@ func (g *generateCmd) generateGetMiddleware() (err error) {
 	)
 	return
 }
-func (g *generateCmd) generateDefaultMetrics() {
+func (g *generateCmd) generateDefaultMetrics() error {
 	if g.generateFirstTime {
+		healthImport, err := utils.GetHealthImportPath(g.name)
+		if err != nil {
+			return err
+		}
 		g.code.NewLine()
 		g.code.appendFunction(
 			"initMetricsEndpoint",
 			nil,
-			[]jen.Code{
-				jen.Id("g").Id("*").Qual("github.com/oklog/oklog/pkg/group", "Group"),
-			},
+			g.groupParams(),
 			[]jen.Code{},
 			"",
 			jen.Qual("net/http", "DefaultServeMux").Dot("Handle").Call(
 				jen.Lit("/metrics"),
 				jen.Qual("github.com/prometheus/client_golang/prometheus/promhttp", "Handler").Call(),
 			),
+			jen.Qual("net/http", "DefaultServeMux").Dot("Handle").Call(
+				jen.Lit("/healthz"),
+				jen.Qual(healthImport, "LivenessHandler").Call(),
+			),
+			jen.Qual("net/http", "DefaultServeMux").Dot("Handle").Call(
+				jen.Lit("/readyz"),
+				jen.Qual(healthImport, "ReadinessHandler").Call(jen.Qual(healthImport, "DefaultRegistry")),
+			),
+			jen.Qual("net/http", "DefaultServeMux").Dot("Handle").Call(
+				jen.Lit("/version"),
+				jen.Qual(healthImport, "VersionHandler").Call(),
+			),
 			jen.List(jen.Id("debugListener"), jen.Err()).Op(":=").Qual("net", "Listen").Call(
 				jen.Lit("tcp"),
-				jen.Id("*debugAddr"),
+				g.setting("debugAddr", "Listeners", "Debug"),
 			),
 			jen.If(
 				jen.Err().Op("!=").Nil().Block(
-					jen.Id("logger").Dot("Log").Call(
+					logLevel("Error", jen.Id("logger")).Dot("Log").Call(
 						jen.Lit("transport"),
 						jen.Lit("debug/HTTP"),
 						jen.Lit("during"),
@@ -2176,27 +2925,82 @@ func (g *generateCmd) generateDefaultMetrics() {
 					),
 				),
 			),
+			jen.Id("debugServer").Op(":=").Op("&").Qual("net/http", "Server").Values(
+				jen.Dict{
+					jen.Id("Handler"): jen.Qual("net/http", "DefaultServeMux"),
+				},
+			),
 			jen.Id("g").Dot("Add").Call(
 				jen.Func().Params().Error().Block(
-					jen.Id("logger").Dot("Log").Call(
+					logLevel("Info", jen.Id("logger")).Dot("Log").Call(
 						jen.Lit("transport"),
 						jen.Lit("debug/HTTP"),
 						jen.Lit("addr"),
-						jen.Id("*debugAddr"),
+						g.setting("debugAddr", "Listeners", "Debug"),
 					),
 					jen.Return(
-						jen.Qual("net/http", "Serve").Call(
+						jen.Id("debugServer").Dot("Serve").Call(
 							jen.Id("debugListener"),
-							jen.Qual("net/http", "DefaultServeMux"),
 						),
 					),
 				),
 				jen.Func().Params(jen.Error()).Block(
-					jen.Id("debugListener").Dot("Close").Call(),
+					g.shutdownHTTPServer("debug/HTTP", "debugServer")...,
 				),
 			),
 		)
 	}
+	return nil
+}
+
+// generateInitEventsRelay generates the function that adds the relay of the outbox to the group.
+func (g *generateCmd) generateInitEventsRelay() error {
+	if !g.events {
+		return nil
+	}
+	for _, v := range g.file.Methods {
+		if v.Name == "initEventsRelay" {
+			return nil
+		}
+	}
+	eventsImport, err := utils.GetEventsImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"initEventsRelay adds the relay that publishes the events of the outbox to the group.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"initEventsRelay",
+		nil,
+		[]jen.Code{
+			jen.Id("d").Op("*").Qual(gormImport, "DB"),
+			jen.Id("g").Op("*").Qual("github.com/oklog/oklog/pkg/group", "Group"),
+		},
+		[]jen.Code{},
+		"",
+		jen.List(jen.Id("publisher"), jen.Err()).Op(":=").Qual(eventsImport, "NewPublisher").Call(),
+		jen.If(jen.Err().Op("!=").Nil()).Block(
+			logLevel("Error", jen.Id("logger")).Dot("Log").Call(jen.Lit("component"), jen.Lit("events"), jen.Lit("err"), jen.Err()),
+			jen.Qual("os", "Exit").Call(jen.Lit(1)),
+		),
+		jen.Id("relay").Op(":=").Qual(eventsImport, "NewRelay").Call(jen.Id("d"), jen.Id("publisher"), jen.Id("logger")),
+		jen.List(jen.Id("ctx"), jen.Id("cancel")).Op(":=").Qual("context", "WithCancel").Call(
+			jen.Qual("context", "Background").Call(),
+		),
+		jen.Id("g").Dot("Add").Call(
+			jen.Func().Params().Error().Block(
+				jen.Defer().Id("publisher").Dot("Close").Call(),
+				logLevel("Info", jen.Id("logger")).Dot("Log").Call(jen.Lit("component"), jen.Lit("events"), jen.Lit("msg"), jen.Lit("relaying the outbox")),
+				jen.Return(jen.Id("relay").Dot("Run").Call(jen.Id("ctx"))),
+			),
+			jen.Func().Params(jen.Error()).Block(jen.Id("cancel").Call()),
+		),
+	)
+	g.code.NewLine()
+	return nil
 }
 func (g *generateCmd) generateCancelInterrupt() {
 	if g.generateFirstTime {
@@ -2243,6 +3047,68 @@ func (g *generateCmd) generateCancelInterrupt() {
 		)
 	}
 }
+
+// setting returns a setting of the service, the field of the loaded configuration
+// or the flag of the services generated before the structured configuration.
+func (g *generateCmd) setting(flag, section, field string) *jen.Statement {
+	if !g.config {
+		return jen.Id("*" + flag)
+	}
+	if section == "" {
+		return jen.Id("conf").Dot(field)
+	}
+	return jen.Id("conf").Dot(section).Dot(field)
+}
+
+// groupParams returns the parameters of the functions adding actors to the group,
+// the configuration is passed along with it.
+func (g *generateCmd) groupParams(params ...jen.Code) []jen.Code {
+	if g.config {
+		params = append(params, jen.Id("conf").Qual(g.configImport, "AppConfig"))
+	}
+	return append(params, jen.Id("g").Id("*").Qual("github.com/oklog/oklog/pkg/group", "Group"))
+}
+
+// shutdownHTTPServer returns the interrupt of an HTTP server actor, it drains the
+// in-flight requests until the shutdown timeout and closes the server after it.
+func (g *generateCmd) shutdownHTTPServer(transport, server string) []jen.Code {
+	return []jen.Code{
+		logLevel("Info", jen.Id("logger")).Dot("Log").Call(
+			jen.Lit("transport"),
+			jen.Lit(transport),
+			jen.Lit("during"),
+			jen.Lit("Shutdown"),
+			jen.Lit("timeout"),
+			g.setting("shutdownTimeout", "", "ShutdownTimeout"),
+		),
+		jen.List(jen.Id("ctx"), jen.Id("cancel")).Op(":=").Qual("context", "WithTimeout").Call(
+			jen.Qual("context", "Background").Call(),
+			g.setting("shutdownTimeout", "", "ShutdownTimeout"),
+		),
+		jen.Defer().Id("cancel").Call(),
+		jen.If(
+			jen.Err().Op(":=").Id(server).Dot("Shutdown").Call(jen.Id("ctx")),
+			jen.Err().Op("!=").Nil(),
+		).Block(
+			logLevel("Error", jen.Id("logger")).Dot("Log").Call(
+				jen.Lit("transport"),
+				jen.Lit(transport),
+				jen.Lit("during"),
+				jen.Lit("Shutdown"),
+				jen.Lit("err"),
+				jen.Err(),
+			),
+			jen.Id(server).Dot("Close").Call(),
+			jen.Return(),
+		),
+		logLevel("Info", jen.Id("logger")).Dot("Log").Call(
+			jen.Lit("transport"),
+			jen.Lit(transport),
+			jen.Lit("status"),
+			jen.Lit("drained"),
+		),
+	}
+}
 func (g *generateCmd) generateCmdMain() error {
 	mainDest := fmt.Sprintf(viper.GetString("gk_cmd_path_format"), utils.ToLowerSnakeCase(g.name))
 	mainFilePath := path.Join(mainDest, "main.go")
@@ -2256,9 +3122,22 @@ func (g *generateCmd) generateCmdMain() error {
 	if err != nil {
 		return err
 	}
+	migration, err := migrationExists(g.fs, g.name)
+	if err != nil {
+		return err
+	}
+	body := []jen.Code{}
+	if migration {
+		body = append(body, jen.If(
+			jen.Len(jen.Qual("os", "Args")).Op(">").Lit(1).Op("&&").
+				Qual("os", "Args").Index(jen.Lit(1)).Op("==").Lit("migrate"),
+		).Block(
+			jen.Qual(cmdSvcImport, "Migrate").Call(jen.Qual("os", "Args").Index(jen.Lit(2), jen.Empty())),
+			jen.Return(),
+		))
+	}
+	body = append(body, jen.Qual(cmdSvcImport, "Run").Call())
 	src := jen.NewFile("main")
-	src.Func().Id("main").Params().Block(
-		jen.Qual(cmdSvcImport, "Run").Call(),
-	)
+	src.Func().Id("main").Params().Block(body...)
 	return g.fs.WriteFile(mainFilePath, src.GoString(), false)
 }
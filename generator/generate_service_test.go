@@ -1 +1,213 @@
 package generator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kujtimiihoxha/kit/parser"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+)
+
+func Test_metricsBuckets(t *testing.T) {
+	setDefaults()
+	defer viper.Set("gk_metrics_buckets", nil)
+	tests := []struct {
+		name    string
+		buckets interface{}
+		want    []float64
+	}{
+		{
+			name:    "Test if the list of numbers is used as is",
+			buckets: []float64{0.1, 1},
+			want:    []float64{0.1, 1},
+		},
+		{
+			name:    "Test if the comma separated buckets are parsed and sorted",
+			buckets: "1, 0.5,bad,0.1",
+			want:    []float64{0.1, 0.5, 1},
+		},
+		{
+			name:    "Test if the buckets of the config file are parsed",
+			buckets: []interface{}{0.25, 2},
+			want:    []float64{0.25, 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Set("gk_metrics_buckets", tt.buckets)
+			if got := metricsBuckets(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("metricsBuckets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateServiceMiddleware_GenerateInstrumenting(t *testing.T) {
+	f := newClientTestFs()
+	f.WriteFile("test/pkg/service/service.go", `package service
+import "context"
+type TestService interface{
+		Foo(ctx context.Context, a string)(b int, err error)
+		Ping(ctx context.Context)(ok bool)
+}`, true)
+	svcSrc, err := f.ReadFile("test/pkg/service/service.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	svcFile, err := parser.NewFileParser().Parse([]byte(svcSrc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := newGenerateServiceMiddleware("test", svcFile, svcFile.Interfaces[0], true).Generate(); err != nil {
+		t.Fatalf("generateServiceMiddleware.Generate() error = %v", err)
+	}
+	src, err := f.ReadFile("test/pkg/service/middleware.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		"func InstrumentingMiddleware(requests, errors metrics.Counter, duration metrics.Histogram) Middleware",
+		`lvs := []string{"method", "Foo", "error_kind", errorKind(err)}`,
+		`lvs := []string{"method", "Ping", "error_kind", "none"}`,
+		"i.errors.With(lvs...).Add(1)",
+		"i.duration.With(lvs...).Observe(time.Since(begin).Seconds())",
+		"return string(KindOf(err))",
+	} {
+		if !strings.Contains(src, v) {
+			t.Errorf("generateServiceMiddleware.Generate() middleware.go does not contain %q", v)
+		}
+	}
+	if err := newGenerateCmdBase("test", svcFile.Interfaces[0], true, false, nil).Generate(); err != nil {
+		t.Fatalf("generateCmdBase.Generate() error = %v", err)
+	}
+	src, err = f.ReadFile("test/cmd/service/service_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		"var serviceDurationBuckets = []float64{0.005, 0.01, 0.025",
+		`Namespace: "test"`,
+		`Subsystem: "service"`,
+		"Buckets:   serviceDurationBuckets",
+		"service.InstrumentingMiddleware(requests, errors, duration)",
+	} {
+		if !strings.Contains(src, v) {
+			t.Errorf("generateCmdBase.Generate() service_gen.go does not contain %q", v)
+		}
+	}
+}
+
+func TestGenerateCmd_GenerateGracefulShutdown(t *testing.T) {
+	f := newClientTestFs()
+	f.MkdirAll("test/pkg/http")
+	f.WriteFile("test/pkg/http/handler.go", `package http
+func makeFooHandler() {}`, true)
+	if err := NewGenerateTracing("test").Generate(); err != nil {
+		t.Fatalf("GenerateTracing.Generate() error = %v", err)
+	}
+	svcSrc, err := f.ReadFile("test/pkg/service/service.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	svcFile, err := parser.NewFileParser().Parse([]byte(svcSrc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := newGenerateCmd("test", "", svcFile.Interfaces[0], false, false, nil).Generate(); err != nil {
+		t.Fatalf("generateCmd.Generate() error = %v", err)
+	}
+	src, err := f.ReadFile("test/cmd/service/service.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		`var shutdownTimeout = fs.Duration("shutdown-timeout", 15*time.Second`,
+		"return httpServer.Serve(httpListener)",
+		"if err := httpServer.Shutdown(ctx); err != nil",
+		"return debugServer.Serve(debugListener)",
+		"if err := tp.Shutdown(ctx); err != nil",
+		"if err := d.Close(); err != nil",
+	} {
+		if !strings.Contains(src, v) {
+			t.Errorf("generateCmd.Generate() service.go does not contain %q", v)
+		}
+	}
+	if strings.Contains(src, "Listener.Close()") || strings.Contains(src, "defer tp.Shutdown") {
+		t.Errorf("generateCmd.Generate() should not close the listeners or defer the tracer shutdown, got %s", src)
+	}
+}
+
+func TestGenerateService_GenerateKeepsUserCode(t *testing.T) {
+	f := newClientTestFs()
+	f.WriteFile("test/pkg/service/service.go", `package service
+
+// TestService describes the service.
+type TestService interface {
+	// Add your methods here
+}
+`, true)
+	// The user adds a method and a type after the interface without kit.
+	afero.WriteFile(f.Fs, "test/pkg/service/service.go", []byte(`package service
+
+import "context"
+
+// TestService describes the service.
+type TestService interface {
+	Foo(ctx context.Context, a string) (b string, err error)
+}
+
+type Todo struct{Title string}
+`), 0644)
+	if err := NewGenerateService("test", "http", "", "", false, false, false, nil).Generate(); err != nil {
+		t.Fatalf("GenerateService.Generate() error = %v", err)
+	}
+	src, err := f.ReadFile("test/pkg/service/service.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(src, "<<<<<<<") {
+		t.Errorf("GenerateService.Generate() should not leave conflict markers, got %s", src)
+	}
+	for _, v := range []string{"type Todo struct{ Title string }", "type basicTestService struct", ") Foo(ctx context.Context, a string) (b string, err error) {"} {
+		if !strings.Contains(src, v) {
+			t.Errorf("GenerateService.Generate() service.go does not contain %q, got %s", v, src)
+		}
+	}
+}
+
+func TestGenerateService_GenerateStreamEndpoints(t *testing.T) {
+	f := newClientTestFs()
+	f.WriteFile("test/pkg/service/service.go", `package service
+
+import "context"
+
+type Item struct{ Name string }
+
+type Events <-chan Item
+
+// TestService describes the service.
+type TestService interface {
+	Watch(ctx context.Context, a string) (items <-chan Item, err error)
+	// kit:stream events:"Item"
+	Subscribe(ctx context.Context, topic string) (events Events, err error)
+}
+`, true)
+	if err := NewGenerateService("test", "grpc", "", "", false, false, false, nil).Generate(); err != nil {
+		t.Fatalf("GenerateService.Generate() error = %v", err)
+	}
+	src, err := f.ReadFile("test/pkg/endpoint/endpoint.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		"Items <-chan service.Item `json:\"-\"`",
+		"Events service.Events `json:\"-\"`",
+		"`json:\"topic\"`",
+	} {
+		if !strings.Contains(src, v) {
+			t.Errorf("GenerateService.Generate() endpoint.go does not contain %q", v)
+		}
+	}
+}
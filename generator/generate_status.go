@@ -0,0 +1,509 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/kujtimiihoxha/kit/fs"
+	"github.com/kujtimiihoxha/kit/parser"
+	"github.com/kujtimiihoxha/kit/utils"
+	"github.com/spf13/viper"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// StatusGroup is the group of the status catalog the typed GenStatus struct is
+// generated from and the new statuses are added to.
+const StatusGroup = "gen"
+
+var statusKeyRegexp = regexp.MustCompile("^[a-z][a-z0-9_]*$")
+
+// statusEntry is a status of the status catalog.
+type statusEntry struct {
+	Code    int    `yaml:"code"`
+	Status  int    `yaml:"status"`
+	Message string `yaml:"message"`
+}
+
+// GenerateStatusEntry adds a status to the status catalog of the service and
+// regenerates the typed statuses.
+type GenerateStatusEntry struct {
+	BaseGenerator
+	name       string
+	key        string
+	code       int
+	httpStatus int
+	message    string
+	filePath   string
+}
+
+// NewGenerateStatusEntry returns a initialized and ready generator, the code of
+// the status is the next free code of the catalog if it is 0.
+func NewGenerateStatusEntry(name, key string, code, httpStatus int, message string) Gen {
+	i := &GenerateStatusEntry{
+		name:       name,
+		key:        key,
+		code:       code,
+		httpStatus: httpStatus,
+		message:    message,
+		filePath:   statusCatalogPath(name),
+	}
+	i.fs = fs.Get()
+	return i
+}
+
+// Generate appends the status to the group of the catalog and regenerates the
+// typed statuses.
+func (g *GenerateStatusEntry) Generate() (err error) {
+	if !statusKeyRegexp.MatchString(g.key) {
+		return fmt.Errorf("the status key `%s` must be lower snake case", g.key)
+	}
+	if g.httpStatus < 100 || g.httpStatus > 599 {
+		return fmt.Errorf("the http status `%d` is not valid", g.httpStatus)
+	}
+	if g.message == "" {
+		return errors.New("the status needs a message")
+	}
+	if b, err := g.fs.Exists(g.filePath); err != nil {
+		return err
+	} else if !b {
+		return fmt.Errorf("the status catalog of service %s was not found", g.name)
+	}
+	src, err := g.fs.ReadFile(g.filePath)
+	if err != nil {
+		return err
+	}
+	keys, entries, err := parseStatusCatalog(src)
+	if err != nil {
+		return err
+	}
+	if _, ok := entries[g.key]; ok {
+		return fmt.Errorf("the status `%s` already exists", g.key)
+	}
+	if g.code == 0 {
+		for _, k := range keys {
+			if entries[k].Code >= g.code {
+				g.code = entries[k].Code + 1
+			}
+		}
+	}
+	for _, k := range keys {
+		if entries[k].Code == g.code {
+			return fmt.Errorf("the code `%d` is already used by the status `%s`", g.code, k)
+		}
+	}
+	src = insertStatusEntry(src, g.key, statusEntry{Code: g.code, Status: g.httpStatus, Message: g.message})
+	if err = g.fs.WriteFile(g.filePath, src, true); err != nil {
+		return err
+	}
+	return newGenerateStatusCatalog(g.name).Generate()
+}
+
+// parseStatusCatalog returns the keys of the group in the order of the catalog
+// and the statuses of the group.
+func parseStatusCatalog(src string) ([]string, map[string]statusEntry, error) {
+	ordered := yaml.MapSlice{}
+	if err := yaml.Unmarshal([]byte(src), &ordered); err != nil {
+		return nil, nil, err
+	}
+	catalog := map[string]map[string]statusEntry{}
+	if err := yaml.Unmarshal([]byte(src), &catalog); err != nil {
+		return nil, nil, err
+	}
+	keys := []string{}
+	for _, v := range ordered {
+		if fmt.Sprint(v.Key) != StatusGroup {
+			continue
+		}
+		group, _ := v.Value.(yaml.MapSlice)
+		for _, s := range group {
+			keys = append(keys, fmt.Sprint(s.Key))
+		}
+	}
+	entries := catalog[StatusGroup]
+	if entries == nil {
+		entries = map[string]statusEntry{}
+	}
+	return keys, entries, nil
+}
+
+// insertStatusEntry adds the status at the end of the group, the rest of the
+// catalog is kept as it is.
+func insertStatusEntry(src, key string, e statusEntry) string {
+	entry := []string{
+		fmt.Sprintf("  %s:", key),
+		fmt.Sprintf("    code: %d", e.Code),
+		fmt.Sprintf("    status: %d", e.Status),
+		fmt.Sprintf("    message: %s", strconv.Quote(e.Message)),
+	}
+	lines := strings.Split(src, "\n")
+	start := -1
+	for i, v := range lines {
+		if strings.TrimRight(v, " \t") == StatusGroup+":" {
+			start = i
+			break
+		}
+	}
+	if start == -1 {
+		src = strings.TrimRight(src, "\n")
+		if src != "" {
+			src += "\n\n"
+		}
+		return src + StatusGroup + ":\n" + strings.Join(entry, "\n") + "\n"
+	}
+	end := len(lines)
+	for i := start + 1; i < len(lines); i++ {
+		if v := lines[i]; v != "" && v[0] != ' ' && v[0] != '\t' && v[0] != '#' {
+			end = i
+			break
+		}
+	}
+	for end > start+1 && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+	if end > start+1 {
+		entry = append([]string{""}, entry...)
+	}
+	res := append(append(append([]string{}, lines[:end]...), entry...), lines[end:]...)
+	src = strings.Join(res, "\n")
+	if !strings.HasSuffix(src, "\n") {
+		src += "\n"
+	}
+	return src
+}
+
+// statusCatalogPath returns the path of the status catalog of the service.
+func statusCatalogPath(name string) string {
+	return path.Join(
+		fmt.Sprintf(viper.GetString("gk_config_path_format"), utils.ToLowerSnakeCase(name)),
+		viper.GetString("gk_status_file_name"),
+	)
+}
+
+// statusCatalogExists returns true if the service has a status catalog.
+func statusCatalogExists(f *fs.KitFs, name string) (bool, error) {
+	return f.Exists(statusCatalogPath(name))
+}
+
+type generateStatusCatalog struct {
+	BaseGenerator
+	name     string
+	destPath string
+	filePath string
+}
+
+func newGenerateStatusCatalog(name string) Gen {
+	i := &generateStatusCatalog{
+		name:     name,
+		destPath: fmt.Sprintf(viper.GetString("gk_utils_path_format"), utils.ToLowerSnakeCase(name)),
+	}
+	i.filePath = path.Join(i.destPath, viper.GetString("gk_utils_status_file_name"))
+	i.srcFile = jen.NewFilePath(strings.Replace(i.destPath, "\\", "/", -1))
+	i.InitPg()
+	i.fs = fs.Get()
+	return i
+}
+
+// Generate generates the typed statuses of the status catalog, a field of
+// GenStatus for every status of the group and a lookup by key.
+func (g *generateStatusCatalog) Generate() (err error) {
+	err = g.CreateFolderStructure(g.destPath)
+	if err != nil {
+		return err
+	}
+	src, err := g.fs.ReadFile(statusCatalogPath(g.name))
+	if err != nil {
+		return err
+	}
+	keys, _, err := parseStatusCatalog(src)
+	if err != nil {
+		return err
+	}
+	fields := []jen.Code{}
+	cases := []jen.Code{}
+	badRequest := false
+	for _, k := range keys {
+		fields = append(fields, jen.Id(utils.ToCamelCase(k)).Id("Status").Tag(map[string]string{"yaml": k}))
+		cases = append(cases, jen.Case(jen.Lit(k)).Block(
+			jen.Return(jen.Id("s").Dot(utils.ToCamelCase(k)), jen.True()),
+		))
+		badRequest = badRequest || k == "bad_request"
+	}
+	catalog := strings.TrimPrefix(
+		statusCatalogPath(g.name),
+		utils.ToLowerSnakeCase(g.name)+"/",
+	)
+	g.srcFile.PackageComment("THIS FILE IS AUTO GENERATED BY GK-CLI DO NOT EDIT!!")
+	g.srcFile.ImportAlias("gopkg.in/yaml.v3", "yaml")
+	g.code.appendMultilineComment([]string{
+		"Status is the format of the statuses of the status catalog.",
+	})
+	g.code.NewLine()
+	g.code.Raw().Type().Id("Status").Op("=").Qual("github.com/praslar/common/response", "ResponseStatus").Line()
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		fmt.Sprintf("GenStatus are the statuses of the `%s` group of the status catalog, use", StatusGroup),
+		"`kit g status add` to add a status.",
+	})
+	g.code.NewLine()
+	g.code.appendStruct("GenStatus", fields...)
+	g.code.NewLine()
+	g.code.appendStruct(
+		"statuses",
+		jen.Id("Gen").Id("GenStatus").Tag(map[string]string{"yaml": StatusGroup}),
+	)
+	g.code.NewLine()
+	g.code.Raw().Var().Defs(
+		jen.Id("all").Id("*statuses"),
+		jen.Id("once").Qual("sync", "Once"),
+	).Line()
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"Init load statuses from the given config file.",
+		"Init panics if cannot access or error while parsing the config file.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"Init",
+		nil,
+		[]jen.Code{jen.Id("conf").String()},
+		[]jen.Code{},
+		"",
+		jen.Id("once").Dot("Do").Call(jen.Func().Params().Block(
+			jen.List(jen.Id("f"), jen.Err()).Op(":=").Qual("os", "Open").Call(jen.Id("conf")),
+			jen.If(jen.Err().Op("!=").Nil()).Block(
+				jen.Qual("github.com/sirupsen/logrus", "Errorf").Call(jen.Lit("Fail to open status file, %v"), jen.Err()),
+				jen.Panic(jen.Err()),
+			),
+			jen.Id("all").Op("=").Op("&").Id("statuses").Values(),
+			jen.If(
+				jen.Err().Op(":=").Qual("gopkg.in/yaml.v3", "NewDecoder").Call(jen.Id("f")).Dot("Decode").Call(jen.Id("all")),
+				jen.Err().Op("!=").Nil(),
+			).Block(
+				jen.Qual("github.com/sirupsen/logrus", "Errorf").Call(jen.Lit("Fail to parse status file data to statuses struct, %v"), jen.Err()),
+				jen.Panic(jen.Err()),
+			),
+		)),
+	)
+	g.code.NewLine()
+	load := []jen.Code{
+		jen.Id("conf").Op(":=").Qual("os", "Getenv").Call(jen.Lit("STATUS_PATH")),
+		jen.If(jen.Id("conf").Op("==").Lit("")).Block(
+			jen.Id("conf").Op("=").Lit(catalog),
+		),
+		jen.Id("Init").Call(jen.Id("conf")),
+		jen.Return(jen.Id("all")),
+	}
+	g.code.appendMultilineComment([]string{
+		"load returns all the statuses, the catalog is loaded from the STATUS_PATH",
+		fmt.Sprintf("environment variable or %s if it is not loaded yet.", catalog),
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"load",
+		nil,
+		[]jen.Code{},
+		[]jen.Code{},
+		"*statuses",
+		load...,
+	)
+	g.code.NewLine()
+	gen := []jen.Code{
+		jen.Id("s").Op(":=").Id("load").Call().Dot("Gen"),
+	}
+	comment := []string{
+		fmt.Sprintf("Gen returns a copy of the statuses of the `%s` group.", StatusGroup),
+	}
+	if badRequest {
+		// The statuses are shared by all the requests, the message of the request
+		// only goes to the copy.
+		gen = append(gen, jen.If(jen.Err().Op("!=").Lit("")).Block(
+			jen.Id("s").Dot("BadRequest").Dot("XMessage").Op("=").Err(),
+		))
+		comment = []string{
+			fmt.Sprintf("Gen returns a copy of the statuses of the `%s` group, the message of", StatusGroup),
+			"BadRequest is err if it is not empty.",
+		}
+	}
+	gen = append(gen, jen.Return(jen.Id("s")))
+	g.code.appendMultilineComment(comment)
+	g.code.NewLine()
+	g.code.appendFunction(
+		"Gen",
+		nil,
+		[]jen.Code{jen.Err().String()},
+		[]jen.Code{},
+		"GenStatus",
+		gen...,
+	)
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		fmt.Sprintf("Lookup returns the status of the key in the `%s` group.", StatusGroup),
+	})
+	g.code.NewLine()
+	body := []jen.Code{}
+	if len(cases) > 0 {
+		body = append(body,
+			jen.Id("s").Op(":=").Id("load").Call().Dot("Gen"),
+			jen.Switch(jen.Id("key")).Block(cases...),
+		)
+	}
+	body = append(body, jen.Return(jen.Id("Status").Values(), jen.False()))
+	g.code.appendFunction(
+		"Lookup",
+		nil,
+		[]jen.Code{jen.Id("key").String()},
+		[]jen.Code{jen.Id("Status"), jen.Bool()},
+		"",
+		body...,
+	)
+	g.code.NewLine()
+	return g.fs.WriteFile(g.filePath, g.srcFile.GoString(), true)
+}
+
+type generateHTTPStatusEncoder struct {
+	BaseGenerator
+	name     string
+	destPath string
+	filePath string
+}
+
+func newGenerateHTTPStatusEncoder(name string) Gen {
+	i := &generateHTTPStatusEncoder{
+		name:     name,
+		destPath: fmt.Sprintf(viper.GetString("gk_http_path_format"), utils.ToLowerSnakeCase(name)),
+	}
+	i.filePath = path.Join(i.destPath, viper.GetString("gk_http_status_file_name"))
+	i.srcFile = jen.NewFilePath(i.destPath)
+	i.InitPg()
+	i.fs = fs.Get()
+	return i
+}
+
+// Generate generates the error encoder that encodes the service errors with the
+// status of the status catalog their kind maps to.
+func (g *generateHTTPStatusEncoder) Generate() (err error) {
+	src, err := g.fs.ReadFile(path.Join(g.destPath, viper.GetString("gk_http_file_name")))
+	if err != nil {
+		return err
+	}
+	f, err := parser.NewFileParser().Parse([]byte(src))
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, v := range f.Methods {
+		found = found || v.Name == "ErrorEncoder"
+	}
+	if !found {
+		return nil
+	}
+	serviceImport, err := utils.GetServiceImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	utilsImport, err := utils.GetUtilsImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	keys := jen.Dict{
+		jen.Qual(serviceImport, "KindUnknown"): jen.Lit("internal"),
+	}
+	for _, v := range errorKinds {
+		keys[jen.Qual(serviceImport, "Kind"+v.name)] = jen.Lit(v.statusKey)
+	}
+	g.srcFile.PackageComment("THIS FILE IS AUTO GENERATED BY GK-CLI DO NOT EDIT!!")
+	g.code.appendMultilineComment([]string{
+		"statusKeys maps the kinds of the service errors to the keys of the status catalog.",
+	})
+	g.code.NewLine()
+	g.code.Raw().Var().Id("statusKeys").Op("=").Map(jen.Qual(serviceImport, "Kind")).String().Values(keys).Line()
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"statusKeyer is implemented by the service errors that carry the key of their",
+		"status in the status catalog.",
+	})
+	g.code.NewLine()
+	g.code.Raw().Type().Id("statusKeyer").Interface(jen.Id("StatusKey").Params().String()).Line()
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"statusWrapper is the body of the errors encoded with the status catalog, it",
+		"extends errorWrapper so ErrorDecoder still decodes it.",
+	})
+	g.code.NewLine()
+	g.code.appendStruct(
+		"statusWrapper",
+		jen.Id("Code").Int().Tag(map[string]string{"json": "code"}),
+		jen.Id("Message").String().Tag(map[string]string{"json": "message"}),
+		jen.Id("Error").String().Tag(map[string]string{"json": "error"}),
+		jen.Id("Kind").Qual(serviceImport, "Kind").Tag(map[string]string{"json": "kind,omitempty"}),
+	)
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"StatusErrorEncoder encodes the error with the code, http status and message of",
+		"the status of the key it carries or else the status its kind maps to, the",
+		"errors without a status in the catalog are encoded by ErrorEncoder.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"StatusErrorEncoder",
+		nil,
+		[]jen.Code{
+			jen.Id("ctx").Qual("context", "Context"),
+			jen.Err().Error(),
+			jen.Id("w").Qual("net/http", "ResponseWriter"),
+		},
+		[]jen.Code{},
+		"",
+		jen.Id("kind").Op(":=").Qual(serviceImport, "KindOf").Call(jen.Err()),
+		jen.List(jen.Id("s"), jen.Id("ok")).Op(":=").Id("lookupStatus").Call(jen.Err(), jen.Id("kind")),
+		jen.If(jen.Op("!").Id("ok")).Block(
+			jen.Id("ErrorEncoder").Call(jen.Id("ctx"), jen.Err(), jen.Id("w")),
+			jen.Return(),
+		),
+		jen.Id("w").Dot("Header").Call().Dot("Set").Call(
+			jen.Lit("Content-Type"), jen.Lit("application/json; charset=utf-8"),
+		),
+		jen.Id("w").Dot("WriteHeader").Call(jen.Id("s").Dot("XStatus")),
+		jen.Qual("encoding/json", "NewEncoder").Call(jen.Id("w")).Dot("Encode").Call(
+			jen.Id("statusWrapper").Values(jen.Dict{
+				jen.Id("Code"):    jen.Id("s").Dot("XCode"),
+				jen.Id("Message"): jen.Id("s").Dot("XMessage"),
+				jen.Id("Error"):   jen.Err().Dot("Error").Call(),
+				jen.Id("Kind"):    jen.Id("kind"),
+			}),
+		),
+	)
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"lookupStatus returns the status of the key the error carries, the status the",
+		"kind maps to if the error carries no key of the catalog.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"lookupStatus",
+		nil,
+		[]jen.Code{
+			jen.Err().Error(),
+			jen.Id("kind").Qual(serviceImport, "Kind"),
+		},
+		[]jen.Code{jen.Qual(utilsImport, "Status"), jen.Bool()},
+		"",
+		jen.Var().Id("e").Id("statusKeyer"),
+		jen.If(jen.Qual("errors", "As").Call(jen.Err(), jen.Op("&").Id("e"))).Block(
+			jen.If(
+				jen.List(jen.Id("s"), jen.Id("ok")).Op(":=").Qual(utilsImport, "Lookup").Call(jen.Id("e").Dot("StatusKey").Call()),
+				jen.Id("ok"),
+			).Block(
+				jen.Return(jen.Id("s"), jen.True()),
+			),
+		),
+		jen.Return(jen.Qual(utilsImport, "Lookup").Call(jen.Id("statusKeys").Index(jen.Id("kind")))),
+	)
+	g.code.NewLine()
+	return g.fs.WriteFile(g.filePath, g.srcFile.GoString(), true)
+}
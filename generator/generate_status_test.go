@@ -0,0 +1,124 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_insertStatusEntry(t *testing.T) {
+	e := statusEntry{Code: 3, Status: 409, Message: "Conflict"}
+	entry := "  conflict:\n    code: 3\n    status: 409\n    message: \"Conflict\"\n"
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "Test if the status is appended to the group",
+			src:  "gen:\n  success:\n    code: 1\n\n",
+			want: "gen:\n  success:\n    code: 1\n\n" + entry + "\n",
+		},
+		{
+			name: "Test if the status is added before the next group",
+			src:  "gen:\n  success:\n    code: 1\n\nother:\n  a:\n    code: 2\n",
+			want: "gen:\n  success:\n    code: 1\n\n" + entry + "\nother:\n  a:\n    code: 2\n",
+		},
+		{
+			name: "Test if the group is created",
+			src:  "other:\n  a:\n    code: 2\n",
+			want: "other:\n  a:\n    code: 2\n\ngen:\n" + entry,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := insertStatusEntry(tt.src, "conflict", e); got != tt.want {
+				t.Errorf("insertStatusEntry() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateStatusEntry_Generate(t *testing.T) {
+	f := newClientTestFs()
+	f.MkdirAll("test/config")
+	f.WriteFile("test/config/status.yml", `gen:
+  success:
+    code: 1001
+    status: 200
+    message: "Success"
+
+  bad_request:
+    code: 1004
+    status: 400
+    message: "Invalid input"
+`, true)
+	if err := NewGenerateStatusEntry("test", "not_found", 0, 404, "Not found").Generate(); err != nil {
+		t.Fatalf("GenerateStatusEntry.Generate() error = %v", err)
+	}
+	src, err := f.ReadFile("test/config/status.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(src, "\n\n  not_found:\n    code: 1005\n    status: 404\n    message: \"Not found\"\n") {
+		t.Errorf("GenerateStatusEntry.Generate() catalog = %q", src)
+	}
+	src, err = f.ReadFile("test/pkg/utils/status.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		"NotFound   Status `yaml:\"not_found\"`",
+		"s := load().Gen\n\tif err != \"\" {\n\t\ts.BadRequest.XMessage = err",
+		`conf = "config/status.yml"`,
+		"func Lookup(key string) (Status, bool)",
+		"return s.NotFound, true",
+	} {
+		if !strings.Contains(src, v) {
+			t.Errorf("GenerateStatusEntry.Generate() statuses do not contain %q", v)
+		}
+	}
+	if strings.Contains(src, "all.Gen.BadRequest.XMessage") {
+		t.Error("GenerateStatusEntry.Generate() should not write the message of the request into the shared statuses")
+	}
+	for _, v := range []struct {
+		key, message string
+		code, status int
+	}{
+		{"not_found", "Not found", 0, 404},
+		{"NotFound", "Not found", 0, 404},
+		{"gone", "Gone", 1001, 410},
+		{"gone", "Gone", 0, 1000},
+	} {
+		if err := NewGenerateStatusEntry("test", v.key, v.code, v.status, v.message).Generate(); err == nil {
+			t.Errorf("GenerateStatusEntry.Generate() should fail for %+v", v)
+		}
+	}
+}
+
+func TestGenerateHTTPStatusEncoder_Generate(t *testing.T) {
+	f := newClientTestFs()
+	f.MkdirAll("test/config")
+	f.WriteFile("test/config/status.yml", "gen:\n  bad_request:\n    code: 1004\n    status: 400\n    message: \"Invalid input\"\n", true)
+	f.MkdirAll("test/pkg/http")
+	f.WriteFile("test/pkg/http/handler.go", "package http\nfunc ErrorEncoder() {}\n", true)
+	if err := NewGenerateStatusEntry("test", "gone", 0, 410, "Gone").Generate(); err != nil {
+		t.Fatalf("GenerateStatusEntry.Generate() error = %v", err)
+	}
+	if err := newGenerateHTTPStatusEncoder("test").Generate(); err != nil {
+		t.Fatalf("generateHTTPStatusEncoder.Generate() error = %v", err)
+	}
+	src, err := f.ReadFile("test/pkg/http/status_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		"s, ok := lookupStatus(err, kind)",
+		// The key the error carries is looked up before the key of its kind.
+		"if errors.As(err, &e) {\n\t\tif s, ok := utils.Lookup(e.StatusKey()); ok {",
+		"return utils.Lookup(statusKeys[kind])",
+	} {
+		if !strings.Contains(src, v) {
+			t.Errorf("generateHTTPStatusEncoder.Generate() does not contain %q", v)
+		}
+	}
+}
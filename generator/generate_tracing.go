@@ -0,0 +1,587 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	ps "go/parser"
+	"go/token"
+	"path"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/kujtimiihoxha/kit/fs"
+	"github.com/kujtimiihoxha/kit/parser"
+	"github.com/kujtimiihoxha/kit/utils"
+	"github.com/spf13/viper"
+)
+
+// The tracers the generated services can use.
+const (
+	TracingOpenTracing = "opentracing"
+	TracingOTel        = "otel"
+)
+
+// SupportedTracings are the tracers the generated services can use.
+var SupportedTracings = []string{TracingOpenTracing, TracingOTel}
+
+const (
+	kitHTTPImport = "github.com/go-kit/kit/transport/http"
+	kitGRPCImport = "github.com/go-kit/kit/transport/grpc"
+	otelImport    = "go.opentelemetry.io/otel"
+	otelTrace     = "go.opentelemetry.io/otel/trace"
+	otelAttribute = "go.opentelemetry.io/otel/attribute"
+	otelProp      = "go.opentelemetry.io/otel/propagation"
+	otelCodes     = "go.opentelemetry.io/otel/codes"
+	otelSdkTrace  = "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// GenerateTracing implements Gen and is used to generate the OpenTelemetry
+// tracing package of a service.
+type GenerateTracing struct {
+	BaseGenerator
+	name              string
+	destPath          string
+	filePath          string
+	file              *parser.File
+	generateFirstTime bool
+}
+
+// NewGenerateTracing returns a generator of the OpenTelemetry tracing package.
+func NewGenerateTracing(name string) Gen {
+	i := &GenerateTracing{
+		name:     name,
+		destPath: fmt.Sprintf(viper.GetString("gk_tracing_path_format"), utils.ToLowerSnakeCase(name)),
+	}
+	i.filePath = path.Join(i.destPath, viper.GetString("gk_tracing_file_name"))
+	i.srcFile = jen.NewFilePath(i.destPath)
+	i.srcFile.ImportAlias(kitHTTPImport, "kithttp")
+	i.srcFile.ImportAlias(kitGRPCImport, "kitgrpc")
+	i.srcFile.ImportAlias(otelCodes, "otelcodes")
+	i.srcFile.ImportAlias(otelSdkTrace, "sdktrace")
+	i.InitPg()
+	i.fs = fs.Get()
+	return i
+}
+
+// Generate generates the tracer provider setup, the endpoint middleware and the
+// transport options that are missing from the tracing package.
+func (g *GenerateTracing) Generate() (err error) {
+	err = g.CreateFolderStructure(g.destPath)
+	if err != nil {
+		return err
+	}
+	if b, err := g.fs.Exists(g.filePath); err != nil {
+		return err
+	} else if !b {
+		if err = g.checkOpenTracing(); err != nil {
+			return err
+		}
+		g.generateFirstTime = true
+		f := jen.NewFile("tracing")
+		g.fs.WriteFile(g.filePath, f.GoString(), false)
+	}
+	src, err := g.fs.ReadFile(g.filePath)
+	if err != nil {
+		return err
+	}
+	g.file, err = parser.NewFileParser().Parse([]byte(src))
+	if err != nil {
+		return err
+	}
+	found := map[string]bool{}
+	for _, v := range g.file.Methods {
+		if v.Struct.Type != "" {
+			found[v.Struct.Type] = true
+			continue
+		}
+		found[v.Name] = true
+	}
+	for _, v := range g.file.Constants {
+		found[v.Name] = true
+	}
+	if !found["ServiceName"] {
+		g.code.appendMultilineComment([]string{
+			"ServiceName is the name the service reports its spans with.",
+		})
+		g.code.NewLine()
+		g.code.Raw().Const().Id("ServiceName").Op("=").Lit(utils.ToLowerSnakeCase(g.name)).Line()
+		g.code.NewLine()
+	}
+	if !found["NewTracerProvider"] {
+		g.generateNewTracerProvider()
+	}
+	if !found["EndpointMiddleware"] {
+		g.generateEndpointMiddleware()
+	}
+	if !found["HTTPServerTrace"] {
+		g.generateHTTPServerTrace()
+	}
+	if !found["HTTPClientTrace"] {
+		g.generateHTTPClientTrace()
+	}
+	if !found["GRPCServerTrace"] {
+		g.generateGRPCServerTrace()
+	}
+	if !found["GRPCClientTrace"] {
+		g.generateGRPCClientTrace()
+	}
+	if !found["metadataCarrier"] {
+		g.generateMetadataCarrier()
+	}
+	if !found["setError"] {
+		g.code.appendMultilineComment([]string{
+			"setError marks the span as failed if err is not nil.",
+		})
+		g.code.NewLine()
+		g.code.appendFunction(
+			"setError",
+			nil,
+			[]jen.Code{
+				jen.Id("span").Qual(otelTrace, "Span"),
+				jen.Err().Error(),
+			},
+			[]jen.Code{},
+			"",
+			jen.If(jen.Err().Op("==").Nil()).Block(
+				jen.Return(),
+			),
+			jen.Id("span").Dot("RecordError").Call(jen.Err()),
+			jen.Id("span").Dot("SetStatus").Call(
+				jen.Qual(otelCodes, "Error"),
+				jen.Err().Dot("Error").Call(),
+			),
+		)
+		g.code.NewLine()
+	}
+	if g.generateFirstTime {
+		return g.fs.WriteFile(g.filePath, g.srcFile.GoString(), true)
+	}
+	pSrc, err := g.partialSource()
+	if err != nil {
+		return err
+	}
+	src += "\n" + pSrc
+	tmpSrc := g.srcFile.GoString()
+	f, err := parser.NewFileParser().Parse([]byte(tmpSrc))
+	if err != nil {
+		return err
+	}
+	imp, err := g.getMissingImports(f.Imports, g.file)
+	if err != nil {
+		return err
+	}
+	if len(imp) > 0 {
+		src, err = g.AddImportsToFile(imp, src)
+		if err != nil {
+			return err
+		}
+	}
+	s, err := utils.GoImportsSource(g.destPath, src)
+	if err != nil {
+		return err
+	}
+	return g.fs.WriteFile(g.filePath, s, true)
+}
+
+func (g *GenerateTracing) generateNewTracerProvider() {
+	exporterCase := func(name string, exporter *jen.Statement, option string) jen.Code {
+		return jen.Case(jen.Lit(name)).Block(
+			jen.List(jen.Id("exp"), jen.Err()).Op(":=").Add(exporter),
+			jen.If(jen.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Nil(), jen.Err()),
+			),
+			jen.Id("opts").Op("=").Append(
+				jen.Id("opts"),
+				jen.Qual(otelSdkTrace, option).Call(jen.Id("exp")),
+			),
+		)
+	}
+	g.code.appendMultilineComment([]string{
+		"NewTracerProvider returns the tracer provider of the service and registers it",
+		"as the global tracer provider along with the W3C trace context propagator.",
+		"The exporter is `otlp` to send the spans to the OTLP gRPC collector at",
+		"endpoint, `stdout` to print them during local development or `none`.",
+	})
+	g.code.NewLine()
+	g.code.Raw().Func().Id("NewTracerProvider").Params(
+		jen.Id("ctx").Qual("context", "Context"),
+		jen.List(jen.Id("exporter"), jen.Id("endpoint")).String(),
+	).Params(
+		jen.Op("*").Qual(otelSdkTrace, "TracerProvider"),
+		jen.Error(),
+	).Block(
+		jen.List(jen.Id("res"), jen.Err()).Op(":=").Qual("go.opentelemetry.io/otel/sdk/resource", "New").Call(
+			jen.Id("ctx"),
+			jen.Qual("go.opentelemetry.io/otel/sdk/resource", "WithAttributes").Call(
+				jen.Qual(otelAttribute, "String").Call(jen.Lit("service.name"), jen.Id("ServiceName")),
+			),
+			jen.Qual("go.opentelemetry.io/otel/sdk/resource", "WithFromEnv").Call(),
+			jen.Qual("go.opentelemetry.io/otel/sdk/resource", "WithTelemetrySDK").Call(),
+		),
+		jen.If(jen.Err().Op("!=").Nil()).Block(
+			jen.Return(jen.Nil(), jen.Err()),
+		),
+		jen.Id("opts").Op(":=").Index().Qual(otelSdkTrace, "TracerProviderOption").Values(
+			jen.Qual(otelSdkTrace, "WithResource").Call(jen.Id("res")),
+		),
+		jen.Switch(jen.Id("exporter")).Block(
+			exporterCase(
+				"otlp",
+				jen.Qual("go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc", "New").Call(
+					jen.Id("ctx"),
+					jen.Qual("go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc", "WithEndpoint").Call(
+						jen.Id("endpoint"),
+					),
+					jen.Qual("go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc", "WithInsecure").Call(),
+				),
+				"WithBatcher",
+			),
+			exporterCase(
+				"stdout",
+				jen.Qual("go.opentelemetry.io/otel/exporters/stdout/stdouttrace", "New").Call(
+					jen.Qual("go.opentelemetry.io/otel/exporters/stdout/stdouttrace", "WithPrettyPrint").Call(),
+				),
+				"WithSyncer",
+			),
+			jen.Case(jen.Lit("none")),
+			jen.Default().Block(
+				jen.Return(jen.Nil(), jen.Qual("fmt", "Errorf").Call(
+					jen.Lit("unknown trace exporter %q"),
+					jen.Id("exporter"),
+				)),
+			),
+		),
+		jen.Id("tp").Op(":=").Qual(otelSdkTrace, "NewTracerProvider").Call(jen.Id("opts").Op("...")),
+		jen.Qual(otelImport, "SetTracerProvider").Call(jen.Id("tp")),
+		jen.Qual(otelImport, "SetTextMapPropagator").Call(
+			jen.Qual(otelProp, "NewCompositeTextMapPropagator").Call(
+				jen.Qual(otelProp, "TraceContext").Values(),
+				jen.Qual(otelProp, "Baggage").Values(),
+			),
+		),
+		jen.Return(jen.Id("tp"), jen.Nil()),
+	).Line()
+	g.code.NewLine()
+}
+
+func (g *GenerateTracing) generateEndpointMiddleware() {
+	g.code.appendMultilineComment([]string{
+		"EndpointMiddleware returns an endpoint middleware that records a span for",
+		"every invocation of the method, the span is marked as failed if the method",
+		"returns an error.",
+	})
+	g.code.NewLine()
+	g.code.Raw().Func().Id("EndpointMiddleware").Params(
+		jen.Id("method").String(),
+	).Qual("github.com/go-kit/kit/endpoint", "Middleware").Block(
+		jen.Return(jen.Func().Params(
+			jen.Id("next").Qual("github.com/go-kit/kit/endpoint", "Endpoint"),
+		).Qual("github.com/go-kit/kit/endpoint", "Endpoint").Block(
+			jen.Return(jen.Func().Params(
+				jen.Id("ctx").Qual("context", "Context"),
+				jen.Id("request").Interface(),
+			).Params(
+				jen.Id("response").Interface(),
+				jen.Err().Error(),
+			).Block(
+				jen.List(jen.Id("ctx"), jen.Id("span")).Op(":=").Qual(otelImport, "Tracer").Call(
+					jen.Id("ServiceName"),
+				).Dot("Start").Call(jen.Id("ctx"), jen.Id("method")),
+				jen.Defer().Func().Params().Block(
+					jen.If(
+						jen.List(jen.Id("f"), jen.Id("ok")).Op(":=").Id("response").Assert(
+							jen.Qual("github.com/go-kit/kit/endpoint", "Failer"),
+						),
+						jen.Id("ok").Op("&&").Err().Op("==").Nil(),
+					).Block(
+						jen.Id("setError").Call(jen.Id("span"), jen.Id("f").Dot("Failed").Call()),
+					).Else().Block(
+						jen.Id("setError").Call(jen.Id("span"), jen.Err()),
+					),
+					jen.Id("span").Dot("End").Call(),
+				).Call(),
+				jen.Return(jen.Id("next").Call(jen.Id("ctx"), jen.Id("request"))),
+			)),
+		)),
+	).Line()
+	g.code.NewLine()
+}
+
+// startSpan returns the statement that starts a span of `kind` named after the
+// transport and the method.
+func startSpan(transport, kind string, attributes ...jen.Code) *jen.Statement {
+	opts := []jen.Code{
+		jen.Id("ctx"),
+		jen.Lit(transport + " ").Op("+").Id("method"),
+		jen.Qual(otelTrace, "WithSpanKind").Call(jen.Qual(otelTrace, kind)),
+	}
+	if len(attributes) > 0 {
+		opts = append(opts, jen.Qual(otelTrace, "WithAttributes").Call(attributes...))
+	}
+	return jen.List(jen.Id("ctx"), jen.Id("_")).Op("=").Qual(otelImport, "Tracer").Call(
+		jen.Id("ServiceName"),
+	).Dot("Start").Call(opts...)
+}
+
+// endSpan returns the finalizer body that ends the span of the context.
+func endSpan() []jen.Code {
+	return []jen.Code{
+		jen.Id("span").Op(":=").Qual(otelTrace, "SpanFromContext").Call(jen.Id("ctx")),
+		jen.Id("setError").Call(jen.Id("span"), jen.Err()),
+		jen.Id("span").Dot("End").Call(),
+	}
+}
+
+func (g *GenerateTracing) generateHTTPServerTrace() {
+	g.code.appendMultilineComment([]string{
+		"HTTPServerTrace returns a http server option that continues the trace of the",
+		"incoming request in a server span of the method.",
+	})
+	g.code.NewLine()
+	g.code.Raw().Func().Id("HTTPServerTrace").Params(
+		jen.Id("method").String(),
+	).Qual(kitHTTPImport, "ServerOption").Block(
+		jen.Return(jen.Func().Params(jen.Id("s").Op("*").Qual(kitHTTPImport, "Server")).Block(
+			jen.Qual(kitHTTPImport, "ServerBefore").Call(
+				jen.Func().Params(
+					jen.Id("ctx").Qual("context", "Context"),
+					jen.Id("r").Op("*").Qual("net/http", "Request"),
+				).Qual("context", "Context").Block(
+					jen.Id("ctx").Op("=").Qual(otelImport, "GetTextMapPropagator").Call().Dot("Extract").Call(
+						jen.Id("ctx"),
+						jen.Qual(otelProp, "HeaderCarrier").Call(jen.Id("r").Dot("Header")),
+					),
+					startSpan(
+						"HTTP",
+						"SpanKindServer",
+						jen.Qual(otelAttribute, "String").Call(jen.Lit("http.method"), jen.Id("r").Dot("Method")),
+						jen.Qual(otelAttribute, "String").Call(jen.Lit("http.target"), jen.Id("r").Dot("URL").Dot("Path")),
+					),
+					jen.Return(jen.Id("ctx")),
+				),
+			).Call(jen.Id("s")),
+			jen.Qual(kitHTTPImport, "ServerFinalizer").Call(
+				jen.Func().Params(
+					jen.Id("ctx").Qual("context", "Context"),
+					jen.Id("code").Int(),
+					jen.Id("r").Op("*").Qual("net/http", "Request"),
+				).Block(
+					jen.Id("span").Op(":=").Qual(otelTrace, "SpanFromContext").Call(jen.Id("ctx")),
+					jen.Id("span").Dot("SetAttributes").Call(
+						jen.Qual(otelAttribute, "Int").Call(jen.Lit("http.status_code"), jen.Id("code")),
+					),
+					jen.If(jen.Id("code").Op(">=").Qual("net/http", "StatusInternalServerError")).Block(
+						jen.Id("span").Dot("SetStatus").Call(
+							jen.Qual(otelCodes, "Error"),
+							jen.Qual("net/http", "StatusText").Call(jen.Id("code")),
+						),
+					),
+					jen.Id("span").Dot("End").Call(),
+				),
+			).Call(jen.Id("s")),
+		)),
+	).Line()
+	g.code.NewLine()
+}
+
+func (g *GenerateTracing) generateHTTPClientTrace() {
+	g.code.appendMultilineComment([]string{
+		"HTTPClientTrace returns a http client option that records a client span of",
+		"the method and propagates the trace in the headers of the outgoing request.",
+	})
+	g.code.NewLine()
+	g.code.Raw().Func().Id("HTTPClientTrace").Params(
+		jen.Id("method").String(),
+	).Qual(kitHTTPImport, "ClientOption").Block(
+		jen.Return(jen.Func().Params(jen.Id("c").Op("*").Qual(kitHTTPImport, "Client")).Block(
+			jen.Qual(kitHTTPImport, "ClientBefore").Call(
+				jen.Func().Params(
+					jen.Id("ctx").Qual("context", "Context"),
+					jen.Id("r").Op("*").Qual("net/http", "Request"),
+				).Qual("context", "Context").Block(
+					startSpan(
+						"HTTP",
+						"SpanKindClient",
+						jen.Qual(otelAttribute, "String").Call(jen.Lit("http.method"), jen.Id("r").Dot("Method")),
+						jen.Qual(otelAttribute, "String").Call(jen.Lit("http.url"), jen.Id("r").Dot("URL").Dot("String").Call()),
+					),
+					jen.Qual(otelImport, "GetTextMapPropagator").Call().Dot("Inject").Call(
+						jen.Id("ctx"),
+						jen.Qual(otelProp, "HeaderCarrier").Call(jen.Id("r").Dot("Header")),
+					),
+					jen.Return(jen.Id("ctx")),
+				),
+			).Call(jen.Id("c")),
+			jen.Qual(kitHTTPImport, "ClientFinalizer").Call(
+				jen.Func().Params(
+					jen.Id("ctx").Qual("context", "Context"),
+					jen.Err().Error(),
+				).Block(endSpan()...),
+			).Call(jen.Id("c")),
+		)),
+	).Line()
+	g.code.NewLine()
+}
+
+func (g *GenerateTracing) generateGRPCServerTrace() {
+	g.code.appendMultilineComment([]string{
+		"GRPCServerTrace returns a grpc server option that continues the trace of the",
+		"incoming metadata in a server span of the method.",
+	})
+	g.code.NewLine()
+	g.code.Raw().Func().Id("GRPCServerTrace").Params(
+		jen.Id("method").String(),
+	).Qual(kitGRPCImport, "ServerOption").Block(
+		jen.Return(jen.Func().Params(jen.Id("s").Op("*").Qual(kitGRPCImport, "Server")).Block(
+			jen.Qual(kitGRPCImport, "ServerBefore").Call(
+				jen.Func().Params(
+					jen.Id("ctx").Qual("context", "Context"),
+					jen.Id("md").Qual("google.golang.org/grpc/metadata", "MD"),
+				).Qual("context", "Context").Block(
+					jen.Id("ctx").Op("=").Qual(otelImport, "GetTextMapPropagator").Call().Dot("Extract").Call(
+						jen.Id("ctx"),
+						jen.Id("metadataCarrier").Call(jen.Id("md")),
+					),
+					startSpan("gRPC", "SpanKindServer"),
+					jen.Return(jen.Id("ctx")),
+				),
+			).Call(jen.Id("s")),
+			jen.Qual(kitGRPCImport, "ServerFinalizer").Call(
+				jen.Func().Params(
+					jen.Id("ctx").Qual("context", "Context"),
+					jen.Err().Error(),
+				).Block(endSpan()...),
+			).Call(jen.Id("s")),
+		)),
+	).Line()
+	g.code.NewLine()
+}
+
+func (g *GenerateTracing) generateGRPCClientTrace() {
+	g.code.appendMultilineComment([]string{
+		"GRPCClientTrace returns a grpc client option that records a client span of",
+		"the method and propagates the trace in the outgoing metadata.",
+	})
+	g.code.NewLine()
+	g.code.Raw().Func().Id("GRPCClientTrace").Params(
+		jen.Id("method").String(),
+	).Qual(kitGRPCImport, "ClientOption").Block(
+		jen.Return(jen.Func().Params(jen.Id("c").Op("*").Qual(kitGRPCImport, "Client")).Block(
+			jen.Qual(kitGRPCImport, "ClientBefore").Call(
+				jen.Func().Params(
+					jen.Id("ctx").Qual("context", "Context"),
+					jen.Id("md").Op("*").Qual("google.golang.org/grpc/metadata", "MD"),
+				).Qual("context", "Context").Block(
+					startSpan("gRPC", "SpanKindClient"),
+					jen.Qual(otelImport, "GetTextMapPropagator").Call().Dot("Inject").Call(
+						jen.Id("ctx"),
+						jen.Id("metadataCarrier").Call(jen.Id("*md")),
+					),
+					jen.Return(jen.Id("ctx")),
+				),
+			).Call(jen.Id("c")),
+			jen.Qual(kitGRPCImport, "ClientFinalizer").Call(
+				jen.Func().Params(
+					jen.Id("ctx").Qual("context", "Context"),
+					jen.Err().Error(),
+				).Block(endSpan()...),
+			).Call(jen.Id("c")),
+		)),
+	).Line()
+	g.code.NewLine()
+}
+
+func (g *GenerateTracing) generateMetadataCarrier() {
+	g.code.appendMultilineComment([]string{
+		"metadataCarrier adapts the gRPC metadata to a propagation.TextMapCarrier.",
+	})
+	g.code.NewLine()
+	g.code.Raw().Type().Id("metadataCarrier").Qual("google.golang.org/grpc/metadata", "MD").Line()
+	g.code.NewLine()
+	g.code.appendFunction(
+		"Get",
+		jen.Id("c").Id("metadataCarrier"),
+		[]jen.Code{
+			jen.Id("key").String(),
+		},
+		[]jen.Code{},
+		"string",
+		jen.Id("v").Op(":=").Qual("google.golang.org/grpc/metadata", "MD").Call(jen.Id("c")).Dot("Get").Call(jen.Id("key")),
+		jen.If(jen.Len(jen.Id("v")).Op("==").Lit(0)).Block(
+			jen.Return(jen.Lit("")),
+		),
+		jen.Return(jen.Id("v").Index(jen.Lit(0))),
+	)
+	g.code.NewLine()
+	g.code.appendFunction(
+		"Set",
+		jen.Id("c").Id("metadataCarrier"),
+		[]jen.Code{
+			jen.List(jen.Id("key"), jen.Id("value")).String(),
+		},
+		[]jen.Code{},
+		"",
+		jen.Qual("google.golang.org/grpc/metadata", "MD").Call(jen.Id("c")).Dot("Set").Call(jen.Id("key"), jen.Id("value")),
+	)
+	g.code.NewLine()
+	g.code.appendFunction(
+		"Keys",
+		jen.Id("c").Id("metadataCarrier"),
+		[]jen.Code{},
+		[]jen.Code{},
+		"[]string",
+		jen.Id("keys").Op(":=").Make(jen.Index().String(), jen.Lit(0), jen.Len(jen.Id("c"))),
+		jen.For(jen.Id("k").Op(":=").Range().Id("c")).Block(
+			jen.Id("keys").Op("=").Append(jen.Id("keys"), jen.Id("k")),
+		),
+		jen.Return(jen.Id("keys")),
+	)
+	g.code.NewLine()
+}
+
+// checkOpenTracing returns an error if the service was generated with OpenTracing,
+// the transport options take the OpenTracing tracer in service.go but not in the
+// regenerated service_gen.go so the service must be migrated first.
+func (g *GenerateTracing) checkOpenTracing() error {
+	cmdFilePath := path.Join(
+		fmt.Sprintf(viper.GetString("gk_cmd_service_path_format"), utils.ToLowerSnakeCase(g.name)),
+		viper.GetString("gk_cmd_svc_file_name"),
+	)
+	if b, err := g.fs.Exists(cmdFilePath); err != nil || !b {
+		return err
+	}
+	src, err := g.fs.ReadFile(cmdFilePath)
+	if err != nil {
+		return err
+	}
+	f, err := ps.ParseFile(token.NewFileSet(), "", src, 0)
+	if err != nil {
+		return err
+	}
+	openTracing := false
+	ast.Inspect(f, func(n ast.Node) bool {
+		if c, ok := n.(*ast.CallExpr); ok && len(c.Args) == 2 {
+			if id, ok := c.Fun.(*ast.Ident); ok && (id.Name == "defaultHttpOptions" || id.Name == "defaultGRPCOptions") {
+				openTracing = true
+			}
+		}
+		return !openTracing
+	})
+	if openTracing {
+		return fmt.Errorf(
+			"%s was generated with OpenTracing, replace the tracer with tracing.NewTracerProvider and "+
+				"call defaultHttpOptions/defaultGRPCOptions without the tracer and addTracingEndpointMiddleware "+
+				"as a new service does before switching to otel",
+			cmdFilePath,
+		)
+	}
+	return nil
+}
+
+// tracingExists returns true if the OpenTelemetry tracing package of the
+// service was generated.
+func tracingExists(f *fs.KitFs, name string) (bool, error) {
+	return f.Exists(path.Join(
+		fmt.Sprintf(viper.GetString("gk_tracing_path_format"), utils.ToLowerSnakeCase(name)),
+		viper.GetString("gk_tracing_file_name"),
+	))
+}
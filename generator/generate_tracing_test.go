@@ -0,0 +1,137 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kujtimiihoxha/kit/parser"
+)
+
+func TestGenerateTracing_Generate(t *testing.T) {
+	f := newClientTestFs()
+	if err := NewGenerateTracing("test").Generate(); err != nil {
+		t.Fatalf("GenerateTracing.Generate() error = %v", err)
+	}
+	src, err := f.ReadFile("test/pkg/tracing/tracing.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		`const ServiceName = "test"`,
+		"func NewTracerProvider(ctx context.Context, exporter, endpoint string) (*sdktrace.TracerProvider, error)",
+		"otlptracegrpc.WithEndpoint(endpoint)",
+		"stdouttrace.New(stdouttrace.WithPrettyPrint())",
+		"otel.SetTextMapPropagator(",
+		"func EndpointMiddleware(method string) endpoint.Middleware",
+		// go-kit responses report their errors with endpoint.Failer.
+		"f, ok := response.(endpoint.Failer); ok && err == nil",
+		"func HTTPServerTrace(method string) kithttp.ServerOption",
+		"otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(r.Header))",
+		"func GRPCClientTrace(method string) kitgrpc.ClientOption",
+		"func (c metadataCarrier) Keys() []string",
+	} {
+		if !strings.Contains(src, v) {
+			t.Errorf("GenerateTracing.Generate() tracing.go does not contain %q", v)
+		}
+	}
+	f.WriteFile("test/pkg/tracing/tracing.go", `package tracing
+
+import "go.opentelemetry.io/otel"
+
+// EndpointMiddleware is customized by the user.
+func EndpointMiddleware(method string) string {
+	return otel.Version() + method
+}
+`, true)
+	if err := NewGenerateTracing("test").Generate(); err != nil {
+		t.Fatalf("GenerateTracing.Generate() error = %v", err)
+	}
+	src, err = f.ReadFile("test/pkg/tracing/tracing.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(src, "func EndpointMiddleware(") != 1 || !strings.Contains(src, "func HTTPClientTrace(") {
+		t.Errorf("GenerateTracing.Generate() should only add the missing functions, got %s", src)
+	}
+}
+
+func TestGenerateTracing_GenerateTransportsAndClients(t *testing.T) {
+	f := newClientTestFs()
+	f.MkdirAll("test/pkg/http")
+	f.WriteFile("test/pkg/http/handler.go", `package http
+func makeFooHandler() {}`, true)
+	if err := NewGenerateTracing("test").Generate(); err != nil {
+		t.Fatalf("GenerateTracing.Generate() error = %v", err)
+	}
+	svcSrc, err := f.ReadFile("test/pkg/service/service.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	svcFile, err := parser.NewFileParser().Parse([]byte(svcSrc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := newGenerateCmdBase("test", svcFile.Interfaces[0], false, false, nil).Generate(); err != nil {
+		t.Fatalf("generateCmdBase.Generate() error = %v", err)
+	}
+	src, err := f.ReadFile("test/cmd/service/service_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		"func defaultHttpOptions(logger log.Logger) map[string][]http.ServerOption",
+		`tracing.HTTPServerTrace("Foo")`,
+		"mw[v] = append(mw[v], tracing.EndpointMiddleware(v))",
+	} {
+		if !strings.Contains(src, v) {
+			t.Errorf("generateCmdBase.Generate() service_gen.go does not contain %q", v)
+		}
+	}
+	if strings.Contains(src, "opentracing") {
+		t.Error("generateCmdBase.Generate() should not use OpenTracing with the otel tracing package")
+	}
+	if err := NewGenerateClient("test", "http", "", false, "", 0, false, ClientLanguageGo).Generate(); err != nil {
+		t.Fatalf("GenerateClient.Generate() error = %v", err)
+	}
+	src, err = f.ReadFile("test/client/http/http.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(src, "tracing.HTTPClientTrace(name)") {
+		t.Error("GenerateClient.Generate() http client does not propagate the trace")
+	}
+}
+
+func TestGenerateTracing_GenerateSwitchTracer(t *testing.T) {
+	f := newClientTestFs()
+	if err := NewGenerateService("test", "http", "", "", false, false, false, nil).Generate(); err != nil {
+		t.Fatalf("GenerateService.Generate() error = %v", err)
+	}
+	src, err := f.ReadFile("test/cmd/service/service.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(src, "defaultHttpOptions(logger, tracer)") {
+		t.Fatalf("GenerateService.Generate() should generate an OpenTracing service, got %s", src)
+	}
+	if err := NewGenerateTracing("test").Generate(); err == nil {
+		t.Error("GenerateTracing.Generate() should not switch an OpenTracing service to otel")
+	}
+	if b, _ := tracingExists(f, "test"); b {
+		t.Error("GenerateTracing.Generate() should not generate the tracing package of an OpenTracing service")
+	}
+	// The user migrates service.go to the OpenTelemetry transport options.
+	f.WriteFile("test/cmd/service/service.go", strings.Replace(src, "defaultHttpOptions(logger, tracer)", "defaultHttpOptions(logger)", 1), true)
+	if err := NewGenerateTracing("test").Generate(); err != nil {
+		t.Fatalf("GenerateTracing.Generate() error = %v", err)
+	}
+	if err := NewGenerateService("test", "http", "", "", false, false, false, nil).Generate(); err != nil {
+		t.Fatalf("GenerateService.Generate() error = %v", err)
+	}
+	if src, err = f.ReadFile("test/cmd/service/service_gen.go"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(src, "func defaultHttpOptions(logger log.Logger) map[string][]http.ServerOption") {
+		t.Errorf("GenerateService.Generate() should generate the otel transport options, got %s", src)
+	}
+}
@@ -0,0 +1,425 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"go/types"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/kujtimiihoxha/kit/fs"
+	"github.com/kujtimiihoxha/kit/parser"
+	"github.com/kujtimiihoxha/kit/utils"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// ValidateAnnotation is the annotation that sets the validation rules of the
+// method parameters (e.x `// kit:validate name:"required,min=3"`).
+const ValidateAnnotation = "validate"
+
+// numberTypes are the types the min, max and oneof rules compare by value.
+var numberTypes = map[string]bool{
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"float32": true, "float64": true, "byte": true, "rune": true,
+}
+
+// validatedField is a request field that has validation rules.
+type validatedField struct {
+	// name is the name used in the validation errors (e.x `todo.title`).
+	name string
+	tp   string
+	sel  *jen.Statement
+	tag  string
+	// parent is the pointer to the struct of the field, the rules are only
+	// checked if it is not nil.
+	parent *jen.Statement
+}
+
+type generateServiceValidation struct {
+	BaseGenerator
+	name             string
+	destPath         string
+	filePath         string
+	serviceInterface parser.Interface
+	serviceFile      *parser.File
+}
+
+func newGenerateServiceValidation(name string, serviceInterface parser.Interface, serviceFile *parser.File) Gen {
+	i := &generateServiceValidation{
+		name:             name,
+		destPath:         fmt.Sprintf(viper.GetString("gk_endpoint_path_format"), utils.ToLowerSnakeCase(name)),
+		serviceInterface: serviceInterface,
+		serviceFile:      serviceFile,
+	}
+	i.filePath = path.Join(i.destPath, viper.GetString("gk_endpoint_validation_file_name"))
+	i.srcFile = jen.NewFilePath(i.destPath)
+	i.InitPg()
+	i.fs = fs.Get()
+	return i
+}
+
+// Generate generates the validation middleware and a Validate method for the
+// requests of the methods that have validation rules, the rules come from the
+// `kit:validate` annotations of the methods and the `validate` tags of the
+// structs the methods take.
+func (g *generateServiceValidation) Generate() (err error) {
+	err = g.CreateFolderStructure(g.destPath)
+	if err != nil {
+		return err
+	}
+	userDefined, err := g.userDefinedValidate()
+	if err != nil {
+		return err
+	}
+	svcImport, err := utils.GetServiceImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	g.srcFile.PackageComment("THIS FILE IS AUTO GENERATED BY GK-CLI DO NOT EDIT!!")
+	g.code.appendMultilineComment([]string{
+		"ValidationError is returned when a request does not pass the validation, the",
+		"http transport responds with a 400 Bad Request and the grpc transport with an",
+		"InvalidArgument status.",
+	})
+	g.code.NewLine()
+	g.code.appendStruct(
+		"ValidationError",
+		jen.Id("Field").String(),
+		jen.Id("Message").String(),
+	)
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"Error implements the error interface.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"Error",
+		jen.Id("e").Id("ValidationError"),
+		[]jen.Code{},
+		[]jen.Code{},
+		"string",
+		jen.Return(jen.Id("e").Dot("Field").Op("+").Lit(" ").Op("+").Id("e").Dot("Message")),
+	)
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"Unwrap returns the validation error as an invalid argument service error.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"Unwrap",
+		jen.Id("e").Id("ValidationError"),
+		[]jen.Code{},
+		[]jen.Code{},
+		"error",
+		jen.Return(jen.Op("&").Qual(svcImport, "Error").Values(jen.Dict{
+			jen.Id("Kind"):    jen.Qual(svcImport, "KindInvalidArgument"),
+			jen.Id("Message"): jen.Id("e").Dot("Error").Call(),
+		})),
+	)
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"GRPCStatus makes the grpc server respond with an InvalidArgument status.",
+	})
+	g.code.NewLine()
+	g.code.Raw().Func().Params(jen.Id("e").Id("ValidationError")).Id("GRPCStatus").Params().Op("*").Qual(
+		"google.golang.org/grpc/status", "Status",
+	).Block(
+		jen.Return(jen.Qual("google.golang.org/grpc/status", "New").Call(
+			jen.Qual("google.golang.org/grpc/codes", "InvalidArgument"),
+			jen.Id("e").Dot("Error").Call(),
+		)),
+	).Line()
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"Validator is implemented by the requests that can be validated.",
+	})
+	g.code.NewLine()
+	g.code.appendInterface("Validator", []jen.Code{jen.Id("Validate").Params().Error()})
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"ValidationMiddleware returns an endpoint middleware that rejects the requests",
+		"that implement Validator and do not pass the validation.",
+	})
+	g.code.NewLine()
+	g.code.Raw().Func().Id("ValidationMiddleware").Params().Qual(
+		"github.com/go-kit/kit/endpoint", "Middleware",
+	).Block(
+		jen.Return(jen.Func().Params(jen.Id("next").Qual("github.com/go-kit/kit/endpoint", "Endpoint")).Qual(
+			"github.com/go-kit/kit/endpoint", "Endpoint",
+		).Block(
+			jen.Return(jen.Func().Params(
+				jen.Id("ctx").Qual("context", "Context"),
+				jen.Id("request").Interface(),
+			).Params(jen.Interface(), jen.Error()).Block(
+				jen.If(jen.List(jen.Id("v"), jen.Id("ok")).Op(":=").Id("request").Assert(jen.Id("Validator")), jen.Id("ok")).Block(
+					jen.If(jen.Err().Op(":=").Id("v").Dot("Validate").Call(), jen.Err().Op("!=").Nil()).Block(
+						jen.Return(jen.Nil(), jen.Err()),
+					),
+				),
+				jen.Return(jen.Id("next").Call(jen.Id("ctx"), jen.Id("request"))),
+			)),
+		)),
+	).Line()
+	g.code.NewLine()
+	for _, m := range g.serviceInterface.Methods {
+		if userDefined[m.Name+"Request"] {
+			continue
+		}
+		if err = g.generateValidate(m); err != nil {
+			return err
+		}
+	}
+	return g.fs.WriteFile(g.filePath, g.srcFile.GoString(), true)
+}
+
+// userDefinedValidate returns the requests that already have a Validate method
+// in the endpoint file, those are left to the user.
+func (g *generateServiceValidation) userDefinedValidate() (map[string]bool, error) {
+	found := map[string]bool{}
+	epFile := path.Join(g.destPath, viper.GetString("gk_endpoint_file_name"))
+	if b, err := g.fs.Exists(epFile); err != nil || !b {
+		return found, err
+	}
+	src, err := g.fs.ReadFile(epFile)
+	if err != nil {
+		return found, err
+	}
+	f, err := parser.NewFileParser().Parse([]byte(src))
+	if err != nil {
+		return found, err
+	}
+	for _, v := range f.Methods {
+		if v.Name == "Validate" {
+			found[strings.TrimPrefix(v.Struct.Type, "*")] = true
+		}
+	}
+	return found, nil
+}
+
+// fields returns the request fields of the method that have validation rules,
+// the fields of the service structs the method takes are validated too.
+func (g *generateServiceValidation) fields(m parser.Method) []validatedField {
+	fields := []validatedField{}
+	for _, p := range m.Parameters {
+		if p.Type == "context.Context" || parser.ChanElemType(p.Type) != "" || streamElem(m, p) != "" {
+			continue
+		}
+		tp := strings.Replace(p.Type, "...", "[]", 1)
+		sel := jen.Id("r").Dot(utils.ToCamelCase(p.Name))
+		if tag := m.ParamTag(ValidateAnnotation, p.Name); tag != "" {
+			fields = append(fields, validatedField{name: jsonFieldTag(p), tp: tp, sel: sel, tag: tag})
+		}
+		st, ok := g.serviceStruct(strings.TrimPrefix(tp, "*"))
+		if !ok {
+			continue
+		}
+		var parent *jen.Statement
+		if strings.HasPrefix(tp, "*") {
+			parent = jen.Id("r").Dot(utils.ToCamelCase(p.Name))
+		}
+		for _, v := range st.Vars {
+			tag := v.TagValue(ValidateAnnotation)
+			if tag == "" || !isExported(v.Name) {
+				continue
+			}
+			name := strings.Split(v.TagValue("json"), ",")[0]
+			if name == "" {
+				name = v.Name
+			}
+			fields = append(fields, validatedField{
+				name:   jsonFieldTag(p) + "." + name,
+				tp:     v.Type,
+				sel:    jen.Id("r").Dot(utils.ToCamelCase(p.Name)).Dot(v.Name),
+				tag:    tag,
+				parent: parent,
+			})
+		}
+	}
+	return fields
+}
+
+func (g *generateServiceValidation) serviceStruct(name string) (parser.Struct, bool) {
+	for _, v := range g.serviceFile.Structures {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return parser.Struct{}, false
+}
+
+func (g *generateServiceValidation) generateValidate(m parser.Method) error {
+	body := []jen.Code{}
+	patterns := []jen.Code{}
+	for _, f := range g.fields(m) {
+		for _, rule := range strings.Split(f.tag, ",") {
+			rule = strings.TrimSpace(rule)
+			if rule == "" {
+				continue
+			}
+			kv := strings.SplitN(rule, "=", 2)
+			value := ""
+			if len(kv) == 2 {
+				value = kv[1]
+			}
+			cond, msg, err := g.rule(m, f, kv[0], value, &patterns)
+			if err != nil {
+				return err
+			}
+			if cond == nil {
+				continue
+			}
+			if f.parent != nil {
+				cond = f.parent.Clone().Op("!=").Nil().Op("&&").Parens(cond)
+			}
+			body = append(body, jen.If(cond).Block(
+				jen.Return(jen.Id("ValidationError").Values(jen.Dict{
+					jen.Id("Field"):   jen.Lit(f.name),
+					jen.Id("Message"): jen.Lit(msg),
+				})),
+			))
+		}
+	}
+	if len(body) == 0 {
+		return nil
+	}
+	if len(patterns) > 0 {
+		g.code.Raw().Var().Defs(patterns...).Line()
+		g.code.NewLine()
+	}
+	g.code.appendMultilineComment([]string{
+		fmt.Sprintf("Validate implements Validator, it checks the rules of the %s parameters.", m.Name),
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"Validate",
+		jen.Id("r").Id(m.Name+"Request"),
+		[]jen.Code{},
+		[]jen.Code{},
+		"error",
+		append(body, jen.Return(jen.Nil()))...,
+	)
+	g.code.NewLine()
+	return nil
+}
+
+// rule returns the condition that fails the rule and the error message, the
+// condition is nil if the rule can not be applied to the field. The error is not
+// nil if the value of the rule does not fit the type of the field.
+func (g *generateServiceValidation) rule(m parser.Method, f validatedField, rule, value string,
+	patterns *[]jen.Code) (*jen.Statement, string, error) {
+	kind := validationKind(f.tp)
+	sel := func() *jen.Statement { return f.sel.Clone() }
+	size := func() *jen.Statement {
+		if kind == "string" {
+			return jen.Qual("unicode/utf8", "RuneCountInString").Call(sel())
+		}
+		return jen.Len(sel())
+	}
+	unit := map[string]string{"string": " characters", "len": " items"}[kind]
+	switch {
+	case rule == "required" && kind == "string":
+		return sel().Op("==").Lit(""), "is required", nil
+	case rule == "required" && kind == "number":
+		return sel().Op("==").Lit(0), "is required", nil
+	case rule == "required" && kind == "len":
+		return jen.Len(sel()).Op("==").Lit(0), "is required", nil
+	case rule == "required" && kind == "nil":
+		return sel().Op("==").Nil(), "is required", nil
+	case (rule == "min" || rule == "max") && (kind == "string" || kind == "len"):
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			break
+		}
+		if rule == "min" {
+			return size().Op("<").Lit(n), fmt.Sprintf("must have at least %d%s", n, unit), nil
+		}
+		return size().Op(">").Lit(n), fmt.Sprintf("must have at most %d%s", n, unit), nil
+	case (rule == "min" || rule == "max") && kind == "number":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			break
+		}
+		if err := checkNumber(f.tp, value); err != nil {
+			return nil, "", fmt.Errorf("the value `%s` of the rule `%s` of `%s` in method `%s` does not fit the type `%s`: %v",
+				value, rule, f.name, m.Name, f.tp, err)
+		}
+		if rule == "min" {
+			return sel().Op("<").Op(value), "must be at least " + value, nil
+		}
+		return sel().Op(">").Op(value), "must be at most " + value, nil
+	case rule == "regex" && kind == "string":
+		if _, err := regexp.Compile(value); err != nil {
+			break
+		}
+		name := utils.ToLowerFirstCamelCase(m.Name + "_" + strings.Replace(f.name, ".", "_", -1) + "_pattern")
+		*patterns = append(*patterns, jen.Id(name).Op("=").Qual("regexp", "MustCompile").Call(jen.Lit(value)))
+		return jen.Op("!").Id(name).Dot("MatchString").Call(sel()), "must match " + value, nil
+	case rule == "oneof" && (kind == "string" || kind == "number"):
+		values := strings.Fields(value)
+		if len(values) == 0 {
+			break
+		}
+		cond := []jen.Code{}
+		for _, v := range values {
+			if kind == "string" {
+				cond = append(cond, sel().Op("!=").Lit(v))
+				continue
+			}
+			if _, err := strconv.ParseFloat(v, 64); err != nil {
+				return g.skip(m, f, rule)
+			}
+			if err := checkNumber(f.tp, v); err != nil {
+				return nil, "", fmt.Errorf("the value `%s` of the rule `%s` of `%s` in method `%s` does not fit the type `%s`: %v",
+					v, rule, f.name, m.Name, f.tp, err)
+			}
+			cond = append(cond, sel().Op("!=").Op(v))
+		}
+		return joinOp("&&", cond), "must be one of " + strings.Join(values, ", "), nil
+	}
+	return g.skip(m, f, rule)
+}
+
+func (g *generateServiceValidation) skip(m parser.Method, f validatedField, rule string) (*jen.Statement, string, error) {
+	logrus.Warnf("Validation rule `%s` of `%s` in method `%s` is not supported for the type `%s`, skipping", rule, f.name, m.Name, f.tp)
+	return nil, "", nil
+}
+
+// checkNumber returns an error if the value is not a constant of the number type,
+// e.x a fraction for an integer or a value that overflows the type, the generated
+// comparison would not compile.
+func checkNumber(tp, value string) error {
+	_, err := types.Eval(token.NewFileSet(), nil, token.NoPos, fmt.Sprintf("%s(%s)", tp, value))
+	if e, ok := err.(types.Error); ok {
+		return errors.New(e.Msg)
+	}
+	return err
+}
+
+// validationKind returns how the rules are applied to the type.
+func validationKind(tp string) string {
+	switch {
+	case tp == "string":
+		return "string"
+	case numberTypes[tp]:
+		return "number"
+	case strings.HasPrefix(tp, "[]"), strings.HasPrefix(tp, "map["):
+		return "len"
+	case strings.HasPrefix(tp, "*"), tp == "interface{}", tp == "error":
+		return "nil"
+	}
+	return ""
+}
+
+// joinOp joins the conditions with the operator.
+func joinOp(op string, cond []jen.Code) *jen.Statement {
+	s := jen.Add(cond[0])
+	for _, v := range cond[1:] {
+		s = s.Op(op).Add(v)
+	}
+	return s
+}
@@ -0,0 +1,102 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kujtimiihoxha/kit/parser"
+)
+
+func TestGenerateServiceValidation_Generate(t *testing.T) {
+	f := newClientTestFs()
+	f.MkdirAll("test/pkg/endpoint")
+	f.WriteFile("test/pkg/endpoint/endpoint.go", `package endpoint
+
+type BarRequest struct{}
+
+func (r BarRequest) Validate() error {
+	return nil
+}
+`, true)
+	file, err := parser.NewFileParser().Parse([]byte(`package service
+
+import "context"
+
+type Todo struct {
+	Title string ` + "`json:\"title\" validate:\"required,max=64\"`" + `
+}
+
+type TestService interface {
+	// kit:validate a:"required,min=3,regex=^[a-z]+$" n:"min=1,oneof=1 2" tags:"max=2"
+	Foo(ctx context.Context, a string, n int, tags []string, todo *Todo) (err error)
+	// kit:validate a:"required"
+	Bar(ctx context.Context, a string) (err error)
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = newGenerateServiceValidation("test", file.Interfaces[0], file).Generate(); err != nil {
+		t.Fatalf("generateServiceValidation.Generate() error = %v", err)
+	}
+	src, err := f.ReadFile("test/pkg/endpoint/validation_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		"func ValidationMiddleware() endpoint.Middleware",
+		"func (e ValidationError) GRPCStatus() *status.Status",
+		"func (e ValidationError) Unwrap() error",
+		"func (r FooRequest) Validate() error",
+		`if r.A == "" {`,
+		"if utf8.RuneCountInString(r.A) < 3 {",
+		`fooAPattern = regexp.MustCompile("^[a-z]+$")`,
+		"if !fooAPattern.MatchString(r.A) {",
+		"if r.N < 1 {",
+		"if r.N != 1 && r.N != 2 {",
+		"if len(r.Tags) > 2 {",
+		`if r.Todo != nil && (r.Todo.Title == "") {`,
+		`Field:   "todo.title",`,
+	} {
+		if !strings.Contains(src, v) {
+			t.Errorf("generateServiceValidation.Generate() does not contain %q", v)
+		}
+	}
+	if strings.Contains(src, "func (r BarRequest) Validate() error") {
+		t.Error("generateServiceValidation.Generate() should not override a user defined Validate")
+	}
+}
+
+func TestGenerateServiceValidation_GenerateNumberBounds(t *testing.T) {
+	newClientTestFs()
+	for _, v := range []struct {
+		rules string
+		tp    string
+		fails bool
+	}{
+		{"min=0.5", "int", true},
+		{"max=-1", "uint", true},
+		{"max=300", "int8", true},
+		{"oneof=1 2.5", "int", true},
+		{"max=0.5", "float64", false},
+		{"min=1e3", "int", false},
+	} {
+		file, err := parser.NewFileParser().Parse([]byte(`package service
+
+import "context"
+
+type TestService interface {
+	// kit:validate n:"` + v.rules + `"
+	Foo(ctx context.Context, n ` + v.tp + `) (err error)
+}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = newGenerateServiceValidation("test", file.Interfaces[0], file).Generate()
+		if v.fails && (err == nil || !strings.Contains(err.Error(), "does not fit the type `"+v.tp+"`")) {
+			t.Errorf("generateServiceValidation.Generate() error = %v, want an error for %s on %s", err, v.rules, v.tp)
+		}
+		if !v.fails && err != nil {
+			t.Errorf("generateServiceValidation.Generate() error = %v for %s on %s", err, v.rules, v.tp)
+		}
+	}
+}
@@ -35,6 +35,25 @@ type BaseGenerator struct {
 func (b *BaseGenerator) InitPg() {
 	b.code = NewPartialGenerator(b.srcFile.Empty())
 }
+
+// partialSource returns the code generated by the partial generator rendered with
+// the import aliases of the source file, without the package clause and the imports.
+func (b *BaseGenerator) partialSource() (string, error) {
+	src := b.srcFile.GoString()
+	fset := token.NewFileSet()
+	f, err := ps.ParseFile(fset, "", src, ps.ImportsOnly)
+	if err != nil {
+		return "", err
+	}
+	end := f.Name.End()
+	for _, d := range f.Decls {
+		if gd, ok := d.(*ast.GenDecl); ok && gd.Tok == token.IMPORT {
+			end = gd.End()
+		}
+	}
+	return src[fset.Position(end).Offset:], nil
+}
+
 func (b *BaseGenerator) getMissingImports(imp []parser.NamedTypeValue, f *parser.File) ([]parser.NamedTypeValue, error) {
 	n := []parser.NamedTypeValue{}
 	for _, v := range imp {
@@ -87,7 +106,7 @@ func (b *BaseGenerator) GenerateNameBySample(sample string, exclude []parser.Nam
 		if v.Name == name {
 			sn++
 			if sn > len(sample) {
-				sample = string(len(sample) - sn)
+				sample += strconv.Itoa(sn)
 			}
 			name = utils.ToLowerFirstCamelCase(sample)[:sn]
 		}
@@ -141,9 +160,11 @@ func (b *BaseGenerator) AddImportsToFile(imp []parser.NamedTypeValue, src string
 				// Add the new import
 				for _, v := range imp {
 					iSpec := &ast.ImportSpec{
-						Name: &ast.Ident{Name: v.Name},
 						Path: &ast.BasicLit{Value: v.Type},
 					}
+					if v.Name != "" {
+						iSpec.Name = &ast.Ident{Name: v.Name}
+					}
 					dd.Specs = append(dd.Specs, iSpec)
 				}
 			}
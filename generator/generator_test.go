@@ -19,19 +19,54 @@ func setDefaults() {
 	viper.SetDefault("gk_http_client_path_format", path.Join("%s", "client", "http"))
 	viper.SetDefault("gk_grpc_client_path_format", path.Join("%s", "client", "grpc"))
 	viper.SetDefault("gk_client_cmd_path_format", path.Join("%s", "cmd", "client"))
+	viper.SetDefault("gk_ts_client_path_format", path.Join("%s", "client", "typescript"))
+	viper.SetDefault("gk_propagation_path_format", path.Join("%s", "pkg", "propagation"))
+	viper.SetDefault("gk_auth_path_format", path.Join("%s", "pkg", "auth"))
+	viper.SetDefault("gk_tracing_path_format", path.Join("%s", "pkg", "tracing"))
+	viper.SetDefault("gk_health_path_format", path.Join("%s", "pkg", "health"))
+	viper.SetDefault("gk_logging_path_format", path.Join("%s", "pkg", "logging"))
+	viper.SetDefault("gk_migration_path_format", path.Join("%s", "pkg", "migration"))
+	viper.SetDefault("gk_events_path_format", path.Join("%s", "pkg", "events"))
 	viper.SetDefault("gk_grpc_path_format", path.Join("%s", "pkg", "grpc"))
 	viper.SetDefault("gk_grpc_pb_path_format", path.Join("%s", "pkg", "grpc", "pb"))
+	viper.SetDefault("gk_config_path_format", path.Join("%s", "config"))
+	viper.SetDefault("gk_model_path_format", path.Join("%s", "pkg", "model"))
+	viper.SetDefault("gk_db_path_format", path.Join("%s", "pkg", "db"))
+	viper.SetDefault("gk_model_file_name", "base.go")
+	viper.SetDefault("gk_db_postgre_file_name", "db.go")
+	viper.SetDefault("gk_config_postgre_file_name", "config.go")
+	viper.SetDefault("gk_utils_path_format", path.Join("%s", "pkg", "utils"))
 
 	viper.SetDefault("gk_service_file_name", "service.go")
 	viper.SetDefault("gk_service_middleware_file_name", "middleware.go")
+	viper.SetDefault("gk_service_errors_file_name", "errors.go")
 	viper.SetDefault("gk_endpoint_base_file_name", "endpoint_gen.go")
 	viper.SetDefault("gk_endpoint_file_name", "endpoint.go")
 	viper.SetDefault("gk_endpoint_middleware_file_name", "middleware.go")
+	viper.SetDefault("gk_endpoint_validation_file_name", "validation_gen.go")
 	viper.SetDefault("gk_http_file_name", "handler.go")
 	viper.SetDefault("gk_http_base_file_name", "handler_gen.go")
+	viper.SetDefault("gk_http_status_file_name", "status_gen.go")
+	viper.SetDefault("gk_config_file_name", "config.go")
+	viper.SetDefault("gk_status_file_name", "status.yml")
+	viper.SetDefault("gk_utils_status_file_name", "status.go")
 	viper.SetDefault("gk_cmd_base_file_name", "service_gen.go")
 	viper.SetDefault("gk_cmd_svc_file_name", "service.go")
 	viper.SetDefault("gk_http_client_file_name", "http.go")
+	viper.SetDefault("gk_client_cmd_file_name", "main.go")
+	viper.SetDefault("gk_ts_client_file_name", "client.ts")
+	viper.SetDefault("gk_propagation_file_name", "propagation.go")
+	viper.SetDefault("gk_auth_file_name", "auth.go")
+	viper.SetDefault("gk_auth_base_file_name", "auth_gen.go")
+	viper.SetDefault("gk_tracing_file_name", "tracing.go")
+	viper.SetDefault("gk_health_file_name", "health.go")
+	viper.SetDefault("gk_logging_file_name", "logging.go")
+	viper.SetDefault("gk_migration_file_name", "migration.go")
+	viper.SetDefault("gk_cmd_migrate_file_name", "migrate.go")
+	viper.SetDefault("gk_events_file_name", "events.go")
+	viper.SetDefault("gk_events_base_file_name", "publisher_gen.go")
+	viper.SetDefault("gk_propagation_headers", []string{"X-Request-ID", "Authorization"})
+	viper.SetDefault("gk_metrics_buckets", []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10})
 	viper.SetDefault("gk_grpc_client_file_name", "grpc.go")
 	viper.SetDefault("gk_grpc_pb_file_name", "%s.proto")
 	viper.SetDefault("gk_grpc_base_file_name", "handler_gen.go")
@@ -0,0 +1,298 @@
+package generator
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/kujtimiihoxha/kit/parser"
+	"github.com/kujtimiihoxha/kit/utils"
+)
+
+// HTTPAnnotation routes a method of the service interface in the http transport
+// and the http clients, e.x `// kit:http GET /todos/{id}`. The annotation can also
+// set the status of the response and the parameter decoded from the body, e.x
+// `// kit:http POST /todos status:"201" body:"todo"`. The methods that are not
+// annotated are served with POST on the path of their name.
+const HTTPAnnotation = "http"
+
+// httpRouteMethods are the HTTP methods a route can use.
+var httpRouteMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE"}
+
+// httpRoute is the route of a method annotated with HTTPAnnotation.
+type httpRoute struct {
+	method string
+	path   string
+	status int
+	// body is the parameter decoded from the body.
+	body string
+	// jsonBody is true if the whole request is decoded from the body, the routes
+	// without a body parameter that are not GET or DELETE decode it.
+	jsonBody bool
+	// pathParams are the parameters in the path, e.x {id}.
+	pathParams []parser.NamedTypeValue
+	// queryParams are the parameters that are neither in the path nor in the body.
+	queryParams []parser.NamedTypeValue
+}
+
+// newHTTPRoute returns the route of the method, nil if the method is not annotated.
+func newHTTPRoute(m parser.Method) (*httpRoute, error) {
+	an := m.Annotations(HTTPAnnotation)
+	if len(an) == 0 {
+		return nil, nil
+	}
+	fields := strings.Fields(an[0])
+	if len(fields) < 2 || !strings.HasPrefix(fields[1], "/") {
+		return nil, fmt.Errorf("the route of %s must be `kit:http <method> /<path>`", m.Name)
+	}
+	r := &httpRoute{
+		method: strings.ToUpper(fields[0]),
+		path:   fields[1],
+		status: http.StatusOK,
+	}
+	supported := false
+	for _, v := range httpRouteMethods {
+		supported = supported || v == r.method
+	}
+	if !supported {
+		return nil, fmt.Errorf("the route of %s uses `%s`, use %s", m.Name, r.method, strings.Join(httpRouteMethods, ", "))
+	}
+	tag := reflect.StructTag(strings.Join(fields[2:], " "))
+	if v, ok := tag.Lookup("status"); ok {
+		status, err := strconv.Atoi(v)
+		if err != nil || http.StatusText(status) == "" {
+			return nil, fmt.Errorf("the route of %s has the unknown status `%s`", m.Name, v)
+		}
+		r.status = status
+	}
+	params := map[string]parser.NamedTypeValue{}
+	for _, p := range m.Parameters {
+		if p.Type != "context.Context" {
+			params[p.Name] = p
+		}
+	}
+	inPath := map[string]bool{}
+	for _, v := range strings.Split(r.path, "/") {
+		if !strings.HasPrefix(v, "{") || !strings.HasSuffix(v, "}") {
+			continue
+		}
+		p, ok := params[v[1:len(v)-1]]
+		if !ok {
+			return nil, fmt.Errorf("the path parameter %s of %s is not a parameter of the method", v, m.Name)
+		}
+		r.pathParams = append(r.pathParams, p)
+		inPath[p.Name] = true
+	}
+	r.body = tag.Get("body")
+	if r.body != "" {
+		if _, ok := params[r.body]; !ok || inPath[r.body] {
+			return nil, fmt.Errorf("the body %s of %s is not a parameter of the method", r.body, m.Name)
+		}
+		if r.method == "GET" || r.method == "DELETE" {
+			return nil, fmt.Errorf("the %s route of %s can not have a body", r.method, m.Name)
+		}
+	}
+	r.jsonBody = r.body == "" && r.method != "GET" && r.method != "DELETE"
+	for _, p := range m.Parameters {
+		if p.Type == "context.Context" || inPath[p.Name] || p.Name == r.body || r.jsonBody {
+			continue
+		}
+		r.queryParams = append(r.queryParams, p)
+	}
+	return r, nil
+}
+
+// noContent returns true if the response of the route does not have a body.
+func (r *httpRoute) noContent() bool {
+	return r.status == http.StatusNoContent
+}
+
+// statusCode returns the net/http constant of the status of the route.
+func (r *httpRoute) statusCode() jen.Code {
+	switch r.status {
+	case http.StatusOK:
+		return jen.Qual("net/http", "StatusOK")
+	case http.StatusCreated:
+		return jen.Qual("net/http", "StatusCreated")
+	case http.StatusAccepted:
+		return jen.Qual("net/http", "StatusAccepted")
+	case http.StatusNoContent:
+		return jen.Qual("net/http", "StatusNoContent")
+	}
+	return jen.Lit(r.status)
+}
+
+// handle returns the statement that routes the server in the mux, the net/http
+// mux matches the method in the pattern.
+func (r *httpRoute) handle(gorillaMux bool, server jen.Code) *jen.Statement {
+	if !gorillaMux {
+		return jen.Id("m").Dot("Handle").Call(jen.Lit(r.method+" "+r.path), server)
+	}
+	return jen.Id("m").Dot("Methods").Call(
+		jen.Lit(r.method),
+	).Dot("Path").Call(
+		jen.Lit(r.path),
+	).Dot("Handler").Call(
+		jen.Qual("github.com/gorilla/handlers", "CORS").Call(
+			jen.Qual("github.com/gorilla/handlers", "AllowedMethods").Call(
+				jen.Index().String().Values(jen.Lit(r.method)),
+			),
+			jen.Qual("github.com/gorilla/handlers", "AllowedOrigins").Call(
+				jen.Index().String().Values(jen.Lit("*")),
+			),
+		).Call(server),
+	)
+}
+
+// decodeRequest returns the body of the server decoder of the request, the path
+// parameters are read from the mux and the invalid values are InvalidArgument
+// errors of the service.
+func (r *httpRoute) decodeRequest(gorillaMux bool, request jen.Code, serviceImport string) []jen.Code {
+	invalid := func(what string) jen.Code {
+		return jen.Return(jen.Nil(), jen.Qual(serviceImport, "InvalidArgument").Call(
+			jen.Lit("invalid "+what+": %v"),
+			jen.Err(),
+		))
+	}
+	body := []jen.Code{jen.Id("req").Op(":=").Add(request).Values()}
+	if r.jsonBody || r.body != "" {
+		target := jen.Op("&").Id("req")
+		if r.body != "" {
+			target = jen.Op("&").Id("req").Dot(utils.ToCamelCase(r.body))
+		}
+		body = append(body, jen.If(
+			jen.Err().Op(":=").Qual("encoding/json", "NewDecoder").Call(jen.Id("r").Dot("Body")).Dot("Decode").Call(target),
+			jen.Err().Op("!=").Nil(),
+		).Block(invalid("request body")))
+	}
+	if gorillaMux && len(r.pathParams) > 0 {
+		body = append(body, jen.Id("vars").Op(":=").Qual("github.com/gorilla/mux", "Vars").Call(jen.Id("r")))
+	}
+	for _, p := range r.pathParams {
+		value := jen.Id("r").Dot("PathValue").Call(jen.Lit(p.Name))
+		if gorillaMux {
+			value = jen.Id("vars").Index(jen.Lit(p.Name))
+		}
+		body = append(body, decodeHTTPParam(p, value, invalid(p.Name)))
+	}
+	if len(r.queryParams) > 0 {
+		body = append(body, jen.Id("q").Op(":=").Id("r").Dot("URL").Dot("Query").Call())
+	}
+	for _, p := range r.queryParams {
+		body = append(body, jen.If(
+			jen.Id("v").Op(":=").Id("q").Dot("Get").Call(jen.Lit(jsonFieldTag(p))),
+			jen.Id("v").Op("!=").Lit(""),
+		).Block(decodeHTTPParam(p, jen.Id("v"), invalid(jsonFieldTag(p)))))
+	}
+	return append(body, jen.Return(jen.Id("req"), jen.Nil()))
+}
+
+// decodeHTTPParam returns the statement that decodes the string value of the path
+// or the query into the field of the parameter, the types that are not strings,
+// numbers or booleans must implement encoding.TextUnmarshaler (e.x uuid.UUID).
+func decodeHTTPParam(p parser.NamedTypeValue, value jen.Code, invalid jen.Code) jen.Code {
+	field := jen.Id("req").Dot(utils.ToCamelCase(p.Name))
+	var parse jen.Code
+	switch p.Type {
+	case "string":
+		return field.Op("=").Add(value)
+	case "int":
+		parse = jen.Qual("strconv", "Atoi").Call(value)
+	case "int64":
+		parse = jen.Qual("strconv", "ParseInt").Call(value, jen.Lit(10), jen.Lit(64))
+	case "uint64":
+		parse = jen.Qual("strconv", "ParseUint").Call(value, jen.Lit(10), jen.Lit(64))
+	case "float64":
+		parse = jen.Qual("strconv", "ParseFloat").Call(value, jen.Lit(64))
+	case "bool":
+		parse = jen.Qual("strconv", "ParseBool").Call(value)
+	default:
+		return jen.If(
+			jen.Err().Op(":=").Add(field).Dot("UnmarshalText").Call(jen.Index().Byte().Parens(value)),
+			jen.Err().Op("!=").Nil(),
+		).Block(invalid)
+	}
+	return jen.If(
+		jen.List(jen.Id("n"), jen.Err()).Op(":=").Add(parse),
+		jen.Err().Op("!=").Nil(),
+	).Block(invalid).Else().Block(field.Clone().Op("=").Id("n"))
+}
+
+// encodeRequest returns the body of the client encoder of the request, it sets
+// the path parameters and the query and encodes the body as JSON.
+func (r *httpRoute) encodeRequest(request jen.Code) []jen.Code {
+	body := []jen.Code{jen.Id("req").Op(":=").Id("request").Assert(request)}
+	for _, p := range r.pathParams {
+		body = append(body, jen.Id("r").Dot("URL").Dot("Path").Op("=").Qual("strings", "Replace").Call(
+			jen.Id("r").Dot("URL").Dot("Path"),
+			jen.Lit("{"+p.Name+"}"),
+			jen.Qual("net/url", "PathEscape").Call(jen.Qual("fmt", "Sprint").Call(jen.Id("req").Dot(utils.ToCamelCase(p.Name)))),
+			jen.Lit(1),
+		))
+	}
+	if len(r.queryParams) > 0 {
+		body = append(body, jen.Id("q").Op(":=").Id("r").Dot("URL").Dot("Query").Call())
+		for _, p := range r.queryParams {
+			body = append(body, jen.Id("q").Dot("Set").Call(
+				jen.Lit(jsonFieldTag(p)),
+				jen.Qual("fmt", "Sprint").Call(jen.Id("req").Dot(utils.ToCamelCase(p.Name))),
+			))
+		}
+		body = append(body, jen.Id("r").Dot("URL").Dot("RawQuery").Op("=").Id("q").Dot("Encode").Call())
+	}
+	switch {
+	case r.jsonBody:
+		return append(body, jen.Return(jen.Id("encodeHTTPGenericRequest").Call(jen.Id("ctx"), jen.Id("r"), jen.Id("req"))))
+	case r.body != "":
+		return append(body, jen.Return(jen.Id("encodeHTTPGenericRequest").Call(
+			jen.Id("ctx"),
+			jen.Id("r"),
+			jen.Id("req").Dot(utils.ToCamelCase(r.body)),
+		)))
+	}
+	return append(body, jen.Return(jen.Nil()))
+}
+
+// tsPath returns the TypeScript expression of the path, the path parameters are
+// read from the request.
+func (r *httpRoute) tsPath() string {
+	parts := strings.Split(r.path, "/")
+	for i, v := range parts {
+		if !strings.HasPrefix(v, "{") || !strings.HasSuffix(v, "}") {
+			continue
+		}
+		for _, p := range r.pathParams {
+			if p.Name == v[1:len(v)-1] {
+				parts[i] = "${encodeURIComponent(String(request." + jsonFieldTag(p) + "))}"
+			}
+		}
+	}
+	return "`" + strings.Join(parts, "/") + "`"
+}
+
+// tsBody returns the TypeScript expression of the body of the request.
+func (r *httpRoute) tsBody() string {
+	switch {
+	case r.jsonBody:
+		return "request"
+	case r.body != "":
+		return "request." + jsonFieldTag(parser.NamedTypeValue{Name: r.body})
+	}
+	return "undefined"
+}
+
+// tsQuery returns the TypeScript object of the query parameters, empty if the
+// route does not have any.
+func (r *httpRoute) tsQuery() string {
+	if len(r.queryParams) == 0 {
+		return ""
+	}
+	fields := []string{}
+	for _, p := range r.queryParams {
+		fields = append(fields, fmt.Sprintf("%s: request.%s", jsonFieldTag(p), jsonFieldTag(p)))
+	}
+	return "{ " + strings.Join(fields, ", ") + " }"
+}
@@ -0,0 +1,152 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kujtimiihoxha/kit/parser"
+)
+
+const httpRouteTestService = "package service\n" +
+	"import (\n\t\"context\"\n\n\t\"github.com/google/uuid\"\n)\n" +
+	"type TestService interface{\n" +
+	"\t// kit:http POST /todos status:\"201\" body:\"title\"\n" +
+	"\tAddTodo(ctx context.Context, title string, dry bool)(id uuid.UUID, err error)\n" +
+	"\t// kit:http GET /todos/{id}\n" +
+	"\tGetTodo(ctx context.Context, id uuid.UUID, limit int)(title string, err error)\n" +
+	"\t// kit:http DELETE /todos/{id} status:\"204\"\n" +
+	"\tDeleteTodo(ctx context.Context, id uuid.UUID)(err error)\n" +
+	"\tFoo(ctx context.Context, a string)(b int, err error)\n" +
+	"}"
+
+func Test_newHTTPRoute(t *testing.T) {
+	method := func(annotation string) parser.Method {
+		return parser.Method{
+			Name:    "Update",
+			Comment: annotation,
+			Parameters: []parser.NamedTypeValue{
+				parser.NewNameType("ctx", "context.Context"),
+				parser.NewNameType("id", "int"),
+				parser.NewNameType("title", "string"),
+			},
+		}
+	}
+	r, err := newHTTPRoute(method(`kit:http put /todos/{id} status:"202" body:"title"`))
+	if err != nil {
+		t.Fatalf("newHTTPRoute() error = %v", err)
+	}
+	if r.method != "PUT" || r.status != 202 || r.body != "title" || r.jsonBody ||
+		len(r.pathParams) != 1 || len(r.queryParams) != 0 {
+		t.Errorf("newHTTPRoute() = %+v", r)
+	}
+	if r, err = newHTTPRoute(method(`kit:http GET /todos`)); err != nil || len(r.queryParams) != 2 || r.jsonBody {
+		t.Errorf("newHTTPRoute() should read the GET parameters from the query, got %+v, %v", r, err)
+	}
+	if r, err = newHTTPRoute(method("")); r != nil || err != nil {
+		t.Errorf("newHTTPRoute() should return nil for methods that are not annotated, got %+v, %v", r, err)
+	}
+	for _, v := range []string{
+		"kit:http GET",
+		"kit:http HEAD /todos",
+		"kit:http GET todos",
+		`kit:http GET /todos status:"999"`,
+		"kit:http GET /todos/{name}",
+		`kit:http GET /todos body:"title"`,
+		`kit:http PUT /todos/{id} body:"id"`,
+	} {
+		if _, err := newHTTPRoute(method(v)); err == nil {
+			t.Errorf("newHTTPRoute(%q) should return an error", v)
+		}
+	}
+}
+
+func TestGenerateTransport_GenerateHTTPRoutes(t *testing.T) {
+	tests := []struct {
+		name       string
+		gorillaMux bool
+		want       []string
+	}{
+		{
+			name: "Test if the routes are served with the net/http mux",
+			want: []string{
+				`m.Handle("POST /todos", http1.NewServer(endpoints.AddTodoEndpoint`,
+				`if err := req.Id.UnmarshalText([]byte(r.PathValue("id"))); err != nil {`,
+				`return nil, service.InvalidArgument("invalid id: %v", err)`,
+				"if err := json.NewDecoder(r.Body).Decode(&req.Title); err != nil {",
+				`if v := q.Get("dry"); v != "" {`,
+				"if n, err := strconv.Atoi(v); err != nil {",
+				"w.WriteHeader(http.StatusCreated)",
+				"w.WriteHeader(http.StatusNoContent)\n\treturn\n}",
+				`m.Handle("/foo", http1.NewServer(endpoints.FooEndpoint`,
+			},
+		},
+		{
+			name:       "Test if the routes are served with the gorilla mux",
+			gorillaMux: true,
+			want: []string{
+				`m.Methods("GET").Path("/todos/{id}").Handler(`,
+				"vars := mux.Vars(r)",
+				`req.Id.UnmarshalText([]byte(vars["id"]))`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newClientTestFs()
+			f.WriteFile("test/pkg/service/service.go", httpRouteTestService, true)
+			if err := NewGenerateTransport("test", tt.gorillaMux, "http", "", "", nil).Generate(); err != nil {
+				t.Fatalf("GenerateTransport.Generate() error = %v", err)
+			}
+			src, err := f.ReadFile("test/pkg/http/handler.go")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, v := range tt.want {
+				if !strings.Contains(src, v) {
+					t.Errorf("GenerateTransport.Generate() handler does not contain %q, got %s", v, src)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateClient_GenerateHTTPRoutes(t *testing.T) {
+	f := newClientTestFs()
+	f.WriteFile("test/pkg/service/service.go", httpRouteTestService, true)
+	if err := NewGenerateClient("test", "http", "", false, "", 0, false, ClientLanguageGo).Generate(); err != nil {
+		t.Fatalf("GenerateClient.Generate() error = %v", err)
+	}
+	src, err := f.ReadFile("test/client/http/http.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		`http.NewClient("GET", copyURL(u, "/todos/{id}"), encodeGetTodoRequest, decodeGetTodoResponse`,
+		`r.URL.Path = strings.Replace(r.URL.Path, "{id}", url.PathEscape(fmt.Sprint(req.Id)), 1)`,
+		`q.Set("limit", fmt.Sprint(req.Limit))`,
+		"return encodeHTTPGenericRequest(ctx, r, req.Title)",
+		"if r.StatusCode != http1.StatusCreated {",
+		"return endpoint1.DeleteTodoResponse{}, nil",
+		`http.NewClient("POST", copyURL(u, "/foo"), encodeHTTPGenericRequest, decodeFooResponse`,
+	} {
+		if !strings.Contains(src, v) {
+			t.Errorf("GenerateClient.Generate() http client does not contain %q", v)
+		}
+	}
+	if err := NewGenerateClient("test", "http", "", false, "", 0, false, ClientLanguageTypeScript).Generate(); err != nil {
+		t.Fatalf("GenerateClient.Generate() error = %v", err)
+	}
+	if src, err = f.ReadFile("test/client/typescript/client.ts"); err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		"this.call<AddTodoResponse>(`/todos`, request.title, \"POST\", { dry: request.dry })",
+		"this.call<GetTodoResponse>(`/todos/${encodeURIComponent(String(request.id))}`, undefined, \"GET\", { limit: request.limit })",
+		`this.call<FooResponse>("/foo", request)`,
+		"if (response.status === 204) {",
+	} {
+		if !strings.Contains(src, v) {
+			t.Errorf("GenerateClient.Generate() typescript client does not contain %q, got %s", v, src)
+		}
+	}
+}
@@ -98,16 +98,18 @@ type NewModel struct {
 	modelName string
 	destPath  string
 	filePath  string
-	postgreFilePath  string
+	db        *dbDriver
 }
 
-func NewNewModel(name string) Gen {
+// NewNewModel returns a generator of the base model and of the model database,
+// it does not generate anything if the service does not have a database.
+func NewNewModel(name, db string) Gen {
 	gs := &NewModel{
 		name:          name,
 		modelName: utils.ToCamelCase(name),
 		destPath:      fmt.Sprintf(viper.GetString("gk_model_path_format"), utils.ToLowerSnakeCase(name)),
+		db:        newDBDriver(db),
 	}
-	gs.postgreFilePath =  path.Join(gs.destPath, viper.GetString("gk_db_postgres_file_name"))
 	gs.filePath = path.Join(gs.destPath, viper.GetString("gk_model_file_name"))
 	gs.srcFile = jen.NewFilePath(strings.Replace(gs.destPath, "\\", "/", -1))
 
@@ -117,12 +119,21 @@ func NewNewModel(name string) Gen {
 }
 
 func (g *NewModel) Generate() error {
+	if g.db == nil {
+		return nil
+	}
 	g.CreateFolderStructure(g.destPath)
+	// Only Postgres generates the UUID of the new records, the other databases
+	// get it from the BeforeCreate hook.
+	idTag := "primary_key;type:char(36)"
+	if g.db.name == DBPostgres {
+		idTag = "primary_key;type:uuid;default:uuid_generate_v4()"
+	}
 	g.code.Raw().Commentf("%s describes the structure.", g.modelName).Line()
 	g.code.appendStruct("BaseModel",
 		jen.Id("ID").Qual("github.com/google/uuid", "UUID").Tag(map[string]string{
 			"json": "id",
-			"gorm": "primary_key;type:uuid;default:uuid_generate_v4()",
+			"gorm": idTag,
 		}),
 		jen.Id("CreatorID").Qual("github.com/google/uuid", "UUID").Tag(map[string]string{
 			"json": "creator_id",
@@ -142,18 +153,38 @@ func (g *NewModel) Generate() error {
 		}),
 	)
 
-	// get posgrest import
-	postgresImports, err := utils.GetPostgresImportPath(g.name)
+	if g.db.name != DBPostgres {
+		g.code.NewLine()
+		g.code.appendMultilineComment([]string{
+			"BeforeCreate sets the ID of the new records, the database does not generate it.",
+		})
+		g.code.NewLine()
+		g.code.Raw().Func().Params(jen.Id("m").Id("*BaseModel")).Id("BeforeCreate").Params(
+			jen.Id("scope").Id("*").Qual(gormImport, "Scope"),
+		).Error().Block(
+			jen.If(jen.Id("m").Dot("ID").Op("==").Qual(uuidImport, "Nil")).Block(
+				jen.Return(jen.Id("scope").Dot("SetColumn").Call(jen.Lit("ID"), jen.Qual(uuidImport, "New").Call())),
+			),
+			jen.Return(jen.Nil()),
+		).Line()
+	}
+	g.code.NewLine()
+	if err := g.generateAutoMigration(); err != nil {
+		return err
+	}
+	g.fs.WriteFile(path.Join(g.destPath, g.db.name+".go"), g.modelDatabase(), false)
+	return g.fs.WriteFile(g.filePath, g.srcFile.GoString(), false)
+}
+
+// generateAutoMigration generates the migration of the models, it bootstraps
+// the database first, e.x it creates the uuid-ossp extension on Postgres.
+func (g *NewModel) generateAutoMigration() error {
+	dbImport, err := utils.GetDBImportPath(g.name, g.db.name)
 	if err != nil {
 		return err
 	}
-	g.code.appendFunction(
-		"AutoMigration",
-		nil,
-		nil,
-		[]jen.Code{jen.Id("err").Error()},
-		"",
-		jen.List(jen.Id("dbPublic"), jen.Err()).Op(":=").Qual(postgresImports, "GetDatabase").Call(
+	body := []jen.Code{
+		jen.List(jen.Id("dbPublic"), jen.Err()).Op(":=").Qual(dbImport, "GetDatabase").Call(
 			jen.Lit("default"),
 		),
 		jen.If(
@@ -161,25 +192,46 @@ func (g *NewModel) Generate() error {
 				jen.Return(),
 			),
 		),
-		jen.List(jen.Id("_"), jen.Err()).Op("=").Id("dbPublic").Dot("DB").Call().Dot("Exec").Call(
-			jen.Lit("CREATE EXTENSION IF NOT EXISTS \"uuid-ossp\""),
-		),
-		jen.If(
-			jen.Err().Op("!=").Nil().Block(
-				jen.Return(
-					jen.Qual("fmt", "Errorf").Call(
-						jen.Lit("error while creating DB extension 'uuid-ossp': %s"),
-						jen.Id("err"),
+	}
+	if g.db.name == DBPostgres {
+		body = append(
+			body,
+			jen.List(jen.Id("_"), jen.Err()).Op("=").Id("dbPublic").Dot("DB").Call().Dot("Exec").Call(
+				jen.Lit("CREATE EXTENSION IF NOT EXISTS \"uuid-ossp\""),
+			),
+			jen.If(
+				jen.Err().Op("!=").Nil().Block(
+					jen.Return(
+						jen.Qual("fmt", "Errorf").Call(
+							jen.Lit("error while creating DB extension 'uuid-ossp': %s"),
+							jen.Id("err"),
+						),
 					),
 				),
 			),
-		),
+		)
+	}
+	body = append(
+		body,
 		jen.Id("t").Op(":=").Id("dbPublic").Dot("AutoMigrate").Call(),
 		jen.Return(
 			jen.Id("t").Dot("Error"),
 		),
 	)
-		postgresDB := `package model
+	g.code.appendFunction(
+		"AutoMigration",
+		nil,
+		nil,
+		[]jen.Code{jen.Id("err").Error()},
+		"",
+		body...,
+	)
+	return nil
+}
+
+// modelDatabase returns the source of the database of the models.
+func (g *NewModel) modelDatabase() string {
+	return fmt.Sprintf(`package model
 
 import (
 	"context"
@@ -187,37 +239,37 @@ import (
 	"github.com/google/uuid"
 )
 
-func NewBasicPostgresDatabase(db *gorm.DB) PostgresDatabase {
-	return &basicPostgresDatabase{
+func NewBasic%[1]sDatabase(db *gorm.DB) %[1]sDatabase {
+	return &basic%[1]sDatabase{
 		db: db,
 	}
 }
 
-type basicPostgresDatabase struct {
+type basic%[1]sDatabase struct {
 	db *gorm.DB
 }
 
-func (b basicPostgresDatabase) Create(ctx context.Context, req interface{}, creatorID uuid.UUID) (interface{}, error) {
+func (b basic%[1]sDatabase) Create(ctx context.Context, req interface{}, creatorID uuid.UUID) (interface{}, error) {
 	panic("implement me")
 }
 
-func (b basicPostgresDatabase) Update(ctx context.Context, req interface{}, objectID uuid.UUID, updaterID uuid.UUID) (interface{}, error) {
+func (b basic%[1]sDatabase) Update(ctx context.Context, req interface{}, objectID uuid.UUID, updaterID uuid.UUID) (interface{}, error) {
 	panic("implement me")
 }
 
-func (b basicPostgresDatabase) GetOneByID(ctx context.Context, id uuid.UUID) (interface{}, error) {
+func (b basic%[1]sDatabase) GetOneByID(ctx context.Context, id uuid.UUID) (interface{}, error) {
 	panic("implement me")
 }
 
-func (b basicPostgresDatabase) GetAll(ctx context.Context) (interface{}, error) {
+func (b basic%[1]sDatabase) GetAll(ctx context.Context) (interface{}, error) {
 	panic("implement me")
 }
 
-func (b basicPostgresDatabase) Delete(ctx context.Context, id uuid.UUID) error {
+func (b basic%[1]sDatabase) Delete(ctx context.Context, id uuid.UUID) error {
 	panic("implement me")
 }
 
-type PostgresDatabase interface {
+type %[1]sDatabase interface {
 // Add your db method here,
 // e.x: Create(s User)(rs User, err error)",
 	Create(ctx context.Context, req interface{}, creatorID uuid.UUID) (interface{}, error)
@@ -225,9 +277,7 @@ type PostgresDatabase interface {
 	GetOneByID(ctx context.Context, id uuid.UUID) (interface{}, error)
 	GetAll(ctx context.Context) (interface{}, error)
 	Delete(ctx context.Context, id uuid.UUID) error
-}`
-	g.fs.WriteFile(g.postgreFilePath, postgresDB, false)
-	return g.fs.WriteFile(g.filePath, g.srcFile.GoString(), false)
+}`, g.db.title)
 }
 
 // thang.pham: new code
@@ -239,18 +289,24 @@ type NewConfig struct {
 
 	statusFilePath  string
 	configFilePath  string
+	db              *dbDriver
 }
 
-func NewNewConfig(name string) Gen {
+// NewNewConfig returns a generator of the configuration of the service, the
+// database settings depend on the database of the service.
+func NewNewConfig(name, db string) Gen {
 	gs := &NewConfig{
 		name:          name,
 		modelName: utils.ToCamelCase(name),
 		destPath:      fmt.Sprintf(viper.GetString("gk_config_path_format"), utils.ToLowerSnakeCase(name)),
+		db:        newDBDriver(db),
 	}
 
 	gs.statusFilePath = path.Join(gs.destPath, viper.GetString("gk_status_file_name"))
 	gs.configFilePath = path.Join(gs.destPath, viper.GetString("gk_config_file_name"))
 	gs.srcFile = jen.NewFilePath(strings.Replace(gs.destPath, "\\", "/", -1))
+	gs.srcFile.ImportAlias(envImport, "env")
+	gs.srcFile.ImportAlias("gopkg.in/yaml.v3", "yaml")
 
 	gs.InitPg()
 	gs.fs = fs.Get()
@@ -260,44 +316,7 @@ func NewNewConfig(name string) Gen {
 func (g *NewConfig) Generate() error {
 	g.CreateFolderStructure(g.destPath)
 
-	g.code.appendStruct("AppConfig",
-		jen.Id("DBHost").Id("string").Tag(map[string]string{
-			"env": "DB_HOST",
-			"envDefault": "localhost",
-		}),
-		jen.Id("DBPort").Id("string").Tag(map[string]string{
-			"env": "DB_PORT",
-			"envDefault": "5432",
-		}),
-		jen.Id("DBUser").Id("string").Tag(map[string]string{
-			"env": "DB_USER",
-			"envDefault": "postgres",
-		}),
-		jen.Id("DBPass").Id("string").Tag(map[string]string{
-			"env": "DB_PASS",
-			"envDefault": "123456",
-		}),
-		jen.Id("DBName").Id("string").Tag(map[string]string{
-			"env": "DB_NAME",
-			"envDefault": "postgres",
-		}),
-		jen.Id("DBSchema").Id("string").Tag(map[string]string{
-			"env": "DB_SCHEMA",
-			"envDefault": "public",
-		}),
-		jen.Id("LogFormat").Id("string").Tag(map[string]string{
-			"env": "LOG_FORMAT",
-			"envDefault": "text",
-		}),
-		jen.Id("LogLevel").Id("string").Tag(map[string]string{
-			"env": "LOG_LEVEL",
-			"envDefault": "debug",
-		}),
-		jen.Id("LogOutput").Id("string").Tag(map[string]string{
-			"env": "LOG_OUTPUT",
-			"envDefault": "file://logs/metadata.log",
-		}),
-	)
+	g.generateAppConfig()
 
 	statusYml := `gen:
   success:
@@ -352,6 +371,7 @@ type NewUtils struct {
 	constantFilePath  string
 	statusFilePath  string
 	utilFilePath  string
+	db              *dbDriver
 }
 
 func (n NewUtils) Generate() error {
@@ -360,124 +380,47 @@ func (n NewUtils) Generate() error {
 	if err != nil {
 		return err
 	}
-	n.code.raw.Var().Id("conf").Qual(configImport,"AppConfig")
-	n.code.NewLine()
-	n.code.appendFunction("IsErrNotFound",
-	nil,
-	[]jen.Code{jen.Id("err").Error()},
-	nil,
-	"bool",
-		jen.Return(
-			jen.Qual("errors", "Is").Call(
-				jen.Id("err"),
-				jen.Qual("github.com/jinzhu/gorm","ErrRecordNotFound"),
+	if n.db != nil {
+		n.code.appendFunction("IsErrNotFound",
+			nil,
+			[]jen.Code{jen.Id("err").Error()},
+			nil,
+			"bool",
+			jen.Return(
+				jen.Qual("errors", "Is").Call(
+					jen.Id("err"),
+					jen.Qual(gormImport, "ErrRecordNotFound"),
+				),
 			),
-		),
-	)
-	n.code.NewLine()
-	n.code.appendFunction("LoadEnv",
-		nil,
-		nil,
-		nil,
-		"",
-		jen.Id("_").Op("=").Qual("github.com/caarlos0/env/v6","Parse").Call(
-			jen.Id("&conf"),
-		),
-	)
-	n.code.NewLine()
+		)
+		n.code.NewLine()
+	}
 	n.code.appendFunction("GetEnv",
 		nil,
 		nil,
 		[]jen.Code{jen.Qual(configImport,"AppConfig")},
 		"",
 		jen.Return(
-			jen.Id("conf"),
+			jen.Qual(configImport,"Get").Call(),
 		),
 	)
 	// write status.yml
 	constant := `package utils`
 	n.fs.WriteFile(n.constantFilePath, constant, true)
 
-	status := `
-package utils
-
-import (
-
-	"github.com/praslar/common/response"
-	"github.com/sirupsen/logrus"
-
-	"gopkg.in/yaml.v3"
-	"os"
-	"sync"
-)
-
-type (
-	//Status format from status pkg
-	Status = response.ResponseStatus
-
-	GenStatus struct {
-		Success      Status
-		BadRequest   Status 
-		Unauthorized Status
-		Internal     Status
-		Database     Status
-	}
-
-	statuses struct {
-		Gen              GenStatus
-	}
-)
-
-var (
-	all  *statuses
-	once sync.Once
-)
-
-// Init load statuses from the given config file.
-// Init panics if cannot access or error while parsing the config file.
-func Init(conf string) {
-	once.Do(func() {
-		f, err := os.Open(conf)
-		if err != nil {
-			logrus.Errorf("Fail to open status file, %v", err)
-			panic(err)
-		}
-		all = &statuses{}
-		if err := yaml.NewDecoder(f).Decode(all); err != nil {
-			logrus.Errorf("Fail to parse status file data to statuses struct, %v", err)
-			panic(err)
-		}
-	})
-}
-
-// all return all registered statuses.
-// all will load statuses from configs/Status.yml if the statuses has not initialized yet.
-func load(err string) *statuses {
-	conf := os.Getenv("STATUS_PATH")
-	if conf == "" {
-		conf = "conf/status.yml"
-	}
-	Init(conf)
-	if err != "" {
-		all.Gen.BadRequest.XMessage = err
+	if err = newGenerateStatusCatalog(n.name).Generate(); err != nil {
+		return err
 	}
-	return all
-}
-
-func Gen(err string) GenStatus {
-	return load(err).Gen
-}
-`
-	n.fs.WriteFile(n.statusFilePath, status, true)
 	// write config.go
 	return n.fs.WriteFile(n.utilFilePath, n.srcFile.GoString(), false)
 }
 
-func NewNewUtils(name string) Gen {
+func NewNewUtils(name, db string) Gen {
 	gs := &NewUtils{
 		name:          name,
 		modelName: 	   utils.ToCamelCase(name),
 		destPath:      fmt.Sprintf(viper.GetString("gk_utils_path_format"), utils.ToLowerSnakeCase(name)),
+		db:        newDBDriver(db),
 	}
 
 	gs.constantFilePath = path.Join(gs.destPath, viper.GetString("gk_utils_constant_file_name"))
@@ -490,23 +433,37 @@ func NewNewUtils(name string) Gen {
 	return gs
 }
 
-type NewPostgreDatabase struct {
+// NewDatabase implements Gen and is used to create the connection registry of
+// the database of a new service.
+type NewDatabase struct {
 	BaseGenerator
 
 	name      string
 	modelName string
 	destPath  string
 
-	postgreFilePath  string
-	configFilePath  string
+	registryFilePath string
+	configFilePath   string
+	db               *dbDriver
 }
 
-func (n NewPostgreDatabase) Generate() error {
+func (n NewDatabase) Generate() error {
+	if n.db == nil {
+		return nil
+	}
 	n.CreateFolderStructure(n.destPath)
 	configImport, err := utils.GetConfigImportPath(n.name)
 	if err != nil {
 		return err
 	}
+	info := jen.Dict{}
+	for _, f := range n.db.configFields(n.name) {
+		field := f.name
+		if field == "Schema" {
+			field = "SearchPath"
+		}
+		info[jen.Id(field)] = jen.Id("conf").Dot(f.name)
+	}
 	n.code.raw.Var().Id("Muxtex").Id("*").Qual("sync","RWMutex")
 	n.code.NewLine()
 	n.code.raw.Const().Id("DefaultConnName").Op("=").Lit("default")
@@ -516,16 +473,8 @@ func (n NewPostgreDatabase) Generate() error {
 		nil,
 		[]jen.Code{jen.Id("dbInfo").Id("DBInfo") ,jen.Id("err").Error()},
 		"",
-		jen.Id("conf").Op(":=").Qual(configImport,"AppConfig").Block(),
-		jen.Id("_").Op("=").Qual("github.com/caarlos0/env/v6","Parse").Call(jen.Id("&conf")),
-		jen.Id("dbInfo").Op("=").Id("DBInfo").Values(
-				jen.Id("Host").Op(":").Id("conf").Dot("DBHost"),
-				jen.Id("Port").Op(":").Id("conf").Dot("DBPort"),
-				jen.Id("Name").Op(":").Id("conf").Dot("DBName"),
-				jen.Id("User").Op(":").Id("conf").Dot("DBUser"),
-				jen.Id("Pass").Op(":").Id("conf").Dot("DBPass"),
-				jen.Id("SearchPath").Op(":").Id("conf").Dot("DBSchema"),
-		),
+		jen.Id("conf").Op(":=").Qual(configImport,"Get").Call().Dot("DB"),
+		jen.Id("dbInfo").Op("=").Id("DBInfo").Values(info),
 		jen.Return(),
 	)
 	n.code.NewLine()
@@ -547,7 +496,7 @@ func (n NewPostgreDatabase) Generate() error {
 			jen.Var().Id("dbInfo").Id("DBInfo"),
 			jen.List(jen.Id("dbInfo"),jen.Id("err")).Op("=").Id("GetDBInfo").Call(),
 			jen.If(jen.Id("err").Op("==").Nil()).Block(
-				jen.Id("err").Op("=").Id("RegisterDataBase").Call(jen.Id("customerSchema"),jen.Lit("postgres"),jen.Id("CreateDBConnectionString").Call(jen.Id("dbInfo"))),
+				jen.Id("err").Op("=").Id("RegisterDataBase").Call(jen.Id("customerSchema"), jen.Id("Dialect"), jen.Id("DSN").Call(jen.Id("dbInfo"))),
 				jen.If(jen.Id("err").Op("==").Nil()).Block(
 					jen.List(jen.Id("db"),jen.Id("err")).Op("=").Id("GetDB").Call(jen.Id("customerSchema")),
 				),
@@ -556,186 +505,24 @@ func (n NewPostgreDatabase) Generate() error {
 		jen.Return(),
 	)
 
-	db := `package postgres
-
-import (
-	"fmt"
-	"sync"
-
-	"github.com/jinzhu/gorm"
-	_ "github.com/jinzhu/gorm/dialects/postgres"
-)
-
-type DBInfo struct {
-	Host       string
-	Port       string
-	Name       string
-	User       string
-	Pass       string
-	SearchPath string
-}
-
-var (
-	dataBaseCache = &_dbCache{cache: make(map[string]*alias)}
-)
-
-type DB struct {
-	*sync.RWMutex
-	DB *gorm.DB
-}
-
-type alias struct {
-	Name         string
-	MaxIdleConns int
-	MaxOpenConns int
-	DB           *DB
-}
-
-type _dbCache struct {
-	mux   sync.RWMutex
-	cache map[string]*alias
-}
-
-func (ac *_dbCache) add(name string, al *alias) (added bool) {
-	ac.mux.Lock()
-	defer ac.mux.Unlock()
-	if _, ok := ac.cache[name]; !ok {
-		ac.cache[name] = al
-		added = true
-	}
-	return
-}
-
-// get db alias if cached.
-func (ac *_dbCache) get(name string) (al *alias, ok bool) {
-	ac.mux.RLock()
-	defer ac.mux.RUnlock()
-	al, ok = ac.cache[name]
-	return
-}
-
-// get default alias.
-func (ac *_dbCache) getDefault() (al *alias) {
-	al, _ = ac.get("default")
-	return
-}
-
-func GetDB(aliasNames ...string) (*gorm.DB, error) {
-	var name string
-	if len(aliasNames) > 0 {
-		name = aliasNames[0]
-	} else {
-		name = "default"
-	}
-	al, ok := dataBaseCache.get(name)
-	if ok {
-		return al.DB.DB.Debug(), nil
-	}
-	return &gorm.DB{}, fmt.Errorf("DataBase of alias name %s not found", name)
-}
-
-func RegisterDataBase(aliasName, driverName, dataSource string, params ...int) error {
-	var (
-		err error
-		db  *gorm.DB
-		al  *alias
-	)
-
-	db, err = gorm.Open(driverName, dataSource)
-	if err != nil {
-		err = fmt.Errorf("register db %s, %s", aliasName, err.Error())
-		goto end
-	}
-
-	al, err = addAliasWthDB(aliasName, driverName, db)
-	if err != nil {
-		goto end
-	}
-
-	for i, v := range params {
-		switch i {
-		case 0:
-			SetMaxIdleConns(al.Name, v)
-		case 1:
-			SetMaxOpenConns(al.Name, v)
-		}
-	}
-
-end:
-	if err != nil {
-		if db != nil {
-			//_ = db.Close()
-		}
-	}
-
-	return err
-}
-
-func addAliasWthDB(aliasName, driverName string, db *gorm.DB) (*alias, error) {
-	al := new(alias)
-	al.Name = aliasName
-	al.DB = &DB{
-		RWMutex: new(sync.RWMutex),
-		DB:      db,
-	}
-
-	err := db.DB().Ping()
-	if err != nil {
-		return nil, fmt.Errorf("register db Ping %s, %s", aliasName, err.Error())
-	}
-
-	if !dataBaseCache.add(aliasName, al) {
-		return nil, fmt.Errorf("DataBase alias name %s already registered, cannot reuse", aliasName)
-	}
-
-	return al, nil
-}
-
-// get table alias.
-func getDbAlias(name string) *alias {
-	if al, ok := dataBaseCache.get(name); ok {
-		return al
-	}
-	panic(fmt.Errorf("unknown DataBase alias name %s", name))
-}
-
-// SetMaxIdleConns ChangeNumber the max idle conns for *sql.DB, use specify db alias name
-func SetMaxIdleConns(aliasName string, maxIdleConns int) {
-	al := getDbAlias(aliasName)
-	al.MaxIdleConns = maxIdleConns
-	al.DB.DB.DB().SetMaxIdleConns(maxIdleConns)
-}
-
-// SetMaxOpenConns ChangeNumber the max open conns for *sql.DB, use specify db alias name
-func SetMaxOpenConns(aliasName string, maxOpenConns int) {
-	al := getDbAlias(aliasName)
-	al.MaxOpenConns = maxOpenConns
-	al.DB.DB.DB().SetMaxOpenConns(maxOpenConns)
-}
-
-func CreateDBConnectionString(info DBInfo) (dbConnString string) {
-	host := info.Host
-	port := info.Port
-	database := info.Name
-	user := info.User
-	pass := info.Pass
-	searchPath := info.SearchPath
-	dbConnString = fmt.Sprintf("host=%s port=%s user=%s dbname=%s sslmode=disable password=%s search_path=%s", host, port, user, database, pass, searchPath)
-	return
-}
-`
-	n.fs.WriteFile(n.postgreFilePath, db, true)
+	n.fs.WriteFile(n.registryFilePath, n.db.registry(), true)
 	// write config.go
 	return n.fs.WriteFile(n.configFilePath, n.srcFile.GoString(), false)
 }
 
-func NewNewPostgreDatabase(name string) Gen {
-	gs := &NewPostgreDatabase{
+// NewNewDatabase returns a generator of the connection registry of the database,
+// it does not generate anything if the service does not have a database.
+func NewNewDatabase(name, db string) Gen {
+	gs := &NewDatabase{
 		name:          name,
 		modelName: 	   utils.ToCamelCase(name),
-		destPath:      fmt.Sprintf(viper.GetString("gk_postgres_path_format"), utils.ToLowerSnakeCase(name)),
+		db:        newDBDriver(db),
+	}
+	if gs.db == nil {
+		return gs
 	}
-	gs.postgreFilePath = path.Join(gs.destPath, viper.GetString("gk_db_postgre_file_name"))
+	gs.destPath = path.Join(fmt.Sprintf(viper.GetString("gk_db_path_format"), utils.ToLowerSnakeCase(name)), gs.db.name)
+	gs.registryFilePath = path.Join(gs.destPath, viper.GetString("gk_db_postgre_file_name"))
 	gs.configFilePath = path.Join(gs.destPath, viper.GetString("gk_config_postgre_file_name"))
 	gs.srcFile = jen.NewFilePath(strings.Replace(gs.destPath, "\\", "/", -1))
 
@@ -7,6 +7,7 @@ import (
 	"go/format"
 	"go/parser"
 	"go/token"
+	"strconv"
 	"strings"
 
 	"github.com/kujtimiihoxha/kit/utils"
@@ -90,6 +91,15 @@ func (fp *FileParser) parseType(ds []ast.Spec, f *File) {
 		case *ast.StructType:
 			st := tsp.Type.(*ast.StructType)
 			str := NewStruct(tsp.Name.Name, fp.parseFieldListAsNamedTypes(st.Fields))
+			i := 0
+			for _, v := range st.Fields.List {
+				n := len(v.Names)
+				if n == 0 {
+					str.Vars[i].Embedded = true
+					n = 1
+				}
+				i += n
+			}
 			f.Structures = append(f.Structures, str)
 		case *ast.FuncType:
 			st := tsp.Type.(*ast.FuncType)
@@ -192,7 +202,9 @@ func (fp *FileParser) parseFieldListAsNamedTypes(list *ast.FieldList) []NamedTyp
 			}
 			if len(names) == 0 {
 				// Anonymous named type, give it a default name
-				if strings.HasPrefix(typ, "[]") {
+				if elem := ChanElemType(typ); elem != "" {
+					names = append(names, utils.ToLowerFirstCamelCase(elem[:1]+fmt.Sprintf("%d", i)))
+				} else if strings.HasPrefix(typ, "[]") {
 					names = append(names, utils.ToLowerFirstCamelCase(typ[2:3]+fmt.Sprintf("%d", i)))
 				} else if strings.HasPrefix(typ, "*") {
 					names = append(names, utils.ToLowerFirstCamelCase(typ[1:2]+fmt.Sprintf("%d", i)))
@@ -200,8 +212,13 @@ func (fp *FileParser) parseFieldListAsNamedTypes(list *ast.FieldList) []NamedTyp
 					names = append(names, utils.ToLowerFirstCamelCase(typ[:1]+fmt.Sprintf("%d", i)))
 				}
 			}
+			tag := ""
+			if p.Tag != nil {
+				tag, _ = strconv.Unquote(p.Tag.Value)
+			}
 			for _, name := range names {
 				namedType := NewNameType(name, typ)
+				namedType.Tag = tag
 				logrus.Debug(fmt.Sprintf("NamedType %+v", namedType))
 				ntv = append(ntv, namedType)
 			}
@@ -233,6 +250,16 @@ func (fp *FileParser) getTypeFromExp(e ast.Expr) string {
 	case *ast.Ellipsis:
 		t := fp.getTypeFromExp(k.Elt)
 		tp = "..." + t
+	case *ast.ChanType:
+		t := fp.getTypeFromExp(k.Value)
+		switch k.Dir {
+		case ast.RECV:
+			tp = "<-chan " + t
+		case ast.SEND:
+			tp = "chan<- " + t
+		default:
+			tp = "chan " + t
+		}
 	default:
 		logrus.Info("Type Expresion not supported")
 		return ""
@@ -248,6 +275,9 @@ func (fp *FileParser) parseFieldListAsMethods(list *ast.FieldList) []Method {
 				m := Method{
 					Name: p.Names[0].Name,
 				}
+				if p.Doc != nil {
+					m.Comment = p.Doc.Text()
+				}
 				m.Parameters = fp.parseFieldListAsNamedTypes(t.Params)
 				m.Results = fp.parseFieldListAsNamedTypes(t.Results)
 				mth = append(mth, m)
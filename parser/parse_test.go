@@ -136,3 +136,112 @@ func TestFileParser_ParseMiddlewareFuncType(t *testing.T) {
 		})
 	})
 }
+
+func TestFileParser_ParseChannelTypes(t *testing.T) {
+	fp := NewFileParser()
+	f, err := fp.Parse([]byte(
+		`package parser
+
+import "context"
+type MyService interface{
+	Chat(ctx context.Context, in <-chan string) (<-chan string, error)
+	Push(ctx context.Context, out chan<- []byte, c chan int) error
+}`))
+	Convey("Test if parser parses file without errors", t, func() {
+		So(err, ShouldBeNil)
+		Convey("Test if channel parameters and results keep their direction", func() {
+			chat := f.Interfaces[0].Methods[0]
+			So(chat.Parameters[1].Type, ShouldEqual, "<-chan string")
+			So(chat.Results[0].Name, ShouldEqual, "s0")
+			So(chat.Results[0].Type, ShouldEqual, "<-chan string")
+			push := f.Interfaces[0].Methods[1]
+			So(push.Parameters[1].Type, ShouldEqual, "chan<- []byte")
+			So(push.Parameters[2].Type, ShouldEqual, "chan int")
+		})
+		Convey("Test if the channel element type is found", func() {
+			So(ChanElemType("<-chan string"), ShouldEqual, "string")
+			So(ChanElemType("chan<- []byte"), ShouldEqual, "[]byte")
+			So(ChanElemType("chan int"), ShouldEqual, "int")
+			So(ChanElemType("int"), ShouldEqual, "")
+		})
+	})
+}
+
+func TestFileParser_ParseStructTags(t *testing.T) {
+	fp := NewFileParser()
+	f, err := fp.Parse([]byte(
+		"package parser\n\ntype Todo struct{\n\tTitle string `json:\"title\" validate:\"required\"`\n\tDone bool\n}"))
+	Convey("Test if parser parses file without errors", t, func() {
+		So(err, ShouldBeNil)
+		Convey("Test if the struct field tags are kept", func() {
+			vars := f.Structures[0].Vars
+			So(vars[0].Tag, ShouldEqual, `json:"title" validate:"required"`)
+			So(vars[0].TagValue("json"), ShouldEqual, "title")
+			So(vars[0].TagValue("validate"), ShouldEqual, "required")
+			So(vars[1].Tag, ShouldEqual, "")
+		})
+	})
+}
+
+func TestFileParser_ParseEmbeddedFields(t *testing.T) {
+	fp := NewFileParser()
+	f, err := fp.Parse([]byte("package parser\n\ntype Todo struct{\n\tA, B string\n\tBaseModel\n\t*Other\n}"))
+	Convey("Test if parser parses file without errors", t, func() {
+		So(err, ShouldBeNil)
+		Convey("Test if the embedded fields are marked", func() {
+			vars := f.Structures[0].Vars
+			So(len(vars), ShouldEqual, 4)
+			So(vars[1].Embedded, ShouldBeFalse)
+			So(vars[2].Type, ShouldEqual, "BaseModel")
+			So(vars[2].Embedded, ShouldBeTrue)
+			So(vars[3].Embedded, ShouldBeTrue)
+		})
+	})
+}
+
+func TestFileParser_ParseMethodAnnotations(t *testing.T) {
+	fp := NewFileParser()
+	f, err := fp.Parse([]byte(
+		`package parser
+
+import "context"
+type MyService interface{
+	// Health reports if the service is up.
+	// kit:noauth
+	Health(ctx context.Context) error
+	Foo(ctx context.Context) error
+}`))
+	Convey("Test if parser parses file without errors", t, func() {
+		So(err, ShouldBeNil)
+		Convey("Test if the method annotations are found", func() {
+			So(f.Interfaces[0].Methods[0].HasAnnotation("noauth"), ShouldBeTrue)
+			So(f.Interfaces[0].Methods[0].HasAnnotation("auth"), ShouldBeFalse)
+			So(f.Interfaces[0].Methods[1].HasAnnotation("noauth"), ShouldBeFalse)
+		})
+	})
+}
+
+func TestFileParser_ParseMethodParamTags(t *testing.T) {
+	fp := NewFileParser()
+	f, err := fp.Parse([]byte(
+		`package parser
+
+import "context"
+type MyService interface{
+	// Create creates a todo.
+	// kit:validate title:"required,min=3" status:"oneof=open done"
+	// kit:validate tags:"max=5"
+	Create(ctx context.Context, title, status string, tags []string) error
+}`))
+	Convey("Test if parser parses file without errors", t, func() {
+		So(err, ShouldBeNil)
+		Convey("Test if the parameter tags are found", func() {
+			m := f.Interfaces[0].Methods[0]
+			So(m.Annotations("validate"), ShouldHaveLength, 2)
+			So(m.ParamTag("validate", "title"), ShouldEqual, "required,min=3")
+			So(m.ParamTag("validate", "status"), ShouldEqual, "oneof=open done")
+			So(m.ParamTag("validate", "tags"), ShouldEqual, "max=5")
+			So(m.ParamTag("validate", "ctx"), ShouldEqual, "")
+		})
+	})
+}
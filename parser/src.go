@@ -1,5 +1,10 @@
 package parser
 
+import (
+	"reflect"
+	"strings"
+)
+
 // File represents a go source file.
 type File struct {
 	Comment string
@@ -45,11 +50,69 @@ type Method struct {
 	Results    []NamedTypeValue
 }
 
+// HasAnnotation returns true if the method comment has the `kit:<name>`
+// annotation (e.x `// kit:noauth`).
+func (m Method) HasAnnotation(name string) bool {
+	for _, v := range strings.Fields(m.Comment) {
+		if v == "kit:"+name {
+			return true
+		}
+	}
+	return false
+}
+
+// Annotations returns the text that follows each `kit:<name>` annotation of the
+// method comment, one entry per comment line (e.x `// kit:validate s:"required"`
+// returns `s:"required"`).
+func (m Method) Annotations(name string) []string {
+	an := []string{}
+	for _, v := range strings.Split(m.Comment, "\n") {
+		v = strings.TrimSpace(v)
+		if v == "kit:"+name || strings.HasPrefix(v, "kit:"+name+" ") {
+			an = append(an, strings.TrimSpace(strings.TrimPrefix(v, "kit:"+name)))
+		}
+	}
+	return an
+}
+
+// ParamTag returns the tag of the parameter from the `kit:<name>` annotations, the
+// annotations are written like struct tags keyed by the parameter name because
+// parameters can not have tags (e.x `// kit:validate s:"required,min=3"`).
+func (m Method) ParamTag(name, param string) string {
+	for _, v := range m.Annotations(name) {
+		if t, ok := reflect.StructTag(v).Lookup(param); ok {
+			return t
+		}
+	}
+	return ""
+}
+
 // NamedTypeValue  is used to store any type of name type = value ( e.x  var a = 2)
 type NamedTypeValue struct {
 	Name  string
 	Type  string
 	Value string
+	// Tag is the tag of a struct field without the quotes (e.x `json:"name"`).
+	Tag string
+	// Embedded is true if the struct field is embedded, its name is made up.
+	Embedded bool
+}
+
+// TagValue returns the value of the `key` struct tag (e.x `json`) or an empty
+// string if the tag is not set.
+func (n NamedTypeValue) TagValue(key string) string {
+	return reflect.StructTag(n.Tag).Get(key)
+}
+
+// ChanElemType returns the element type of a channel type (e.x `<-chan string` => `string`)
+// or an empty string if the given type is not a channel.
+func ChanElemType(tp string) string {
+	for _, prefix := range []string{"<-chan ", "chan<- ", "chan "} {
+		if strings.HasPrefix(tp, prefix) {
+			return tp[len(prefix):]
+		}
+	}
+	return ""
 }
 
 // NewNameType create a NamedTypeValue without a value.
@@ -87,6 +87,56 @@ func GetHTTPTransportImportPath(name string) (string, error) {
 	return getImportPath(name, "gk_http_path_format")
 }
 
+// GetHTTPClientImportPath returns the import path of the service http client.
+func GetHTTPClientImportPath(name string) (string, error) {
+	return getImportPath(name, "gk_http_client_path_format")
+}
+
+// GetGRPCClientImportPath returns the import path of the service grpc client.
+func GetGRPCClientImportPath(name string) (string, error) {
+	return getImportPath(name, "gk_grpc_client_path_format")
+}
+
+// GetPropagationImportPath returns the import path of the service propagation package.
+func GetPropagationImportPath(name string) (string, error) {
+	return getImportPath(name, "gk_propagation_path_format")
+}
+
+// GetAuthImportPath returns the import path of the service auth package.
+func GetAuthImportPath(name string) (string, error) {
+	return getImportPath(name, "gk_auth_path_format")
+}
+
+// GetTracingImportPath returns the import path of the service tracing package.
+func GetTracingImportPath(name string) (string, error) {
+	return getImportPath(name, "gk_tracing_path_format")
+}
+
+// GetHealthImportPath returns the import path of the service health package.
+func GetHealthImportPath(name string) (string, error) {
+	return getImportPath(name, "gk_health_path_format")
+}
+
+// GetLoggingImportPath returns the import path of the service logging package.
+func GetLoggingImportPath(name string) (string, error) {
+	return getImportPath(name, "gk_logging_path_format")
+}
+
+// GetMigrationImportPath returns the import path of the service migration package.
+func GetMigrationImportPath(name string) (string, error) {
+	return getImportPath(name, "gk_migration_path_format")
+}
+
+// GetEventsImportPath returns the import path of the service events package.
+func GetEventsImportPath(name string) (string, error) {
+	return getImportPath(name, "gk_events_path_format")
+}
+
+// GetUtilsImportPath returns the import path of the service utils package.
+func GetUtilsImportPath(name string) (string, error) {
+	return getImportPath(name, "gk_utils_path_format")
+}
+
 // GetDockerFileProjectPath returns the path of the project.
 func GetDockerFileProjectPath() (string, error) {
 	gosrc := GetGOPATH() + "/src/"
@@ -217,9 +267,13 @@ func IsExist(path string) bool {
 
 
 // thang.pham: new code
-// GetEndpointImportPath returns the import path of the service endpoints.
-func GetPostgresImportPath(name string) (string, error) {
-	return getImportPath(name, "gk_postgres_path_format")
+// GetDBImportPath returns the import path of the connection registry of the database.
+func GetDBImportPath(name, db string) (string, error) {
+	dbImport, err := getImportPath(name, "gk_db_path_format")
+	if err != nil {
+		return "", err
+	}
+	return dbImport + "/" + db, nil
 }
 
 // get config import path